@@ -2,121 +2,444 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/gin-gonic/gin"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
 	"mysvelteapp/server_new/internal/docs"
 	authapi "mysvelteapp/server_new/internal/modules/auth/api"
 	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authemail "mysvelteapp/server_new/internal/modules/auth/infra/email"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+	authmetrics "mysvelteapp/server_new/internal/modules/auth/infra/metrics"
 	authpersistence "mysvelteapp/server_new/internal/modules/auth/infra/persistence"
 	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
 	authtoken "mysvelteapp/server_new/internal/modules/auth/infra/token"
 	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
 	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemonoffline "mysvelteapp/server_new/internal/modules/pokemon/infra/offline"
+	pokemonpersistence "mysvelteapp/server_new/internal/modules/pokemon/infra/persistence"
 	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
 	"mysvelteapp/server_new/internal/platform/config"
+	platformemail "mysvelteapp/server_new/internal/platform/email"
+	"mysvelteapp/server_new/internal/platform/health"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpclient"
 	"mysvelteapp/server_new/internal/platform/httpserver"
+	"mysvelteapp/server_new/internal/platform/idempotency"
+	"mysvelteapp/server_new/internal/platform/kvstore"
+	"mysvelteapp/server_new/internal/platform/lifecycle"
 	"mysvelteapp/server_new/internal/platform/logging"
+	"mysvelteapp/server_new/internal/platform/metrics"
 	"mysvelteapp/server_new/internal/platform/persistence"
+	"mysvelteapp/server_new/internal/platform/ratelimit"
 	"mysvelteapp/server_new/internal/platform/tracing"
+	"mysvelteapp/server_new/internal/platform/workers"
 )
 
+const (
+	// shutdownTimeout bounds how long shutdown hooks may take.
+	shutdownTimeout = 30 * time.Second
+	// loginAttemptCleanupInterval is how often expired lockout records are deleted.
+	loginAttemptCleanupInterval = 10 * time.Minute
+	// kvCleanupInterval is how often expired key/value entries are deleted.
+	kvCleanupInterval = 10 * time.Minute
+)
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run starts the server and blocks until it stops, returning the process exit
+// code. Exiting from main instead of via log.Fatal lets deferred cleanup run.
+func run(args []string) int {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	migrateOnly := flags.Bool("migrate-only", false, "apply database migrations, reconcile legacy rows and exit")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("failed to load config: %v", err)
+		logging.NewDefaultLogger().Error("failed to load config", "error", err)
+		return 1
+	}
+
+	logConfig := logging.DefaultConfig()
+	logConfig.Output = cfg.LogOutput
+	logConfig.MaxSizeMB = cfg.LogMaxSizeMB
+	logConfig.MaxBackups = cfg.LogMaxBackups
+	logConfig.MaxAgeDays = cfg.LogMaxAgeDays
+	logConfig.TimeFormat = cfg.LogTimeFormat
+	logConfig.UTC = cfg.LogUTC
+	logger, logCloser := logging.NewLogger(logConfig)
+	defer func() {
+		if err := logCloser.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close log output: %v\n", err)
+		}
+	}()
+	cfg.LogEffective(logger)
+	for _, warning := range cfg.Warnings() {
+		logger.Warn(warning, "environment", cfg.Environment)
 	}
 
-	logger := logging.NewDefaultLogger()
+	// Hooks run in reverse registration order when the server stops.
+	shutdown := lifecycle.NewRegistry()
+	// Flushes whatever was registered when run returns early; hooks only run
+	// once, so this is a no-op after the graceful shutdown below.
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = shutdown.Shutdown(ctx)
+	}()
 
 	// Initialize OpenTelemetry tracing
 	tracingProvider, err := tracing.New(cfg.ServiceName, cfg.ServiceVersion, logger)
 	if err != nil {
-		log.Fatalf("failed to initialize tracing: %v", err)
+		logger.Error("failed to initialize tracing", "error", err)
+		return 1
 	}
-	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := tracingProvider.Shutdown(ctx); err != nil {
-			log.Printf("failed to shutdown tracing provider: %v", err)
-		}
-	}()
+	shutdown.Register("tracing", tracingProvider.Shutdown)
+
+	metricsRegistry := metrics.NewRegistry()
+	metricsProvider, err := metrics.New(cfg.MetricsExporter, metricsRegistry, logger)
+	if err != nil {
+		logger.Error("failed to initialize metrics", "error", err)
+		return 1
+	}
+	shutdown.Register("metrics", metricsProvider.Shutdown)
 
-	docs.SwaggerInfo.BasePath = "/"
+	docs.SwaggerInfo.BasePath = httpserver.APIVersionPrefix
 	docs.SwaggerInfo.Title = "MySvelteApp Server API"
 	docs.SwaggerInfo.Description = "This is the Go implementation of the MySvelteApp backend."
 
-	engine := httpserver.New(logger, cfg.ServiceName)
+	clientIPResolver, err := httpapi.NewClientIPResolver(cfg.TrustedProxies, cfg.TrustedProxyCount)
+	if err != nil {
+		logger.Error("failed to configure trusted proxies", "error", err)
+		return 1
+	}
+	serverOptions := []httpserver.Option{httpserver.WithClientIPResolver(clientIPResolver)}
+	if cfg.ForceHTTPS {
+		serverOptions = append(serverOptions, httpserver.WithForceHTTPS())
+	}
+	if cfg.ErrorFormat == config.ErrorFormatProblem {
+		serverOptions = append(serverOptions, httpserver.WithProblemDetails())
+	}
+	if cfg.StrictJSON {
+		serverOptions = append(serverOptions, httpserver.WithStrictJSON())
+	}
+	if cfg.IndentsJSON() {
+		serverOptions = append(serverOptions, httpserver.WithIndentedJSON())
+	}
+	serverOptions = append(serverOptions,
+		httpserver.WithAccessLogSampling(cfg.AccessLogSampleRate),
+		httpserver.WithAccessLogSkipPaths(cfg.AccessLogSkipPaths...),
+	)
+	if cfg.SwaggerEnabled {
+		serverOptions = append(serverOptions, httpserver.WithSwaggerUI())
+	}
+	if cfg.MetricsExporter == config.MetricsExporterPrometheus {
+		// The prometheus bridge only exports counters; otelgin's request
+		// histograms would be rejected at startup and dropped anyway.
+		serverOptions = append(serverOptions, httpserver.WithMeterProvider(metricnoop.NewMeterProvider()))
+	}
+	if cfg.CSRFEnabled {
+		secureCookie := cfg.Environment == config.EnvironmentProduction || cfg.Environment == config.EnvironmentStaging
+		serverOptions = append(serverOptions, httpserver.WithCSRFProtection(secureCookie))
+	}
+	engine := httpserver.New(logger, cfg.ServiceName, serverOptions...)
 
-	appDB, err := persistence.NewAppDB(sqlite.Open(cfg.DatabaseDSN), &gorm.Config{})
+	appDB, err := persistence.NewAppDB(sqlite.Open(cfg.DatabaseDSN), &gorm.Config{}, persistence.PoolOptions{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	})
 	if err != nil {
-		log.Fatalf("failed to initialise database: %v", err)
+		logger.Error("failed to initialise database", "error", err)
+		return 1
 	}
-	if err := appDB.AutoMigrate(); err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
+	if err := appDB.Migrate(context.Background()); err != nil {
+		logger.Error("failed to migrate database", "error", err)
+		return 1
 	}
+	if cfg.UsernameCaseInsensitive {
+		if err := appDB.EnforceUniqueNormalizedUsernames(context.Background()); err != nil {
+			logger.Error("failed to enforce case-insensitive usernames", "error", err)
+			return 1
+		}
+	}
+	appDB.RegisterPoolMetrics(metricsRegistry)
+	if *migrateOnly {
+		logger.Info("database migrated; exiting because -migrate-only was set")
+		return 0
+	}
+
+	// Background workers share one root context; shutdown cancels it and
+	// waits for them within the grace period, after the HTTP server stops.
+	backgroundWorkers := workers.New(logger)
+	shutdown.Register("background workers", backgroundWorkers.Stop)
 
 	passwordHasher := authsecurity.NewHMACPasswordHasher()
 
-	tokenGenerator, err := authtoken.NewJWTTokenGenerator(authtoken.JWTOptions{
+	jwtOptions := authtoken.JWTOptions{
 		Key:                      cfg.JWTKey,
+		PreviousKeys:             cfg.JWTPreviousKeys,
 		Issuer:                   cfg.JWTIssuer,
-		Audience:                 cfg.JWTAudience,
+		Audiences:                cfg.JWTAudiences,
 		AccessTokenLifetimeHours: cfg.JWTAccessLifetimeHours,
+		Leeway:                   cfg.JWTLeeway,
+		ClaimNames:               cfg.JWTClaimNames,
+	}
+	tokenGenerator, err := authtoken.NewJWTTokenGenerator(jwtOptions)
+	if err != nil {
+		logger.Error("failed to initialise JWT generator", "error", err)
+		return 1
+	}
+
+	writeRetry := persistence.DefaultRetryOptions()
+	writeRetry.Attempts = cfg.DBWriteRetryAttempts
+	userRepository := authpersistence.NewGormUserRepository(appDB.DB, writeRetry, authpersistence.WithQueryTimeout(cfg.DBQueryTimeout))
+	authEventBus := authevents.NewBus(func(event authapp.DomainEvent, err error) {
+		logger.Warn("auth event handler failed", "event", event.EventName(), "error", err)
 	})
+	authEvents := authevents.NewHub(authevents.DefaultSubscriberBuffer)
+	authEvents.StreamFrom(authEventBus)
+	accountEvents := authpersistence.NewGormAccountEventLog(appDB.DB, writeRetry)
+	authevents.RecordAccountEvents(authEventBus, accountEvents)
+	loginAttempts := authpersistence.NewGormLoginAttemptStore(appDB.DB, writeRetry)
+	backgroundWorkers.Go("login attempt cleanup", func(ctx context.Context) {
+		loginAttempts.RunCleanup(ctx, loginAttemptCleanupInterval, func(err error) {
+			logger.Warn("failed to delete expired login attempts", "error", err)
+		})
+	})
+	kvStore := kvstore.NewGormStore(appDB.DB, writeRetry)
+	backgroundWorkers.Go("kv entry cleanup", func(ctx context.Context) {
+		kvStore.RunCleanup(ctx, kvCleanupInterval, func(err error) {
+			logger.Warn("failed to delete expired kv entries", "error", err)
+		})
+	})
+	emailTemplates, err := authemail.NewTemplates(cfg.EmailTemplatesDir)
 	if err != nil {
-		log.Fatalf("failed to initialise JWT generator: %v", err)
+		logger.Error("failed to load email templates", "error", err)
+		return 1
+	}
+	// APP_BASE_URL was validated as an absolute URL by config.Load.
+	appBaseURL, _ := url.Parse(cfg.AppBaseURL)
+	var emailSender platformemail.Sender = platformemail.NewLogSender(logger)
+	if cfg.SMTPEnabled() {
+		emailSender, err = platformemail.NewSMTPSender(platformemail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+		if err != nil {
+			logger.Error("failed to configure smtp", "error", err)
+			return 1
+		}
+	}
+	accountMailer := authemail.NewMailer(emailSender, emailTemplates, appBaseURL)
+	authService := authapp.NewService(userRepository, passwordHasher, tokenGenerator, tracingProvider.Tracer("auth"), authapp.Options{
+		LowercaseWholeEmail:     cfg.LowercaseWholeEmail,
+		UsernameCaseInsensitive: cfg.UsernameCaseInsensitive,
+		AllowedEmailDomains:     cfg.AllowedEmailDomains,
+		ReservedUsernames:       cfg.ReservedUsernames,
+		RememberMeLifetime:      time.Duration(cfg.JWTRememberMeHours) * time.Hour,
+		TokenLifetimes: map[string]time.Duration{
+			authdomain.RoleService: time.Duration(cfg.JWTServiceLifetimeHours) * time.Hour,
+		},
+		Events:        authEventBus,
+		AccountEvents: accountEvents,
+		Metrics:       authmetrics.NewPrometheusRecorder(metricsRegistry),
+		LoginAttempts: loginAttempts,
+		Lockout: authapp.LockoutPolicy{
+			MaxFailures: cfg.LockoutMaxFailures,
+			Window:      cfg.LockoutWindow,
+			Duration:    cfg.LockoutDuration,
+		},
+		EmailChanges:   userRepository,
+		Tokens:         kvStore,
+		Mailer:         accountMailer,
+		EmailChangeTTL: cfg.EmailChangeTTL,
+	})
+	if cfg.SeedAdminUsername != "" {
+		created, err := authService.SeedAdmin(context.Background(), authapp.SeedAdminRequest{
+			Username: cfg.SeedAdminUsername,
+			Email:    cfg.SeedAdminEmail,
+			Password: cfg.SeedAdminPassword,
+		})
+		if err != nil {
+			logger.Error("failed to seed admin user", "error", err)
+			return 1
+		}
+		if created {
+			logger.Info("seeded admin user", "username", cfg.SeedAdminUsername)
+		} else {
+			logger.Info("admin user already exists; skipping seed", "username", cfg.SeedAdminUsername)
+		}
 	}
+	var registerByDomain *ratelimit.Limiter
+	if cfg.RegisterDomainRateLimit > 0 {
+		registerByDomain = ratelimit.New(cfg.RegisterDomainRateLimit, time.Hour)
+	}
+	authHandlerOptions := []authapi.HandlersOption{authapi.WithRegistrationLimits(
+		ratelimit.New(cfg.RegisterRateLimit, time.Hour).WithBurst(cfg.RegisterBurst),
+		registerByDomain,
+	)}
+	if cfg.AuthCookieMode == config.AuthCookieModeBoth || cfg.AuthCookieMode == config.AuthCookieModeOnly {
+		authHandlerOptions = append(authHandlerOptions, authapi.WithAuthCookie(authapi.AuthCookie{
+			MaxAge:        time.Duration(cfg.JWTAccessLifetimeHours) * time.Hour,
+			OmitBodyToken: cfg.AuthCookieMode == config.AuthCookieModeOnly,
+		}))
+	}
+	availabilityLimiter := ratelimit.New(cfg.AvailabilityRateLimit, time.Minute)
+	idempotent := httpapi.Idempotency(idempotency.NewMemoryStore(), cfg.IdempotencyTTL)
+	authenticator := authapp.NewTokenAuthenticator(tokenGenerator, userRepository)
+	requireAuth := authapi.RequireAuth(authenticator)
+	authAdminHandlers := authapi.NewAdminHandlers(authService)
+	authEventsHandlers := authapi.NewEventsHandlers(authEvents, authapi.DefaultEventsHeartbeat)
 
-	userRepository := authpersistence.NewGormUserRepository(appDB.DB)
-	authService := authapp.NewService(userRepository, passwordHasher, tokenGenerator)
-	authHandlers := authapi.NewHandlers(authService)
-	authapi.RegisterRoutes(engine, authHandlers)
+	healthChecks := []health.Check{
+		{Name: "database", Critical: true, Run: appDB.Ping},
+		{Name: "token_key", Critical: true, Run: func(context.Context) error { return jwtOptions.Validate() }},
+	}
 
-	pokemonAdapter := pokemoninfra.NewAdapter(http.DefaultClient)
-	pokemonService := pokemonapp.NewService(pokemonAdapter)
+	var pokemonSource interface {
+		pokemonapp.RandomPokemonPort
+		pokemonapp.PokemonLookupPort
+		pokemonapp.PokemonListPort
+	}
+	var pokemonAdminHandlers *pokemonapi.AdminHandlers
+	var randomSource pokemonapp.RandomSource
+	if cfg.PokemonSeededRandom {
+		logger.Warn("seeded random Pokemon enabled; picks are predictable", "seed", cfg.PokemonRandomSeed)
+		randomSource = pokemonapp.NewSeededSource(int64(cfg.PokemonRandomSeed))
+	}
+	switch cfg.PokemonSource {
+	case config.PokemonSourceOffline:
+		pokemonSource, err = pokemonoffline.NewAdapter(pokemonoffline.WithRandomSource(randomSource))
+		if err != nil {
+			logger.Error("failed to initialise offline Pokemon source", "error", err)
+			return 1
+		}
+	default:
+		pokeAPIAdapter := pokemoninfra.NewAdapter(httpclient.New(httpclient.Options{}),
+			pokemoninfra.WithPlaceholderImage(cfg.PokemonPlaceholderImage),
+			pokemoninfra.WithRandomSource(randomSource),
+			pokemoninfra.WithRateLimit(cfg.PokeAPIRequestsPerSec),
+		)
+		pokemonSource = pokeAPIAdapter
+		pokemonAdminHandlers = pokemonapi.NewAdminHandlers(pokeAPIAdapter)
+		healthChecks = append(healthChecks, health.Check{
+			Name: "pokeapi",
+			Run:  health.NewCachedCheck(pokeAPIAdapter.Ping, 30*time.Second).Run,
+		})
+	}
+	pokemonService := pokemonapp.NewService(pokemonSource)
 	pokemonHandlers := pokemonapi.NewHandlers(pokemonService)
-	pokemonapi.RegisterRoutes(engine, pokemonHandlers)
+	streamHandlers := pokemonapi.NewStreamHandlers(pokemonService, pokemonapi.StreamOptions{
+		Interval:   cfg.PokemonStreamInterval,
+		MaxClients: cfg.PokemonStreamMaxClients,
+	})
+	catalogHandlers := pokemonapi.NewCatalogHandlers(pokemonapp.NewCatalogService(pokemonSource))
+	favoriteRepository := pokemonpersistence.NewGormFavoriteRepository(appDB.DB)
+	favoritesService := pokemonapp.NewFavoritesService(favoriteRepository, pokemonSource)
+	favoritesHandlers := pokemonapi.NewFavoritesHandlers(favoritesService)
+	authHandlerOptions = append(authHandlerOptions, authapi.WithExportSections(pokemonapi.FavoritesExportSection(favoritesService)))
+	authHandlers := authapi.NewHandlers(authService, authHandlerOptions...)
 
-	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Module routes live under /api/v1; MountAPI also keeps the deprecated
+	// unversioned paths working until clients have moved over.
+	httpserver.MountAPI(engine, func(router gin.IRouter) {
+		authapi.RegisterRoutes(router, authHandlers, httpapi.RateLimitByClientIP(availabilityLimiter), idempotent, requireAuth)
+		authapi.RegisterAdminRoutes(router, authAdminHandlers, authEventsHandlers, idempotent, requireAuth)
+		authapi.RegisterIntrospectionRoutes(router, authapi.NewIntrospectionHandlers(authenticator), authapi.RequireAPIKeyOrAdmin(cfg.IntrospectionAPIKey, authenticator))
+		pokemonapi.RegisterRoutes(router, pokemonHandlers)
+		if cfg.PokemonSeededRandom {
+			pokemonapi.RegisterSeededRoutes(router, pokemonHandlers)
+		}
+		pokemonapi.RegisterStreamRoutes(router, streamHandlers)
+		pokemonapi.RegisterCatalogRoutes(router, catalogHandlers)
+		pokemonapi.RegisterFavoriteRoutes(router, favoritesHandlers, requireAuth)
+		if pokemonAdminHandlers != nil {
+			pokemonapi.RegisterAdminRoutes(router, pokemonAdminHandlers, requireAuth, authapi.RequireRole(authdomain.RoleAdmin))
+		}
+	})
+
+	healthChecker := health.NewChecker(healthChecks...)
+	readiness := health.NewReadiness()
+	engine.GET("/health", health.Handler(healthChecker))
+	engine.GET("/readyz", health.ReadyHandler(readiness, healthChecker))
+	engine.GET("/metrics", gin.WrapH(metricsRegistry))
 
 	// Setup graceful shutdown
 	srv := &http.Server{
 		Addr:    ":" + cfg.Port,
 		Handler: engine,
 	}
+	srv.RegisterOnShutdown(streamHandlers.Close)
+	srv.RegisterOnShutdown(authEvents.Close)
+	shutdown.Register("http server", srv.Shutdown)
 
+	serverErr := make(chan error, 1)
 	go func() {
-		log.Printf("Server listening on http://localhost:%s", cfg.Port)
-		log.Printf("OpenTelemetry tracing enabled (development mode: stdout exporter)")
-		log.Printf("Service: %s v%s", cfg.ServiceName, cfg.ServiceVersion)
-
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+		logger.Info("server listening",
+			"port", cfg.Port,
+			"service", cfg.ServiceName,
+			"version", cfg.ServiceVersion,
+			"environment", cfg.Environment,
+		)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server
+	// Wait for an interrupt signal or a listener failure, then shut down.
+	exitCode := 0
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
+	select {
+	case sig := <-quit:
+		logger.Info("shutting down server", "signal", sig.String())
+		// Fail /readyz but keep serving for the drain delay so load balancers
+		// deregister the instance before the listener closes. A second signal
+		// skips the wait.
+		readiness.StartDraining()
+		if cfg.ShutdownDrainDelay > 0 {
+			logger.Info("draining before shutdown", "delay", cfg.ShutdownDrainDelay.String())
+			select {
+			case <-time.After(cfg.ShutdownDrainDelay):
+			case <-quit:
+			}
+		}
+	case err := <-serverErr:
+		logger.Error("server error", "error", err)
+		exitCode = 1
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("server forced to shutdown: %v", err)
+	if err := shutdown.Shutdown(ctx); err != nil {
+		logger.Error("shutdown incomplete", "error", err)
 	}
 
-	log.Println("Server exited")
+	logger.Info("server exited")
+	return exitCode
 }
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// AdminHandlers exposes account management endpoints for administrators.
+type AdminHandlers struct {
+	service *authapp.Service
+}
+
+// NewAdminHandlers wires the auth service into the admin handlers.
+func NewAdminHandlers(service *authapp.Service) *AdminHandlers {
+	return &AdminHandlers{service: service}
+}
+
+// GetUser godoc
+// @Summary Get a user
+// @Description Returns an account by ID without credential fields
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} AdminUserResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Router /admin/users/{id} [get]
+func (h *AdminHandlers) GetUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil || id == 0 {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, "User ID must be a positive whole number.")
+		return
+	}
+
+	user, err := h.service.GetUser(c.Request.Context(), uint(id))
+	if err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusOK, AdminUserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	})
+}
+
+// Import result statuses.
+const (
+	ImportStatusCreated = "created"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportUsers godoc
+// @Summary Import users in bulk
+// @Description Creates accounts migrated from another system in one transaction. Invalid rows and rows whose username or email is taken are skipped and reported without failing the rest of the batch. If a concurrent write claims a username or email mid-import, nothing is imported and 409 is returned.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body []ImportUserRequest true "Users to import"
+// @Success 200 {object} ImportUsersResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Router /admin/users/import [post]
+func (h *AdminHandlers) ImportUsers(c *gin.Context) {
+	var req []ImportUserRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+
+	rows := make([]authapp.ImportUserRequest, 0, len(req))
+	for _, row := range req {
+		rows = append(rows, authapp.ImportUserRequest(row))
+	}
+	results, err := h.service.ImportUsers(c.Request.Context(), rows)
+	if err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	response := ImportUsersResponse{Results: make([]ImportUserResultResponse, 0, len(results))}
+	for _, result := range results {
+		item := ImportUserResultResponse{Index: result.Index, Username: result.Username}
+		if result.Err != nil {
+			_, body := mapAppError(c, result.Err)
+			item.Status = ImportStatusFailed
+			item.Error = &body
+			response.Failed++
+		} else {
+			item.Status = ImportStatusCreated
+			item.UserID = result.UserID
+			response.Created++
+		}
+		response.Results = append(response.Results, item)
+	}
+	httpapi.WriteJSON(c, http.StatusOK, response)
+}
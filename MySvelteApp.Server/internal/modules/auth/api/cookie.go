@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// AuthCookieName is the HttpOnly cookie carrying the access token for browser
+// clients. RequireAuth falls back to it when no Authorization header is sent.
+const AuthCookieName = "access_token"
+
+// AuthCookie configures how register and login hand the access token to
+// browsers.
+type AuthCookie struct {
+	// MaxAge bounds the cookie's lifetime; it should match the default token
+	// lifetime. Tokens issued with a different lifetime use theirs instead.
+	MaxAge time.Duration
+	// OmitBodyToken leaves the token out of the JSON response so scripts
+	// never see it.
+	OmitBodyToken bool
+}
+
+// WithAuthCookie sets the access token in an HttpOnly, Secure,
+// SameSite=Strict cookie on successful register and login.
+func WithAuthCookie(cookie AuthCookie) HandlersOption {
+	return func(h *Handlers) {
+		h.cookie = &cookie
+	}
+}
+
+// writeAuthSuccess responds to a successful register or login, setting the
+// token cookie when cookie mode is enabled.
+func (h *Handlers) writeAuthSuccess(c *gin.Context, result *authapp.AuthSuccess) {
+	response := AuthSuccessResponse{
+		Token:    result.Token,
+		UserID:   result.UserID,
+		Username: result.Username,
+	}
+	if h.cookie != nil {
+		maxAge := h.cookie.MaxAge
+		if result.Lifetime > 0 {
+			maxAge = result.Lifetime
+		}
+		setAuthCookie(c, result.Token, int(maxAge/time.Second))
+		if h.cookie.OmitBodyToken {
+			response.Token = ""
+		}
+	}
+	httpapi.WriteJSON(c, http.StatusOK, response)
+}
+
+// Logout godoc
+// @Summary Sign out of this browser
+// @Description Clears the access token cookie. Bearer tokens are unaffected; use /auth/logout-all to revoke them.
+// @Tags auth
+// @Success 204
+// @Router /auth/logout [post]
+func (h *Handlers) Logout(c *gin.Context) {
+	setAuthCookie(c, "", -1)
+	c.Status(http.StatusNoContent)
+}
+
+// setAuthCookie writes the token cookie; a negative maxAge deletes it.
+func setAuthCookie(c *gin.Context, token string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     AuthCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// ChangeEmail godoc
+// @Summary Change the caller's email
+// @Description Emails a confirmation link to the new address. The current address stays in use until the link's token is sent to /auth/email/confirm.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangeEmailRequest true "New email"
+// @Success 202 {object} ChangeEmailResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Router /auth/me/email [put]
+func (h *Handlers) ChangeEmail(c *gin.Context) {
+	principal, ok := PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required.")
+		return
+	}
+	var req ChangeEmailRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ChangeEmail(c.Request.Context(), principal.UserID, req.Email); err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusAccepted, ChangeEmailResponse{Status: "confirmation_sent"})
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm an email change
+// @Description Switches the account to the pending address the token was emailed to
+// @Tags auth
+// @Accept json
+// @Param request body ConfirmEmailChangeRequest true "Emailed token"
+// @Success 204
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Router /auth/email/confirm [post]
+func (h *Handlers) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+
+	if err := h.service.ConfirmEmailChange(c.Request.Context(), req.Token); err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+)
+
+// DefaultEventsHeartbeat is how often an idle event stream sends a keep-alive
+// comment so proxies do not time the connection out.
+const DefaultEventsHeartbeat = 15 * time.Second
+
+// EventsHandlers streams auth events to administrators.
+type EventsHandlers struct {
+	events    authapp.EventSubscriber
+	heartbeat time.Duration
+}
+
+// NewEventsHandlers wires the event subscriber into the SSE endpoint. A
+// non-positive heartbeat uses DefaultEventsHeartbeat.
+func NewEventsHandlers(events authapp.EventSubscriber, heartbeat time.Duration) *EventsHandlers {
+	if heartbeat <= 0 {
+		heartbeat = DefaultEventsHeartbeat
+	}
+	return &EventsHandlers{events: events, heartbeat: heartbeat}
+}
+
+// StreamEvents godoc
+// @Summary Stream auth events
+// @Description Streams login and logout events as Server-Sent Events until the client disconnects. Each event is named after its type and carries an AuthEventResponse.
+// @Tags admin
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} AuthEventResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Router /admin/events [get]
+func (h *EventsHandlers) StreamEvents(c *gin.Context) {
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, AuthEventResponse{
+				Type:       event.Type,
+				UserID:     event.UserID,
+				Username:   event.Username,
+				OccurredAt: event.OccurredAt,
+			})
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
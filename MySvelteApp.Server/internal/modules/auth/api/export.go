@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+// ExportPageSize is how many records sections should read per query while
+// streaming an export.
+const ExportPageSize = 100
+
+// ExportSection adds a list of records to a user's data export. Other modules
+// contribute their data this way, so auth does not depend on them. Export
+// passes each record of userID to emit, which returns an error once the
+// download can no longer be written.
+type ExportSection struct {
+	Name   string
+	Export func(ctx context.Context, userID uint, emit func(record any) error) error
+}
+
+// WithExportSections adds sections, in order, to the documents served by
+// ExportMe after the profile and auth history.
+func WithExportSections(sections ...ExportSection) HandlersOption {
+	return func(h *Handlers) {
+		h.exportSections = append(h.exportSections, sections...)
+	}
+}
+
+// ExportMe godoc
+// @Summary Download my data
+// @Description Streams a JSON document with the caller's profile, auth history and the data other modules keep for them, such as favorites, as an attachment. Password hashes and salts are never included.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object "Export document"
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Router /auth/me/export [get]
+func (h *Handlers) ExportMe(c *gin.Context) {
+	principal, ok := PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required.")
+		return
+	}
+
+	// Load the profile before streaming so a failure still gets an error response.
+	ctx := c.Request.Context()
+	user, err := h.service.GetUser(ctx, principal.UserID)
+	if err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	sections := append([]ExportSection{{Name: "authEvents", Export: h.exportAccountEvents}}, h.exportSections...)
+
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="mysvelteapp-export.json"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	export := &exportWriter{w: c.Writer}
+	export.raw(`{"exportedAt":`)
+	export.value(time.Now().UTC())
+	export.raw(`,"profile":`)
+	export.value(ExportProfileResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	})
+	for _, section := range sections {
+		if err := export.section(ctx, section, user.ID); err != nil {
+			// The status is already sent; leave the document unterminated so
+			// the client sees invalid JSON rather than a partial export.
+			// Write errors mean the client went away and are not logged.
+			if export.err == nil {
+				logging.FromContext(ctx).Error("data export failed", "section", section.Name, "error", err)
+			}
+			return
+		}
+	}
+	export.raw("}")
+}
+
+func (h *Handlers) exportAccountEvents(ctx context.Context, userID uint, emit func(record any) error) error {
+	for offset := 0; ; offset += ExportPageSize {
+		events, err := h.service.ListAccountEvents(ctx, userID, offset, ExportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := emit(AccountEventResponse{Type: event.Type, Reason: event.Reason, OccurredAt: event.OccurredAt}); err != nil {
+				return err
+			}
+		}
+		if len(events) < ExportPageSize {
+			return nil
+		}
+	}
+}
+
+// exportWriter writes the export document piece by piece, remembering the
+// first write error so later writes become no-ops.
+type exportWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *exportWriter) raw(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+func (e *exportWriter) value(v any) {
+	if e.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		e.err = err
+		return
+	}
+	_, e.err = e.w.Write(data)
+}
+
+// section writes `,"name":[...]`, streaming the records section emits.
+func (e *exportWriter) section(ctx context.Context, section ExportSection, userID uint) error {
+	name, _ := json.Marshal(section.Name)
+	e.raw("," + string(name) + ":[")
+	first := true
+	err := section.Export(ctx, userID, func(record any) error {
+		if !first {
+			e.raw(",")
+		}
+		first = false
+		e.value(record)
+		return e.err
+	})
+	if err != nil {
+		return err
+	}
+	e.raw("]")
+	return e.err
+}
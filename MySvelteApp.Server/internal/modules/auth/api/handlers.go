@@ -1,100 +1,242 @@
 package api
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/logging"
+	"mysvelteapp/server_new/internal/platform/persistence"
+	"mysvelteapp/server_new/internal/platform/ratelimit"
 )
 
 // Handlers exposes HTTP endpoints for the auth module.
 type Handlers struct {
-	service *authapp.Service
+	service          *authapp.Service
+	registerByIP     *ratelimit.Limiter
+	registerByDomain *ratelimit.Limiter
+	cookie           *AuthCookie
+	exportSections   []ExportSection
+}
+
+// HandlersOption customises Handlers.
+type HandlersOption func(*Handlers)
+
+// WithRegistrationLimits throttles sign-ups per client IP and, when
+// byEmailDomain is non-nil, per email domain. These budgets are separate from
+// any router-level limiter.
+func WithRegistrationLimits(byIP, byEmailDomain *ratelimit.Limiter) HandlersOption {
+	return func(h *Handlers) {
+		h.registerByIP = byIP
+		h.registerByDomain = byEmailDomain
+	}
 }
 
 // NewHandlers wires the auth service into HTTP handlers.
-func NewHandlers(service *authapp.Service) *Handlers {
-	return &Handlers{service: service}
+func NewHandlers(service *authapp.Service, opts ...HandlersOption) *Handlers {
+	handlers := &Handlers{service: service}
+	for _, opt := range opts {
+		opt(handlers)
+	}
+	return handlers
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Creates a new user account and returns a JWT
+// @Description Creates a new user account and returns a JWT. In cookie mode the token is also, or only, set in an HttpOnly cookie.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body RegisterRequest true "Register Request"
 // @Success 200 {object} AuthSuccessResponse
-// @Failure 400 {object} AuthErrorResponse
-// @Failure 409 {object} AuthErrorResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Failure 429 {object} httpapi.ErrorResponse
 // @Router /auth/register [post]
 func (h *Handlers) Register(c *gin.Context) {
-	var cmd authapp.RegisterRequest
-	if err := c.ShouldBindJSON(&cmd); err != nil {
-		writeError(c, http.StatusBadRequest, "Invalid request payload.")
+	if !allow(c, h.registerByIP, httpapi.ClientIP(c)) {
 		return
 	}
 
-	result, err := h.service.Register(c.Request.Context(), cmd)
-	if err != nil {
-		status, message := mapAppError(err)
-		writeError(c, status, message)
+	var req RegisterRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+	if domain, ok := emailDomain(req.Email); ok && !allow(c, h.registerByDomain, domain) {
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthSuccessResponse{
-		Token:    result.Token,
-		UserID:   result.UserID,
-		Username: result.Username,
+	result, err := h.service.Register(c.Request.Context(), authapp.RegisterRequest{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
 	})
+	if err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	h.writeAuthSuccess(c, result)
 }
 
 // Login godoc
 // @Summary Authenticate a user
-// @Description Validates credentials and returns a JWT
+// @Description Validates credentials and returns a JWT. In cookie mode the token is also, or only, set in an HttpOnly cookie. Set rememberMe for a longer-lived token.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login Request"
 // @Success 200 {object} AuthSuccessResponse
-// @Failure 400 {object} AuthErrorResponse
-// @Failure 401 {object} AuthErrorResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 429 {object} httpapi.ErrorResponse
 // @Router /auth/login [post]
 func (h *Handlers) Login(c *gin.Context) {
-	var cmd authapp.LoginRequest
-	if err := c.ShouldBindJSON(&cmd); err != nil {
-		writeError(c, http.StatusBadRequest, "Invalid request payload.")
+	var req LoginRequest
+	if !httpapi.BindJSON(c, &req) {
 		return
 	}
 
-	result, err := h.service.Login(c.Request.Context(), cmd)
+	result, err := h.service.Login(c.Request.Context(), authapp.LoginRequest{
+		Username:   req.Username,
+		Password:   req.Password,
+		RememberMe: req.RememberMe,
+	})
 	if err != nil {
-		status, message := mapAppError(err)
-		writeError(c, status, message)
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
 		return
 	}
 
-	c.JSON(http.StatusOK, AuthSuccessResponse{
-		Token:    result.Token,
-		UserID:   result.UserID,
-		Username: result.Username,
-	})
+	h.writeAuthSuccess(c, result)
 }
 
-func mapAppError(err error) (int, string) {
+// Availability godoc
+// @Summary Check username or email availability
+// @Description Reports whether a username or email is free to register. Pass exactly one of the query parameters. Rate limited per client IP.
+// @Tags auth
+// @Produce json
+// @Param username query string false "Username to check"
+// @Param email query string false "Email address to check"
+// @Success 200 {object} AvailabilityResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 429 {object} httpapi.ErrorResponse
+// @Router /auth/availability [get]
+func (h *Handlers) Availability(c *gin.Context) {
+	username, hasUsername := c.GetQuery("username")
+	email, hasEmail := c.GetQuery("email")
+	if hasUsername == hasEmail {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeInvalidRequest, "Provide exactly one of username or email.")
+		return
+	}
+
+	var available bool
+	var err error
+	if hasUsername {
+		available, err = h.service.UsernameAvailable(c.Request.Context(), username)
+	} else {
+		available, err = h.service.EmailAvailable(c.Request.Context(), email)
+	}
+	if err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusOK, AvailabilityResponse{Available: available})
+}
+
+// LogoutAll godoc
+// @Summary Sign out everywhere
+// @Description Revokes every token issued to the caller, including the one used for this request
+// @Tags auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Router /auth/logout-all [post]
+func (h *Handlers) LogoutAll(c *gin.Context) {
+	principal, ok := PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required.")
+		return
+	}
+
+	if err := h.service.LogoutAll(c.Request.Context(), principal.UserID); err != nil {
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// allow spends one token from limiter for key, writing a 429 when none is
+// left. A nil limiter always allows.
+func allow(c *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	if limiter == nil {
+		return true
+	}
+	if allowed, retryAfter := limiter.Allow(key); !allowed {
+		httpapi.AbortRateLimited(c, retryAfter)
+		return false
+	}
+	return true
+}
+
+// emailDomain returns the lowercase domain of email, if it has one.
+func emailDomain(email string) (string, bool) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	return domain, domain != ""
+}
+
+func mapAppError(c *gin.Context, err error) (int, httpapi.ErrorBody) {
+	if status, body, ok := httpapi.ContextError(c.Request.Context(), err); ok {
+		return status, body
+	}
+
+	var validation authapp.ValidationError
+	var locked authapp.LockedError
 	switch {
-	case authapp.IsValidationError(err):
-		return http.StatusBadRequest, err.Error()
+	case errors.As(err, &validation):
+		return http.StatusBadRequest, httpapi.ErrorBody{
+			Code:    httpapi.CodeValidation,
+			Message: validation.Message,
+			Field:   validation.Field,
+			Reason:  validation.Code,
+		}
 	case authapp.IsConflictError(err):
-		return http.StatusConflict, err.Error()
+		return http.StatusConflict, httpapi.ErrorBody{Code: httpapi.CodeConflict, Message: err.Error()}
 	case authapp.IsUnauthorizedError(err):
-		return http.StatusUnauthorized, err.Error()
+		return http.StatusUnauthorized, httpapi.ErrorBody{Code: httpapi.CodeUnauthorized, Message: err.Error()}
+	case errors.As(err, &locked):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		return http.StatusTooManyRequests, httpapi.ErrorBody{Code: httpapi.CodeAccountLocked, Message: locked.Message}
+	case authapp.IsNotFoundError(err):
+		return http.StatusNotFound, httpapi.ErrorBody{Code: httpapi.CodeNotFound, Message: err.Error()}
 	default:
-		return http.StatusInternalServerError, "Failed to process request."
+		logInternalError(c, err)
+		return http.StatusInternalServerError, httpapi.ErrorBody{Code: httpapi.CodeInternal, Message: "Failed to process request."}
 	}
 }
 
-func writeError(c *gin.Context, status int, message string) {
-	c.JSON(status, AuthErrorResponse{Message: message})
+// logInternalError records an error the client only sees as a generic 500,
+// naming the repository operation that failed when there is one.
+func logInternalError(c *gin.Context, err error) {
+	attrs := []any{"error", err}
+	var repoErr *persistence.RepositoryError
+	if errors.As(err, &repoErr) {
+		attrs = append(attrs, "operation", repoErr.Op)
+	}
+	logging.FromContext(c.Request.Context()).Error("auth request failed", attrs...)
 }
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// IntrospectionHandlers lets trusted callers check tokens without parsing
+// JWTs themselves.
+type IntrospectionHandlers struct {
+	authenticator authapp.Authenticator
+}
+
+// NewIntrospectionHandlers wires the authenticator that decides whether a
+// token is active, so revoked tokens report inactive as well as expired ones.
+func NewIntrospectionHandlers(authenticator authapp.Authenticator) *IntrospectionHandlers {
+	return &IntrospectionHandlers{authenticator: authenticator}
+}
+
+// RegisterIntrospectionRoutes mounts POST /auth/introspect behind
+// requireCaller, normally RequireAPIKeyOrAdmin.
+func RegisterIntrospectionRoutes(router gin.IRouter, handlers *IntrospectionHandlers, requireCaller gin.HandlerFunc) {
+	router.POST("/auth/introspect", requireCaller, handlers.Introspect)
+}
+
+// RequireAPIKeyOrAdmin admits requests presenting apiKey in the
+// httpapi.APIKeyHeader header and otherwise requires an authenticated admin.
+// An empty apiKey admits admins only.
+func RequireAPIKeyOrAdmin(apiKey string, authenticator authapp.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if presented := c.GetHeader(httpapi.APIKeyHeader); apiKey != "" && presented != "" {
+			if subtle.ConstantTimeCompare([]byte(presented), []byte(apiKey)) != 1 {
+				httpapi.AbortWithError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Invalid API key.")
+				return
+			}
+			c.Next()
+			return
+		}
+		if !authenticateRequest(c, authenticator) {
+			return
+		}
+		if principal, _ := PrincipalFromContext(c); principal.Role != authdomain.RoleAdmin {
+			httpapi.AbortWithError(c, http.StatusForbidden, httpapi.CodeForbidden, "You do not have permission to access this resource.")
+			return
+		}
+		c.Next()
+	}
+}
+
+// Introspect godoc
+// @Summary Introspect a token
+// @Description Reports whether a token is active and whom it identifies, in the style of RFC 7662. Expired, revoked and tampered tokens return active=false rather than an error. Requires the X-API-Key header or an admin token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body IntrospectRequest true "Token to introspect"
+// @Success 200 {object} IntrospectResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Router /auth/introspect [post]
+func (h *IntrospectionHandlers) Introspect(c *gin.Context) {
+	var req IntrospectRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+
+	principal, err := h.authenticator.Authenticate(c.Request.Context(), req.Token)
+	if err != nil {
+		if authapp.IsUnauthorizedError(err) {
+			httpapi.WriteJSON(c, http.StatusOK, IntrospectResponse{Active: false})
+			return
+		}
+		status, body := mapAppError(c, err)
+		httpapi.WriteErrorBody(c, status, body)
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusOK, IntrospectResponse{
+		Active:   true,
+		Username: principal.Username,
+		UserID:   principal.UserID,
+		Exp:      principal.ExpiresAt.Unix(),
+	})
+}
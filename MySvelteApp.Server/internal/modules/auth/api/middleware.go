@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+const principalContextKey = "auth.principal"
+
+// RequireAuth rejects requests without a valid bearer token and stores the
+// resolved principal on the gin context for downstream handlers. Without an
+// Authorization header the token is read from the AuthCookieName cookie.
+func RequireAuth(authenticator authapp.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticateRequest(c, authenticator) {
+			c.Next()
+		}
+	}
+}
+
+// authenticateRequest resolves the caller's token and stores the principal on
+// c. On failure it aborts with the matching error and returns false.
+func authenticateRequest(c *gin.Context, authenticator authapp.Authenticator) bool {
+	token, ok := requestToken(c)
+	if !ok {
+		httpapi.AbortWithError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required.")
+		return false
+	}
+
+	principal, err := authenticator.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		if authapp.IsUnauthorizedError(err) {
+			httpapi.AbortWithError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Invalid or expired token.")
+			return false
+		}
+		if status, body, ok := httpapi.ContextError(c.Request.Context(), err); ok {
+			c.Abort()
+			httpapi.WriteErrorBody(c, status, body)
+			return false
+		}
+		httpapi.AbortWithError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to process request.")
+		return false
+	}
+
+	c.Set(principalContextKey, principal)
+	return true
+}
+
+// RequireRole rejects callers whose role differs from role. It must run after
+// RequireAuth, which resolves the caller.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := PrincipalFromContext(c)
+		if !ok {
+			httpapi.AbortWithError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required.")
+			return
+		}
+		if principal.Role != role {
+			httpapi.AbortWithError(c, http.StatusForbidden, httpapi.CodeForbidden, "You do not have permission to access this resource.")
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestToken prefers the Authorization header and only consults the cookie
+// when the header is absent, so a malformed header is never masked.
+func requestToken(c *gin.Context) (string, bool) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		return BearerToken(header)
+	}
+	token, err := c.Cookie(AuthCookieName)
+	return token, err == nil && token != ""
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header
+// value. The scheme is matched case-insensitively.
+func BearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// PrincipalFromContext returns the caller resolved by RequireAuth.
+func PrincipalFromContext(c *gin.Context) (*authapp.Principal, bool) {
+	value, ok := c.Get(principalContextKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*authapp.Principal)
+	return principal, ok && principal != nil
+}
@@ -1,30 +1,147 @@
 package api
 
+import (
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
 // AuthSuccessResponse matches the JSON contract expected by the frontend generator.
 // @name AuthSuccessResponse
 type AuthSuccessResponse struct {
-	Token    string `json:"token"`
+	Token    string `json:"token,omitempty"`
 	UserID   uint   `json:"userId"`
 	Username string `json:"username"`
 }
 
-// AuthErrorResponse wraps error messages in a serialisable structure.
-// @name AuthErrorResponse
-type AuthErrorResponse struct {
-	Message string `json:"message"`
+// AvailabilityResponse reports whether a username or email can be registered.
+// @name AvailabilityResponse
+type AvailabilityResponse struct {
+	Available bool `json:"available"`
 }
 
 // RegisterRequest represents the registration payload.
+// Binding tags only cap field sizes; missing values and the nuanced business
+// rules are left to the auth service, which names the offending field.
 // @name RegisterRequest
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Username string `json:"username"`
+	Email    string `json:"email" binding:"max=1024"`
+	Password string `json:"password" binding:"max=2048"`
+	Username string `json:"username" binding:"max=256"`
+}
+
+// IntrospectRequest carries the token a trusted caller wants checked.
+// @name IntrospectRequest
+type IntrospectRequest struct {
+	Token string `json:"token" binding:"required,max=8192"`
+}
+
+// IntrospectResponse describes a token in the style of RFC 7662. Inactive
+// tokens carry only active=false, whatever made them unusable.
+// @name IntrospectResponse
+type IntrospectResponse struct {
+	Active   bool   `json:"active"`
+	Username string `json:"username,omitempty"`
+	UserID   uint   `json:"userId,omitempty"`
+	// Exp is the token's expiry in seconds since the Unix epoch.
+	Exp int64 `json:"exp,omitempty"`
+}
+
+// ChangeEmailRequest names the address the caller wants to switch to.
+// @name ChangeEmailRequest
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"max=1024"`
+}
+
+// ChangeEmailResponse acknowledges that a confirmation was sent to the new
+// address.
+// @name ChangeEmailResponse
+type ChangeEmailResponse struct {
+	Status string `json:"status"`
+}
+
+// ConfirmEmailChangeRequest carries the token emailed to the new address.
+// @name ConfirmEmailChangeRequest
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"max=256"`
 }
 
 // LoginRequest represents the login payload.
 // @name LoginRequest
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" binding:"max=256"`
+	Password string `json:"password" binding:"max=2048"`
+	// RememberMe requests a longer-lived session, within the server's policy.
+	RememberMe bool `json:"rememberMe"`
+}
+
+// AdminUserResponse describes an account to administrators. Credential
+// fields are deliberately absent.
+// @name AdminUserResponse
+type AdminUserResponse struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AuthEventResponse is the data of one /admin/events message.
+// @name AuthEventResponse
+type AuthEventResponse struct {
+	Type       string    `json:"type"`
+	UserID     uint      `json:"userId,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// ExportProfileResponse is the account section of a data export. Credential
+// fields are deliberately absent.
+// @name ExportProfileResponse
+type ExportProfileResponse struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AccountEventResponse is one entry of the auth history in a data export.
+// @name AccountEventResponse
+type AccountEventResponse struct {
+	Type       string    `json:"type"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// ImportUserRequest is one account in an admin bulk import. The password
+// hash and salt are stored as given and must use the server's hash format.
+// @name ImportUserRequest
+type ImportUserRequest struct {
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"passwordHash"`
+	PasswordSalt string `json:"passwordSalt"`
+	Role         string `json:"role,omitempty"`
+}
+
+// ImportUserResultResponse reports the outcome of one import row. Error is
+// set only when Status is "failed".
+// @name ImportUserResultResponse
+type ImportUserResultResponse struct {
+	Index    int                `json:"index"`
+	Username string             `json:"username"`
+	Status   string             `json:"status"`
+	UserID   uint               `json:"userId,omitempty"`
+	Error    *httpapi.ErrorBody `json:"error,omitempty"`
+}
+
+// ImportUsersResponse summarises an admin bulk import.
+// @name ImportUsersResponse
+type ImportUsersResponse struct {
+	Created int                        `json:"created"`
+	Failed  int                        `json:"failed"`
+	Results []ImportUserResultResponse `json:"results"`
 }
@@ -1,10 +1,33 @@
 package api
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
 
 // RegisterRoutes mounts the auth routes beneath the provided router group.
-func RegisterRoutes(router gin.IRouter, handlers *Handlers) {
+// availabilityLimit guards the availability check against enumeration,
+// idempotent lets clients safely retry registration and requireAuth protects
+// routes acting on the caller's account.
+func RegisterRoutes(router gin.IRouter, handlers *Handlers, availabilityLimit, idempotent, requireAuth gin.HandlerFunc) {
 	auth := router.Group("/auth")
-	auth.POST("/register", handlers.Register)
+	auth.POST("/register", idempotent, handlers.Register)
 	auth.POST("/login", handlers.Login)
+	auth.POST("/logout", handlers.Logout)
+	auth.GET("/availability", availabilityLimit, handlers.Availability)
+	auth.POST("/logout-all", requireAuth, handlers.LogoutAll)
+	auth.GET("/me/export", requireAuth, handlers.ExportMe)
+	auth.PUT("/me/email", requireAuth, handlers.ChangeEmail)
+	auth.POST("/email/confirm", handlers.ConfirmEmailChange)
+}
+
+// RegisterAdminRoutes mounts the administrator routes behind requireAuth and
+// the admin role check. idempotent lets a retried import replay its results
+// instead of creating the batch twice.
+func RegisterAdminRoutes(router gin.IRouter, handlers *AdminHandlers, events *EventsHandlers, idempotent, requireAuth gin.HandlerFunc) {
+	admin := router.Group("/admin", requireAuth, RequireRole(authdomain.RoleAdmin))
+	admin.POST("/users/import", idempotent, handlers.ImportUsers)
+	admin.GET("/users/:id", handlers.GetUser)
+	admin.GET("/events", events.StreamEvents)
 }
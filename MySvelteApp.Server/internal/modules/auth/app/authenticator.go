@@ -0,0 +1,36 @@
+package app
+
+import "context"
+
+var _ Authenticator = (*TokenAuthenticator)(nil)
+
+// TokenAuthenticator validates access tokens and rejects those revoked by a
+// later token version bump, giving global logout without a token blacklist.
+type TokenAuthenticator struct {
+	validator TokenValidator
+	users     UserRepository
+}
+
+// NewTokenAuthenticator wires the token validator and user store.
+func NewTokenAuthenticator(validator TokenValidator, users UserRepository) *TokenAuthenticator {
+	return &TokenAuthenticator{validator: validator, users: users}
+}
+
+// Authenticate returns the caller when the token is valid, its user still
+// exists and its version is current.
+func (a *TokenAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	principal, err := a.validator.ValidateToken(token)
+	if err != nil {
+		return nil, UnauthorizedError{Message: "Invalid or expired token."}
+	}
+
+	user, err := a.users.GetByID(ctx, principal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || principal.TokenVersion < user.TokenVersion {
+		return nil, UnauthorizedError{Message: "Token has been revoked."}
+	}
+	principal.Role = user.Role
+	return principal, nil
+}
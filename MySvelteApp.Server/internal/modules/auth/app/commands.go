@@ -1,5 +1,7 @@
 package app
 
+import "time"
+
 // RegisterRequest represents the payload required to create a new user account.
 type RegisterRequest struct {
 	Username string `json:"username"`
@@ -7,10 +9,19 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 }
 
+// SeedAdminRequest describes the administrator account created at startup.
+type SeedAdminRequest struct {
+	Username string
+	Email    string
+	Password string
+}
+
 // LoginRequest represents the credentials submitted by an existing user.
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// RememberMe asks for the longer Options.RememberMeLifetime session.
+	RememberMe bool `json:"rememberMe"`
 }
 
 // AuthSuccess encapsulates the data returned on successful authentication.
@@ -18,4 +29,19 @@ type AuthSuccess struct {
 	Token    string
 	UserID   uint
 	Username string
+	// Lifetime is how long Token stays valid when it differs from the
+	// generator's default; zero means the default applies.
+	Lifetime time.Duration
+}
+
+// Principal identifies the authenticated caller of a request.
+type Principal struct {
+	UserID   uint
+	Username string
+	// TokenVersion is the user's token version when the token was issued.
+	TokenVersion int
+	// Role is the user's current role, resolved by the Authenticator.
+	Role string
+	// ExpiresAt is when the token stops being accepted.
+	ExpiresAt time.Time
 }
@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"mysvelteapp/server_new/internal/platform/kvstore"
+)
+
+// DefaultEmailChangeTTL is how long an emailed confirmation token is accepted
+// unless Options.EmailChangeTTL says otherwise.
+const DefaultEmailChangeTTL = 24 * time.Hour
+
+// emailChangeTokenBytes is the entropy of confirmation tokens.
+const emailChangeTokenBytes = 32
+
+// emailChangeKeyPrefix namespaces confirmation tokens in Options.Tokens.
+const emailChangeKeyPrefix = "email_change:"
+
+var errEmailChangesDisabled = errors.New("email changes are not configured")
+
+// pendingEmailChange is the value stored under a confirmation token.
+type pendingEmailChange struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+}
+
+func (s *Service) emailChangesEnabled() bool {
+	return s.options.EmailChanges != nil && s.options.Tokens != nil && s.options.Mailer != nil
+}
+
+// ChangeEmail records newEmail as the user's pending address and emails a
+// confirmation token to it, superseding tokens sent for earlier requests. The
+// current address stays in use until the token is passed to
+// ConfirmEmailChange. Addresses held by another account are
+// rejected with a ConflictError.
+func (s *Service) ChangeEmail(ctx context.Context, userID uint, newEmail string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "auth.ChangeEmail")
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	if !s.emailChangesEnabled() {
+		return errEmailChangesDisabled
+	}
+	if err := validateEmail(newEmail); err != nil {
+		return err
+	}
+	if err := s.validateEmailDomain(newEmail); err != nil {
+		return err
+	}
+	normalizedEmail := s.normalizeEmail(newEmail)
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return NotFoundError{Message: "User not found."}
+	}
+	if strings.EqualFold(user.Email, normalizedEmail) {
+		return ValidationError{Field: FieldEmail, Code: CodeUnchanged, Message: "The new email must differ from your current one."}
+	}
+	emailExists, err := s.users.EmailExists(ctx, normalizedEmail)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Bool("auth.email_exists", emailExists))
+	if emailExists {
+		return emailTakenError()
+	}
+
+	token, err := newEmailChangeToken()
+	if err != nil {
+		return err
+	}
+	pending, err := json.Marshal(pendingEmailChange{UserID: user.ID, Email: normalizedEmail})
+	if err != nil {
+		return err
+	}
+	if err := s.options.EmailChanges.SetPendingEmail(ctx, user.ID, normalizedEmail); err != nil {
+		return err
+	}
+	if err := s.options.Tokens.Set(ctx, emailChangeKey(token), pending, s.options.EmailChangeTTL); err != nil {
+		return err
+	}
+	expiresAt := s.options.Now().UTC().Add(s.options.EmailChangeTTL)
+	return s.options.Mailer.SendVerification(ctx, normalizedEmail, user.Username, token, expiresAt)
+}
+
+// ConfirmEmailChange switches the account to the pending address that token
+// was emailed to. Unknown and expired tokens fail with a ValidationError; an
+// address taken by another account since the change was requested fails with
+// a ConflictError.
+func (s *Service) ConfirmEmailChange(ctx context.Context, token string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "auth.ConfirmEmailChange")
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
+	if !s.emailChangesEnabled() {
+		return errEmailChangesDisabled
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ValidationError{Field: FieldToken, Code: CodeRequired, Message: "Token is required."}
+	}
+
+	invalidToken := ValidationError{Field: FieldToken, Code: CodeInvalidToken, Message: "This confirmation link is invalid or has expired."}
+	key := emailChangeKey(token)
+	stored, err := s.options.Tokens.Get(ctx, key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return invalidToken
+	}
+	if err != nil {
+		return err
+	}
+	var pending pendingEmailChange
+	if err := json.Unmarshal(stored, &pending); err != nil {
+		return err
+	}
+
+	emailExists, err := s.users.EmailExists(ctx, pending.Email)
+	if err != nil {
+		return err
+	}
+	if emailExists {
+		return emailTakenError()
+	}
+	confirmed, err := s.options.EmailChanges.ConfirmEmail(ctx, pending.UserID, pending.Email)
+	if errors.Is(err, ErrDuplicateEmail) {
+		return emailTakenError()
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.options.Tokens.Delete(ctx, key); err != nil {
+		return err
+	}
+	if !confirmed {
+		// A later ChangeEmail request replaced the address this token confirms.
+		return invalidToken
+	}
+	return nil
+}
+
+// newEmailChangeToken returns a random URL-safe token. Only its hash is stored.
+func newEmailChangeToken() (string, error) {
+	raw := make([]byte, emailChangeTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// emailChangeKey is the Tokens key of token: its hash, so a leaked store does
+// not reveal usable tokens.
+func emailChangeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return emailChangeKeyPrefix + hex.EncodeToString(sum[:])
+}
@@ -1,9 +1,51 @@
 package app
 
-import "errors"
+import (
+	"errors"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/validation"
+)
+
+// Field names reported on ValidationError.
+const (
+	FieldUsername = "username"
+	FieldEmail    = "email"
+	FieldPassword = "password"
+	// FieldPasswordHash and FieldRole are reported by ImportUsers.
+	FieldPasswordHash = "passwordHash"
+	FieldRole         = "role"
+	// FieldToken is reported by ConfirmEmailChange.
+	FieldToken = "token"
+)
+
+// Validation codes reported on ValidationError. The generic ones come from
+// the validation package so every module reports them alike.
+const (
+	CodeRequired         = validation.CodeRequired
+	CodeTooShort         = validation.CodeTooShort
+	CodeTooLong          = validation.CodeTooLong
+	CodeInvalidFormat    = validation.CodeInvalidFormat
+	CodeTooWeak          = "too_weak"
+	CodeDomainNotAllowed = "domain_not_allowed"
+	CodeReserved         = "reserved"
+	CodeUnchanged        = "unchanged"
+	CodeInvalidToken     = "invalid_token"
+)
+
+// Repository errors reported when a write violates a unique constraint.
+// Implementations should wrap them so errors.Is matches.
+var (
+	ErrDuplicateUsername = errors.New("duplicate username")
+	ErrDuplicateEmail    = errors.New("duplicate email")
+)
 
 // ValidationError indicates the payload failed validation rules.
+// Field names the offending request property (e.g. "username") and Code
+// carries a stable machine-readable reason so clients can highlight inputs.
 type ValidationError struct {
+	Field   string
+	Code    string
 	Message string
 }
 
@@ -29,6 +71,26 @@ func (e UnauthorizedError) Error() string {
 	return e.Message
 }
 
+// LockedError indicates the account is locked after too many failed logins.
+// RetryAfter is how long the lock has left.
+type LockedError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e LockedError) Error() string {
+	return e.Message
+}
+
+// NotFoundError indicates the requested account does not exist.
+type NotFoundError struct {
+	Message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
 // IsValidationError returns true when err is a ValidationError.
 func IsValidationError(err error) bool {
 	var target ValidationError
@@ -46,3 +108,15 @@ func IsUnauthorizedError(err error) bool {
 	var target UnauthorizedError
 	return errors.As(err, &target)
 }
+
+// IsLockedError returns true when err is a LockedError.
+func IsLockedError(err error) bool {
+	var target LockedError
+	return errors.As(err, &target)
+}
+
+// IsNotFoundError returns true when err is a NotFoundError.
+func IsNotFoundError(err error) bool {
+	var target NotFoundError
+	return errors.As(err, &target)
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Names of the domain events published on the EventBus. The login and logout
+// names double as the AuthEvent types streamed to administrators.
+const (
+	EventUserRegistered = "user_registered"
+	EventLoginSucceeded = "login_succeeded"
+	EventLoginFailed    = "login_failed"
+	EventLogoutAll      = "logout_all"
+)
+
+// Reasons carried by LoginFailed.
+const (
+	LoginFailureUnknownUser   = "unknown_user"
+	LoginFailureWrongPassword = "wrong_password"
+)
+
+// DomainEvent is something the auth service reports after it happened.
+// Subscribers switch on the concrete type; EventName selects handlers.
+type DomainEvent interface {
+	EventName() string
+}
+
+// UserRegistered is published once a new account has been stored.
+type UserRegistered struct {
+	UserID     uint
+	Username   string
+	Email      string
+	OccurredAt time.Time
+}
+
+// UserLoggedIn is published after a token was issued to the user.
+type UserLoggedIn struct {
+	UserID     uint
+	Username   string
+	OccurredAt time.Time
+}
+
+// LoginFailed is published when credentials are rejected. UserID is zero
+// when the username matched no account.
+type LoginFailed struct {
+	UserID     uint
+	Username   string
+	Reason     string
+	OccurredAt time.Time
+}
+
+// UserLoggedOutEverywhere is published after every token of the user was
+// revoked.
+type UserLoggedOutEverywhere struct {
+	UserID     uint
+	OccurredAt time.Time
+}
+
+func (UserRegistered) EventName() string          { return EventUserRegistered }
+func (UserLoggedIn) EventName() string            { return EventLoginSucceeded }
+func (LoginFailed) EventName() string             { return EventLoginFailed }
+func (UserLoggedOutEverywhere) EventName() string { return EventLogoutAll }
+
+// EventBus delivers domain events to the side effects subscribed to them,
+// such as auditing, email and metrics. Publish runs within the use-case, so
+// implementations must not fail it: subscriber errors are theirs to handle.
+type EventBus interface {
+	Publish(ctx context.Context, event DomainEvent)
+}
+
+// AuthEvent is the stream form of a login or logout event. UserID is zero
+// when a login names an unknown user.
+type AuthEvent struct {
+	Type       string
+	UserID     uint
+	Username   string
+	OccurredAt time.Time
+}
+
+// EventSubscriber delivers auth events to live consumers. The returned
+// function ends the subscription and closes the channel.
+type EventSubscriber interface {
+	Subscribe() (<-chan AuthEvent, func())
+}
+
+func (s *Service) publish(ctx context.Context, event DomainEvent) {
+	if s.options.Events == nil {
+		return
+	}
+	s.options.Events.Publish(ctx, event)
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// MaxImportBatch bounds how many users one ImportUsers call may create.
+const MaxImportBatch = 1000
+
+// Column sizes of the stored credentials.
+const (
+	maxImportHashLength = 512
+	maxImportSaltLength = 256
+)
+
+// ImportUserRequest is one account to create during a bulk import from
+// another system. The hash and salt are stored as given, so they must be in
+// the configured PasswordHasher's format for the user to be able to sign in.
+type ImportUserRequest struct {
+	Username     string
+	Email        string
+	PasswordHash string
+	PasswordSalt string
+	// Role defaults to authdomain.RoleUser when empty.
+	Role string
+}
+
+// ImportUserResult reports what happened to the row at Index. Err is nil
+// when the user was created with UserID, and otherwise a ValidationError or
+// ConflictError explaining why the row was skipped.
+type ImportUserResult struct {
+	Index    int
+	Username string
+	UserID   uint
+	Err      error
+}
+
+// ImportUsers creates the valid, non-conflicting rows in a single
+// transaction and reports a result per row, in order. Invalid rows and rows
+// whose username or email is taken, either already or by an earlier row in
+// the batch, are skipped without failing the rest. A returned error means
+// nothing was imported.
+//
+// Conflicts are found by checking before each insert, never by letting one
+// fail: on Postgres a failed statement aborts the whole transaction. An
+// insert that still hits a unique constraint lost a race with a concurrent
+// write, so the batch is rolled back and reported as a ConflictError.
+func (s *Service) ImportUsers(ctx context.Context, rows []ImportUserRequest) (results []ImportUserResult, err error) {
+	ctx, span := s.tracer.Start(ctx, "auth.ImportUsers")
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+	span.SetAttributes(attribute.Int("auth.import.rows", len(rows)))
+
+	if len(rows) == 0 || len(rows) > MaxImportBatch {
+		return nil, ValidationError{Message: fmt.Sprintf("Import between 1 and %d users at a time.", MaxImportBatch)}
+	}
+
+	var created []*authdomain.User
+	err = s.users.WithinTransaction(ctx, func(ctx context.Context) error {
+		results = make([]ImportUserResult, len(rows))
+		created = created[:0]
+		seenUsernames := make(map[string]bool, len(rows))
+		seenEmails := make(map[string]bool, len(rows))
+
+		for i, row := range rows {
+			username := authdomain.CleanUsername(row.Username)
+			results[i] = ImportUserResult{Index: i, Username: username}
+
+			user, err := s.importUser(ctx, row, seenUsernames, seenEmails)
+			if err != nil {
+				if !isRowError(err) {
+					return err
+				}
+				results[i].Err = err
+				continue
+			}
+			results[i].UserID = user.ID
+			created = append(created, user)
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrDuplicateUsername):
+		return nil, usernameTakenError()
+	case errors.Is(err, ErrDuplicateEmail):
+		return nil, emailTakenError()
+	case err != nil:
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("auth.import.created", len(created)))
+	for _, user := range created {
+		s.publish(ctx, UserRegistered{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Email:      user.Email,
+			OccurredAt: s.options.Now().UTC(),
+		})
+	}
+	return results, nil
+}
+
+// importUser validates and inserts one row. seenUsernames and seenEmails
+// hold the keys of earlier rows so duplicates within the batch conflict too.
+func (s *Service) importUser(ctx context.Context, row ImportUserRequest, seenUsernames, seenEmails map[string]bool) (*authdomain.User, error) {
+	if err := validateImportRow(row); err != nil {
+		return nil, err
+	}
+	username := authdomain.CleanUsername(row.Username)
+	email := s.normalizeEmail(row.Email)
+	role := strings.TrimSpace(row.Role)
+	if role == "" {
+		role = authdomain.RoleUser
+	}
+
+	usernameKey := username
+	if s.options.UsernameCaseInsensitive {
+		usernameKey = authdomain.NormalizeUsername(username)
+	}
+	if seenUsernames[usernameKey] {
+		return nil, usernameTakenError()
+	}
+	if seenEmails[email] {
+		return nil, emailTakenError()
+	}
+
+	exists, err := s.usernameExists(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, usernameTakenError()
+	}
+	if exists, err = s.users.EmailExists(ctx, email); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, emailTakenError()
+	}
+
+	user, err := authdomain.NewUser(username, email, row.PasswordHash, row.PasswordSalt)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	// Any insert failure is fatal to the batch; see ImportUsers.
+	if err := s.users.Add(ctx, user); err != nil {
+		return nil, err
+	}
+	seenUsernames[usernameKey] = true
+	seenEmails[email] = true
+	return user, nil
+}
+
+// validateImportRow applies the registration rules for usernames and emails.
+// Reserved names and email domain restrictions are not enforced: imports
+// carry over accounts that already exist elsewhere.
+func validateImportRow(row ImportUserRequest) error {
+	if err := validateUsername(row.Username); err != nil {
+		return err
+	}
+	if err := validateEmail(row.Email); err != nil {
+		return err
+	}
+	if strings.TrimSpace(row.PasswordHash) == "" || strings.TrimSpace(row.PasswordSalt) == "" {
+		return ValidationError{Field: FieldPasswordHash, Code: CodeRequired, Message: "Password hash and salt are required."}
+	}
+	if len(row.PasswordHash) > maxImportHashLength || len(row.PasswordSalt) > maxImportSaltLength {
+		return ValidationError{Field: FieldPasswordHash, Code: CodeTooLong, Message: fmt.Sprintf("Password hash must not exceed %d characters and salt %d.", maxImportHashLength, maxImportSaltLength)}
+	}
+	switch strings.TrimSpace(row.Role) {
+	case "", authdomain.RoleUser, authdomain.RoleAdmin, authdomain.RoleService:
+		return nil
+	default:
+		return ValidationError{Field: FieldRole, Code: CodeInvalidFormat, Message: fmt.Sprintf("Role must be one of %s, %s or %s.", authdomain.RoleUser, authdomain.RoleAdmin, authdomain.RoleService)}
+	}
+}
+
+// isRowError reports whether err only concerns one import row.
+func isRowError(err error) bool {
+	var validation ValidationError
+	return errors.As(err, &validation) || IsConflictError(err)
+}
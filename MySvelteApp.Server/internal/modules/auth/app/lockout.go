@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// LockoutPolicy locks an account for Duration once MaxFailures failed logins
+// land within Window of the first. A MaxFailures below one disables lockout.
+type LockoutPolicy struct {
+	MaxFailures int
+	Window      time.Duration
+	Duration    time.Duration
+}
+
+func (p LockoutPolicy) enabled() bool {
+	return p.MaxFailures > 0 && p.Window > 0 && p.Duration > 0
+}
+
+// LoginAttempts is the failed-login state of one account.
+type LoginAttempts struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Locked reports whether the account is locked at now.
+func (a LoginAttempts) Locked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// lockoutKey identifies the account a login targets, matching usernames the
+// way lookups do: "Ash" and "ash" share one counter only when usernames are
+// case-insensitive, since otherwise they are different accounts.
+func (s *Service) lockoutKey(username string) string {
+	if s.options.UsernameCaseInsensitive {
+		return authdomain.NormalizeUsername(username)
+	}
+	return authdomain.CleanUsername(username)
+}
+
+// checkLockout rejects logins to a locked account.
+func (s *Service) checkLockout(ctx context.Context, key string) error {
+	if !s.lockoutEnabled() {
+		return nil
+	}
+	attempts, err := s.options.LoginAttempts.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if now := s.options.Now(); attempts.Locked(now) {
+		return LockedError{Message: "Too many failed login attempts. Please try again later.", RetryAfter: attempts.LockedUntil.Sub(now)}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login towards the account's lockout.
+func (s *Service) recordLoginFailure(ctx context.Context, key string) error {
+	if !s.lockoutEnabled() {
+		return nil
+	}
+	_, err := s.options.LoginAttempts.RecordFailure(ctx, key, s.options.Lockout, s.options.Now())
+	return err
+}
+
+// resetLoginFailures forgets earlier failures after a successful login.
+func (s *Service) resetLoginFailures(ctx context.Context, key string) error {
+	if !s.lockoutEnabled() {
+		return nil
+	}
+	return s.options.LoginAttempts.Reset(ctx, key)
+}
+
+func (s *Service) lockoutEnabled() bool {
+	return s.options.LoginAttempts != nil && s.options.Lockout.enabled()
+}
@@ -0,0 +1,44 @@
+package app
+
+import "context"
+
+// Outcomes reported to the MetricsRecorder.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailure  = "failure"
+	OutcomeLocked   = "locked"
+	OutcomeConflict = "conflict"
+	OutcomeInvalid  = "invalid"
+	OutcomeError    = "error"
+)
+
+// MetricsRecorder counts auth use-case outcomes. Implementations must be safe
+// for concurrent use.
+type MetricsRecorder interface {
+	RecordLogin(ctx context.Context, outcome string)
+	RecordRegistration(ctx context.Context, outcome string)
+}
+
+// NoopMetrics discards every measurement. It is the default recorder.
+type NoopMetrics struct{}
+
+func (NoopMetrics) RecordLogin(context.Context, string)        {}
+func (NoopMetrics) RecordRegistration(context.Context, string) {}
+
+// outcomeOf classifies a use-case result for metrics.
+func outcomeOf(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case IsUnauthorizedError(err):
+		return OutcomeFailure
+	case IsLockedError(err):
+		return OutcomeLocked
+	case IsConflictError(err):
+		return OutcomeConflict
+	case IsValidationError(err):
+		return OutcomeInvalid
+	default:
+		return OutcomeError
+	}
+}
@@ -2,16 +2,74 @@ package app
 
 import (
 	"context"
+	"time"
 
 	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
 )
 
+// UnitOfWork groups repository calls into a single atomic transaction. The
+// callback's context carries the transaction; repository calls made with it
+// participate in the same unit of work.
+type UnitOfWork interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // UserRepository exposes persistence operations required by the auth use-cases.
 type UserRepository interface {
+	UnitOfWork
 	Add(ctx context.Context, user *authdomain.User) error
+	GetByID(ctx context.Context, id uint) (*authdomain.User, error)
 	GetByUsername(ctx context.Context, username string) (*authdomain.User, error)
+	GetByNormalizedUsername(ctx context.Context, normalizedUsername string) (*authdomain.User, error)
 	UsernameExists(ctx context.Context, username string) (bool, error)
+	NormalizedUsernameExists(ctx context.Context, normalizedUsername string) (bool, error)
 	EmailExists(ctx context.Context, email string) (bool, error)
+	// IncrementTokenVersion bumps the user's token version, revoking every
+	// token issued before the call.
+	IncrementTokenVersion(ctx context.Context, id uint) error
+}
+
+// LoginAttemptStore tracks failed logins per account key. Stores shared by
+// several server instances must apply RecordFailure atomically so concurrent
+// failures are never lost.
+type LoginAttemptStore interface {
+	// Get returns the current attempts for key; unknown keys have none.
+	Get(ctx context.Context, key string) (LoginAttempts, error)
+	// RecordFailure counts a failed login at now, starting a new window once
+	// the previous one has passed and locking key when policy's limit is
+	// reached. It returns the resulting attempts.
+	RecordFailure(ctx context.Context, key string, policy LockoutPolicy, now time.Time) (LoginAttempts, error)
+	// Reset forgets every failure recorded for key.
+	Reset(ctx context.Context, key string) error
+	// DeleteExpired removes records whose window and lock ended by now and
+	// returns how many were removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AccountEventLog keeps the auth events of each account.
+type AccountEventLog interface {
+	Append(ctx context.Context, event *authdomain.AccountEvent) error
+	// ListByUser returns a page of the user's events, oldest first.
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]authdomain.AccountEvent, error)
+}
+
+// EmailChangeStore keeps the address a user asked to switch to until the
+// change is confirmed.
+type EmailChangeStore interface {
+	// SetPendingEmail replaces any pending change of the user with email.
+	SetPendingEmail(ctx context.Context, userID uint, email string) error
+	// ConfirmEmail makes the user's pending email their address and clears
+	// the pending change, provided email is still the pending one; it reports
+	// whether it was. It fails with ErrDuplicateEmail when another account
+	// holds the address.
+	ConfirmEmail(ctx context.Context, userID uint, email string) (bool, error)
+}
+
+// AccountMailer sends the emails behind account flows. token is the secret
+// the flow issued and expiresAt when it stops being accepted.
+type AccountMailer interface {
+	SendVerification(ctx context.Context, to, username, token string, expiresAt time.Time) error
+	SendPasswordReset(ctx context.Context, to, username, token string, expiresAt time.Time) error
 }
 
 // PasswordHasher hashes and verifies passwords.
@@ -23,4 +81,19 @@ type PasswordHasher interface {
 // TokenGenerator issues access tokens for authenticated users.
 type TokenGenerator interface {
 	GenerateToken(user *authdomain.User) (string, error)
+	// GenerateTokenWithLifetime issues a token that expires after lifetime
+	// instead of the configured default. Implementations reject lifetimes
+	// above their policy maximum.
+	GenerateTokenWithLifetime(user *authdomain.User, lifetime time.Duration) (string, error)
+}
+
+// TokenValidator verifies access tokens and resolves the caller they were issued to.
+type TokenValidator interface {
+	ValidateToken(token string) (*Principal, error)
+}
+
+// Authenticator resolves the caller behind an access token, applying checks
+// that need current user state. Failures to authenticate are UnauthorizedError.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
 }
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// SeedAdmin creates an administrator account unless the username is already
+// taken, in which case the existing account is left untouched. It reports
+// whether a user was created. Input is validated with the registration rules.
+func (s *Service) SeedAdmin(ctx context.Context, cmd SeedAdminRequest) (bool, error) {
+	if err := validateRegister(RegisterRequest(cmd)); err != nil {
+		return false, err
+	}
+	username := authdomain.CleanUsername(cmd.Username)
+
+	exists, err := s.usernameExists(ctx, username)
+	if err != nil || exists {
+		return false, err
+	}
+
+	hash, salt, err := s.hasher.HashPassword(cmd.Password)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := authdomain.NewUser(username, s.normalizeEmail(cmd.Email), hash, salt)
+	if err != nil {
+		return false, err
+	}
+	user.Role = authdomain.RoleAdmin
+
+	// Another instance may seed concurrently; losing that race is not an error.
+	err = s.users.Add(ctx, user)
+	if errors.Is(err, ErrDuplicateUsername) || errors.Is(err, ErrDuplicateEmail) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -2,11 +2,19 @@ package app
 
 import (
 	"context"
-	"regexp"
+	"errors"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	"mysvelteapp/server_new/internal/platform/kvstore"
+	"mysvelteapp/server_new/internal/platform/validation"
 )
 
 const (
@@ -15,29 +23,99 @@ const (
 	maxPasswordLength = 512
 )
 
-var (
-	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
-	emailRegex    = regexp.MustCompile(`^[^\s@]+@[^\s@.]+\.[^\s@.]+$`)
-)
+// Options tunes how the service normalises user input.
+type Options struct {
+	// LowercaseWholeEmail lowercases the local part of email addresses as well
+	// as the domain, matching the behaviour of earlier releases.
+	LowercaseWholeEmail bool
+	// UsernameCaseInsensitive matches usernames on their lowercase form so that
+	// "Alice" and "alice" resolve to the same account. Display casing is kept.
+	UsernameCaseInsensitive bool
+	// TokenLifetimes overrides the access token lifetime for users holding the
+	// given role. Roles without an entry receive the generator's default.
+	TokenLifetimes map[string]time.Duration
+	// RememberMeLifetime is the access token lifetime for logins that set
+	// RememberMe. It only applies when longer than the lifetime the user would
+	// otherwise get; zero ignores RememberMe.
+	RememberMeLifetime time.Duration
+	// AllowedEmailDomains limits registration to addresses at these domains or
+	// their subdomains, compared case-insensitively. Empty allows any domain.
+	AllowedEmailDomains []string
+	// ReservedUsernames cannot be registered, compared case-insensitively.
+	// Seeding an admin account bypasses the list.
+	ReservedUsernames []string
+	// Events receives the domain events of registration, login and logout.
+	// Nil disables publishing.
+	Events EventBus
+	// AccountEvents holds the auth history each user can export. It is
+	// filled by an Events subscriber; nil leaves exports without history.
+	AccountEvents AccountEventLog
+	// Metrics counts login and registration outcomes. Nil uses NoopMetrics.
+	Metrics MetricsRecorder
+	// LoginAttempts and Lockout lock accounts after repeated failed logins.
+	// A nil store or a disabled policy turns lockout off.
+	LoginAttempts LoginAttemptStore
+	Lockout       LockoutPolicy
+	// EmailChanges, Tokens and Mailer back ChangeEmail and
+	// ConfirmEmailChange; both fail while any is nil. Tokens holds the
+	// emailed tokens for EmailChangeTTL; zero uses DefaultEmailChangeTTL.
+	EmailChanges   EmailChangeStore
+	Tokens         kvstore.Store
+	Mailer         AccountMailer
+	EmailChangeTTL time.Duration
+	// Now reports the current time for lockout decisions and event timestamps.
+	// Nil uses time.Now.
+	Now func() time.Time
+}
 
 // Service exposes the authentication use-cases.
 type Service struct {
-	users  UserRepository
-	hasher PasswordHasher
-	tokens TokenGenerator
+	users   UserRepository
+	hasher  PasswordHasher
+	tokens  TokenGenerator
+	tracer  trace.Tracer
+	options Options
+
+	dummyOnce sync.Once
+	dummyHash string
+	dummySalt string
 }
 
-// NewService wires the service dependencies.
-func NewService(users UserRepository, hasher PasswordHasher, tokens TokenGenerator) *Service {
+// NewService wires the service dependencies. A nil tracer disables tracing.
+func NewService(users UserRepository, hasher PasswordHasher, tokens TokenGenerator, tracer trace.Tracer, options Options) *Service {
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer("")
+	}
+	if options.Metrics == nil {
+		options.Metrics = NoopMetrics{}
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	if options.EmailChangeTTL <= 0 {
+		options.EmailChangeTTL = DefaultEmailChangeTTL
+	}
 	return &Service{
-		users:  users,
-		hasher: hasher,
-		tokens: tokens,
+		users:   users,
+		hasher:  hasher,
+		tokens:  tokens,
+		tracer:  tracer,
+		options: options,
 	}
 }
 
 // Register creates a new user account when the command is valid.
 func (s *Service) Register(ctx context.Context, cmd RegisterRequest) (*AuthSuccess, error) {
+	ctx, span := s.tracer.Start(ctx, "auth.Register")
+	defer span.End()
+
+	result, err := s.register(ctx, span, cmd)
+	recordSpanError(span, err)
+	s.options.Metrics.RecordRegistration(ctx, outcomeOf(err))
+	return result, err
+}
+
+func (s *Service) register(ctx context.Context, span trace.Span, cmd RegisterRequest) (*AuthSuccess, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -45,41 +123,78 @@ func (s *Service) Register(ctx context.Context, cmd RegisterRequest) (*AuthSucce
 	if err := validateRegister(cmd); err != nil {
 		return nil, err
 	}
-
-	trimmedUsername := strings.TrimSpace(cmd.Username)
-	normalizedEmail := strings.ToLower(strings.TrimSpace(cmd.Email))
-
-	exists, err := s.users.UsernameExists(ctx, trimmedUsername)
-	if err != nil {
+	if err := s.validateReservedUsername(cmd.Username); err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, ConflictError{Message: "This username is already taken. Please choose a different one."}
-	}
-
-	emailExists, err := s.users.EmailExists(ctx, normalizedEmail)
-	if err != nil {
+	if err := s.validateEmailDomain(cmd.Email); err != nil {
 		return nil, err
 	}
-	if emailExists {
-		return nil, ConflictError{Message: "This email is already registered. Please use a different email address."}
-	}
 
+	trimmedUsername := authdomain.CleanUsername(cmd.Username)
+	normalizedEmail := s.normalizeEmail(cmd.Email)
+
+	// Hash before opening the transaction so the connection is not held during
+	// the deliberately expensive hashing step.
+	_, hashSpan := s.tracer.Start(ctx, "auth.PasswordHasher.HashPassword")
 	hash, salt, err := s.hasher.HashPassword(cmd.Password)
+	endSpan(hashSpan, err)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := authdomain.NewUser(trimmedUsername, normalizedEmail, hash, salt)
-	if err != nil {
-		return nil, err
-	}
+	var user *authdomain.User
+	err = s.users.WithinTransaction(ctx, func(ctx context.Context) error {
+		exists, err := s.usernameExists(ctx, trimmedUsername)
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.Bool("auth.username_exists", exists))
+		if exists {
+			return usernameTakenError()
+		}
+
+		emailCtx, emailSpan := s.tracer.Start(ctx, "auth.UserRepository.EmailExists")
+		emailExists, err := s.users.EmailExists(emailCtx, normalizedEmail)
+		endSpan(emailSpan, err)
+		if err != nil {
+			return err
+		}
+		span.SetAttributes(attribute.Bool("auth.email_exists", emailExists))
+		if emailExists {
+			return emailTakenError()
+		}
+
+		user, err = authdomain.NewUser(trimmedUsername, normalizedEmail, hash, salt)
+		if err != nil {
+			return err
+		}
 
-	if err := s.users.Add(ctx, user); err != nil {
+		// The existence checks above give friendly errors for the common case, but a
+		// concurrent submit can still slip between check and insert; rely on the
+		// unique constraints to close that gap.
+		addCtx, addSpan := s.tracer.Start(ctx, "auth.UserRepository.Add")
+		err = s.users.Add(addCtx, user)
+		endSpan(addSpan, err)
+		switch {
+		case errors.Is(err, ErrDuplicateUsername):
+			return usernameTakenError()
+		case errors.Is(err, ErrDuplicateEmail):
+			return emailTakenError()
+		}
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.Int64("auth.user_id", int64(user.ID)))
+	s.publish(ctx, UserRegistered{
+		UserID:     user.ID,
+		Username:   user.Username,
+		Email:      user.Email,
+		OccurredAt: s.options.Now().UTC(),
+	})
 
-	token, err := s.tokens.GenerateToken(user)
+	token, lifetime, err := s.generateToken(ctx, user, false)
 	if err != nil {
 		return nil, err
 	}
@@ -88,11 +203,22 @@ func (s *Service) Register(ctx context.Context, cmd RegisterRequest) (*AuthSucce
 		Token:    token,
 		UserID:   user.ID,
 		Username: user.Username,
+		Lifetime: lifetime,
 	}, nil
 }
 
 // Login authenticates an existing user with the provided credentials.
 func (s *Service) Login(ctx context.Context, cmd LoginRequest) (*AuthSuccess, error) {
+	ctx, span := s.tracer.Start(ctx, "auth.Login")
+	defer span.End()
+
+	result, err := s.login(ctx, span, cmd)
+	recordSpanError(span, err)
+	s.options.Metrics.RecordLogin(ctx, outcomeOf(err))
+	return result, err
+}
+
+func (s *Service) login(ctx context.Context, span trace.Span, cmd LoginRequest) (*AuthSuccess, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -101,83 +227,320 @@ func (s *Service) Login(ctx context.Context, cmd LoginRequest) (*AuthSuccess, er
 		return nil, err
 	}
 
-	trimmedUsername := strings.TrimSpace(cmd.Username)
+	trimmedUsername := authdomain.CleanUsername(cmd.Username)
+	// Unknown usernames are locked the same way, so lockout does not reveal
+	// which accounts exist.
+	key := s.lockoutKey(trimmedUsername)
+	if err := s.checkLockout(ctx, key); err != nil {
+		return nil, err
+	}
 
-	user, err := s.users.GetByUsername(ctx, trimmedUsername)
+	user, err := s.findByUsername(ctx, trimmedUsername)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.Bool("auth.user_found", user != nil))
 	if user == nil {
+		// Verify against a throwaway hash so unknown usernames cost the same as
+		// a wrong password; otherwise response timing reveals which usernames exist.
+		s.verifyDummyPassword(ctx, cmd.Password)
+		s.publish(ctx, LoginFailed{
+			Username:   trimmedUsername,
+			Reason:     LoginFailureUnknownUser,
+			OccurredAt: s.options.Now().UTC(),
+		})
+		if err := s.recordLoginFailure(ctx, key); err != nil {
+			return nil, err
+		}
 		return nil, unauthorizedError()
 	}
 
+	_, verifySpan := s.tracer.Start(ctx, "auth.PasswordHasher.VerifyPassword")
 	valid, err := s.hasher.VerifyPassword(cmd.Password, user.PasswordHash, user.PasswordSalt)
+	endSpan(verifySpan, err)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attribute.Bool("auth.password_valid", valid))
 	if !valid {
+		s.publish(ctx, LoginFailed{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Reason:     LoginFailureWrongPassword,
+			OccurredAt: s.options.Now().UTC(),
+		})
+		if err := s.recordLoginFailure(ctx, key); err != nil {
+			return nil, err
+		}
 		return nil, unauthorizedError()
 	}
+	if err := s.resetLoginFailures(ctx, key); err != nil {
+		return nil, err
+	}
 
-	token, err := s.tokens.GenerateToken(user)
+	token, lifetime, err := s.generateToken(ctx, user, cmd.RememberMe)
 	if err != nil {
 		return nil, err
 	}
+	s.publish(ctx, UserLoggedIn{UserID: user.ID, Username: user.Username, OccurredAt: s.options.Now().UTC()})
 
 	return &AuthSuccess{
 		Token:    token,
 		UserID:   user.ID,
 		Username: user.Username,
+		Lifetime: lifetime,
 	}, nil
 }
 
+// LogoutAll revokes every token issued to the user so far.
+func (s *Service) LogoutAll(ctx context.Context, userID uint) error {
+	ctx, span := s.tracer.Start(ctx, "auth.LogoutAll")
+	defer span.End()
+
+	err := s.users.IncrementTokenVersion(ctx, userID)
+	recordSpanError(span, err)
+	if err == nil {
+		s.publish(ctx, UserLoggedOutEverywhere{UserID: userID, OccurredAt: s.options.Now().UTC()})
+	}
+	return err
+}
+
+// GetUser returns the account with the given ID for administrative lookups.
+func (s *Service) GetUser(ctx context.Context, id uint) (*authdomain.User, error) {
+	ctx, span := s.tracer.Start(ctx, "auth.GetUser")
+	defer span.End()
+
+	user, err := s.users.GetByID(ctx, id)
+	if err == nil && user == nil {
+		err = NotFoundError{Message: "User not found."}
+	}
+	recordSpanError(span, err)
+	return user, err
+}
+
+// ListAccountEvents returns a page of the auth events recorded for the user,
+// oldest first.
+func (s *Service) ListAccountEvents(ctx context.Context, userID uint, offset, limit int) ([]authdomain.AccountEvent, error) {
+	if s.options.AccountEvents == nil {
+		return nil, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "auth.ListAccountEvents")
+	defer span.End()
+
+	events, err := s.options.AccountEvents.ListByUser(ctx, userID, offset, limit)
+	recordSpanError(span, err)
+	return events, err
+}
+
 func validateRegister(cmd RegisterRequest) error {
-	username := strings.TrimSpace(cmd.Username)
-	switch {
-	case username == "":
-		return ValidationError{Message: "Username is required."}
-	case len(username) < minUsernameLength:
-		return ValidationError{Message: "Username must be at least 3 characters long."}
-	case len(username) > authdomain.MaxUsernameLength:
-		return ValidationError{Message: "Username must not exceed 64 characters."}
-	case !usernameRegex.MatchString(username):
-		return ValidationError{Message: "Username can only contain letters, numbers, and underscores."}
-	}
-
-	email := strings.TrimSpace(cmd.Email)
-	switch {
-	case email == "":
-		return ValidationError{Message: "Email is required."}
-	case len(email) > authdomain.MaxEmailLength:
-		return ValidationError{Message: "Email must not exceed 320 characters."}
-	case strings.Contains(email, ".."):
-		return ValidationError{Message: "Please enter a valid email address."}
-	case !emailRegex.MatchString(email):
-		return ValidationError{Message: "Please enter a valid email address."}
-	}
-
-	switch {
-	case strings.TrimSpace(cmd.Password) == "":
-		return ValidationError{Message: "Password is required."}
-	case len(cmd.Password) < minPasswordLength:
-		return ValidationError{Message: "Password must be at least 8 characters long."}
-	case len(cmd.Password) > maxPasswordLength:
-		return ValidationError{Message: "Password must not exceed 512 characters."}
-	case !passwordMeetsRequirements(cmd.Password):
-		return ValidationError{Message: "Password must contain at least one uppercase letter, one lowercase letter, and one number."}
+	var v validation.Validator
+	v.Field(FieldUsername, authdomain.CleanUsername(cmd.Username), usernameRules...)
+	v.Field(FieldEmail, strings.TrimSpace(cmd.Email), emailRules...)
+	v.Field(FieldPassword, cmd.Password, passwordRules...)
+	return firstValidationError(&v)
+}
+
+// usernameRules check the cleaned username. Lengths count characters, not
+// bytes, and letters and digits from any script are allowed.
+var usernameRules = []validation.Rule{
+	validation.Required("Username is required."),
+	validation.MinLen(minUsernameLength, "Username must be at least 3 characters long."),
+	validation.MaxLen(authdomain.MaxUsernameLength, "Username must not exceed 64 characters."),
+	validation.Check(CodeInvalidFormat, "Username can only contain letters, numbers, and underscores.", isUsernameText),
+}
+
+// emailRules check the trimmed address. The length limit is in bytes to
+// match the column.
+var emailRules = []validation.Rule{
+	validation.Required("Email is required."),
+	validation.MaxBytes(authdomain.MaxEmailLength, "Email must not exceed 320 characters."),
+	validation.Email("Please enter a valid email address."),
+}
+
+var passwordRules = []validation.Rule{
+	validation.Required("Password is required."),
+	validation.MinBytes(minPasswordLength, "Password must be at least 8 characters long."),
+	validation.MaxBytes(maxPasswordLength, "Password must not exceed 512 characters."),
+	validation.Check(CodeTooWeak, "Password must contain at least one uppercase letter, one lowercase letter, and one number.", passwordMeetsRequirements),
+}
+
+func validateUsername(username string) error {
+	var v validation.Validator
+	v.Field(FieldUsername, authdomain.CleanUsername(username), usernameRules...)
+	return firstValidationError(&v)
+}
+
+// isUsernameText accepts letters, decimal digits and underscores, plus
+// combining marks that extend a letter. NFC precomposes most Latin accents,
+// but scripts such as Devanagari write vowel signs and viramas as marks that
+// have no precomposed form, so they must be allowed after a letter. A mark
+// may follow another mark so stacked signs are accepted too.
+func isUsernameText(username string) bool {
+	afterLetter := false
+	for _, r := range username {
+		switch {
+		case unicode.IsLetter(r):
+			afterLetter = true
+		case unicode.In(r, unicode.Mn, unicode.Mc):
+			if !afterLetter {
+				return false
+			}
+		case unicode.IsDigit(r) || r == '_':
+			afterLetter = false
+		default:
+			return false
+		}
 	}
+	return true
+}
+
+func validateEmail(email string) error {
+	var v validation.Validator
+	v.Field(FieldEmail, strings.TrimSpace(email), emailRules...)
+	return firstValidationError(&v)
+}
+
+// firstValidationError converts the validator's first failure, if any, into
+// the ValidationError the API maps to a 400.
+func firstValidationError(v *validation.Validator) error {
+	if fieldErr, ok := v.First(); ok {
+		return ValidationError(fieldErr)
+	}
+	return nil
+}
 
+// validateReservedUsername enforces Options.ReservedUsernames on a username
+// that already passed validateUsername.
+func (s *Service) validateReservedUsername(username string) error {
+	username = authdomain.CleanUsername(username)
+	for _, reserved := range s.options.ReservedUsernames {
+		if strings.EqualFold(username, reserved) {
+			return ValidationError{Field: FieldUsername, Code: CodeReserved, Message: "This username is reserved. Please choose another."}
+		}
+	}
 	return nil
 }
 
+// validateEmailDomain enforces Options.AllowedEmailDomains on an address that
+// already passed validateEmail.
+func (s *Service) validateEmailDomain(email string) error {
+	if len(s.options.AllowedEmailDomains) == 0 {
+		return nil
+	}
+
+	email = strings.TrimSpace(email)
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+	for _, allowed := range s.options.AllowedEmailDomains {
+		allowed = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(allowed), "@"))
+		if allowed == "" {
+			continue
+		}
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return nil
+		}
+	}
+	return ValidationError{Field: FieldEmail, Code: CodeDomainNotAllowed, Message: "Registration is not open to this email domain."}
+}
+
+// UsernameAvailable reports whether username is free to register. The input is
+// validated and matched exactly as Register would.
+func (s *Service) UsernameAvailable(ctx context.Context, username string) (bool, error) {
+	if err := validateUsername(username); err != nil {
+		return false, err
+	}
+	if err := s.validateReservedUsername(username); err != nil {
+		return false, err
+	}
+	exists, err := s.usernameExists(ctx, authdomain.CleanUsername(username))
+	return !exists, err
+}
+
+// EmailAvailable reports whether email is free to register. The input is
+// validated and normalised exactly as Register would.
+func (s *Service) EmailAvailable(ctx context.Context, email string) (available bool, err error) {
+	if err := validateEmail(email); err != nil {
+		return false, err
+	}
+	if err := s.validateEmailDomain(email); err != nil {
+		return false, err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "auth.UserRepository.EmailExists")
+	defer func() { endSpan(span, err) }()
+
+	taken, err := s.users.EmailExists(ctx, s.normalizeEmail(email))
+	return !taken, err
+}
+
 func validateLogin(cmd LoginRequest) error {
-	if strings.TrimSpace(cmd.Username) == "" {
-		return ValidationError{Message: "Username is required."}
+	var v validation.Validator
+	v.Field(FieldUsername, cmd.Username, validation.Required("Username is required."))
+	v.Field(FieldPassword, cmd.Password, validation.Required("Password is required."))
+	return firstValidationError(&v)
+}
+
+func (s *Service) usernameExists(ctx context.Context, username string) (exists bool, err error) {
+	ctx, span := s.tracer.Start(ctx, "auth.UserRepository.UsernameExists")
+	defer func() { endSpan(span, err) }()
+
+	if s.options.UsernameCaseInsensitive {
+		return s.users.NormalizedUsernameExists(ctx, authdomain.NormalizeUsername(username))
 	}
-	if strings.TrimSpace(cmd.Password) == "" {
-		return ValidationError{Message: "Password is required."}
+	return s.users.UsernameExists(ctx, username)
+}
+
+func (s *Service) findByUsername(ctx context.Context, username string) (user *authdomain.User, err error) {
+	ctx, span := s.tracer.Start(ctx, "auth.UserRepository.GetByUsername")
+	defer func() { endSpan(span, err) }()
+
+	if s.options.UsernameCaseInsensitive {
+		return s.users.GetByNormalizedUsername(ctx, authdomain.NormalizeUsername(username))
 	}
-	return nil
+	return s.users.GetByUsername(ctx, username)
+}
+
+// verifyDummyPassword runs the hasher against credentials that can never match.
+// The dummy hash is produced by the configured hasher on first use so it has the
+// same format and cost as real stored hashes.
+func (s *Service) verifyDummyPassword(ctx context.Context, password string) {
+	s.dummyOnce.Do(func() {
+		s.dummyHash, s.dummySalt, _ = s.hasher.HashPassword("dummy-password-for-timing-equalisation")
+	})
+	if s.dummyHash == "" {
+		return
+	}
+
+	_, span := s.tracer.Start(ctx, "auth.PasswordHasher.VerifyPassword")
+	_, err := s.hasher.VerifyPassword(password, s.dummyHash, s.dummySalt)
+	endSpan(span, err)
+}
+
+// generateToken issues the user's access token and reports its lifetime, or
+// zero when the generator's default was used.
+func (s *Service) generateToken(ctx context.Context, user *authdomain.User, rememberMe bool) (string, time.Duration, error) {
+	_, span := s.tracer.Start(ctx, "auth.TokenGenerator.GenerateToken")
+	lifetime := s.options.TokenLifetimes[user.Role]
+	if rememberMe && s.options.RememberMeLifetime > lifetime {
+		lifetime = s.options.RememberMeLifetime
+	}
+	span.SetAttributes(attribute.Bool("auth.remember_me", rememberMe))
+	var token string
+	var err error
+	if lifetime > 0 {
+		token, err = s.tokens.GenerateTokenWithLifetime(user, lifetime)
+	} else {
+		token, err = s.tokens.GenerateToken(user)
+	}
+	endSpan(span, err)
+	return token, lifetime, err
+}
+
+func (s *Service) normalizeEmail(email string) string {
+	normalized := authdomain.NormalizeEmail(email)
+	if s.options.LowercaseWholeEmail {
+		normalized = strings.ToLower(normalized)
+	}
+	return normalized
 }
 
 func passwordMeetsRequirements(password string) bool {
@@ -195,6 +558,14 @@ func passwordMeetsRequirements(password string) bool {
 	return hasUpper && hasLower && hasDigit
 }
 
+func usernameTakenError() error {
+	return ConflictError{Message: "This username is already taken. Please choose a different one."}
+}
+
+func emailTakenError() error {
+	return ConflictError{Message: "This email is already registered. Please use a different email address."}
+}
+
 func unauthorizedError() error {
 	return UnauthorizedError{Message: "Invalid username or password. Please check your credentials and try again."}
 }
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpanError marks the span as failed. Error messages produced by this
+// package never include credentials, so they are safe to attach.
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// endSpan records err (if any) and ends the span.
+func endSpan(span trace.Span, err error) {
+	recordSpanError(span, err)
+	span.End()
+}
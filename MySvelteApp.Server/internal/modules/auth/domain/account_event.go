@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// AccountEvent is an auth event kept against the account it concerns, so a
+// user can review and export their own sign-in history. Reason is set for
+// failed logins only.
+type AccountEvent struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null;index"`
+	Type       string    `gorm:"size:32;not null"`
+	Reason     string    `gorm:"size:32"`
+	OccurredAt time.Time `gorm:"not null"`
+}
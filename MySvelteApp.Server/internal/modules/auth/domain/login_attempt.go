@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// LoginAttempt counts recent failed logins for one normalized username so an
+// account can be locked across every server instance sharing the database.
+type LoginAttempt struct {
+	Username    string    `gorm:"primaryKey;size:64"`
+	Failures    int       `gorm:"not null;default:0"`
+	WindowStart time.Time `gorm:"not null"`
+	// LockedUntil is the zero time unless the account is or was locked.
+	LockedUntil time.Time
+	// ExpiresAt is when neither the failure window nor the lock matters any
+	// more and the row may be deleted.
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
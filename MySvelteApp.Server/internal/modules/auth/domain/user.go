@@ -5,24 +5,43 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
-	// MaxUsernameLength mirrors the legacy constraints.
+	// MaxUsernameLength mirrors the legacy constraints, counted in characters
+	// (runes) rather than bytes.
 	MaxUsernameLength = 64
 	// MaxEmailLength mirrors the legacy constraints.
 	MaxEmailLength = 320
 )
 
+// Roles a user can hold.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleService marks accounts used by integrations rather than people.
+	RoleService = "service"
+)
+
 // User represents an authenticated user persisted in the system.
 type User struct {
-	ID           uint      `gorm:"primaryKey"`
-	Username     string    `gorm:"size:64;uniqueIndex;not null"`
-	Email        string    `gorm:"size:320;uniqueIndex;not null"`
-	PasswordHash string    `gorm:"size:512;not null"`
-	PasswordSalt string    `gorm:"size:256;not null"`
+	ID                 uint   `gorm:"primaryKey"`
+	Username           string `gorm:"size:64;uniqueIndex;not null"`
+	NormalizedUsername string `gorm:"size:64;index;not null;default:''"`
+	Email              string `gorm:"size:320;uniqueIndex;not null"`
+	PasswordHash       string `gorm:"size:512;not null"`
+	PasswordSalt       string `gorm:"size:256;not null"`
+	Role               string `gorm:"size:32;not null;default:'user'"`
+	// TokenVersion is embedded in issued tokens; bumping it revokes them all.
+	TokenVersion int       `gorm:"not null;default:0"`
 	CreatedAt    time.Time `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	// PendingEmail is the address the user asked to switch to. Email stays in
+	// use until the change is confirmed with the emailed token.
+	PendingEmail string `gorm:"size:320;not null;default:''"`
 }
 
 // NewUser enforces invariants before creating a User aggregate.
@@ -31,7 +50,7 @@ func NewUser(username, email, passwordHash, passwordSalt string) (*User, error)
 	if len(username) == 0 {
 		return nil, errors.New("username cannot be empty")
 	}
-	if len(username) > MaxUsernameLength {
+	if utf8.RuneCountInString(username) > MaxUsernameLength {
 		return nil, fmt.Errorf("username must not exceed %d characters", MaxUsernameLength)
 	}
 
@@ -42,19 +61,44 @@ func NewUser(username, email, passwordHash, passwordSalt string) (*User, error)
 		return nil, errors.New("password salt cannot be empty")
 	}
 
-	trimmedEmail := strings.TrimSpace(email)
-	if len(trimmedEmail) == 0 {
+	normalizedEmail := NormalizeEmail(email)
+	if len(normalizedEmail) == 0 {
 		return nil, errors.New("email cannot be empty")
 	}
-	normalizedEmail := strings.ToLower(trimmedEmail)
 	if len(normalizedEmail) > MaxEmailLength {
 		return nil, fmt.Errorf("email must not exceed %d characters", MaxEmailLength)
 	}
 
 	return &User{
-		Username:     username,
-		Email:        normalizedEmail,
-		PasswordHash: passwordHash,
-		PasswordSalt: passwordSalt,
+		Username:           username,
+		NormalizedUsername: NormalizeUsername(username),
+		Email:              normalizedEmail,
+		PasswordHash:       passwordHash,
+		PasswordSalt:       passwordSalt,
+		Role:               RoleUser,
 	}, nil
 }
+
+// CleanUsername trims username and puts it in Unicode normalization form C,
+// so the same visible name is stored and matched identically whichever way
+// the client composed its accents.
+func CleanUsername(username string) string {
+	return norm.NFC.String(strings.TrimSpace(username))
+}
+
+// NormalizeUsername returns the cleaned, lowercase form used to compare
+// usernames case-insensitively.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(CleanUsername(username))
+}
+
+// NormalizeEmail trims the address and lowercases its domain. The local part
+// keeps its casing because RFC 5321 treats it as case-sensitive.
+func NormalizeEmail(email string) string {
+	trimmed := strings.TrimSpace(email)
+	at := strings.LastIndex(trimmed, "@")
+	if at < 0 {
+		return trimmed
+	}
+	return trimmed[:at] + strings.ToLower(trimmed[at:])
+}
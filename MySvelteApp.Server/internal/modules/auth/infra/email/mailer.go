@@ -0,0 +1,58 @@
+package email
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	platformemail "mysvelteapp/server_new/internal/platform/email"
+)
+
+// Frontend paths that consume the emailed tokens, relative to the app base URL.
+const (
+	verificationPath  = "/verify-email"
+	passwordResetPath = "/reset-password"
+)
+
+var _ authapp.AccountMailer = (*Mailer)(nil)
+
+// Mailer renders account emails and hands them to a platform email sender.
+type Mailer struct {
+	sender    platformemail.Sender
+	templates *Templates
+	baseURL   *url.URL
+	now       func() time.Time
+}
+
+// NewMailer wires a sender and templates into the mailer. Links in the emails
+// point at baseURL, the address users reach the frontend on.
+func NewMailer(sender platformemail.Sender, templates *Templates, baseURL *url.URL) *Mailer {
+	return &Mailer{sender: sender, templates: templates, baseURL: baseURL, now: time.Now}
+}
+
+// SendVerification emails username a link confirming their address.
+func (m *Mailer) SendVerification(ctx context.Context, to, username, token string, expiresAt time.Time) error {
+	return m.send(ctx, TemplateVerification, verificationPath, to, username, token, expiresAt)
+}
+
+// SendPasswordReset emails username a link for choosing a new password.
+func (m *Mailer) SendPasswordReset(ctx context.Context, to, username, token string, expiresAt time.Time) error {
+	return m.send(ctx, TemplatePasswordReset, passwordResetPath, to, username, token, expiresAt)
+}
+
+func (m *Mailer) send(ctx context.Context, name, path, to, username, token string, expiresAt time.Time) error {
+	link := m.baseURL.JoinPath(path)
+	link.RawQuery = url.Values{"token": {token}}.Encode()
+
+	rendered, err := m.templates.Render(name, TemplateData{
+		Username:  username,
+		Link:      link.String(),
+		ExpiresAt: expiresAt,
+		ExpiresIn: describeDuration(expiresAt.Sub(m.now())),
+	})
+	if err != nil {
+		return err
+	}
+	return m.sender.Send(ctx, platformemail.Message{To: to, Subject: rendered.Subject, Body: rendered.HTML, HTML: true})
+}
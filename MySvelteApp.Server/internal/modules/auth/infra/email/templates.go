@@ -0,0 +1,116 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Template names, matching the files under templates/ and in an override
+// directory.
+const (
+	TemplateVerification  = "verification"
+	TemplatePasswordReset = "password_reset"
+)
+
+//go:embed templates/*.html
+var defaultTemplates embed.FS
+
+// TemplateData is substituted into an email template.
+type TemplateData struct {
+	Username  string
+	Link      string
+	ExpiresAt time.Time
+	// ExpiresIn is the remaining lifetime of Link in words, e.g. "24 hours".
+	ExpiresIn string
+}
+
+// Rendered is a filled-in email.
+type Rendered struct {
+	Subject string
+	HTML    string
+}
+
+// Templates renders account emails. Each template defines a "subject" and a
+// "body"; the body is HTML and values are escaped for its context.
+type Templates struct {
+	sets map[string]*template.Template
+}
+
+// NewTemplates parses the embedded templates. When overrideDir is set, a
+// <name>.html file there replaces the embedded template of that name, so
+// deployments can rebrand or translate emails without a rebuild.
+func NewTemplates(overrideDir string) (*Templates, error) {
+	templates := &Templates{sets: make(map[string]*template.Template, 2)}
+	for _, name := range []string{TemplateVerification, TemplatePasswordReset} {
+		var source fs.FS = defaultTemplates
+		path := "templates/" + name + ".html"
+		if overrideDir != "" {
+			if _, err := os.Stat(filepath.Join(overrideDir, name+".html")); err == nil {
+				source, path = os.DirFS(overrideDir), name+".html"
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("read email template override %s: %w", name, err)
+			}
+		}
+
+		set, err := template.New(name).Option("missingkey=error").ParseFS(source, path)
+		if err != nil {
+			return nil, fmt.Errorf("parse email template %s: %w", name, err)
+		}
+		for _, part := range []string{"subject", "body"} {
+			if set.Lookup(part) == nil {
+				return nil, fmt.Errorf("email template %s does not define %q", name, part)
+			}
+		}
+		templates.sets[name] = set
+	}
+	return templates, nil
+}
+
+// Render fills the named template with data.
+func (t *Templates) Render(name string, data TemplateData) (Rendered, error) {
+	set, ok := t.sets[name]
+	if !ok {
+		return Rendered{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := set.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Rendered{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := set.ExecuteTemplate(&body, "body", data); err != nil {
+		return Rendered{}, fmt.Errorf("render %s body: %w", name, err)
+	}
+	// The subject is a plain header, so undo the HTML escaping.
+	return Rendered{
+		Subject: strings.TrimSpace(html.UnescapeString(subject.String())),
+		HTML:    strings.TrimSpace(body.String()),
+	}, nil
+}
+
+// describeDuration words d for an email, rounding to whole hours or minutes.
+func describeDuration(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return plural(int(d.Round(time.Hour)/time.Hour), "hour")
+	case d >= time.Minute:
+		return plural(int(d.Round(time.Minute)/time.Minute), "minute")
+	default:
+		return "less than a minute"
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// RecordAccountEvents subscribes log to every auth event that concerns a
+// known account. Failed logins for unknown usernames belong to no account
+// and are not kept.
+func RecordAccountEvents(bus *Bus, log authapp.AccountEventLog) {
+	bus.Subscribe(func(ctx context.Context, event authapp.DomainEvent) error {
+		record := authdomain.AccountEvent{Type: event.EventName()}
+		switch e := event.(type) {
+		case authapp.UserRegistered:
+			record.UserID, record.OccurredAt = e.UserID, e.OccurredAt
+		case authapp.UserLoggedIn:
+			record.UserID, record.OccurredAt = e.UserID, e.OccurredAt
+		case authapp.LoginFailed:
+			record.UserID, record.Reason, record.OccurredAt = e.UserID, e.Reason, e.OccurredAt
+		case authapp.UserLoggedOutEverywhere:
+			record.UserID, record.OccurredAt = e.UserID, e.OccurredAt
+		}
+		if record.UserID == 0 {
+			return nil
+		}
+		return log.Append(ctx, &record)
+	}, authapp.EventUserRegistered, authapp.EventLoginSucceeded, authapp.EventLoginFailed, authapp.EventLogoutAll)
+}
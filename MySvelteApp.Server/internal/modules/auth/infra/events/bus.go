@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+)
+
+var _ authapp.EventBus = (*Bus)(nil)
+
+// Handler reacts to one published domain event.
+type Handler func(ctx context.Context, event authapp.DomainEvent) error
+
+// Bus delivers domain events synchronously, calling the handlers subscribed
+// to an event's name in subscription order before Publish returns. A failing
+// or panicking handler is reported to the bus's error callback and does not
+// stop the remaining handlers or the use-case that published the event.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	onError  func(event authapp.DomainEvent, err error)
+}
+
+// NewBus creates an empty bus. onError receives handler failures; nil
+// discards them.
+func NewBus(onError func(event authapp.DomainEvent, err error)) *Bus {
+	if onError == nil {
+		onError = func(authapp.DomainEvent, error) {}
+	}
+	return &Bus{handlers: make(map[string][]Handler), onError: onError}
+}
+
+// Subscribe calls handler for every event published under each of names.
+func (b *Bus) Subscribe(handler Handler, names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range names {
+		b.handlers[name] = append(b.handlers[name], handler)
+	}
+}
+
+// Publish runs the handlers subscribed to event.
+func (b *Bus) Publish(ctx context.Context, event authapp.DomainEvent) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := runHandler(ctx, handler, event); err != nil {
+			b.onError(event, err)
+		}
+	}
+}
+
+func runHandler(ctx context.Context, handler Handler, event authapp.DomainEvent) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%s handler panicked: %v", event.EventName(), recovered)
+		}
+	}()
+	return handler(ctx, event)
+}
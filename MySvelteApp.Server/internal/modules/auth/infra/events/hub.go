@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+)
+
+// DefaultSubscriberBuffer is the number of events queued per subscriber
+// before further events are dropped for it.
+const DefaultSubscriberBuffer = 64
+
+var _ authapp.EventSubscriber = (*Hub)(nil)
+
+// Hub fans auth events out to in-process subscribers. Publishing never
+// blocks: a subscriber whose buffer is full misses the event instead of
+// stalling the login that produced it.
+type Hub struct {
+	mu          sync.Mutex
+	buffer      int
+	subscribers map[chan authapp.AuthEvent]struct{}
+	closed      bool
+	dropped     uint64
+}
+
+// NewHub creates a hub queueing up to buffer events per subscriber. A
+// non-positive buffer uses DefaultSubscriberBuffer.
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultSubscriberBuffer
+	}
+	return &Hub{buffer: buffer, subscribers: make(map[chan authapp.AuthEvent]struct{})}
+}
+
+// Publish delivers event to every subscriber with room for it.
+func (h *Hub) Publish(event authapp.AuthEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			h.dropped++
+		}
+	}
+}
+
+// StreamFrom subscribes the hub to the login and logout events on bus.
+func (h *Hub) StreamFrom(bus *Bus) {
+	bus.Subscribe(h.handle, authapp.EventLoginSucceeded, authapp.EventLoginFailed, authapp.EventLogoutAll)
+}
+
+func (h *Hub) handle(_ context.Context, event authapp.DomainEvent) error {
+	stream := authapp.AuthEvent{Type: event.EventName()}
+	switch e := event.(type) {
+	case authapp.UserLoggedIn:
+		stream.UserID, stream.Username, stream.OccurredAt = e.UserID, e.Username, e.OccurredAt
+	case authapp.LoginFailed:
+		stream.UserID, stream.Username, stream.OccurredAt = e.UserID, e.Username, e.OccurredAt
+	case authapp.UserLoggedOutEverywhere:
+		stream.UserID, stream.OccurredAt = e.UserID, e.OccurredAt
+	default:
+		return fmt.Errorf("hub cannot stream %T", event)
+	}
+	h.Publish(stream)
+	return nil
+}
+
+// Subscribe registers a subscriber. Call the returned function to
+// unsubscribe; it is safe to call more than once. After Close the channel is
+// returned already closed.
+func (h *Hub) Subscribe() (<-chan authapp.AuthEvent, func()) {
+	ch := make(chan authapp.AuthEvent, h.buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	h.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Dropped reports how many deliveries were skipped because a subscriber
+// was too slow.
+func (h *Hub) Dropped() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.dropped
+}
+
+// Close ends every subscription so long-lived streams return, which lets
+// http.Server.Shutdown finish. Later publishes are discarded.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for ch := range h.subscribers {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	platformmetrics "mysvelteapp/server_new/internal/platform/metrics"
+)
+
+var _ authapp.MetricsRecorder = (*PrometheusRecorder)(nil)
+
+// PrometheusRecorder counts auth outcomes in a metrics registry served on
+// /metrics.
+type PrometheusRecorder struct {
+	logins        *platformmetrics.CounterVec
+	registrations *platformmetrics.CounterVec
+}
+
+// NewPrometheusRecorder registers the auth counters in registry.
+func NewPrometheusRecorder(registry *platformmetrics.Registry) *PrometheusRecorder {
+	return &PrometheusRecorder{
+		logins:        registry.Counter("auth_logins_total", "Login attempts by outcome.", "outcome"),
+		registrations: registry.Counter("auth_registrations_total", "Registration attempts by outcome.", "outcome"),
+	}
+}
+
+// RecordLogin counts a login attempt.
+func (r *PrometheusRecorder) RecordLogin(_ context.Context, outcome string) {
+	r.logins.Inc(outcome)
+}
+
+// RecordRegistration counts a registration attempt.
+func (r *PrometheusRecorder) RecordRegistration(_ context.Context, outcome string) {
+	r.registrations.Inc(outcome)
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+var _ authapp.AccountEventLog = (*GormAccountEventLog)(nil)
+
+// GormAccountEventLog stores account events in the database.
+type GormAccountEventLog struct {
+	db    *gorm.DB
+	retry platformpersistence.RetryOptions
+}
+
+// NewGormAccountEventLog constructs a log backed by GORM. Appends failing
+// with transient errors are retried according to retry.
+func NewGormAccountEventLog(db *gorm.DB, retry platformpersistence.RetryOptions) *GormAccountEventLog {
+	return &GormAccountEventLog{db: db, retry: retry}
+}
+
+// Append stores event.
+func (l *GormAccountEventLog) Append(ctx context.Context, event *authdomain.AccountEvent) error {
+	return platformpersistence.Retry(ctx, l.retry, func() error {
+		return platformpersistence.Conn(ctx, l.db).Create(event).Error
+	})
+}
+
+// ListByUser returns a page of the user's events, oldest first.
+func (l *GormAccountEventLog) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]authdomain.AccountEvent, error) {
+	var events []authdomain.AccountEvent
+	err := platformpersistence.Conn(ctx, l.db).
+		Where("user_id = ?", userID).
+		Order("occurred_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&events).
+		Error
+	return events, err
+}
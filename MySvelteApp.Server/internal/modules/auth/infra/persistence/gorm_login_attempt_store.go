@@ -0,0 +1,130 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+var _ authapp.LoginAttemptStore = (*GormLoginAttemptStore)(nil)
+
+// GormLoginAttemptStore keeps failed-login counts in the database so every
+// server instance sharing it enforces the same lockout.
+type GormLoginAttemptStore struct {
+	db    *gorm.DB
+	retry platformpersistence.RetryOptions
+}
+
+// NewGormLoginAttemptStore constructs a store backed by GORM. Writes failing
+// with transient errors are retried according to retry.
+func NewGormLoginAttemptStore(db *gorm.DB, retry platformpersistence.RetryOptions) *GormLoginAttemptStore {
+	return &GormLoginAttemptStore{db: db, retry: retry}
+}
+
+// Get returns the attempts recorded for key.
+func (s *GormLoginAttemptStore) Get(ctx context.Context, key string) (authapp.LoginAttempts, error) {
+	var attempt authdomain.LoginAttempt
+	err := platformpersistence.Conn(ctx, s.db).Where("username = ?", key).Take(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return authapp.LoginAttempts{}, nil
+	}
+	if err != nil {
+		return authapp.LoginAttempts{}, err
+	}
+	return attemptsOf(attempt), nil
+}
+
+// RecordFailure counts a failed login for key. The increment is a single
+// UPDATE inside a transaction, which holds the row (or, on SQLite, the
+// database) until the window and lock are settled, so concurrent failures
+// from any instance are all counted.
+func (s *GormLoginAttemptStore) RecordFailure(ctx context.Context, key string, policy authapp.LockoutPolicy, now time.Time) (authapp.LoginAttempts, error) {
+	now = now.UTC()
+	var attempt authdomain.LoginAttempt
+	err := platformpersistence.Retry(ctx, s.retry, func() error {
+		return platformpersistence.WithinTransaction(ctx, s.db, func(ctx context.Context) error {
+			tx := platformpersistence.Conn(ctx, s.db)
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&authdomain.LoginAttempt{Username: key, WindowStart: now, ExpiresAt: now.Add(policy.Window)}).
+				Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&authdomain.LoginAttempt{}).
+				Where("username = ?", key).
+				UpdateColumn("failures", gorm.Expr("failures + 1")).
+				Error; err != nil {
+				return err
+			}
+			if err := tx.Where("username = ?", key).Take(&attempt).Error; err != nil {
+				return err
+			}
+
+			if !now.Before(attempt.WindowStart.Add(policy.Window)) {
+				attempt.Failures, attempt.WindowStart = 1, now
+			}
+			if policy.MaxFailures > 0 && attempt.Failures >= policy.MaxFailures {
+				// The lock replaces the window: once it ends the account gets
+				// a full set of attempts again.
+				attempt.Failures, attempt.WindowStart = 0, now
+				attempt.LockedUntil = now.Add(policy.Duration)
+			}
+			attempt.ExpiresAt = attempt.WindowStart.Add(policy.Window)
+			if attempt.LockedUntil.After(attempt.ExpiresAt) {
+				attempt.ExpiresAt = attempt.LockedUntil
+			}
+			return tx.Save(&attempt).Error
+		})
+	})
+	if err != nil {
+		return authapp.LoginAttempts{}, fmt.Errorf("record failed login: %w", err)
+	}
+	return attemptsOf(attempt), nil
+}
+
+// Reset deletes the record for key.
+func (s *GormLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return platformpersistence.Retry(ctx, s.retry, func() error {
+		return platformpersistence.Conn(ctx, s.db).Where("username = ?", key).Delete(&authdomain.LoginAttempt{}).Error
+	})
+}
+
+// DeleteExpired removes records that no longer affect any login.
+func (s *GormLoginAttemptStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := platformpersistence.Retry(ctx, s.retry, func() error {
+		result := platformpersistence.Conn(ctx, s.db).Where("expires_at <= ?", now.UTC()).Delete(&authdomain.LoginAttempt{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// RunCleanup calls DeleteExpired every interval until ctx is done, passing
+// each failure to onError. It blocks, so run it in its own goroutine.
+func (s *GormLoginAttemptStore) RunCleanup(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.DeleteExpired(ctx, now); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func attemptsOf(attempt authdomain.LoginAttempt) authapp.LoginAttempts {
+	return authapp.LoginAttempts{Failures: attempt.Failures, LockedUntil: attempt.LockedUntil}
+}
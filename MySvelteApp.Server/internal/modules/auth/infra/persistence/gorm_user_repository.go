@@ -5,43 +5,193 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	authapp "mysvelteapp/server_new/internal/modules/auth/app"
 	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
 )
 
-var _ authapp.UserRepository = (*GormUserRepository)(nil)
+var (
+	_ authapp.UserRepository   = (*GormUserRepository)(nil)
+	_ authapp.EmailChangeStore = (*GormUserRepository)(nil)
+)
 
-// GormUserRepository persists users using GORM.
+// GormUserRepository persists users using GORM. Every error it returns is a
+// *platformpersistence.RepositoryError naming the failed method.
 type GormUserRepository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	retry        platformpersistence.RetryOptions
+	queryTimeout time.Duration
+}
+
+// Option customises a GormUserRepository.
+type Option func(*GormUserRepository)
+
+// WithQueryTimeout bounds each repository call whose context has no deadline.
+// A non-positive timeout leaves such calls unbounded.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(r *GormUserRepository) {
+		r.queryTimeout = timeout
+	}
 }
 
-// NewGormUserRepository constructs a repository backed by GORM.
-func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
-	return &GormUserRepository{db: db}
+// NewGormUserRepository constructs a repository backed by GORM. Writes failing
+// with transient errors are retried according to retry. Calls without a
+// deadline are bounded by platformpersistence.DefaultQueryTimeout unless
+// overridden with WithQueryTimeout.
+func NewGormUserRepository(db *gorm.DB, retry platformpersistence.RetryOptions, opts ...Option) *GormUserRepository {
+	repository := &GormUserRepository{
+		db:           db,
+		retry:        retry,
+		queryTimeout: platformpersistence.DefaultQueryTimeout,
+	}
+	for _, opt := range opts {
+		opt(repository)
+	}
+	return repository
+}
+
+// WithinTransaction runs fn inside a database transaction shared by every
+// repository call made with the context it receives. Statements inside fn are
+// not retried individually; instead the whole transaction is re-run when it
+// fails with a transient error, so fn must be safe to call again.
+func (r *GormUserRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return platformpersistence.Retry(ctx, r.retry, func() error {
+		return platformpersistence.WithinTransaction(ctx, r.db, fn)
+	})
 }
 
 // Add inserts the provided user into the database.
 func (r *GormUserRepository) Add(ctx context.Context, user *authdomain.User) error {
 	if user == nil {
-		return fmt.Errorf("user cannot be nil")
+		return platformpersistence.WrapError("Add", fmt.Errorf("user cannot be nil"))
 	}
-	return r.db.WithContext(ctx).Create(user).Error
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	err := platformpersistence.Retry(ctx, r.retry, func() error {
+		return platformpersistence.Conn(ctx, r.db).Create(user).Error
+	})
+	return platformpersistence.WrapError("Add", translateUniqueViolation(err))
+}
+
+// GetByID fetches a user by primary key; returns nil when not found.
+func (r *GormUserRepository) GetByID(ctx context.Context, id uint) (*authdomain.User, error) {
+	if id == 0 {
+		return nil, platformpersistence.WrapError("GetByID", fmt.Errorf("user id cannot be zero"))
+	}
+	return r.getBy(ctx, "GetByID", "id", id)
 }
 
 // GetByUsername fetches a user by username; returns nil when not found.
 func (r *GormUserRepository) GetByUsername(ctx context.Context, username string) (*authdomain.User, error) {
 	trimmed := strings.TrimSpace(username)
 	if trimmed == "" {
-		return nil, fmt.Errorf("username cannot be blank")
+		return nil, platformpersistence.WrapError("GetByUsername", fmt.Errorf("username cannot be blank"))
+	}
+	return r.getBy(ctx, "GetByUsername", "username", trimmed)
+}
+
+// GetByNormalizedUsername fetches a user by the lowercase username form; returns nil when not found.
+func (r *GormUserRepository) GetByNormalizedUsername(ctx context.Context, normalizedUsername string) (*authdomain.User, error) {
+	trimmed := strings.TrimSpace(normalizedUsername)
+	if trimmed == "" {
+		return nil, platformpersistence.WrapError("GetByNormalizedUsername", fmt.Errorf("username cannot be blank"))
+	}
+	return r.getBy(ctx, "GetByNormalizedUsername", "normalized_username", trimmed)
+}
+
+// UsernameExists checks whether a username is already stored.
+func (r *GormUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	trimmed := strings.TrimSpace(username)
+	if trimmed == "" {
+		return false, platformpersistence.WrapError("UsernameExists", fmt.Errorf("username cannot be blank"))
+	}
+	return r.existsBy(ctx, "UsernameExists", "username", trimmed)
+}
+
+// NormalizedUsernameExists checks whether any username matches the lowercase form.
+func (r *GormUserRepository) NormalizedUsernameExists(ctx context.Context, normalizedUsername string) (bool, error) {
+	trimmed := strings.TrimSpace(normalizedUsername)
+	if trimmed == "" {
+		return false, platformpersistence.WrapError("NormalizedUsernameExists", fmt.Errorf("username cannot be blank"))
+	}
+	return r.existsBy(ctx, "NormalizedUsernameExists", "normalized_username", trimmed)
+}
+
+// EmailExists checks whether an email address is already stored.
+func (r *GormUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	trimmed := strings.TrimSpace(email)
+	if trimmed == "" {
+		return false, platformpersistence.WrapError("EmailExists", fmt.Errorf("email cannot be blank"))
 	}
+	return r.existsBy(ctx, "EmailExists", "email", trimmed)
+}
+
+// IncrementTokenVersion bumps the stored token version in a single statement
+// so concurrent calls each take effect.
+func (r *GormUserRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	err := platformpersistence.Retry(ctx, r.retry, func() error {
+		return platformpersistence.Conn(ctx, r.db).
+			Model(&authdomain.User{}).
+			Where("id = ?", id).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).
+			Error
+	})
+	return platformpersistence.WrapError("IncrementTokenVersion", err)
+}
+
+// SetPendingEmail stores the user's pending email change, replacing any
+// earlier one.
+func (r *GormUserRepository) SetPendingEmail(ctx context.Context, userID uint, email string) error {
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	err := platformpersistence.Retry(ctx, r.retry, func() error {
+		return platformpersistence.Conn(ctx, r.db).
+			Model(&authdomain.User{}).
+			Where("id = ?", userID).
+			Update("pending_email", email).
+			Error
+	})
+	return platformpersistence.WrapError("SetPendingEmail", err)
+}
+
+// ConfirmEmail moves the pending email into email in a single statement,
+// provided it still matches, and clears the pending change. The unique index
+// on email rejects addresses another account took in the meantime.
+func (r *GormUserRepository) ConfirmEmail(ctx context.Context, userID uint, email string) (bool, error) {
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	var confirmed bool
+	err := platformpersistence.Retry(ctx, r.retry, func() error {
+		result := platformpersistence.Conn(ctx, r.db).
+			Model(&authdomain.User{}).
+			Where("id = ? AND pending_email = ? AND pending_email <> ?", userID, email, "").
+			Updates(map[string]any{
+				"email":         gorm.Expr("pending_email"),
+				"pending_email": "",
+			})
+		confirmed = result.RowsAffected > 0
+		return result.Error
+	})
+	return confirmed, platformpersistence.WrapError("ConfirmEmail", translateUniqueViolation(err))
+}
+
+func (r *GormUserRepository) getBy(ctx context.Context, op, column string, value any) (*authdomain.User, error) {
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
 	var user authdomain.User
-	err := r.db.WithContext(ctx).
-		Where("username = ?", trimmed).
+	err := platformpersistence.Conn(ctx, r.db).
+		Where(column+" = ?", value).
 		Take(&user).
 		Error
 
@@ -49,46 +199,44 @@ func (r *GormUserRepository) GetByUsername(ctx context.Context, username string)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, platformpersistence.WrapError(op, err)
 	}
 
 	return &user, nil
 }
 
-// UsernameExists checks whether a username is already stored.
-func (r *GormUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
-	trimmed := strings.TrimSpace(username)
-	if trimmed == "" {
-		return false, fmt.Errorf("username cannot be blank")
-	}
+func (r *GormUserRepository) existsBy(ctx context.Context, op, column, value string) (bool, error) {
+	ctx, cancel := platformpersistence.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
 	var count int64
-	if err := r.db.WithContext(ctx).
+	if err := platformpersistence.Conn(ctx, r.db).
 		Model(&authdomain.User{}).
-		Where("username = ?", trimmed).
+		Where(column+" = ?", value).
 		Count(&count).
 		Error; err != nil {
-		return false, err
+		return false, platformpersistence.WrapError(op, err)
 	}
 
 	return count > 0, nil
 }
 
-// EmailExists checks whether an email address is already stored.
-func (r *GormUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
-	trimmed := strings.TrimSpace(email)
-	if trimmed == "" {
-		return false, fmt.Errorf("email cannot be blank")
+// translateUniqueViolation maps unique constraint failures onto the sentinel
+// errors understood by the auth service.
+func translateUniqueViolation(err error) error {
+	violation, ok := platformpersistence.AsUniqueViolation(err)
+	if !ok {
+		return err
 	}
 
-	var count int64
-	if err := r.db.WithContext(ctx).
-		Model(&authdomain.User{}).
-		Where("email = ?", trimmed).
-		Count(&count).
-		Error; err != nil {
-		return false, err
+	switch {
+	// The unique normalized_username index added for case-insensitive
+	// usernames guards the same rule as the username one.
+	case violation.Involves("normalized_username"), violation.Involves("username"):
+		return fmt.Errorf("%w: %v", authapp.ErrDuplicateUsername, err)
+	case violation.Involves("email"):
+		return fmt.Errorf("%w: %v", authapp.ErrDuplicateEmail, err)
+	default:
+		return err
 	}
-
-	return count > 0, nil
 }
@@ -11,7 +11,11 @@ import (
 	authapp "mysvelteapp/server_new/internal/modules/auth/app"
 )
 
-const defaultSaltSize = 64
+const (
+	defaultSaltSize = 64
+	// MinSaltSize is the smallest salt, in bytes, a hasher may be configured with.
+	MinSaltSize = 16
+)
 
 var _ authapp.PasswordHasher = (*HMACPasswordHasher)(nil)
 
@@ -25,6 +29,16 @@ func NewHMACPasswordHasher() *HMACPasswordHasher {
 	return &HMACPasswordHasher{saltSize: defaultSaltSize}
 }
 
+// NewHMACPasswordHasherWithSaltSize constructs a hasher generating salts of
+// size bytes. Hashes verify regardless of the salt size they were created with,
+// so the size can change without invalidating stored passwords.
+func NewHMACPasswordHasherWithSaltSize(size int) (*HMACPasswordHasher, error) {
+	if size < MinSaltSize {
+		return nil, fmt.Errorf("salt size must be at least %d bytes, got %d", MinSaltSize, size)
+	}
+	return &HMACPasswordHasher{saltSize: size}, nil
+}
+
 // HashPassword generates a base64-encoded hash and salt.
 func (h *HMACPasswordHasher) HashPassword(password string) (string, string, error) {
 	if password == "" {
@@ -72,5 +86,7 @@ func (h *HMACPasswordHasher) VerifyPassword(password, storedHash, storedSalt str
 
 	computed := mac.Sum(nil)
 
+	// hmac.Equal compares in constant time so timing does not reveal how much
+	// of the hash matched.
 	return hmac.Equal(computed, decodedHash), nil
 }
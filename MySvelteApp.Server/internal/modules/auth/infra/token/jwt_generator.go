@@ -11,53 +11,79 @@ import (
 	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
 )
 
-var _ authapp.TokenGenerator = (*JWTTokenGenerator)(nil)
+var (
+	_ authapp.TokenGenerator = (*JWTTokenGenerator)(nil)
+	_ authapp.TokenValidator = (*JWTTokenGenerator)(nil)
+)
 
 // JWTTokenGenerator implements TokenGenerator using github.com/golang-jwt/jwt/v5.
 type JWTTokenGenerator struct {
 	options    JWTOptions
 	signingKey []byte
+	verifier   *Verifier
+	now        func() time.Time
+	claims     ClaimsFunc
 }
 
 // NewJWTTokenGenerator validates the provided options and prepares a generator instance.
-func NewJWTTokenGenerator(options JWTOptions) (*JWTTokenGenerator, error) {
-	if err := options.Validate(); err != nil {
-		return nil, err
-	}
-
-	keyBytes, err := DecodeKey(options.Key)
+func NewJWTTokenGenerator(options JWTOptions, opts ...Option) (*JWTTokenGenerator, error) {
+	verifier, err := NewVerifier(options, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("decode key: %w", err)
+		return nil, err
 	}
 
 	return &JWTTokenGenerator{
 		options:    options,
-		signingKey: keyBytes,
+		signingKey: verifier.signingKey,
+		verifier:   verifier,
+		now:        verifier.now,
+		claims:     newSettings(opts).claims,
 	}, nil
 }
 
-// GenerateToken produces a signed JWT for the supplied user entity.
+// GenerateToken produces a signed JWT for the supplied user entity using the
+// configured access token lifetime.
 func (g *JWTTokenGenerator) GenerateToken(user *authdomain.User) (string, error) {
+	return g.GenerateTokenWithLifetime(user, time.Duration(g.options.AccessTokenLifetimeHours)*time.Hour)
+}
+
+// GenerateTokenWithLifetime produces a signed JWT that expires after lifetime.
+// Lifetimes must be positive and no longer than MaxAccessTokenLifetime.
+func (g *JWTTokenGenerator) GenerateTokenWithLifetime(user *authdomain.User, lifetime time.Duration) (string, error) {
 	if user == nil {
 		return "", fmt.Errorf("user must not be nil")
 	}
+	if lifetime <= 0 || lifetime > MaxAccessTokenLifetime {
+		return "", fmt.Errorf("token lifetime %s must be between 0 and %s", lifetime, MaxAccessTokenLifetime)
+	}
 
-	now := time.Now().UTC()
-	expiresAt := now.Add(time.Duration(g.options.AccessTokenLifetimeHours) * time.Hour)
+	now := g.now().UTC()
+	expiresAt := now.Add(lifetime)
 
-	claims := authClaims{
-		Username: user.Username,
-		NameID:   fmt.Sprintf("%d", user.ID),
+	userID := fmt.Sprintf("%d", user.ID)
+	claims := Claims{
+		TokenVersion: user.TokenVersion,
+		CustomClaims: g.claims(user),
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   fmt.Sprintf("%d", user.ID),
+			Subject:   userID,
 			Issuer:    g.options.Issuer,
-			Audience:  []string{g.options.Audience},
+			Audience:  []string{g.options.Audiences[0]},
 			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			ID:        uuid.NewString(),
 		},
 	}
 
+	if g.options.ClaimNames != ClaimNamesStandard {
+		claims.Username = user.Username
+		claims.NameID = userID
+	}
+	if g.options.ClaimNames == ClaimNamesStandard || g.options.ClaimNames == ClaimNamesBoth {
+		claims.StandardUsername = user.Username
+		claims.StandardUserID = userID
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	signedToken, err := token.SignedString(g.signingKey)
@@ -68,8 +94,23 @@ func (g *JWTTokenGenerator) GenerateToken(user *authdomain.User) (string, error)
 	return signedToken, nil
 }
 
-type authClaims struct {
-	Username string `json:"name"`
-	NameID   string `json:"nameid"`
-	jwt.RegisteredClaims
+// ValidateToken verifies a token produced by GenerateToken and returns the user
+// it identifies.
+func (g *JWTTokenGenerator) ValidateToken(tokenString string) (*authapp.Principal, error) {
+	claims, err := g.verifier.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &authapp.Principal{
+		UserID:       userID,
+		Username:     claims.Name(),
+		TokenVersion: claims.TokenVersion,
+		ExpiresAt:    claims.ExpiresAt.Time,
+	}, nil
 }
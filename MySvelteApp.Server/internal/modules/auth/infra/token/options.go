@@ -5,14 +5,91 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
 )
 
 // JWTOptions controls how tokens are generated.
 type JWTOptions struct {
-	Key                      string
-	Issuer                   string
-	Audience                 string
+	Key string
+	// PreviousKeys are retired signing keys that verification still accepts,
+	// so rotating Key does not invalidate tokens already issued. New tokens
+	// are always signed with Key.
+	PreviousKeys []string
+	Issuer       string
+	// Audiences lists the accepted aud values. Generated tokens carry only the
+	// first (primary) audience; verification accepts any of them.
+	Audiences                []string
 	AccessTokenLifetimeHours int
+	// Leeway tolerates clock skew between services when checking the nbf and
+	// exp claims. Zero requires exact agreement.
+	Leeway time.Duration
+	// ClaimNames selects which user claims tokens carry: ClaimNamesLegacy
+	// (the default when empty), ClaimNamesStandard or ClaimNamesBoth. sub
+	// always holds the user ID.
+	ClaimNames string
+}
+
+// Claim name sets for JWTOptions.ClaimNames.
+const (
+	// ClaimNamesLegacy issues name and nameid, as the original .NET app did.
+	ClaimNamesLegacy = "legacy"
+	// ClaimNamesStandard issues username and userId instead.
+	ClaimNamesStandard = "standard"
+	// ClaimNamesBoth issues both sets so old and new clients can read tokens
+	// during a migration.
+	ClaimNamesBoth = "both"
+)
+
+const (
+	maxLeeway = 5 * time.Minute
+	// MaxAccessTokenLifetime caps every issued token, including per-user overrides.
+	MaxAccessTokenLifetime = 168 * time.Hour
+)
+
+// Option customises a JWTTokenGenerator or Verifier.
+type Option func(*settings)
+
+type settings struct {
+	now    func() time.Time
+	claims ClaimsFunc
+}
+
+// ClaimsFunc derives the custom claims embedded in a user's token.
+type ClaimsFunc func(user *authdomain.User) CustomClaims
+
+// DefaultClaims embeds the user's role.
+func DefaultClaims(user *authdomain.User) CustomClaims {
+	return CustomClaims{Role: user.Role}
+}
+
+// WithCustomClaims replaces DefaultClaims as the source of custom claims, e.g.
+// to add a tenant ID or email-verified flag held outside the user record.
+func WithCustomClaims(claims ClaimsFunc) Option {
+	return func(s *settings) {
+		if claims != nil {
+			s.claims = claims
+		}
+	}
+}
+
+// WithClock overrides the time source used to stamp and check tokens. Tests use
+// it to move time forward without sleeping.
+func WithClock(now func() time.Time) Option {
+	return func(s *settings) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{now: time.Now, claims: DefaultClaims}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
 }
 
 // Validate ensures all fields are populated and sufficiently strong.
@@ -27,16 +104,38 @@ func (o JWTOptions) Validate() error {
 	if len(keyBytes) < 32 {
 		return errors.New("jwt: key must be at least 32 bytes after decoding")
 	}
+	for i, key := range o.PreviousKeys {
+		previous, err := decodeKey(key)
+		if err != nil {
+			return fmt.Errorf("jwt: invalid previous key %d: %w", i+1, err)
+		}
+		if len(previous) < 32 {
+			return fmt.Errorf("jwt: previous key %d must be at least 32 bytes after decoding", i+1)
+		}
+	}
 
 	if strings.TrimSpace(o.Issuer) == "" {
 		return errors.New("jwt: issuer must be provided")
 	}
-	if strings.TrimSpace(o.Audience) == "" {
+	if len(o.Audiences) == 0 {
 		return errors.New("jwt: audience must be provided")
 	}
-	if o.AccessTokenLifetimeHours < 1 || o.AccessTokenLifetimeHours > 168 {
+	for _, audience := range o.Audiences {
+		if strings.TrimSpace(audience) == "" {
+			return errors.New("jwt: audiences must not be empty")
+		}
+	}
+	if o.AccessTokenLifetimeHours < 1 || time.Duration(o.AccessTokenLifetimeHours)*time.Hour > MaxAccessTokenLifetime {
 		return errors.New("jwt: access token lifetime must be between 1 and 168 hours")
 	}
+	if o.Leeway < 0 || o.Leeway > maxLeeway {
+		return errors.New("jwt: leeway must be between 0 and 5 minutes")
+	}
+	switch o.ClaimNames {
+	case "", ClaimNamesLegacy, ClaimNamesStandard, ClaimNamesBoth:
+	default:
+		return fmt.Errorf("jwt: claim names must be %s, %s or %s", ClaimNamesLegacy, ClaimNamesStandard, ClaimNamesBoth)
+	}
 
 	return nil
 }
@@ -0,0 +1,114 @@
+package token
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims are the JWT claims issued by JWTTokenGenerator. Username and NameID
+// are the legacy claim names; StandardUsername and StandardUserID carry the
+// same values under the names newer clients expect. JWTOptions.ClaimNames
+// picks which are issued.
+type Claims struct {
+	Username         string `json:"name,omitempty"`
+	NameID           string `json:"nameid,omitempty"`
+	StandardUsername string `json:"username,omitempty"`
+	StandardUserID   string `json:"userId,omitempty"`
+	// TokenVersion is the user's token version at issue time. Tokens issued
+	// before versioning omit it and read as version zero.
+	TokenVersion int `json:"ver,omitempty"`
+	CustomClaims
+	jwt.RegisteredClaims
+}
+
+// CustomClaims carries application claims that let downstream services act on
+// the caller without looking the user up. Empty values are omitted from the
+// token. Add new claims here so they flow through signing and parsing alike.
+type CustomClaims struct {
+	Role          string `json:"role,omitempty"`
+	TenantID      string `json:"tid,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+// Name returns the username from whichever claim the token carries.
+func (c Claims) Name() string {
+	if c.StandardUsername != "" {
+		return c.StandardUsername
+	}
+	return c.Username
+}
+
+// UserID returns the numeric user ID carried in the subject claim.
+func (c Claims) UserID() (uint, error) {
+	userID, err := strconv.ParseUint(c.Subject, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, fmt.Errorf("invalid subject %q", c.Subject)
+	}
+	return uint(userID), nil
+}
+
+// Verifier parses and validates tokens issued with the same JWTOptions.
+type Verifier struct {
+	options    JWTOptions
+	signingKey []byte
+	// keys accepts the current signing key first, then any previous keys.
+	keys jwt.VerificationKeySet
+	now  func() time.Time
+}
+
+// NewVerifier validates the provided options and prepares a verifier.
+func NewVerifier(options JWTOptions, opts ...Option) (*Verifier, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := DecodeKey(options.Key)
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+
+	keys := jwt.VerificationKeySet{Keys: []jwt.VerificationKey{keyBytes}}
+	for _, key := range options.PreviousKeys {
+		previous, err := DecodeKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("decode previous key: %w", err)
+		}
+		keys.Keys = append(keys.Keys, previous)
+	}
+
+	return &Verifier{
+		options:    options,
+		signingKey: keyBytes,
+		keys:       keys,
+		now:        newSettings(opts).now,
+	}, nil
+}
+
+// Parse checks the signature, algorithm, issuer, audience, and expiry of
+// tokenString and returns its claims. The token must name at least one of the
+// configured audiences and be signed with the current key or one of the
+// previous keys. Errors wrap the jwt package's sentinel
+// errors, e.g. jwt.ErrTokenExpired or jwt.ErrTokenSignatureInvalid.
+func (v *Verifier) Parse(tokenString string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenString, &claims,
+		func(*jwt.Token) (any, error) { return v.keys, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(v.options.Issuer),
+		jwt.WithAudience(v.options.Audiences...),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(v.now),
+		jwt.WithLeeway(v.options.Leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
+	if _, err := claims.UserID(); err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+	return &claims, nil
+}
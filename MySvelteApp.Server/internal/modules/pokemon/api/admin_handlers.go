@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// AdminHandlers exposes Pokemon maintenance endpoints for administrators.
+type AdminHandlers struct {
+	counts pokemonapp.CountRefresher
+}
+
+// NewAdminHandlers wires a count refresher into the admin handlers.
+func NewAdminHandlers(counts pokemonapp.CountRefresher) *AdminHandlers {
+	return &AdminHandlers{counts: counts}
+}
+
+// RefreshCount godoc
+// @Summary Refresh the Pokemon count
+// @Description Re-fetches the number of Pokemon from PokeAPI, replacing the cached count used for random picks
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} PokemonCountResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 403 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 502 {object} httpapi.ErrorResponse
+// @Router /admin/pokemon/refresh-count [post]
+func (h *AdminHandlers) RefreshCount(c *gin.Context) {
+	count, err := h.counts.RefreshCount(c.Request.Context())
+	if err != nil {
+		if httpapi.WriteContextError(c, err) {
+			return
+		}
+		if pokemonapp.IsUpstreamError(err) {
+			httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+			return
+		}
+		httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to refresh Pokemon count")
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusOK, PokemonCountResponse{Count: count})
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+const defaultListPageSize = 20
+
+// CatalogHandlers exposes HTTP endpoints for browsing Pokemon.
+type CatalogHandlers struct {
+	service *pokemonapp.CatalogService
+}
+
+// NewCatalogHandlers wires the catalog service into HTTP handlers.
+func NewCatalogHandlers(service *pokemonapp.CatalogService) *CatalogHandlers {
+	return &CatalogHandlers{service: service}
+}
+
+// ListPokemon godoc
+// @Summary List Pokemon
+// @Description Returns a page of every Pokemon known to the configured source
+// @Tags pokemon
+// @Produce json
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} httpapi.PagedResponse[PokemonSummaryResponse]
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 502 {object} httpapi.ErrorResponse
+// @Router /pokemon [get]
+func (h *CatalogHandlers) ListPokemon(c *gin.Context) {
+	page, err := httpapi.ParsePageQuery(c, defaultListPageSize, pokemonapp.MaxListLimit)
+	if err != nil {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		return
+	}
+
+	summaries, total, err := h.service.List(c.Request.Context(), page.Offset(), page.Limit())
+	if err != nil {
+		if httpapi.WriteContextError(c, err) {
+			return
+		}
+		switch {
+		case pokemonapp.IsValidationError(err):
+			httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		case pokemonapp.IsUpstreamError(err):
+			httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+		default:
+			httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to list Pokemon")
+		}
+		return
+	}
+
+	items := make([]PokemonSummaryResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		items = append(items, PokemonSummaryResponse{Name: summary.Name})
+	}
+	httpapi.WriteJSON(c, http.StatusOK, httpapi.NewPagedResponse(items, page.Offset(), page.Limit(), total))
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+)
+
+// FavoritesExportSection contributes the user's favorites to their data
+// export, in the same shape the favorites endpoints return.
+func FavoritesExportSection(service *pokemonapp.FavoritesService) authapi.ExportSection {
+	return authapi.ExportSection{
+		Name: "favorites",
+		Export: func(ctx context.Context, userID uint, emit func(record any) error) error {
+			for offset := 0; ; offset += authapi.ExportPageSize {
+				favorites, _, err := service.List(ctx, userID, offset, authapi.ExportPageSize)
+				if err != nil {
+					return err
+				}
+				for _, favorite := range favorites {
+					if err := emit(toFavoriteResponse(favorite)); err != nil {
+						return err
+					}
+				}
+				if len(favorites) < authapi.ExportPageSize {
+					return nil
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+const (
+	defaultFavoritesPageSize = 20
+	maxFavoritesPageSize     = 100
+)
+
+// FavoritesHandlers exposes HTTP endpoints for a user's favorite Pokemon.
+type FavoritesHandlers struct {
+	service *pokemonapp.FavoritesService
+}
+
+// NewFavoritesHandlers wires the favorites service into HTTP handlers.
+func NewFavoritesHandlers(service *pokemonapp.FavoritesService) *FavoritesHandlers {
+	return &FavoritesHandlers{service: service}
+}
+
+// AddFavorite godoc
+// @Summary Save a favorite Pokemon
+// @Description Stores a Pokemon in the caller's favorites along with its current type and image
+// @Tags pokemon
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddFavoriteRequest true "Favorite"
+// @Success 201 {object} FavoritePokemonResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 409 {object} httpapi.ErrorResponse
+// @Router /pokemon/favorites [post]
+func (h *FavoritesHandlers) AddFavorite(c *gin.Context) {
+	principal, ok := authapi.PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	var req AddFavoriteRequest
+	if !httpapi.BindJSON(c, &req) {
+		return
+	}
+
+	favorite, err := h.service.Add(c.Request.Context(), principal.UserID, req.Name)
+	if err != nil {
+		writeFavoriteError(c, err)
+		return
+	}
+
+	httpapi.WriteJSON(c, http.StatusCreated, toFavoriteResponse(*favorite))
+}
+
+// ListFavorites godoc
+// @Summary List favorite Pokemon
+// @Description Returns a page of the Pokemon saved by the caller, oldest first
+// @Tags pokemon
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Items per page (max 100)" default(20)
+// @Success 200 {object} httpapi.PagedResponse[FavoritePokemonResponse]
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Router /pokemon/favorites [get]
+func (h *FavoritesHandlers) ListFavorites(c *gin.Context) {
+	principal, ok := authapi.PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	page, err := httpapi.ParsePageQuery(c, defaultFavoritesPageSize, maxFavoritesPageSize)
+	if err != nil {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		return
+	}
+
+	favorites, total, err := h.service.List(c.Request.Context(), principal.UserID, page.Offset(), page.Limit())
+	if err != nil {
+		writeFavoriteError(c, err)
+		return
+	}
+
+	items := make([]FavoritePokemonResponse, 0, len(favorites))
+	for _, favorite := range favorites {
+		items = append(items, toFavoriteResponse(favorite))
+	}
+	httpapi.WriteJSON(c, http.StatusOK, httpapi.NewPagedResponse(items, page.Offset(), page.Limit(), total))
+}
+
+// RemoveFavorite godoc
+// @Summary Remove a favorite Pokemon
+// @Description Deletes a Pokemon from the caller's favorites
+// @Tags pokemon
+// @Security BearerAuth
+// @Param name path string true "Pokemon name"
+// @Success 204
+// @Failure 401 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Router /pokemon/favorites/{name} [delete]
+func (h *FavoritesHandlers) RemoveFavorite(c *gin.Context) {
+	principal, ok := authapi.PrincipalFromContext(c)
+	if !ok {
+		httpapi.WriteError(c, http.StatusUnauthorized, httpapi.CodeUnauthorized, "Authentication required")
+		return
+	}
+
+	if err := h.service.Remove(c.Request.Context(), principal.UserID, c.Param("name")); err != nil {
+		writeFavoriteError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeFavoriteError(c *gin.Context, err error) {
+	if httpapi.WriteContextError(c, err) {
+		return
+	}
+	switch {
+	case pokemonapp.IsValidationError(err):
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+	case pokemonapp.IsNotFoundError(err):
+		httpapi.WriteError(c, http.StatusNotFound, httpapi.CodeNotFound, err.Error())
+	case pokemonapp.IsConflictError(err):
+		httpapi.WriteError(c, http.StatusConflict, httpapi.CodeConflict, err.Error())
+	case pokemonapp.IsUpstreamError(err):
+		httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+	default:
+		httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to process favorites request")
+	}
+}
+
+func toFavoriteResponse(favorite pokemondomain.FavoritePokemon) FavoritePokemonResponse {
+	return FavoritePokemonResponse{
+		Name:      favorite.Name,
+		Type:      favorite.Type,
+		Image:     favorite.Image,
+		CreatedAt: favorite.CreatedAt,
+	}
+}
@@ -2,10 +2,13 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	"mysvelteapp/server_new/internal/platform/httpapi"
 )
 
 // Handlers exposes HTTP endpoints for the pokemon module.
@@ -20,23 +23,147 @@ func NewHandlers(service *pokemonapp.Service) *Handlers {
 
 // GetRandomPokemon godoc
 // @Summary Get a random Pokemon
-// @Description Retrieves a random Pokemon from the PokeAPI
+// @Description Retrieves a random Pokemon from the PokeAPI, optionally restricted to one type
 // @Tags pokemon
 // @Accept json
 // @Produce json
+// @Param type query string false "Only pick Pokemon of this type, e.g. fire"
 // @Success 200 {object} RandomPokemonResponse
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 404 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 502 {object} httpapi.ErrorResponse
 // @Router /RandomPokemon [get]
 func (h *Handlers) GetRandomPokemon(c *gin.Context) {
-	pokemon, err := h.service.GetRandomPokemon(c.Request.Context())
+	var pokemon *pokemondomain.RandomPokemon
+	var err error
+	if typeName, ok := c.GetQuery("type"); ok {
+		pokemon, err = h.service.GetRandomPokemonByType(c.Request.Context(), typeName)
+	} else {
+		pokemon, err = h.service.GetRandomPokemon(c.Request.Context())
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get random Pokemon"})
+		if httpapi.WriteContextError(c, err) {
+			return
+		}
+		switch {
+		case pokemonapp.IsValidationError(err):
+			httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		case pokemonapp.IsNotFoundError(err):
+			httpapi.WriteError(c, http.StatusNotFound, httpapi.CodeNotFound, err.Error())
+		case pokemonapp.IsUpstreamError(err):
+			httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+		default:
+			httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to get random Pokemon")
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, RandomPokemonResponse{
+	httpapi.WriteJSON(c, http.StatusOK, RandomPokemonResponse{
 		Name:  pokemon.Name,
 		Type:  pokemon.Type,
 		Image: pokemon.Image,
 	})
 }
+
+// GetRandomPokemonBatch godoc
+// @Summary Get several random Pokemon
+// @Description Retrieves up to count distinct random Pokemon from the PokeAPI in one call
+// @Tags pokemon
+// @Accept json
+// @Produce json
+// @Param count query int true "Number of Pokemon (1-20)"
+// @Success 200 {array} RandomPokemonResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 502 {object} httpapi.ErrorResponse
+// @Router /RandomPokemon/batch [get]
+func (h *Handlers) GetRandomPokemonBatch(c *gin.Context) {
+	count, err := strconv.Atoi(c.Query("count"))
+	if err != nil {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, "Count must be a whole number")
+		return
+	}
+
+	batch, err := h.service.GetRandomPokemonBatch(c.Request.Context(), count)
+	if err != nil {
+		if httpapi.WriteContextError(c, err) {
+			return
+		}
+		switch {
+		case pokemonapp.IsValidationError(err):
+			httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		case pokemonapp.IsUpstreamError(err):
+			httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+		default:
+			httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to get random Pokemon")
+		}
+		return
+	}
+
+	response := make([]RandomPokemonResponse, 0, len(batch))
+	for _, pokemon := range batch {
+		response = append(response, RandomPokemonResponse{
+			Name:  pokemon.Name,
+			Type:  pokemon.Type,
+			Image: pokemon.Image,
+		})
+	}
+	httpapi.WriteJSON(c, http.StatusOK, response)
+}
+
+// RandomSeedHeader carries the seed for GetSeededRandomPokemon.
+const RandomSeedHeader = "X-Random-Seed"
+
+// GetSeededRandomPokemon godoc
+// @Summary Get a repeatable sequence of random Pokemon
+// @Description Test hook for demos and load tests: draws count Pokemon from a source seeded with the X-Random-Seed header, so the same seed returns the same sequence. Only mounted when POKEMON_SEEDED_RANDOM is enabled, which production refuses.
+// @Tags pokemon
+// @Produce json
+// @Param X-Random-Seed header int true "Seed for the random sequence"
+// @Param count query int false "Number of Pokemon (1-20, default 1)"
+// @Success 200 {array} RandomPokemonResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 500 {object} httpapi.ErrorResponse
+// @Failure 502 {object} httpapi.ErrorResponse
+// @Router /RandomPokemon/seeded [get]
+func (h *Handlers) GetSeededRandomPokemon(c *gin.Context) {
+	seed, err := strconv.ParseInt(c.GetHeader(RandomSeedHeader), 10, 64)
+	if err != nil {
+		httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, RandomSeedHeader+" must be a whole number")
+		return
+	}
+	count := 1
+	if raw, ok := c.GetQuery("count"); ok {
+		if count, err = strconv.Atoi(raw); err != nil {
+			httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, "Count must be a whole number")
+			return
+		}
+	}
+
+	sequence, err := h.service.GetSeededRandomPokemon(c.Request.Context(), seed, count)
+	if err != nil {
+		if httpapi.WriteContextError(c, err) {
+			return
+		}
+		switch {
+		case pokemonapp.IsValidationError(err):
+			httpapi.WriteError(c, http.StatusBadRequest, httpapi.CodeValidation, err.Error())
+		case pokemonapp.IsUpstreamError(err):
+			httpapi.WriteError(c, http.StatusBadGateway, httpapi.CodeUpstreamUnavailable, "Pokemon service is currently unavailable")
+		default:
+			httpapi.WriteError(c, http.StatusInternalServerError, httpapi.CodeInternal, "Failed to get random Pokemon")
+		}
+		return
+	}
+
+	response := make([]RandomPokemonResponse, 0, len(sequence))
+	for _, pokemon := range sequence {
+		response = append(response, RandomPokemonResponse{
+			Name:  pokemon.Name,
+			Type:  pokemon.Type,
+			Image: pokemon.Image,
+		})
+	}
+	httpapi.WriteJSON(c, http.StatusOK, response)
+}
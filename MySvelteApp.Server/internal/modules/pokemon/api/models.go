@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // RandomPokemonResponse represents the response model for a random Pokemon.
 // @name RandomPokemonResponse
 type RandomPokemonResponse struct {
@@ -7,3 +9,30 @@ type RandomPokemonResponse struct {
 	Type  *string `json:"type,omitempty"`
 	Image *string `json:"image,omitempty"`
 }
+
+// PokemonSummaryResponse represents one entry in the Pokemon list.
+// @name PokemonSummaryResponse
+type PokemonSummaryResponse struct {
+	Name string `json:"name"`
+}
+
+// PokemonCountResponse reports the number of Pokemon random picks draw from.
+// @name PokemonCountResponse
+type PokemonCountResponse struct {
+	Count int `json:"count"`
+}
+
+// AddFavoriteRequest represents the payload to save a favorite Pokemon.
+// @name AddFavoriteRequest
+type AddFavoriteRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// FavoritePokemonResponse represents a saved favorite Pokemon.
+// @name FavoritePokemonResponse
+type FavoritePokemonResponse struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Image     string    `json:"image"`
+	CreatedAt time.Time `json:"createdAt"`
+}
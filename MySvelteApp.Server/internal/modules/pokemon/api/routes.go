@@ -5,4 +5,36 @@ import "github.com/gin-gonic/gin"
 // RegisterRoutes mounts the pokemon routes beneath the provided router group.
 func RegisterRoutes(router gin.IRouter, handlers *Handlers) {
 	router.GET("/RandomPokemon", handlers.GetRandomPokemon)
+	router.GET("/RandomPokemon/batch", handlers.GetRandomPokemonBatch)
+}
+
+// RegisterSeededRoutes mounts the seeded random Pokemon test hook. Only call
+// it outside production.
+func RegisterSeededRoutes(router gin.IRouter, handlers *Handlers) {
+	router.GET("/RandomPokemon/seeded", handlers.GetSeededRandomPokemon)
+}
+
+// RegisterCatalogRoutes mounts the Pokemon list route.
+func RegisterCatalogRoutes(router gin.IRouter, handlers *CatalogHandlers) {
+	router.GET("/pokemon", handlers.ListPokemon)
+}
+
+// RegisterFavoriteRoutes mounts the favorites routes behind the supplied auth middleware.
+func RegisterFavoriteRoutes(router gin.IRouter, handlers *FavoritesHandlers, requireAuth gin.HandlerFunc) {
+	favorites := router.Group("/pokemon/favorites", requireAuth)
+	favorites.POST("", handlers.AddFavorite)
+	favorites.GET("", handlers.ListFavorites)
+	favorites.DELETE("/:name", handlers.RemoveFavorite)
+}
+
+// RegisterAdminRoutes mounts the Pokemon maintenance routes behind the supplied
+// auth and admin role middleware.
+func RegisterAdminRoutes(router gin.IRouter, handlers *AdminHandlers, requireAuth, requireAdmin gin.HandlerFunc) {
+	admin := router.Group("/admin/pokemon", requireAuth, requireAdmin)
+	admin.POST("/refresh-count", handlers.RefreshCount)
+}
+
+// RegisterStreamRoutes mounts the random Pokemon WebSocket feed.
+func RegisterStreamRoutes(router gin.IRouter, handlers *StreamHandlers) {
+	router.GET("/RandomPokemon/stream", handlers.StreamRandomPokemon)
 }
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// Defaults applied to zero-valued StreamOptions fields.
+const (
+	DefaultStreamInterval     = 5 * time.Second
+	DefaultStreamMaxClients   = 100
+	DefaultStreamWriteTimeout = 5 * time.Second
+)
+
+// StreamOptions tunes the random Pokemon WebSocket feed.
+type StreamOptions struct {
+	// Interval is the delay between pushed Pokemon.
+	Interval time.Duration
+	// MaxClients caps concurrently open streams; further upgrades get a 503.
+	MaxClients int
+	// WriteTimeout bounds each send so a slow client is dropped instead of
+	// holding its slot indefinitely.
+	WriteTimeout time.Duration
+}
+
+// StreamHandlers pushes random Pokemon to WebSocket clients.
+type StreamHandlers struct {
+	service      *pokemonapp.Service
+	interval     time.Duration
+	writeTimeout time.Duration
+	slots        chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewStreamHandlers wires the pokemon service into the streaming endpoint.
+func NewStreamHandlers(service *pokemonapp.Service, opts StreamOptions) *StreamHandlers {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultStreamInterval
+	}
+	if opts.MaxClients <= 0 {
+		opts.MaxClients = DefaultStreamMaxClients
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultStreamWriteTimeout
+	}
+	return &StreamHandlers{
+		service:      service,
+		interval:     opts.Interval,
+		writeTimeout: opts.WriteTimeout,
+		slots:        make(chan struct{}, opts.MaxClients),
+		done:         make(chan struct{}),
+	}
+}
+
+// Close ends every open stream. http.Server.Shutdown does not track hijacked
+// connections, so register it with RegisterOnShutdown.
+func (h *StreamHandlers) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// StreamRandomPokemon godoc
+// @Summary Stream random Pokemon
+// @Description Upgrades to a WebSocket and sends a RandomPokemonResponse JSON message at a fixed interval until the client disconnects
+// @Tags pokemon
+// @Success 101 {object} RandomPokemonResponse
+// @Failure 400 {object} httpapi.ErrorResponse
+// @Failure 503 {object} httpapi.ErrorResponse
+// @Router /RandomPokemon/stream [get]
+func (h *StreamHandlers) StreamRandomPokemon(c *gin.Context) {
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		httpapi.WriteError(c, http.StatusServiceUnavailable, httpapi.CodeRateLimited, "Too many open Pokemon streams")
+		return
+	}
+
+	ctx := c.Request.Context()
+	// A nil Handshake skips the Origin check: the feed is public and read-only.
+	server := websocket.Server{Handler: func(conn *websocket.Conn) {
+		h.stream(ctx, conn)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// stream sends Pokemon until the client goes away or ctx is cancelled.
+func (h *StreamHandlers) stream(ctx context.Context, conn *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Hijacked connections do not cancel the request context on disconnect,
+	// so watch the read side instead. Client frames are discarded.
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		defer cancel()
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+	defer func() {
+		_ = conn.Close()
+		<-readerDone
+	}()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		// Upstream failures skip a tick rather than ending the stream.
+		if pokemon, err := h.service.GetRandomPokemon(ctx); err == nil {
+			if err := conn.SetWriteDeadline(time.Now().Add(h.writeTimeout)); err != nil {
+				return
+			}
+			if err := websocket.JSON.Send(conn, RandomPokemonResponse{
+				Name:  pokemon.Name,
+				Type:  pokemon.Type,
+				Image: pokemon.Image,
+			}); err != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
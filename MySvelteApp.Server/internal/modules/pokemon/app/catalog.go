@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+// MaxListLimit bounds how many Pokemon a single list request may return.
+const MaxListLimit = 100
+
+// CatalogService lets users browse the available Pokemon.
+type CatalogService struct {
+	port PokemonListPort
+}
+
+// NewCatalogService wires the list port into the service.
+func NewCatalogService(port PokemonListPort) *CatalogService {
+	return &CatalogService{port: port}
+}
+
+// List returns one page of Pokemon and the total number available.
+func (s *CatalogService) List(ctx context.Context, offset, limit int) ([]pokemondomain.PokemonSummary, int64, error) {
+	if offset < 0 {
+		return nil, 0, ValidationError{Message: "Offset must not be negative."}
+	}
+	if limit < 1 || limit > MaxListLimit {
+		return nil, 0, ValidationError{Message: fmt.Sprintf("Limit must be between 1 and %d.", MaxListLimit)}
+	}
+	return s.port.ListPokemon(ctx, offset, limit)
+}
@@ -0,0 +1,73 @@
+package app
+
+import "errors"
+
+// UpstreamError indicates the external Pokemon provider failed or could not be reached.
+type UpstreamError struct {
+	// StatusCode is the upstream HTTP status, or zero when no response was received.
+	StatusCode int
+	Err        error
+}
+
+func (e UpstreamError) Error() string {
+	return e.Err.Error()
+}
+
+func (e UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// IsUpstreamError returns true when err is an UpstreamError.
+func IsUpstreamError(err error) bool {
+	var target UpstreamError
+	return errors.As(err, &target)
+}
+
+// ErrDuplicateFavorite is returned by FavoriteRepository implementations when the
+// user already saved the Pokemon. Implementations should wrap it so errors.Is matches.
+var ErrDuplicateFavorite = errors.New("duplicate favorite")
+
+// ValidationError indicates the request failed validation rules.
+type ValidationError struct {
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Message
+}
+
+// NotFoundError indicates the requested Pokemon or favorite does not exist.
+type NotFoundError struct {
+	Message string
+}
+
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+// ConflictError indicates the request conflicts with existing state (e.g. a duplicate favorite).
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+// IsValidationError returns true when err is a ValidationError.
+func IsValidationError(err error) bool {
+	var target ValidationError
+	return errors.As(err, &target)
+}
+
+// IsNotFoundError returns true when err is a NotFoundError.
+func IsNotFoundError(err error) bool {
+	var target NotFoundError
+	return errors.As(err, &target)
+}
+
+// IsConflictError returns true when err is a ConflictError.
+func IsConflictError(err error) bool {
+	var target ConflictError
+	return errors.As(err, &target)
+}
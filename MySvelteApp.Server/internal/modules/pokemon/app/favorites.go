@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+// FavoritesService manages the Pokemon a user has saved.
+type FavoritesService struct {
+	favorites FavoriteRepository
+	lookup    PokemonLookupPort
+}
+
+// NewFavoritesService wires the favorites dependencies.
+func NewFavoritesService(favorites FavoriteRepository, lookup PokemonLookupPort) *FavoritesService {
+	return &FavoritesService{
+		favorites: favorites,
+		lookup:    lookup,
+	}
+}
+
+// Add saves a Pokemon for the user, capturing its current type and image.
+func (s *FavoritesService) Add(ctx context.Context, userID uint, name string) (*pokemondomain.FavoritePokemon, error) {
+	normalized := pokemondomain.NormalizePokemonName(name)
+	switch {
+	case normalized == "":
+		return nil, ValidationError{Message: "Pokemon name is required."}
+	case len(normalized) > pokemondomain.MaxPokemonNameLength:
+		return nil, ValidationError{Message: "Pokemon name must not exceed 128 characters."}
+	}
+
+	pokemon, err := s.lookup.GetPokemonByName(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite, err := pokemondomain.NewFavoritePokemon(userID, normalized, derefString(pokemon.Type), derefString(pokemon.Image))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.favorites.Add(ctx, favorite); err != nil {
+		if errors.Is(err, ErrDuplicateFavorite) {
+			return nil, ConflictError{Message: "This Pokemon is already in your favorites."}
+		}
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// List returns one page of the user's favorites and the total number saved.
+func (s *FavoritesService) List(ctx context.Context, userID uint, offset, limit int) ([]pokemondomain.FavoritePokemon, int64, error) {
+	return s.favorites.ListByUser(ctx, userID, offset, limit)
+}
+
+// Remove deletes a favorite, returning NotFoundError when it was not saved.
+func (s *FavoritesService) Remove(ctx context.Context, userID uint, name string) error {
+	normalized := pokemondomain.NormalizePokemonName(name)
+	if normalized == "" {
+		return ValidationError{Message: "Pokemon name is required."}
+	}
+
+	deleted, err := s.favorites.Delete(ctx, userID, normalized)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return NotFoundError{Message: "This Pokemon is not in your favorites."}
+	}
+	return nil
+}
+
+func derefString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -9,4 +9,31 @@ import (
 // RandomPokemonPort defines the contract for retrieving random Pokemon data.
 type RandomPokemonPort interface {
 	GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error)
+	// GetRandomPokemonByType picks a random Pokemon of the given lowercase
+	// type, returning NotFoundError when the type is unknown or has no Pokemon.
+	GetRandomPokemonByType(ctx context.Context, typeName string) (*pokemondomain.RandomPokemon, error)
+}
+
+// PokemonLookupPort resolves a single Pokemon by name.
+type PokemonLookupPort interface {
+	GetPokemonByName(ctx context.Context, name string) (*pokemondomain.RandomPokemon, error)
+}
+
+// PokemonListPort pages through every Pokemon the source knows about.
+type PokemonListPort interface {
+	ListPokemon(ctx context.Context, offset, limit int) ([]pokemondomain.PokemonSummary, int64, error)
+}
+
+// CountRefresher forces a Pokemon source to re-fetch the number of Pokemon
+// it picks random Pokemon from.
+type CountRefresher interface {
+	RefreshCount(ctx context.Context) (int, error)
+}
+
+// FavoriteRepository persists the Pokemon users mark as favorites.
+type FavoriteRepository interface {
+	Add(ctx context.Context, favorite *pokemondomain.FavoritePokemon) error
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]pokemondomain.FavoritePokemon, int64, error)
+	// Delete removes the favorite and reports whether a row existed.
+	Delete(ctx context.Context, userID uint, name string) (bool, error)
 }
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+// RandomSource picks the numbers behind "random" Pokemon. Implementations
+// must be safe for concurrent use because batch draws run in parallel.
+type RandomSource interface {
+	// Intn returns a number in [0, n). n is always positive.
+	Intn(n int) int
+}
+
+type globalSource struct{}
+
+func (globalSource) Intn(n int) int { return rand.Intn(n) }
+
+// seededSource guards a *rand.Rand, which is not safe for concurrent use.
+type seededSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSeededSource returns a RandomSource that yields the same sequence for
+// the same seed. It exists for reproducible demos and load tests and must
+// never back a production deployment.
+func NewSeededSource(seed int64) RandomSource {
+	return &seededSource{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *seededSource) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Intn(n)
+}
+
+type randomSourceKey struct{}
+
+// WithRandomSource scopes source to ctx so adapters draw from it instead of
+// their own source for the duration of one request.
+func WithRandomSource(ctx context.Context, source RandomSource) context.Context {
+	return context.WithValue(ctx, randomSourceKey{}, source)
+}
+
+// RandomSourceFrom returns the source scoped to ctx, falling back to fallback
+// and then to the process-wide math/rand source.
+func RandomSourceFrom(ctx context.Context, fallback RandomSource) RandomSource {
+	if source, ok := ctx.Value(randomSourceKey{}).(RandomSource); ok && source != nil {
+		return source
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return globalSource{}
+}
+
+// GetSeededRandomPokemon draws count Pokemon one after another from a source
+// seeded with seed, so the same seed always yields the same sequence from the
+// same Pokemon source. Duplicates are kept to preserve the sequence.
+func (s *Service) GetSeededRandomPokemon(ctx context.Context, seed int64, count int) ([]pokemondomain.RandomPokemon, error) {
+	if count < MinBatchSize || count > MaxBatchSize {
+		return nil, ValidationError{Message: fmt.Sprintf("Count must be between %d and %d.", MinBatchSize, MaxBatchSize)}
+	}
+
+	ctx = WithRandomSource(ctx, NewSeededSource(seed))
+	results := make([]pokemondomain.RandomPokemon, 0, count)
+	for i := 0; i < count; i++ {
+		pokemon, err := s.port.GetRandomPokemon(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *pokemon)
+	}
+	return results, nil
+}
@@ -2,10 +2,24 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 
 	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
 )
 
+const (
+	// MinBatchSize and MaxBatchSize bound GetRandomPokemonBatch requests.
+	MinBatchSize = 1
+	MaxBatchSize = 20
+
+	batchWorkers = 4
+	// batchAttemptsPerPokemon limits retries spent replacing duplicate draws.
+	batchAttemptsPerPokemon = 3
+	maxTypeNameLength       = 32
+)
+
 // Service orchestrates Pokemon use-cases.
 type Service struct {
 	port RandomPokemonPort
@@ -20,3 +34,106 @@ func NewService(port RandomPokemonPort) *Service {
 func (s *Service) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
 	return s.port.GetRandomPokemon(ctx)
 }
+
+// GetRandomPokemonByType fetches a random Pokemon of typeName, e.g. "fire".
+// The name is matched case-insensitively; unknown types yield NotFoundError.
+func (s *Service) GetRandomPokemonByType(ctx context.Context, typeName string) (*pokemondomain.RandomPokemon, error) {
+	typeName = strings.ToLower(strings.TrimSpace(typeName))
+	if !isTypeName(typeName) {
+		return nil, ValidationError{Message: "Type must contain only letters and hyphens."}
+	}
+	return s.port.GetRandomPokemonByType(ctx, typeName)
+}
+
+// isTypeName reports whether name looks like a PokeAPI type such as "fire"
+// or "shadow-fire"; anything else cannot exist upstream.
+func isTypeName(name string) bool {
+	if name == "" || len(name) > maxTypeNameLength {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetRandomPokemonBatch fetches up to count distinct random Pokemon concurrently
+// using a bounded worker pool. Remaining fetches are cancelled as soon as enough
+// Pokemon are collected, the first fetch fails, or ctx is cancelled. Fewer than
+// count results are returned when the source cannot supply enough distinct Pokemon.
+func (s *Service) GetRandomPokemonBatch(ctx context.Context, count int) ([]pokemondomain.RandomPokemon, error) {
+	if count < MinBatchSize || count > MaxBatchSize {
+		return nil, ValidationError{Message: fmt.Sprintf("Count must be between %d and %d.", MinBatchSize, MaxBatchSize)}
+	}
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan struct{}, count*batchAttemptsPerPokemon)
+	for i := 0; i < cap(attempts); i++ {
+		attempts <- struct{}{}
+	}
+	close(attempts)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		seen     = make(map[string]struct{}, count)
+		results  = make([]pokemondomain.RandomPokemon, 0, count)
+		firstErr error
+	)
+
+	for i := 0; i < min(batchWorkers, count); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range attempts {
+				if batchCtx.Err() != nil {
+					return
+				}
+
+				pokemon, err := s.port.GetRandomPokemon(batchCtx)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				key := pokemonKey(pokemon)
+				if _, duplicate := seen[key]; !duplicate && len(results) < count {
+					seen[key] = struct{}{}
+					results = append(results, *pokemon)
+					if len(results) == count {
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(results) == count {
+		return results, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+func pokemonKey(pokemon *pokemondomain.RandomPokemon) string {
+	if pokemon.Name != nil {
+		return *pokemon.Name
+	}
+	return fmt.Sprintf("%p", pokemon)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxPokemonNameLength bounds stored Pokemon names.
+const MaxPokemonNameLength = 128
+
+// FavoritePokemon is a Pokemon saved by a user. Type and image are captured at
+// save time so favorites can be listed without calling the upstream API.
+type FavoritePokemon struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_favorite_pokemon_user_name"`
+	Name      string    `gorm:"size:128;not null;uniqueIndex:idx_favorite_pokemon_user_name"`
+	Type      string    `gorm:"size:256"`
+	Image     string    `gorm:"size:1024"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// NewFavoritePokemon enforces invariants before creating a FavoritePokemon.
+func NewFavoritePokemon(userID uint, name, pokemonType, image string) (*FavoritePokemon, error) {
+	if userID == 0 {
+		return nil, errors.New("user id cannot be empty")
+	}
+
+	name = NormalizePokemonName(name)
+	if name == "" {
+		return nil, errors.New("pokemon name cannot be empty")
+	}
+	if len(name) > MaxPokemonNameLength {
+		return nil, fmt.Errorf("pokemon name must not exceed %d characters", MaxPokemonNameLength)
+	}
+
+	return &FavoritePokemon{
+		UserID: userID,
+		Name:   name,
+		Type:   pokemonType,
+		Image:  image,
+	}, nil
+}
+
+// NormalizePokemonName returns the trimmed, lowercase form PokeAPI uses for names.
+func NormalizePokemonName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
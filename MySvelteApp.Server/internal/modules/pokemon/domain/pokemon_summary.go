@@ -0,0 +1,6 @@
+package domain
+
+// PokemonSummary is the lightweight entry returned when browsing Pokemon.
+type PokemonSummary struct {
+	Name string
+}
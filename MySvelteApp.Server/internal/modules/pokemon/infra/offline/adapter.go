@@ -0,0 +1,138 @@
+package offline
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+//go:embed pokemon.json
+var dataset []byte
+
+var (
+	_ pokemonapp.RandomPokemonPort = (*Adapter)(nil)
+	_ pokemonapp.PokemonLookupPort = (*Adapter)(nil)
+	_ pokemonapp.PokemonListPort   = (*Adapter)(nil)
+)
+
+// Adapter serves Pokemon from an embedded dataset so the API works without network access.
+type Adapter struct {
+	pokemon []pokemonEntry
+	rng     pokemonapp.RandomSource
+}
+
+// Option customises an Adapter.
+type Option func(*Adapter)
+
+// WithRandomSource replaces the process-wide math/rand source used to pick
+// random Pokemon, e.g. with pokemonapp.NewSeededSource for repeatable runs.
+// A source scoped to the request context still takes precedence.
+func WithRandomSource(source pokemonapp.RandomSource) Option {
+	return func(a *Adapter) {
+		a.rng = source
+	}
+}
+
+// NewAdapter loads the embedded dataset.
+func NewAdapter(opts ...Option) (*Adapter, error) {
+	var entries []pokemonEntry
+	if err := json.Unmarshal(dataset, &entries); err != nil {
+		return nil, fmt.Errorf("failed to deserialize offline Pokemon data: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("offline Pokemon dataset is empty")
+	}
+	adapter := &Adapter{pokemon: entries}
+	for _, opt := range opts {
+		opt(adapter)
+	}
+	return adapter, nil
+}
+
+// GetRandomPokemon returns a random entry from the embedded dataset.
+func (a *Adapter) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entry := a.pokemon[pokemonapp.RandomSourceFrom(ctx, a.rng).Intn(len(a.pokemon))]
+
+	return &pokemondomain.RandomPokemon{
+		Name:  &entry.Name,
+		Type:  &entry.Type,
+		Image: &entry.Image,
+	}, nil
+}
+
+// GetRandomPokemonByType returns a random dataset entry listing typeName
+// among its types.
+func (a *Adapter) GetRandomPokemonByType(ctx context.Context, typeName string) (*pokemondomain.RandomPokemon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var candidates []pokemonEntry
+	for _, entry := range a.pokemon {
+		for _, entryType := range strings.Split(entry.Type, ",") {
+			if strings.EqualFold(strings.TrimSpace(entryType), typeName) {
+				candidates = append(candidates, entry)
+				break
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, pokemonapp.NotFoundError{Message: "Pokemon type not found."}
+	}
+
+	entry := candidates[pokemonapp.RandomSourceFrom(ctx, a.rng).Intn(len(candidates))]
+	return &pokemondomain.RandomPokemon{
+		Name:  &entry.Name,
+		Type:  &entry.Type,
+		Image: &entry.Image,
+	}, nil
+}
+
+// GetPokemonByName returns the dataset entry with the given name.
+func (a *Adapter) GetPokemonByName(ctx context.Context, name string) (*pokemondomain.RandomPokemon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range a.pokemon {
+		if strings.EqualFold(entry.Name, name) {
+			return &pokemondomain.RandomPokemon{
+				Name:  &entry.Name,
+				Type:  &entry.Type,
+				Image: &entry.Image,
+			}, nil
+		}
+	}
+	return nil, pokemonapp.NotFoundError{Message: "Pokemon not found."}
+}
+
+// ListPokemon returns a page of the dataset in its embedded order.
+func (a *Adapter) ListPokemon(ctx context.Context, offset, limit int) ([]pokemondomain.PokemonSummary, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	start := min(offset, len(a.pokemon))
+	end := min(start+limit, len(a.pokemon))
+	summaries := make([]pokemondomain.PokemonSummary, 0, end-start)
+	for _, entry := range a.pokemon[start:end] {
+		summaries = append(summaries, pokemondomain.PokemonSummary{Name: entry.Name})
+	}
+	return summaries, int64(len(a.pokemon)), nil
+}
+
+type pokemonEntry struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Image string `json:"image"`
+}
@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+var _ pokemonapp.FavoriteRepository = (*GormFavoriteRepository)(nil)
+
+// GormFavoriteRepository persists favorite Pokemon using GORM.
+type GormFavoriteRepository struct {
+	db *gorm.DB
+}
+
+// NewGormFavoriteRepository constructs a repository backed by GORM.
+func NewGormFavoriteRepository(db *gorm.DB) *GormFavoriteRepository {
+	return &GormFavoriteRepository{db: db}
+}
+
+// Add inserts the favorite, reporting ErrDuplicateFavorite on a (user, name) clash.
+func (r *GormFavoriteRepository) Add(ctx context.Context, favorite *pokemondomain.FavoritePokemon) error {
+	if favorite == nil {
+		return fmt.Errorf("favorite cannot be nil")
+	}
+
+	err := platformpersistence.Conn(ctx, r.db).Create(favorite).Error
+	if _, ok := platformpersistence.AsUniqueViolation(err); ok {
+		return fmt.Errorf("%w: %v", pokemonapp.ErrDuplicateFavorite, err)
+	}
+	return err
+}
+
+// ListByUser returns a page of the user's favorites, oldest first, together
+// with the total number of favorites the user has saved.
+func (r *GormFavoriteRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]pokemondomain.FavoritePokemon, int64, error) {
+	query := platformpersistence.Conn(ctx, r.db).
+		Model(&pokemondomain.FavoritePokemon{}).
+		Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var favorites []pokemondomain.FavoritePokemon
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&favorites).
+		Error; err != nil {
+		return nil, 0, err
+	}
+	return favorites, total, nil
+}
+
+// Delete removes the user's favorite with the given name.
+func (r *GormFavoriteRepository) Delete(ctx context.Context, userID uint, name string) (bool, error) {
+	result := platformpersistence.Conn(ctx, r.db).
+		Where("user_id = ? AND name = ?", userID, name).
+		Delete(&pokemondomain.FavoritePokemon{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
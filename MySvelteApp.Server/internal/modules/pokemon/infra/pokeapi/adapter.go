@@ -5,58 +5,336 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
 	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
 	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	"mysvelteapp/server_new/internal/platform/ratelimit"
 )
 
 const (
 	pokemonAPIBaseURL = "https://pokeapi.co/api/v2/pokemon/"
 	pokemonCountURL   = "https://pokeapi.co/api/v2/pokemon-species/?limit=0"
+	pokemonListURL    = "https://pokeapi.co/api/v2/pokemon"
+	pokemonTypeURL    = "https://pokeapi.co/api/v2/type/"
+	tracerName        = "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+	// unknownType stands in when PokeAPI reports no types for a Pokemon.
+	unknownType = "unknown"
+	// rateLimitKey is the single bucket every upstream call draws from.
+	rateLimitKey = "pokeapi"
+)
+
+var (
+	_ pokemonapp.RandomPokemonPort = (*Adapter)(nil)
+	_ pokemonapp.PokemonLookupPort = (*Adapter)(nil)
+	_ pokemonapp.PokemonListPort   = (*Adapter)(nil)
+	_ pokemonapp.CountRefresher    = (*Adapter)(nil)
 )
 
-var _ pokemonapp.RandomPokemonPort = (*Adapter)(nil)
+// HTTPDoer sends HTTP requests. *http.Client satisfies it; tests can supply
+// a fake that returns crafted responses or transport errors.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
 
 // Adapter integrates with the external PokeAPI.
 type Adapter struct {
-	httpClient *http.Client
+	httpClient HTTPDoer
+	tracer     trace.Tracer
+	breaker    *circuitBreaker
+	limiter    *ratelimit.Limiter
+	cache      *lookupCache
+	countCache *countCache
+	cacheHits  metric.Int64Counter
+	// placeholderImage is served when PokeAPI has no sprite or artwork.
+	placeholderImage string
+	rng              pokemonapp.RandomSource
+}
+
+// Option customises an Adapter.
+type Option func(*Adapter)
+
+// WithTracer overrides the tracer used for upstream call spans. Pass a no-op
+// tracer to disable tracing.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(a *Adapter) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
 }
 
-// NewAdapter creates a new Adapter instance.
-func NewAdapter(httpClient *http.Client) *Adapter {
+// WithCircuitBreaker short-circuits upstream calls for cooldown after
+// failureThreshold consecutive network errors or 5xx responses. A threshold
+// below one disables the breaker.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(a *Adapter) {
+		a.breaker = newCircuitBreaker(failureThreshold, cooldown)
+	}
+}
+
+// WithRateLimit spaces upstream calls to at most requestsPerSecond, allowing
+// bursts of the same size, so concurrent lookups stay within PokeAPI's
+// fair-use limits. Calls wait for a token until their context ends. A rate
+// below one disables limiting.
+func WithRateLimit(requestsPerSecond int) Option {
+	return func(a *Adapter) {
+		if requestsPerSecond < 1 {
+			a.limiter = nil
+			return
+		}
+		a.limiter = ratelimit.New(requestsPerSecond, time.Second)
+	}
+}
+
+// WithLookupCache caches up to size Pokemon fetched by name for ttl. A size or
+// ttl of zero or less disables the cache.
+func WithLookupCache(size int, ttl time.Duration) Option {
+	return func(a *Adapter) {
+		if size <= 0 || ttl <= 0 {
+			a.cache = nil
+			return
+		}
+		a.cache = newLookupCache(size, ttl)
+	}
+}
+
+// WithCountCacheTTL reuses the Pokemon count for ttl before asking PokeAPI
+// again. A ttl of zero or less fetches the count on every random pick.
+func WithCountCacheTTL(ttl time.Duration) Option {
+	return func(a *Adapter) {
+		if ttl <= 0 {
+			a.countCache = nil
+			return
+		}
+		a.countCache = newCountCache(ttl)
+	}
+}
+
+// WithPlaceholderImage sets the image URL returned when PokeAPI has neither a
+// front sprite nor official artwork for a Pokemon. Without it the image is nil.
+func WithPlaceholderImage(imageURL string) Option {
+	return func(a *Adapter) {
+		a.placeholderImage = imageURL
+	}
+}
+
+// WithRandomSource replaces the process-wide math/rand source used to pick
+// random Pokemon, e.g. with pokemonapp.NewSeededSource for repeatable runs.
+// A source scoped to the request context still takes precedence.
+func WithRandomSource(source pokemonapp.RandomSource) Option {
+	return func(a *Adapter) {
+		a.rng = source
+	}
+}
+
+// NewAdapter creates a new Adapter instance. A nil httpClient uses an
+// *http.Client with a 30 second timeout.
+func NewAdapter(httpClient HTTPDoer, opts ...Option) *Adapter {
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 30 * time.Second}
 	}
-	return &Adapter{httpClient: httpClient}
+	adapter := &Adapter{
+		httpClient: httpClient,
+		tracer:     otel.Tracer(tracerName),
+		breaker:    newCircuitBreaker(defaultBreakerFailureThreshold, defaultBreakerCooldown),
+		cache:      newLookupCache(defaultLookupCacheSize, defaultLookupCacheTTL),
+		countCache: newCountCache(defaultCountCacheTTL),
+	}
+	for _, opt := range opts {
+		opt(adapter)
+	}
+
+	cacheHits, err := otel.Meter(tracerName).Int64Counter("pokeapi.lookup_cache.requests",
+		metric.WithDescription("Pokemon lookups by name, labelled by cache result"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	adapter.cacheHits = cacheHits
+	return adapter
 }
 
 // GetRandomPokemon retrieves a random Pokemon from the PokeAPI.
 func (a *Adapter) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
-	count, err := a.getPokemonCount(ctx)
+	count, err := a.cachedPokemonCount(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Pokemon count: %w", err)
 	}
 
-	randomPokemon := rand.Intn(count) + 1
-	pokemonURL := fmt.Sprintf("%s%d", pokemonAPIBaseURL, randomPokemon)
+	randomPokemon := pokemonapp.RandomSourceFrom(ctx, a.rng).Intn(count) + 1
+	return a.getPokemon(ctx, strconv.Itoa(randomPokemon), attribute.Int("pokemon.number", randomPokemon))
+}
+
+// GetRandomPokemonByType lists the Pokemon of typeName from PokeAPI's type
+// endpoint, picks one at random and fetches it by name, going through the
+// lookup cache. An unknown type, or one without Pokemon, is a NotFoundError.
+func (a *Adapter) GetRandomPokemonByType(ctx context.Context, typeName string) (*pokemondomain.RandomPokemon, error) {
+	names, err := a.getPokemonNamesByType(ctx, typeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, pokemonapp.NotFoundError{Message: "No Pokemon have that type."}
+	}
+	return a.GetPokemonByName(ctx, names[pokemonapp.RandomSourceFrom(ctx, a.rng).Intn(len(names))])
+}
+
+// RefreshCount fetches the Pokemon count from PokeAPI, replacing any cached
+// value, and returns it. Use it when PokeAPI adds Pokemon before the cached
+// count expires.
+func (a *Adapter) RefreshCount(ctx context.Context) (int, error) {
+	count, err := a.getPokemonCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if a.countCache != nil {
+		a.countCache.put(count)
+	}
+	return count, nil
+}
+
+// cachedPokemonCount serves the count from the count cache, fetching it when
+// the cache is empty, expired or disabled.
+func (a *Adapter) cachedPokemonCount(ctx context.Context) (int, error) {
+	if a.countCache != nil {
+		if count, ok := a.countCache.get(); ok {
+			return count, nil
+		}
+	}
+	return a.RefreshCount(ctx)
+}
+
+// Ping checks that the PokeAPI is reachable using its cheapest endpoint.
+func (a *Adapter) Ping(ctx context.Context) error {
+	_, err := a.getPokemonCount(ctx)
+	return err
+}
+
+// GetPokemonByName retrieves a single Pokemon by its PokeAPI name. Successful
+// lookups are served from the lookup cache until they expire.
+func (a *Adapter) GetPokemonByName(ctx context.Context, name string) (*pokemondomain.RandomPokemon, error) {
+	if a.cache == nil {
+		return a.getPokemon(ctx, url.PathEscape(name), attribute.String("pokemon.name", name))
+	}
+
+	key := strings.ToLower(name)
+	if pokemon, ok := a.cache.get(key); ok {
+		a.recordCacheResult(ctx, "hit")
+		return pokemon, nil
+	}
+	a.recordCacheResult(ctx, "miss")
+
+	pokemon, err := a.getPokemon(ctx, url.PathEscape(name), attribute.String("pokemon.name", name))
+	if err != nil {
+		return nil, err
+	}
+	a.cache.put(key, pokemon)
+	return pokemon, nil
+}
+
+func (a *Adapter) recordCacheResult(ctx context.Context, result string) {
+	trace.SpanFromContext(ctx).AddEvent("pokeapi.lookup_cache", trace.WithAttributes(attribute.String("cache.result", result)))
+	if a.cacheHits != nil {
+		a.cacheHits.Add(ctx, 1, metric.WithAttributes(attribute.String("cache.result", result)))
+	}
+}
+
+// ListPokemon retrieves one page of the PokeAPI Pokemon index.
+func (a *Adapter) ListPokemon(ctx context.Context, offset, limit int) (summaries []pokemondomain.PokemonSummary, total int64, err error) {
+	listURL := pokemonListURL + "?" + url.Values{
+		"offset": {strconv.Itoa(offset)},
+		"limit":  {strconv.Itoa(limit)},
+	}.Encode()
+
+	ctx, span := a.tracer.Start(ctx, "pokeapi.ListPokemon", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(attribute.String("url.full", listURL))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create list request: %w", err)
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, 0, pokemonapp.UpstreamError{Err: fmt.Errorf("failed to list Pokemon: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, pokemonapp.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("Pokemon list API returned status %d", resp.StatusCode),
+		}
+	}
+
+	var listResp struct {
+		Count   int64 `json:"count"`
+		Results []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, 0, fmt.Errorf("failed to deserialize Pokemon list: %w", err)
+	}
+
+	summaries = make([]pokemondomain.PokemonSummary, 0, len(listResp.Results))
+	for _, result := range listResp.Results {
+		summaries = append(summaries, pokemondomain.PokemonSummary{Name: result.Name})
+	}
+	return summaries, listResp.Count, nil
+}
+
+func (a *Adapter) getPokemon(ctx context.Context, identifier string, attrs ...attribute.KeyValue) (pokemon *pokemondomain.RandomPokemon, err error) {
+	pokemonURL := pokemonAPIBaseURL + identifier
+
+	ctx, span := a.tracer.Start(ctx, "pokeapi.GetPokemon", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(attribute.String("url.full", pokemonURL))
+	span.SetAttributes(attrs...)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pokemonURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get Pokemon data: %w", err)
+		return nil, pokemonapp.UpstreamError{Err: fmt.Errorf("failed to get Pokemon data: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, pokemonapp.NotFoundError{Message: "Pokemon not found."}
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Pokemon API returned status %d", resp.StatusCode)
+		return nil, pokemonapp.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("Pokemon API returned status %d", resp.StatusCode),
+		}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -69,33 +347,98 @@ func (a *Adapter) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPo
 		return nil, fmt.Errorf("failed to deserialize Pokemon data: %w", err)
 	}
 
-	var types []string
-	for _, t := range apiResp.Types {
-		types = append(types, t.Type.Name)
+	typeStr := joinTypes(apiResp.Types)
+	if typeStr == "" {
+		typeStr = unknownType
 	}
-	typeStr := strings.Join(types, ", ")
 
 	return &pokemondomain.RandomPokemon{
 		Name:  &apiResp.Name,
 		Type:  &typeStr,
-		Image: apiResp.Sprites.FrontDefault,
+		Image: a.pickImage(apiResp.Sprites),
 	}, nil
 }
 
-func (a *Adapter) getPokemonCount(ctx context.Context) (int, error) {
+func (a *Adapter) getPokemonNamesByType(ctx context.Context, typeName string) (names []string, err error) {
+	typeURL := pokemonTypeURL + url.PathEscape(typeName)
+
+	ctx, span := a.tracer.Start(ctx, "pokeapi.GetPokemonByType", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(attribute.String("url.full", typeURL), attribute.String("pokemon.type", typeName))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, typeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create type request: %w", err)
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, pokemonapp.UpstreamError{Err: fmt.Errorf("failed to get Pokemon type: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, pokemonapp.NotFoundError{Message: "Pokemon type not found."}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, pokemonapp.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("Pokemon type API returned status %d", resp.StatusCode),
+		}
+	}
+
+	var typeResp struct {
+		Pokemon []struct {
+			Pokemon typeInfo `json:"pokemon"`
+		} `json:"pokemon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&typeResp); err != nil {
+		return nil, fmt.Errorf("failed to deserialize Pokemon type: %w", err)
+	}
+
+	names = make([]string, 0, len(typeResp.Pokemon))
+	for _, entry := range typeResp.Pokemon {
+		if entry.Pokemon.Name != "" {
+			names = append(names, entry.Pokemon.Name)
+		}
+	}
+	span.SetAttributes(attribute.Int("pokemon.count", len(names)))
+	return names, nil
+}
+
+func (a *Adapter) getPokemonCount(ctx context.Context) (count int, err error) {
+	ctx, span := a.tracer.Start(ctx, "pokeapi.GetPokemonCount", trace.WithSpanKind(trace.SpanKindClient))
+	defer func() {
+		if err != nil {
+			recordError(span, err)
+		}
+		span.End()
+	}()
+	span.SetAttributes(attribute.String("url.full", pokemonCountURL))
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pokemonCountURL, nil)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create count request: %w", err)
 	}
 
-	resp, err := a.httpClient.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get Pokemon count: %w", err)
+		return 0, pokemonapp.UpstreamError{Err: fmt.Errorf("failed to get Pokemon count: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("Pokemon count API returned status %d", resp.StatusCode)
+		return 0, pokemonapp.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("Pokemon count API returned status %d", resp.StatusCode),
+		}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -110,9 +453,80 @@ func (a *Adapter) getPokemonCount(ctx context.Context) (int, error) {
 		return 0, fmt.Errorf("failed to deserialize count data: %w", err)
 	}
 
+	span.SetAttributes(attribute.Int("pokemon.count", countResp.Count))
+	// Intn panics on non-positive bounds, so treat an empty or malformed
+	// count as an upstream failure rather than letting it reach the caller.
+	if countResp.Count <= 0 {
+		return 0, pokemonapp.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("Pokemon count API returned invalid count %d", countResp.Count),
+		}
+	}
 	return countResp.Count, nil
 }
 
+// do sends req through the rate limiter and circuit breaker. Network errors
+// and 5xx responses count as failures; other statuses, including 404, count
+// as successes. Calls abandoned because req's context ended count as neither.
+func (a *Adapter) do(req *http.Request) (*http.Response, error) {
+	// Wait for a token first, so a half-open trial is not claimed by a call
+	// that may still give up in the queue.
+	if a.limiter != nil {
+		if err := a.limiter.Wait(req.Context(), rateLimitKey); err != nil {
+			return nil, err
+		}
+	}
+	if !a.breaker.allow() {
+		return nil, errCircuitOpen
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil && req.Context().Err() != nil {
+		// The caller gave up; that says nothing about PokeAPI's health.
+		a.breaker.release()
+		return nil, err
+	}
+	a.breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err
+}
+
+// pickImage prefers the front sprite, then the official artwork, then the
+// configured placeholder. PokeAPI reports missing images as null.
+func (a *Adapter) pickImage(sprites pokeAPISprites) *string {
+	for _, candidate := range []*string{sprites.FrontDefault, sprites.Other.OfficialArtwork.FrontDefault} {
+		if candidate != nil && *candidate != "" {
+			return candidate
+		}
+	}
+	if a.placeholderImage == "" {
+		return nil
+	}
+	placeholder := a.placeholderImage
+	return &placeholder
+}
+
+// joinTypes lists type names once each, in the order PokeAPI first reports them.
+func joinTypes(types []pokeAPIType) string {
+	seen := make(map[string]struct{}, len(types))
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		if t.Type.Name == "" {
+			continue
+		}
+		if _, duplicate := seen[t.Type.Name]; duplicate {
+			continue
+		}
+		seen[t.Type.Name] = struct{}{}
+		names = append(names, t.Type.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
 type pokeAPIResponse struct {
 	Name    string         `json:"name"`
 	Types   []pokeAPIType  `json:"types"`
@@ -128,5 +542,14 @@ type typeInfo struct {
 }
 
 type pokeAPISprites struct {
+	FrontDefault *string      `json:"front_default"`
+	Other        otherSprites `json:"other"`
+}
+
+type otherSprites struct {
+	OfficialArtwork artworkSprites `json:"official-artwork"`
+}
+
+type artworkSprites struct {
 	FrontDefault *string `json:"front_default"`
 }
@@ -0,0 +1,116 @@
+package pokeapi
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+const (
+	defaultLookupCacheSize = 256
+	defaultLookupCacheTTL  = 10 * time.Minute
+)
+
+type cacheEntry struct {
+	key       string
+	pokemon   pokemondomain.RandomPokemon
+	expiresAt time.Time
+}
+
+// lookupCache is a size-bounded LRU of parsed Pokemon whose entries expire
+// after ttl. It is safe for concurrent use.
+type lookupCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+	now     func() time.Time
+}
+
+func newLookupCache(size int, ttl time.Duration) *lookupCache {
+	return &lookupCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the cached Pokemon, dropping it if it has expired.
+func (c *lookupCache) get(key string) (*pokemondomain.RandomPokemon, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	pokemon := entry.pokemon
+	return &pokemon, true
+}
+
+// put stores pokemon under key, evicting the least recently used entry when full.
+func (c *lookupCache) put(key string, pokemon *pokemondomain.RandomPokemon) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.pokemon, entry.expiresAt = *pokemon, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, pokemon: *pokemon, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+const defaultCountCacheTTL = time.Hour
+
+// countCache remembers the Pokemon count for ttl. It is safe for concurrent use.
+type countCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	count     int
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+func newCountCache(ttl time.Duration) *countCache {
+	return &countCache{ttl: ttl, now: time.Now}
+}
+
+// get returns the cached count while it is fresh.
+func (c *countCache) get() (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.count <= 0 || !c.now().Before(c.expiresAt) {
+		return 0, false
+	}
+	return c.count, true
+}
+
+// put stores count and restarts its ttl.
+func (c *countCache) put(count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count, c.expiresAt = count, c.now().Add(c.ttl)
+}
@@ -1,9 +1,17 @@
 package config
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -13,52 +21,770 @@ const (
 	defaultJWTIssuer        = "mysvelteapp"
 	defaultJWTAudience      = "mysvelteapp"
 	defaultJWTLifetimeHours = 24
+	defaultJWTLeeway        = 30 * time.Second
+	defaultJWTClaimNames    = "legacy"
 	defaultServiceName      = "mysvelteapp-server"
 	defaultServiceVersion   = "1.0.0"
-	defaultEnvironment      = "development"
+	defaultEnvironment      = EnvironmentDevelopment
+	defaultPokemonSource    = PokemonSourcePokeAPI
+	// defaultAppBaseURL is the SvelteKit dev server, where emailed links land.
+	defaultAppBaseURL = "http://localhost:5173"
+	// defaultSMTPPort is the mail submission port, which expects STARTTLS.
+	defaultSMTPPort = 587
+
+	defaultShutdownDrainDelay = 5 * time.Second
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultEmailChangeTTL     = 24 * time.Hour
+
+	defaultPokemonStreamInterval   = 5 * time.Second
+	defaultPokemonStreamMaxClients = 100
+	defaultPokemonRandomSeed       = 1
+	defaultPokeAPIRequestsPerSec   = 10
+
+	defaultAccessLogSkipPaths = "/health,/healthz,/readyz,/metrics"
+	defaultReservedUsernames  = "admin,administrator,root,superuser,support,system,moderator"
+
+	// SQLite allows a single writer, so one open connection avoids
+	// "database is locked" errors under concurrent requests.
+	defaultDBMaxOpenConns        = 1
+	defaultDBMaxIdleConns        = 1
+	defaultDBConnMaxLifetime     = 0
+	defaultDBWriteRetries        = 3
+	defaultDBQueryTimeout        = 5 * time.Second
+	defaultAvailabilityRateLimit = 30
+	defaultRegisterRateLimit     = 10
+	defaultRegisterBurst         = 20
+	defaultLockoutMaxFailures    = 5
+	defaultLockoutWindow         = 15 * time.Minute
+	defaultLockoutDuration       = 15 * time.Minute
+	// minIntrospectionAPIKeyLength keeps the shared introspection key out of
+	// brute-force range.
+	minIntrospectionAPIKeyLength = 32
+	// maxJWTLifetimeHours mirrors the token package's lifetime policy.
+	maxJWTLifetimeHours  = 168
+	defaultLogOutput     = "stdout"
+	defaultLogMaxSizeMB  = 100
+	defaultLogMaxBackups = 5
+	defaultLogMaxAgeDays = 28
+	// defaultConfigFile is read when present; CONFIG_FILE selects another file
+	// and makes its absence an error.
+	defaultConfigFile = ".env"
+)
+
+// Supported values for ENVIRONMENT.
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentTest        = "test"
+	EnvironmentStaging     = "staging"
+	EnvironmentProduction  = "production"
+)
+
+// Supported values for POKEMON_SOURCE.
+const (
+	PokemonSourcePokeAPI = "pokeapi"
+	PokemonSourceOffline = "offline"
+)
+
+// Supported values for ERROR_FORMAT.
+const (
+	ErrorFormatEnvelope = "envelope"
+	ErrorFormatProblem  = "problem"
+)
+
+// Supported values for AUTH_COOKIE_MODE.
+const (
+	AuthCookieModeOff  = "off"
+	AuthCookieModeBoth = "both"
+	AuthCookieModeOnly = "only"
+)
+
+// Supported values for OTEL_METRICS_EXPORTER.
+const (
+	MetricsExporterNone       = "none"
+	MetricsExporterPrometheus = "prometheus"
 )
 
 // Server holds runtime configuration needed to start the API server.
 type Server struct {
-	Port                   string
-	DatabaseDSN            string
-	JWTKey                 string
-	JWTIssuer              string
-	JWTAudience            string
-	JWTAccessLifetimeHours int
-	ServiceName            string
-	ServiceVersion         string
-	Environment            string
-}
-
-// Load reads configuration from environment variables, applying defaults where required.
+	Port                    string
+	DatabaseDSN             string
+	JWTKey                  string
+	JWTPreviousKeys         []string
+	JWTIssuer               string
+	JWTAudiences            []string
+	JWTAccessLifetimeHours  int
+	JWTServiceLifetimeHours int
+	JWTRememberMeHours      int
+	JWTLeeway               time.Duration
+	JWTClaimNames           string
+	ServiceName             string
+	ServiceVersion          string
+	Environment             string
+	LowercaseWholeEmail     bool
+	AvailabilityRateLimit   int
+	RegisterRateLimit       int
+	RegisterBurst           int
+	RegisterDomainRateLimit int
+	LockoutMaxFailures      int
+	LockoutWindow           time.Duration
+	LockoutDuration         time.Duration
+	UsernameCaseInsensitive bool
+	PokemonSource           string
+	PokemonStreamInterval   time.Duration
+	PokemonStreamMaxClients int
+	PokemonPlaceholderImage string
+	PokemonSeededRandom     bool
+	PokemonRandomSeed       int
+	PokeAPIRequestsPerSec   int
+	AppBaseURL              string
+	EmailTemplatesDir       string
+	EmailChangeTTL          time.Duration
+	SMTPHost                string
+	SMTPPort                int
+	SMTPUsername            string
+	SMTPPassword            string
+	SMTPFrom                string
+	DBMaxOpenConns          int
+	DBMaxIdleConns          int
+	DBConnMaxLifetime       time.Duration
+	DBWriteRetryAttempts    int
+	DBQueryTimeout          time.Duration
+	ErrorFormat             string
+	StrictJSON              bool
+	JSONIndent              bool
+	AuthCookieMode          string
+	MetricsExporter         string
+	CSRFEnabled             bool
+	SwaggerEnabled          bool
+	ShutdownDrainDelay      time.Duration
+	IdempotencyTTL          time.Duration
+	TrustedProxies          []string
+	ForceHTTPS              bool
+	TrustedProxyCount       int
+	LogOutput               string
+	LogMaxSizeMB            int
+	LogMaxBackups           int
+	LogMaxAgeDays           int
+	LogTimeFormat           string
+	LogUTC                  bool
+	AccessLogSampleRate     int
+	AccessLogSkipPaths      []string
+	AllowedEmailDomains     []string
+	ReservedUsernames       []string
+	SeedAdminUsername       string
+	SeedAdminEmail          string
+	SeedAdminPassword       string
+	IntrospectionAPIKey     string
+}
+
+// Load reads configuration from an optional dotenv file and environment
+// variables, applying defaults where required. Environment variables take
+// precedence over values from the file.
 func Load() (Server, error) {
+	env, err := newEnvSource()
+	if err != nil {
+		return Server{}, err
+	}
+
 	cfg := Server{
-		Port:                   getEnv("SERVER_PORT", defaultPort),
-		DatabaseDSN:            getEnv("DATABASE_DSN", defaultDatabaseDSN),
-		JWTKey:                 getEnv("JWT_KEY", defaultJWTKey),
-		JWTIssuer:              getEnv("JWT_ISSUER", defaultJWTIssuer),
-		JWTAudience:            getEnv("JWT_AUDIENCE", defaultJWTAudience),
-		JWTAccessLifetimeHours: defaultJWTLifetimeHours,
-		ServiceName:            getEnv("OTEL_SERVICE_NAME", defaultServiceName),
-		ServiceVersion:         getEnv("OTEL_SERVICE_VERSION", defaultServiceVersion),
-		Environment:            getEnv("ENVIRONMENT", defaultEnvironment),
-	}
-
-	if lifetimeStr := os.Getenv("JWT_ACCESS_TOKEN_LIFETIME_HOURS"); lifetimeStr != "" {
-		parsed, err := strconv.Atoi(lifetimeStr)
+		Port:            env.getEnv("SERVER_PORT", defaultPort),
+		DatabaseDSN:     env.getEnv("DATABASE_DSN", defaultDatabaseDSN),
+		JWTKey:          env.getEnv("JWT_KEY", defaultJWTKey),
+		JWTPreviousKeys: splitList(env.getEnv("JWT_PREVIOUS_KEYS", "")),
+		JWTIssuer:       env.getEnv("JWT_ISSUER", defaultJWTIssuer),
+		JWTAudiences:    splitList(env.getEnv("JWT_AUDIENCE", defaultJWTAudience)),
+		ServiceName:     env.getEnv("OTEL_SERVICE_NAME", defaultServiceName),
+		ServiceVersion:  env.getEnv("OTEL_SERVICE_VERSION", defaultServiceVersion),
+		Environment:     env.getEnv("ENVIRONMENT", defaultEnvironment),
+		PokemonSource:   strings.ToLower(env.getEnv("POKEMON_SOURCE", defaultPokemonSource)),
+		LogOutput:       env.getEnv("LOG_OUTPUT", defaultLogOutput),
+		ErrorFormat:     strings.ToLower(env.getEnv("ERROR_FORMAT", ErrorFormatEnvelope)),
+		TrustedProxies:  splitList(env.getEnv("TRUSTED_PROXIES", "")),
+
+		AllowedEmailDomains:     splitList(strings.ToLower(env.getEnv("AUTH_ALLOWED_EMAIL_DOMAINS", ""))),
+		PokemonPlaceholderImage: strings.TrimSpace(env.getEnv("POKEMON_PLACEHOLDER_IMAGE_URL", "")),
+
+		AppBaseURL:        strings.TrimRight(strings.TrimSpace(env.getEnv("APP_BASE_URL", defaultAppBaseURL)), "/"),
+		EmailTemplatesDir: strings.TrimSpace(env.getEnv("EMAIL_TEMPLATES_DIR", "")),
+		SMTPHost:          strings.TrimSpace(env.getEnv("SMTP_HOST", "")),
+		SMTPUsername:      env.getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:      env.getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:          strings.TrimSpace(env.getEnv("SMTP_FROM", "")),
+
+		AuthCookieMode: strings.ToLower(strings.TrimSpace(env.getEnv("AUTH_COOKIE_MODE", AuthCookieModeOff))),
+
+		MetricsExporter: strings.ToLower(strings.TrimSpace(env.getEnv("OTEL_METRICS_EXPORTER", MetricsExporterNone))),
+
+		SeedAdminUsername: strings.TrimSpace(env.getEnv("SEED_ADMIN_USERNAME", "")),
+		SeedAdminEmail:    strings.TrimSpace(env.getEnv("SEED_ADMIN_EMAIL", "")),
+		SeedAdminPassword: env.getEnv("SEED_ADMIN_PASSWORD", ""),
+
+		IntrospectionAPIKey: env.getEnv("INTROSPECTION_API_KEY", ""),
+	}
+	if cfg.SeedAdminUsername != "" && cfg.SeedAdminEmail == "" {
+		cfg.SeedAdminEmail = cfg.SeedAdminUsername + "@localhost"
+	}
+
+	if cfg.JWTAccessLifetimeHours, err = env.getEnvInt("JWT_ACCESS_TOKEN_LIFETIME_HOURS", defaultJWTLifetimeHours); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.JWTServiceLifetimeHours, err = env.getEnvInt("JWT_SERVICE_TOKEN_LIFETIME_HOURS", 0); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.JWTRememberMeHours, err = env.getEnvInt("JWT_REMEMBER_ME_LIFETIME_HOURS", 0); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.JWTLeeway, err = env.getEnvDuration("JWT_LEEWAY", defaultJWTLeeway); err != nil {
+		return Server{}, err
+	}
+
+	cfg.JWTClaimNames = strings.ToLower(env.getEnv("JWT_CLAIM_NAMES", defaultJWTClaimNames))
+
+	lowercaseWholeEmail, err := env.getEnvBool("AUTH_LOWERCASE_WHOLE_EMAIL", false)
+	if err != nil {
+		return Server{}, err
+	}
+	cfg.LowercaseWholeEmail = lowercaseWholeEmail
+
+	if cfg.AvailabilityRateLimit, err = env.getEnvInt("AUTH_AVAILABILITY_RATE_LIMIT", defaultAvailabilityRateLimit); err != nil {
+		return Server{}, err
+	}
+	if cfg.RegisterRateLimit, err = env.getEnvInt("AUTH_REGISTER_RATE_LIMIT", defaultRegisterRateLimit); err != nil {
+		return Server{}, err
+	}
+	if cfg.RegisterBurst, err = env.getEnvInt("AUTH_REGISTER_BURST", defaultRegisterBurst); err != nil {
+		return Server{}, err
+	}
+	if cfg.RegisterDomainRateLimit, err = env.getEnvInt("AUTH_REGISTER_DOMAIN_RATE_LIMIT", 0); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.AccessLogSampleRate, err = env.getEnvInt("ACCESS_LOG_SAMPLE_RATE", 1); err != nil {
+		return Server{}, err
+	}
+	cfg.AccessLogSkipPaths = splitList(env.getEnv("ACCESS_LOG_SKIP_PATHS", defaultAccessLogSkipPaths))
+
+	cfg.ReservedUsernames = splitList(strings.ToLower(env.getEnv("AUTH_RESERVED_USERNAMES", defaultReservedUsernames)))
+	if path := strings.TrimSpace(env.getEnv("AUTH_RESERVED_USERNAMES_FILE", "")); path != "" {
+		fromFile, err := readList(path)
 		if err != nil {
-			return Server{}, fmt.Errorf("parse JWT_ACCESS_TOKEN_LIFETIME_HOURS: %w", err)
+			return Server{}, fmt.Errorf("read AUTH_RESERVED_USERNAMES_FILE: %w", err)
 		}
-		cfg.JWTAccessLifetimeHours = parsed
+		cfg.ReservedUsernames = append(cfg.ReservedUsernames, fromFile...)
+	}
+
+	usernameCaseInsensitive, err := env.getEnvBool("AUTH_USERNAME_CASE_INSENSITIVE", false)
+	if err != nil {
+		return Server{}, err
+	}
+	cfg.UsernameCaseInsensitive = usernameCaseInsensitive
+
+	if cfg.StrictJSON, err = env.getEnvBool("JSON_DISALLOW_UNKNOWN_FIELDS", false); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.JSONIndent, err = env.getEnvBool("JSON_INDENT", false); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.CSRFEnabled, err = env.getEnvBool("CSRF_ENABLED", false); err != nil {
+		return Server{}, err
+	}
+
+	// API docs are public by default everywhere except production.
+	if cfg.SwaggerEnabled, err = env.getEnvBool("ENABLE_SWAGGER", cfg.Environment != EnvironmentProduction); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.LockoutMaxFailures, err = env.getEnvInt("AUTH_LOCKOUT_MAX_FAILURES", defaultLockoutMaxFailures); err != nil {
+		return Server{}, err
+	}
+	if cfg.LockoutWindow, err = env.getEnvDuration("AUTH_LOCKOUT_WINDOW", defaultLockoutWindow); err != nil {
+		return Server{}, err
+	}
+	if cfg.LockoutDuration, err = env.getEnvDuration("AUTH_LOCKOUT_DURATION", defaultLockoutDuration); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.ShutdownDrainDelay, err = env.getEnvDuration("SHUTDOWN_DRAIN_DELAY", defaultShutdownDrainDelay); err != nil {
+		return Server{}, err
+	}
+	if cfg.IdempotencyTTL, err = env.getEnvDuration("IDEMPOTENCY_TTL", defaultIdempotencyTTL); err != nil {
+		return Server{}, err
+	}
+	if cfg.EmailChangeTTL, err = env.getEnvDuration("EMAIL_CHANGE_TTL", defaultEmailChangeTTL); err != nil {
+		return Server{}, err
+	}
+	if cfg.SMTPPort, err = env.getEnvInt("SMTP_PORT", defaultSMTPPort); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.PokemonStreamInterval, err = env.getEnvDuration("POKEMON_STREAM_INTERVAL", defaultPokemonStreamInterval); err != nil {
+		return Server{}, err
+	}
+	if cfg.PokemonStreamMaxClients, err = env.getEnvInt("POKEMON_STREAM_MAX_CLIENTS", defaultPokemonStreamMaxClients); err != nil {
+		return Server{}, err
+	}
+	if cfg.PokemonSeededRandom, err = env.getEnvBool("POKEMON_SEEDED_RANDOM", false); err != nil {
+		return Server{}, err
+	}
+	if cfg.PokemonRandomSeed, err = env.getEnvInt("POKEMON_RANDOM_SEED", defaultPokemonRandomSeed); err != nil {
+		return Server{}, err
+	}
+	if cfg.PokeAPIRequestsPerSec, err = env.getEnvInt("POKEAPI_REQUESTS_PER_SECOND", defaultPokeAPIRequestsPerSec); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.TrustedProxyCount, err = env.getEnvInt("TRUSTED_PROXY_COUNT", 0); err != nil {
+		return Server{}, err
+	}
+	if cfg.ForceHTTPS, err = env.getEnvBool("FORCE_HTTPS", false); err != nil {
+		return Server{}, err
+	}
+
+	if cfg.DBMaxOpenConns, err = env.getEnvInt("DB_MAX_OPEN_CONNS", defaultDBMaxOpenConns); err != nil {
+		return Server{}, err
+	}
+	if cfg.DBMaxIdleConns, err = env.getEnvInt("DB_MAX_IDLE_CONNS", defaultDBMaxIdleConns); err != nil {
+		return Server{}, err
+	}
+	if cfg.DBConnMaxLifetime, err = env.getEnvDuration("DB_CONN_MAX_LIFETIME", defaultDBConnMaxLifetime); err != nil {
+		return Server{}, err
+	}
+	if cfg.DBWriteRetryAttempts, err = env.getEnvInt("DB_WRITE_RETRY_ATTEMPTS", defaultDBWriteRetries); err != nil {
+		return Server{}, err
+	}
+	if cfg.DBQueryTimeout, err = env.getEnvDuration("DB_QUERY_TIMEOUT", defaultDBQueryTimeout); err != nil {
+		return Server{}, err
+	}
+	if cfg.LogMaxSizeMB, err = env.getEnvInt("LOG_MAX_SIZE_MB", defaultLogMaxSizeMB); err != nil {
+		return Server{}, err
+	}
+	if cfg.LogMaxBackups, err = env.getEnvInt("LOG_MAX_BACKUPS", defaultLogMaxBackups); err != nil {
+		return Server{}, err
+	}
+	if cfg.LogMaxAgeDays, err = env.getEnvInt("LOG_MAX_AGE_DAYS", defaultLogMaxAgeDays); err != nil {
+		return Server{}, err
+	}
+	cfg.LogTimeFormat = timeLayout(strings.TrimSpace(env.getEnv("LOG_TIME_FORMAT", "")))
+	if cfg.LogUTC, err = env.getEnvBool("LOG_UTC", false); err != nil {
+		return Server{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Server{}, err
 	}
 
 	return cfg, nil
 }
 
-func getEnv(key, fallback string) string {
+// Validate reports every invalid setting at once so misconfiguration fails at
+// startup with a complete list rather than one problem per restart.
+func (s Server) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(s.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid SERVER_PORT %q: expected a number between 1 and 65535", s.Port))
+	}
+	if strings.TrimSpace(s.DatabaseDSN) == "" {
+		errs = append(errs, errors.New("DATABASE_DSN must not be empty"))
+	}
+	if strings.TrimSpace(s.JWTKey) == "" {
+		errs = append(errs, errors.New("JWT_KEY must not be empty"))
+	} else if s.UsesDefaultJWTKey() && s.Environment == EnvironmentProduction {
+		errs = append(errs, errors.New("JWT_KEY is set to the publicly known default; set JWT_KEY to a private secret of at least 32 bytes before running in production"))
+	}
+	if strings.TrimSpace(s.JWTIssuer) == "" {
+		errs = append(errs, errors.New("JWT_ISSUER must not be empty"))
+	}
+	if len(s.JWTAudiences) == 0 {
+		errs = append(errs, errors.New("JWT_AUDIENCE must not be empty"))
+	}
+	if s.JWTAccessLifetimeHours <= 0 {
+		errs = append(errs, fmt.Errorf("invalid JWT_ACCESS_TOKEN_LIFETIME_HOURS %d: must be positive", s.JWTAccessLifetimeHours))
+	}
+	if s.JWTServiceLifetimeHours < 0 || s.JWTServiceLifetimeHours > maxJWTLifetimeHours {
+		errs = append(errs, fmt.Errorf("invalid JWT_SERVICE_TOKEN_LIFETIME_HOURS %d: expected 0 (use the default) up to %d", s.JWTServiceLifetimeHours, maxJWTLifetimeHours))
+	}
+	if s.JWTRememberMeHours < 0 || s.JWTRememberMeHours > maxJWTLifetimeHours {
+		errs = append(errs, fmt.Errorf("invalid JWT_REMEMBER_ME_LIFETIME_HOURS %d: expected 0 (disabled) up to %d", s.JWTRememberMeHours, maxJWTLifetimeHours))
+	}
+	if s.JWTLeeway < 0 {
+		errs = append(errs, fmt.Errorf("invalid JWT_LEEWAY %s: must not be negative", s.JWTLeeway))
+	}
+	switch s.JWTClaimNames {
+	case "legacy", "standard", "both":
+	default:
+		errs = append(errs, fmt.Errorf("invalid JWT_CLAIM_NAMES %q: expected legacy, standard or both", s.JWTClaimNames))
+	}
+
+	switch s.Environment {
+	case EnvironmentDevelopment, EnvironmentTest, EnvironmentStaging, EnvironmentProduction:
+	default:
+		errs = append(errs, fmt.Errorf("invalid ENVIRONMENT %q: expected one of %q, %q, %q or %q",
+			s.Environment, EnvironmentDevelopment, EnvironmentTest, EnvironmentStaging, EnvironmentProduction))
+	}
+	switch s.PokemonSource {
+	case PokemonSourcePokeAPI, PokemonSourceOffline:
+	default:
+		errs = append(errs, fmt.Errorf("invalid POKEMON_SOURCE %q: expected %q or %q", s.PokemonSource, PokemonSourcePokeAPI, PokemonSourceOffline))
+	}
+
+	if s.PokemonPlaceholderImage != "" && !isHTTPURL(s.PokemonPlaceholderImage) {
+		errs = append(errs, fmt.Errorf("invalid POKEMON_PLACEHOLDER_IMAGE_URL %q: expected an absolute http or https URL", s.PokemonPlaceholderImage))
+	}
+	if !isHTTPURL(s.AppBaseURL) {
+		errs = append(errs, fmt.Errorf("invalid APP_BASE_URL %q: expected an absolute http or https URL", s.AppBaseURL))
+	}
+	if s.EmailTemplatesDir != "" {
+		if info, err := os.Stat(s.EmailTemplatesDir); err != nil || !info.IsDir() {
+			errs = append(errs, fmt.Errorf("invalid EMAIL_TEMPLATES_DIR %q: expected an existing directory", s.EmailTemplatesDir))
+		}
+	}
+	errs = append(errs, s.validateSMTP()...)
+	// Zero stream settings fall back to the handler defaults.
+	if s.LockoutMaxFailures < 0 {
+		errs = append(errs, fmt.Errorf("invalid AUTH_LOCKOUT_MAX_FAILURES %d: must not be negative", s.LockoutMaxFailures))
+	}
+	if s.LockoutMaxFailures > 0 && (s.LockoutWindow <= 0 || s.LockoutDuration <= 0) {
+		errs = append(errs, fmt.Errorf("invalid AUTH_LOCKOUT_WINDOW %s / AUTH_LOCKOUT_DURATION %s: must be positive while lockout is enabled", s.LockoutWindow, s.LockoutDuration))
+	}
+	if s.ShutdownDrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("invalid SHUTDOWN_DRAIN_DELAY %s: must not be negative", s.ShutdownDrainDelay))
+	}
+	if s.IdempotencyTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid IDEMPOTENCY_TTL %s: must be positive", s.IdempotencyTTL))
+	}
+	if s.EmailChangeTTL <= 0 {
+		errs = append(errs, fmt.Errorf("invalid EMAIL_CHANGE_TTL %s: must be positive", s.EmailChangeTTL))
+	}
+	if s.PokemonStreamInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid POKEMON_STREAM_INTERVAL %s: must not be negative", s.PokemonStreamInterval))
+	}
+	if s.PokemonStreamMaxClients < 0 {
+		errs = append(errs, fmt.Errorf("invalid POKEMON_STREAM_MAX_CLIENTS %d: must not be negative", s.PokemonStreamMaxClients))
+	}
+	if s.PokeAPIRequestsPerSec < 0 {
+		errs = append(errs, fmt.Errorf("invalid POKEAPI_REQUESTS_PER_SECOND %d: must not be negative", s.PokeAPIRequestsPerSec))
+	}
+	if s.PokemonSeededRandom && s.Environment == EnvironmentProduction {
+		errs = append(errs, errors.New("POKEMON_SEEDED_RANDOM makes random Pokemon predictable and must not be enabled in production"))
+	}
+
+	// An empty format means the default envelope.
+	if s.ErrorFormat != "" && s.ErrorFormat != ErrorFormatEnvelope && s.ErrorFormat != ErrorFormatProblem {
+		errs = append(errs, fmt.Errorf("invalid ERROR_FORMAT %q: expected %q or %q", s.ErrorFormat, ErrorFormatEnvelope, ErrorFormatProblem))
+	}
+
+	switch s.AuthCookieMode {
+	case "", AuthCookieModeOff, AuthCookieModeBoth, AuthCookieModeOnly:
+	default:
+		errs = append(errs, fmt.Errorf("invalid AUTH_COOKIE_MODE %q: expected %q, %q or %q", s.AuthCookieMode, AuthCookieModeOff, AuthCookieModeBoth, AuthCookieModeOnly))
+	}
+
+	if s.AccessLogSampleRate < 0 {
+		errs = append(errs, fmt.Errorf("invalid ACCESS_LOG_SAMPLE_RATE %d: must not be negative", s.AccessLogSampleRate))
+	}
+
+	switch s.MetricsExporter {
+	case "", MetricsExporterNone, MetricsExporterPrometheus:
+	case "otlp", "stdout", "console":
+		errs = append(errs, fmt.Errorf("invalid OTEL_METRICS_EXPORTER %q: this build has no %s metrics exporter; use %q and scrape /metrics", s.MetricsExporter, s.MetricsExporter, MetricsExporterPrometheus))
+	default:
+		errs = append(errs, fmt.Errorf("invalid OTEL_METRICS_EXPORTER %q: expected %q or %q", s.MetricsExporter, MetricsExporterNone, MetricsExporterPrometheus))
+	}
+
+	for _, proxy := range s.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: expected an IP address or CIDR range", proxy))
+		}
+	}
+	if s.RegisterRateLimit < 0 || s.RegisterBurst < 0 || s.RegisterDomainRateLimit < 0 {
+		errs = append(errs, errors.New("AUTH_REGISTER_RATE_LIMIT, AUTH_REGISTER_BURST and AUTH_REGISTER_DOMAIN_RATE_LIMIT must not be negative"))
+	}
+	if s.TrustedProxyCount < 0 {
+		errs = append(errs, fmt.Errorf("invalid TRUSTED_PROXY_COUNT %d: must not be negative", s.TrustedProxyCount))
+	}
+	// The server only listens over plain HTTP, so without a trusted proxy
+	// every request would be redirected, including the redirected ones.
+	if s.ForceHTTPS && len(s.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("FORCE_HTTPS requires TRUSTED_PROXIES so X-Forwarded-Proto from the TLS-terminating proxy is honoured; otherwise every request redirects in a loop"))
+	}
+
+	if s.DBMaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_MAX_OPEN_CONNS %d: must not be negative", s.DBMaxOpenConns))
+	}
+	if s.DBMaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_MAX_IDLE_CONNS %d: must not be negative", s.DBMaxIdleConns))
+	}
+	if s.DBConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME %s: must not be negative", s.DBConnMaxLifetime))
+	}
+	if s.DBQueryTimeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid DB_QUERY_TIMEOUT %s: must not be negative", s.DBQueryTimeout))
+	}
+	if s.DBWriteRetryAttempts < 1 {
+		errs = append(errs, fmt.Errorf("invalid DB_WRITE_RETRY_ATTEMPTS %d: must be at least 1", s.DBWriteRetryAttempts))
+	}
+
+	if s.LogMaxSizeMB < 0 || s.LogMaxBackups < 0 || s.LogMaxAgeDays < 0 {
+		errs = append(errs, errors.New("LOG_MAX_SIZE_MB, LOG_MAX_BACKUPS and LOG_MAX_AGE_DAYS must not be negative"))
+	}
+	if s.LogTimeFormat != "" && !isTimeLayout(s.LogTimeFormat) {
+		errs = append(errs, fmt.Errorf("invalid LOG_TIME_FORMAT %q: must be a Go time layout such as 2006-01-02T15:04:05Z07:00 or a name such as RFC3339", s.LogTimeFormat))
+	}
+
+	if (s.SeedAdminUsername == "") != (s.SeedAdminPassword == "") {
+		errs = append(errs, errors.New("SEED_ADMIN_USERNAME and SEED_ADMIN_PASSWORD must be set together"))
+	}
+
+	if s.IntrospectionAPIKey != "" && len(s.IntrospectionAPIKey) < minIntrospectionAPIKeyLength {
+		errs = append(errs, fmt.Errorf("INTROSPECTION_API_KEY must be at least %d characters", minIntrospectionAPIKeyLength))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateSMTP checks the SMTP settings, which are all optional until
+// SMTP_HOST is set.
+func (s Server) validateSMTP() []error {
+	if !s.SMTPEnabled() {
+		if s.SMTPUsername != "" || s.SMTPPassword != "" || s.SMTPFrom != "" {
+			return []error{errors.New("SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM require SMTP_HOST")}
+		}
+		return nil
+	}
+
+	var errs []error
+	if s.SMTPPort < 1 || s.SMTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("invalid SMTP_PORT %d: expected 1-65535", s.SMTPPort))
+	}
+	if _, err := mail.ParseAddress(s.SMTPFrom); err != nil {
+		errs = append(errs, fmt.Errorf("invalid SMTP_FROM %q: expected an email address", s.SMTPFrom))
+	}
+	if (s.SMTPUsername == "") != (s.SMTPPassword == "") {
+		errs = append(errs, errors.New("SMTP_USERNAME and SMTP_PASSWORD must be set together"))
+	}
+	return errs
+}
+
+// SMTPEnabled reports whether emails are delivered over SMTP rather than
+// written to the log.
+func (s Server) SMTPEnabled() bool {
+	return s.SMTPHost != ""
+}
+
+// UsesDefaultJWTKey reports whether the signing key is the sample key that
+// ships with the source code.
+func (s Server) UsesDefaultJWTKey() bool {
+	return s.JWTKey == defaultJWTKey
+}
+
+// IndentsJSON reports whether responses should be indented: JSON_INDENT is
+// honoured in development only, so every other environment stays compact.
+func (s Server) IndentsJSON() bool {
+	return s.JSONIndent && s.Environment == EnvironmentDevelopment
+}
+
+// Warnings lists settings that are allowed but unsafe outside development.
+// Callers should log them once the logger is available.
+func (s Server) Warnings() []string {
+	var warnings []string
+	if s.UsesDefaultJWTKey() {
+		warnings = append(warnings, "JWT_KEY is using the built-in default key; tokens can be forged by anyone with the source code. Set JWT_KEY before deploying.")
+	}
+	if s.JSONIndent && !s.IndentsJSON() {
+		warnings = append(warnings, "JSON_INDENT only applies in development and is ignored; responses stay compact.")
+	}
+	return warnings
+}
+
+// envSource resolves configuration keys from the process environment, falling
+// back to values loaded from a dotenv file.
+type envSource struct {
+	file map[string]string
+}
+
+func newEnvSource() (envSource, error) {
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit || path == "" {
+		path = defaultConfigFile
+	}
+
+	values, err := readDotEnv(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return envSource{}, nil
+	}
+	if err != nil {
+		return envSource{}, fmt.Errorf("load config file %s: %w", path, err)
+	}
+	return envSource{file: values}, nil
+}
+
+func (e envSource) lookup(key string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
 	}
+	return e.file[key]
+}
+
+func (e envSource) getEnv(key, fallback string) string {
+	if val := e.lookup(key); val != "" {
+		return val
+	}
 	return fallback
 }
+
+func (e envSource) getEnvBool(key string, fallback bool) (bool, error) {
+	val := e.lookup(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func (e envSource) getEnvInt(key string, fallback int) (int, error) {
+	val := e.lookup(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func (e envSource) getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	val := e.lookup(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// splitList parses a comma-separated value, dropping blank entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readList reads one lowercase entry per line from path, skipping blank lines
+// and "#" comments.
+func readList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var items []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		items = append(items, strings.ToLower(line))
+	}
+	return items, scanner.Err()
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// namedTimeLayouts lets LOG_TIME_FORMAT name a standard layout instead of
+// spelling out the reference time.
+var namedTimeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339NANO": time.RFC3339Nano,
+	"DATETIME":    time.DateTime,
+	"STAMPMILLI":  time.StampMilli,
+	"UNIXDATE":    time.UnixDate,
+}
+
+// timeLayout resolves a layout name, case-insensitively, and returns any
+// other value unchanged.
+func timeLayout(value string) string {
+	if layout, ok := namedTimeLayouts[strings.ToUpper(value)]; ok {
+		return layout
+	}
+	return value
+}
+
+// isTimeLayout reports whether layout contains at least one element of the
+// reference time, so it formats differently from the literal text.
+func isTimeLayout(layout string) bool {
+	reference := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	return reference.Format(layout) != layout
+}
+
+func isIPOrCIDR(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+// readDotEnv parses KEY=VALUE lines. Blank lines, "#" comments and an optional
+// "export " prefix are ignored; values may be wrapped in single or double quotes.
+func readDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNumber)
+		}
+		values[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
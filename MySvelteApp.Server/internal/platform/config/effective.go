@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const redacted = "REDACTED"
+
+// secretFields never appear in logs; only whether they are set and their
+// length is reported.
+var secretFields = map[string]bool{
+	"JWTKey":              true,
+	"JWTPreviousKeys":     true,
+	"SeedAdminPassword":   true,
+	"IntrospectionAPIKey": true,
+	"SMTPPassword":        true,
+}
+
+// LogEffective logs every setting at info level so operators can see what
+// the server booted with. Secrets are masked and passwords embedded in the
+// database DSN are redacted.
+func (s Server) LogEffective(logger *slog.Logger) {
+	value := reflect.ValueOf(s)
+	fields := value.Type()
+	attrs := make([]any, 0, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Field(i).Name
+		attrs = append(attrs, slog.Any(snakeCase(name), effectiveValue(name, value.Field(i).Interface())))
+	}
+	logger.Info("effective configuration", attrs...)
+}
+
+func effectiveValue(name string, value any) any {
+	switch {
+	case secretFields[name]:
+		if secrets, ok := value.([]string); ok {
+			masked := make([]string, len(secrets))
+			for i, secret := range secrets {
+				masked[i] = maskSecret(secret)
+			}
+			return masked
+		}
+		return maskSecret(value.(string))
+	case name == "DatabaseDSN":
+		return maskDSN(value.(string))
+	}
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String()
+	}
+	return value
+}
+
+// maskSecret reports whether a secret is set without revealing it.
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%d chars)", redacted, len(secret))
+}
+
+// maskDSN redacts a URL password and any query parameter that looks like a
+// credential, such as SQLite's _auth_pass.
+func maskDSN(dsn string) string {
+	base, query, hasQuery := strings.Cut(dsn, "?")
+	if parsed, err := url.Parse(base); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+			base = parsed.String()
+		}
+	}
+	if !hasQuery {
+		return base
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, ok := strings.Cut(param, "=")
+		lower := strings.ToLower(key)
+		if ok && (strings.Contains(lower, "pass") || strings.Contains(lower, "secret") || strings.Contains(lower, "token")) {
+			params[i] = key + "=" + redacted
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// snakeCase converts a Go field name such as "JWTAccessLifetimeHours" to
+// "jwt_access_lifetime_hours".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var out strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				out.WriteByte('_')
+			}
+		}
+		out.WriteRune(unicode.ToLower(r))
+	}
+	return out.String()
+}
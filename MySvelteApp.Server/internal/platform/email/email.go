@@ -0,0 +1,62 @@
+// Package email delivers plain-text and HTML email.
+package email
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// Message is a single email. Body is plain text unless HTML is set.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    bool
+}
+
+// Validate rejects messages without a recipient or whose headers contain line
+// breaks, which would let a caller inject extra headers.
+func (m Message) Validate() error {
+	if strings.TrimSpace(m.To) == "" {
+		return errors.New("email recipient is required")
+	}
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return errors.New("email headers must not contain line breaks")
+	}
+	return nil
+}
+
+// Sender delivers messages. Implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender writes a line to the log for each message instead of sending it.
+// It is the default when no SMTP server is configured. Only the recipient and
+// subject are logged: bodies carry single-use tokens, and anyone who can read
+// the logs must not be able to use them.
+type LogSender struct {
+	logger *slog.Logger
+}
+
+// NewLogSender builds a LogSender. A nil logger uses slog.Default.
+func NewLogSender(logger *slog.Logger) *LogSender {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LogSender{logger: logger}
+}
+
+// Send logs msg's recipient and subject.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "email not sent; no SMTP server configured",
+		"to", msg.To,
+		"subject", msg.Subject,
+	)
+	return nil
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// Handler godoc
+// @Summary Service health
+// @Description Reports each dependency with its status and latency. Returns 503 when a critical dependency is down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /health [get]
+func Handler(checker *Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Run(c.Request.Context())
+		status := http.StatusOK
+		if !report.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+		httpapi.WriteJSON(c, status, report)
+	}
+}
+
+// ReadyHandler godoc
+// @Summary Service readiness
+// @Description Reports whether the service should receive traffic. Returns 503 while shutting down or when a critical dependency is down.
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func ReadyHandler(readiness *Readiness, checker *Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readiness.Draining() {
+			httpapi.WriteJSON(c, http.StatusServiceUnavailable, Report{Status: StatusDraining, Components: []ComponentReport{}})
+			return
+		}
+		Handler(checker)(c)
+	}
+}
@@ -0,0 +1,144 @@
+// Package health reports the status of the service's dependencies.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Component statuses.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+// ErrCheckPending is reported by a CachedCheck before its first result arrives.
+var ErrCheckPending = errors.New("check has not completed yet")
+
+// Check probes a single dependency. Failing critical checks make the service
+// unhealthy; failing non-critical checks are reported but tolerated.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// ComponentReport is the outcome of one check.
+type ComponentReport struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report aggregates all component results.
+type Report struct {
+	Status     string            `json:"status"`
+	Components []ComponentReport `json:"components"`
+}
+
+// Healthy reports whether every critical component is up.
+func (r Report) Healthy() bool {
+	return r.Status == StatusUp
+}
+
+// Checker runs a fixed set of checks concurrently.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker builds a Checker. Each check gets its own timeout so one slow
+// dependency cannot hold up the whole report.
+func NewChecker(checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: defaultCheckTimeout}
+}
+
+// Run executes all checks and returns the aggregated report.
+func (c *Checker) Run(ctx context.Context) Report {
+	components := make([]ComponentReport, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			components[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: components}
+	for _, component := range components {
+		if component.Critical && component.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	component := ComponentReport{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Critical:  check.Critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// CachedCheck wraps a slow probe so health requests never wait on it. Calls
+// return the last known result immediately and trigger a background refresh
+// once it is older than ttl.
+type CachedCheck struct {
+	probe func(ctx context.Context) error
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	lastErr    error
+	checkedAt  time.Time
+	refreshing bool
+}
+
+// NewCachedCheck builds a CachedCheck around probe.
+func NewCachedCheck(probe func(ctx context.Context) error, ttl time.Duration) *CachedCheck {
+	return &CachedCheck{probe: probe, ttl: ttl, lastErr: ErrCheckPending}
+}
+
+// Run returns the cached result, scheduling a refresh when it is stale.
+func (c *CachedCheck) Run(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.refreshing && time.Since(c.checkedAt) >= c.ttl {
+		c.refreshing = true
+		go c.refresh()
+	}
+	return c.lastErr
+}
+
+func (c *CachedCheck) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCheckTimeout)
+	defer cancel()
+	err := c.probe(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastErr = err
+	c.checkedAt = time.Now()
+	c.refreshing = false
+}
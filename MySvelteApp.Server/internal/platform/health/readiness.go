@@ -0,0 +1,29 @@
+package health
+
+import "sync/atomic"
+
+// StatusDraining reports that the service is shutting down and should receive
+// no new traffic.
+const StatusDraining = "draining"
+
+// Readiness records whether the service is shutting down. It is safe for
+// concurrent use.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+// NewReadiness returns a Readiness that accepts traffic.
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// StartDraining makes readiness probes fail from now on so load balancers
+// stop routing new requests while in-flight ones finish.
+func (r *Readiness) StartDraining() {
+	r.draining.Store(true)
+}
+
+// Draining reports whether StartDraining has been called.
+func (r *Readiness) Draining() bool {
+	return r.draining.Load()
+}
@@ -0,0 +1,5 @@
+package httpapi
+
+// APIKeyHeader carries a shared key identifying an internal service caller.
+// Like Authorization, browsers never attach it on their own.
+const APIKeyHeader = "X-API-Key"
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const clientIPContextKey = "httpapi.client_ip"
+
+// ClientIPResolver determines the caller's address. X-Forwarded-For is only
+// honoured when the request arrives from a trusted proxy; otherwise the
+// connection's remote address is used, so clients cannot spoof their IP.
+type ClientIPResolver struct {
+	trusted    []netip.Prefix
+	proxyCount int
+}
+
+// NewClientIPResolver parses trustedProxies as IP addresses or CIDR ranges.
+// A positive proxyCount selects the client as the entry that many hops from
+// the end of the forwarded chain, counting the proxy that connected to us;
+// zero instead skips every trailing hop that belongs to a trusted range.
+func NewClientIPResolver(trustedProxies []string, proxyCount int) (*ClientIPResolver, error) {
+	if proxyCount < 0 {
+		return nil, fmt.Errorf("trusted proxy count %d must not be negative", proxyCount)
+	}
+	resolver := &ClientIPResolver{proxyCount: proxyCount}
+	for _, proxy := range trustedProxies {
+		prefix, err := parsePrefix(strings.TrimSpace(proxy))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		resolver.trusted = append(resolver.trusted, prefix)
+	}
+	return resolver, nil
+}
+
+// TrustedProxies returns the trusted ranges in CIDR notation, e.g. for
+// gin.Engine.SetTrustedProxies.
+func (r *ClientIPResolver) TrustedProxies() []string {
+	proxies := make([]string, 0, len(r.trusted))
+	for _, prefix := range r.trusted {
+		proxies = append(proxies, prefix.String())
+	}
+	return proxies
+}
+
+// Resolve returns the client address for req.
+func (r *ClientIPResolver) Resolve(req *http.Request) string {
+	remote, ok := parseHostAddr(req.RemoteAddr)
+	if !ok {
+		return req.RemoteAddr
+	}
+	if !r.isTrusted(remote) {
+		return remote.String()
+	}
+
+	var hops []netip.Addr
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		for _, entry := range strings.Split(header, ",") {
+			addr, ok := parseHostAddr(strings.TrimSpace(entry))
+			if !ok {
+				// A malformed chain cannot be trusted past this point.
+				return remote.String()
+			}
+			hops = append(hops, addr)
+		}
+	}
+	if len(hops) == 0 {
+		return remote.String()
+	}
+
+	if r.proxyCount > 0 {
+		if len(hops) < r.proxyCount {
+			return remote.String()
+		}
+		return hops[len(hops)-r.proxyCount].String()
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !r.isTrusted(hops[i]) {
+			return hops[i].String()
+		}
+	}
+	return hops[0].String()
+}
+
+// Scheme returns "https" or "http" for the client's side of req. A direct TLS
+// connection is https; otherwise X-Forwarded-Proto is honoured only when the
+// request arrives from a trusted proxy, taking its first entry, which the
+// proxy nearest the client set.
+func (r *ClientIPResolver) Scheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	remote, ok := parseHostAddr(req.RemoteAddr)
+	if !ok || !r.isTrusted(remote) {
+		return "http"
+	}
+	proto, _, _ := strings.Cut(req.Header.Get("X-Forwarded-Proto"), ",")
+	if strings.EqualFold(strings.TrimSpace(proto), "https") {
+		return "https"
+	}
+	return "http"
+}
+
+func (r *ClientIPResolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveClientIP stores the resolved client address for ClientIP.
+func ResolveClientIP(resolver *ClientIPResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(clientIPContextKey, resolver.Resolve(c.Request))
+		c.Next()
+	}
+}
+
+// ClientIP returns the address resolved by ResolveClientIP, falling back to
+// gin's own resolution when the middleware is not installed.
+func ClientIP(c *gin.Context) string {
+	if ip := c.GetString(clientIPContextKey); ip != "" {
+		return ip
+	}
+	return c.ClientIP()
+}
+
+func parsePrefix(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parseHostAddr accepts "ip", "ip:port", "[ipv6]" and "[ipv6]:port" forms and
+// normalises IPv4-mapped IPv6 addresses to plain IPv4.
+func parseHostAddr(value string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireJSON rejects POST, PUT and PATCH requests whose body is not JSON with
+// 415 Unsupported Media Type. Requests without a body pass through so endpoints
+// that take no payload keep working.
+func RequireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if hasEmptyBody(c.Request) || isJSONContentType(c.GetHeader("Content-Type")) {
+			c.Next()
+			return
+		}
+
+		AbortWithError(c, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Request body must be JSON (Content-Type: application/json).")
+	}
+}
+
+func hasEmptyBody(r *http.Request) bool {
+	return r.ContentLength == 0 && len(r.TransferEncoding) == 0
+}
+
+// isJSONContentType accepts application/json and structured "+json" types,
+// ignoring parameters such as charset.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest is the non-standard status nginx uses when the
+// client disconnects before the response is written. Nobody receives it; it
+// keeps access logs and metrics from counting the request as a server error.
+const StatusClientClosedRequest = 499
+
+// ContextError maps err to a response when it stems from the request's own
+// context being cancelled or timing out. ok is false for any other error,
+// including timeouts of outbound calls made while the request was still live.
+func ContextError(ctx context.Context, err error) (status int, body ErrorBody, ok bool) {
+	if ctx.Err() == nil {
+		return 0, ErrorBody{}, false
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest, ErrorBody{Code: CodeClientClosedRequest, Message: "Client closed the request."}, true
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusRequestTimeout, ErrorBody{Code: CodeRequestTimeout, Message: "The request took too long to process."}, true
+	default:
+		return 0, ErrorBody{}, false
+	}
+}
+
+// WriteContextError writes the ContextError response for err and reports
+// whether it did. Handlers call it before their own error mapping.
+func WriteContextError(c *gin.Context, err error) bool {
+	status, body, ok := ContextError(c.Request.Context(), err)
+	if ok {
+		WriteErrorBody(c, status, body)
+	}
+	return ok
+}
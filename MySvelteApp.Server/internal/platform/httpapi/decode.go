@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const strictJSONContextKey = "httpapi.strictJSON"
+
+// DisallowUnknownFields makes BindJSON reject payloads carrying fields the
+// target struct does not declare, so client typos fail loudly instead of
+// being ignored.
+func DisallowUnknownFields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictJSONContextKey, true)
+		c.Next()
+	}
+}
+
+// BindJSON decodes the request body into obj and applies its binding tags.
+// On failure it writes a 400 describing what was wrong with the body and
+// returns false; handlers should return without writing anything else.
+func BindJSON(c *gin.Context, obj any) bool {
+	decoder := json.NewDecoder(c.Request.Body)
+	if c.GetBool(strictJSONContextKey) {
+		decoder.DisallowUnknownFields()
+	}
+
+	err := decoder.Decode(obj)
+	if err == nil && decoder.More() {
+		err = errTrailingData
+	}
+	if err == nil {
+		if validateErr := binding.Validator.ValidateStruct(obj); validateErr != nil {
+			WriteError(c, http.StatusBadRequest, CodeInvalidRequest, "Invalid request payload.")
+			return false
+		}
+		return true
+	}
+
+	WriteErrorBody(c, http.StatusBadRequest, decodeErrorBody(err))
+	return false
+}
+
+var errTrailingData = errors.New("trailing data after JSON value")
+
+// decodeErrorBody describes a json.Decoder failure in client terms.
+func decodeErrorBody(err error) ErrorBody {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrorBody{Code: CodeInvalidRequest, Message: "Request body is required."}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorBody{Code: CodeInvalidRequest, Message: "Request body is not valid JSON."}
+	case errors.Is(err, errTrailingData):
+		return ErrorBody{Code: CodeInvalidRequest, Message: "Request body must contain a single JSON value."}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return ErrorBody{Code: CodeInvalidRequest, Message: fmt.Sprintf("Request body must be a JSON %s.", jsonKind(typeErr.Type))}
+		}
+		return ErrorBody{
+			Code:    CodeInvalidRequest,
+			Message: fmt.Sprintf("Field %q must be a %s.", typeErr.Field, jsonKind(typeErr.Type)),
+			Field:   typeErr.Field,
+		}
+	}
+
+	// encoding/json has no typed error for unknown fields.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field = strings.Trim(field, `"`)
+		return ErrorBody{Code: CodeInvalidRequest, Message: fmt.Sprintf("Unknown field %q.", field), Field: field}
+	}
+	return ErrorBody{Code: CodeInvalidRequest, Message: "Invalid request payload."}
+}
+
+// jsonKind names a Go type the way a JSON client would.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,60 @@
+package httpapi
+
+import "github.com/gin-gonic/gin"
+
+// Stable error codes clients can branch on instead of parsing messages.
+const (
+	CodeInvalidRequest        = "INVALID_REQUEST"
+	CodeValidation            = "VALIDATION_FAILED"
+	CodeUnauthorized          = "UNAUTHORIZED"
+	CodeForbidden             = "FORBIDDEN"
+	CodeNotFound              = "NOT_FOUND"
+	CodeMethodNotAllowed      = "METHOD_NOT_ALLOWED"
+	CodeUnsupportedMediaType  = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeAccountLocked         = "ACCOUNT_LOCKED"
+	CodeConflict              = "CONFLICT"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+	CodeUpstreamUnavailable   = "UPSTREAM_UNAVAILABLE"
+	CodeClientClosedRequest   = "CLIENT_CLOSED_REQUEST"
+	CodeRequestTimeout        = "REQUEST_TIMEOUT"
+	CodeInternal              = "INTERNAL_ERROR"
+)
+
+// ErrorResponse is the envelope returned by every failing endpoint.
+// @name ErrorResponse
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a failure. Field and Reason are only populated for
+// validation failures and name the offending input and the rule it broke.
+// @name ErrorBody
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// WriteError writes the standard error envelope.
+func WriteError(c *gin.Context, status int, code, message string) {
+	WriteErrorBody(c, status, ErrorBody{Code: code, Message: message})
+}
+
+// WriteErrorBody writes the standard error envelope around a prepared body, or
+// problem details when NegotiateProblemDetails selected them for the request.
+func WriteErrorBody(c *gin.Context, status int, body ErrorBody) {
+	if c.GetBool(problemContextKey) {
+		writeProblem(c, status, body)
+		return
+	}
+	WriteJSON(c, status, ErrorResponse{Error: body})
+}
+
+// AbortWithError writes the error response and stops the handler chain.
+func AbortWithError(c *gin.Context, status int, code, message string) {
+	c.Abort()
+	WriteError(c, status, code, message)
+}
@@ -0,0 +1,157 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/idempotency"
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a retryable request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes bounds the body read to fingerprint a request.
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// Idempotency replays the stored response when a request repeats the
+// Idempotency-Key of an earlier one on the same route, instead of running the
+// handler again. Routes are compared by RouteID, so a versioned path and its
+// unversioned alias count as the same route. Keys are remembered for ttl. Requests without the header are
+// not affected. Reusing a key with a different body is rejected with 422, and
+// a retry arriving while the original is still running gets 409. Server
+// errors and 429 responses are not stored, so the client can retry them.
+func Idempotency(store idempotency.Store, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			AbortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "Idempotency-Key must be at most 255 characters.")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			AbortWithError(c, http.StatusRequestEntityTooLarge, CodeInvalidRequest, "Request body is too large.")
+			return
+		case err != nil:
+			AbortWithError(c, http.StatusBadRequest, CodeInvalidRequest, "Request body could not be read.")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := c.Request.Method + " " + RouteID(c) + " " + key
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		stored, err := store.Begin(ctx, storeKey, ttl)
+		switch {
+		case errors.Is(err, idempotency.ErrInFlight):
+			AbortWithError(c, http.StatusConflict, CodeIdempotencyInProgress, "A request with this Idempotency-Key is still in progress.")
+			return
+		case err != nil:
+			c.Error(err)
+			AbortWithError(c, http.StatusInternalServerError, CodeInternal, "An unexpected error occurred.")
+			return
+		case stored != nil:
+			if stored.Fingerprint != fingerprint {
+				AbortWithError(c, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "Idempotency-Key was already used with a different request body.")
+				return
+			}
+			replay(c, stored)
+			return
+		}
+
+		release := func() {
+			if err := store.Release(ctx, storeKey); err != nil {
+				logging.FromContext(ctx).Warn("failed to release idempotency key", "error", err)
+			}
+		}
+		// Recovery runs outside this middleware, so a panicking handler must
+		// give the key back on its way out.
+		completed := false
+		defer func() {
+			if !completed {
+				release()
+			}
+		}()
+
+		before := c.Writer.Header().Clone()
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		completed = true
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			release()
+			return
+		}
+		err = store.Complete(ctx, storeKey, idempotency.Response{
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      changedHeaders(before, c.Writer.Header()),
+			Body:        recorder.body.Bytes(),
+		}, ttl)
+		if err != nil {
+			logging.FromContext(ctx).Warn("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// changedHeaders returns the headers the handler set, leaving out those
+// earlier middleware wrote for this request alone, such as its request ID.
+func changedHeaders(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			changed[name] = slices.Clone(values)
+		}
+	}
+	return changed
+}
+
+func replay(c *gin.Context, stored *idempotency.Response) {
+	header := c.Writer.Header()
+	for name, values := range stored.Header {
+		header[name] = values
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+	c.Status(stored.Status)
+	_, _ = c.Writer.Write(stored.Body)
+	c.Abort()
+}
+
+// responseRecorder copies the response body while passing it through.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,82 @@
+// Package httpapi holds presentation types shared by the module HTTP handlers.
+package httpapi
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PagedResponse is the envelope returned by list endpoints.
+type PagedResponse[T any] struct {
+	Items      []T   `json:"items"`
+	Page       int   `json:"page"`
+	PageSize   int   `json:"pageSize"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+// NewPagedResponse builds the envelope for items fetched with offset and limit
+// out of total matching records. Page numbers start at one.
+func NewPagedResponse[T any](items []T, offset, limit int, total int64) PagedResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	page, totalPages := 1, 0
+	if limit > 0 {
+		page = offset/limit + 1
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	} else if total > 0 {
+		totalPages = 1
+	}
+
+	return PagedResponse[T]{
+		Items:      items,
+		Page:       page,
+		PageSize:   limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// PageQuery is a validated page/pageSize pair from the query string.
+type PageQuery struct {
+	Page     int
+	PageSize int
+}
+
+// Offset returns the number of records to skip.
+func (q PageQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// Limit returns the maximum number of records to fetch.
+func (q PageQuery) Limit() int {
+	return q.PageSize
+}
+
+// ParsePageQuery reads the "page" and "pageSize" query parameters, applying
+// defaultPageSize when absent. It rejects non-positive values and page sizes
+// above maxPageSize.
+func ParsePageQuery(c *gin.Context, defaultPageSize, maxPageSize int) (PageQuery, error) {
+	query := PageQuery{Page: 1, PageSize: defaultPageSize}
+
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return PageQuery{}, fmt.Errorf("page must be a positive integer")
+		}
+		query.Page = page
+	}
+	if raw := c.Query("pageSize"); raw != "" {
+		pageSize, err := strconv.Atoi(raw)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return PageQuery{}, fmt.Errorf("pageSize must be between 1 and %d", maxPageSize)
+		}
+		query.PageSize = pageSize
+	}
+
+	return query, nil
+}
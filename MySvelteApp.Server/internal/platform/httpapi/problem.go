@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the RFC 7807 media type for problem details.
+const ProblemContentType = "application/problem+json"
+
+const problemContextKey = "httpapi.problem"
+
+// ProblemDetails is the RFC 7807 rendering of an ErrorBody. Code, Field and
+// Reason are extension members carrying the same values as the envelope.
+// @name ProblemDetails
+type ProblemDetails struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewProblemDetails converts an error body into problem details. The type is
+// "about:blank", so the title is the status text as RFC 7807 prescribes.
+func NewProblemDetails(status int, body ErrorBody) ProblemDetails {
+	return ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: body.Message,
+		Code:   body.Code,
+		Field:  body.Field,
+		Reason: body.Reason,
+	}
+}
+
+// NegotiateProblemDetails makes the error helpers answer with problem details
+// when the client's Accept header lists application/problem+json. Other
+// clients keep receiving the standard envelope.
+func NegotiateProblemDetails() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if acceptsProblemDetails(c.GetHeader("Accept")) {
+			c.Set(problemContextKey, true)
+		}
+		c.Next()
+	}
+}
+
+func acceptsProblemDetails(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && mediaType == ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
+
+func writeProblem(c *gin.Context, status int, body ErrorBody) {
+	var payload []byte
+	var err error
+	if c.GetBool(indentJSONContextKey) {
+		payload, err = json.MarshalIndent(NewProblemDetails(status, body), "", "    ")
+	} else {
+		payload, err = json.Marshal(NewProblemDetails(status, body))
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, ProblemContentType, payload)
+}
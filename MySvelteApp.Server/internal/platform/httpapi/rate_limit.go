@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/ratelimit"
+)
+
+// RateLimitByClientIP rejects requests with 429 Too Many Requests once the
+// caller's IP exceeds limiter's budget, setting Retry-After in seconds.
+func RateLimitByClientIP(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.Allow(ClientIP(c))
+		if allowed {
+			c.Next()
+			return
+		}
+		AbortRateLimited(c, retryAfter)
+	}
+}
+
+// AbortRateLimited rejects the request with 429 Too Many Requests, setting
+// Retry-After to retryAfter rounded up to whole seconds.
+func AbortRateLimited(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	AbortWithError(c, http.StatusTooManyRequests, CodeRateLimited, "Too many requests. Please try again later.")
+}
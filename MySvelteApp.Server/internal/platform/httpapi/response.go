@@ -0,0 +1,25 @@
+package httpapi
+
+import "github.com/gin-gonic/gin"
+
+const indentJSONContextKey = "httpapi.indentJSON"
+
+// IndentJSON makes WriteJSON and the error writers indent their output so
+// responses are readable in a terminal. Meant for development only.
+func IndentJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(indentJSONContextKey, true)
+		c.Next()
+	}
+}
+
+// WriteJSON writes obj as the JSON response body, indented when IndentJSON
+// is active for the request. Handlers use it instead of c.JSON so every
+// module honours the setting.
+func WriteJSON(c *gin.Context, status int, obj any) {
+	if c.GetBool(indentJSONContextKey) {
+		c.IndentedJSON(status, obj)
+		return
+	}
+	c.JSON(status, obj)
+}
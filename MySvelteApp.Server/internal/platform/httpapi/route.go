@@ -0,0 +1,25 @@
+package httpapi
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const routePrefixContextKey = "httpapi.routePrefix"
+
+// RoutePrefix marks the routes of a group as mounted beneath prefix, so that
+// RouteID names them the same as the same routes mounted elsewhere.
+func RoutePrefix(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(routePrefixContextKey, prefix)
+		c.Next()
+	}
+}
+
+// RouteID identifies the matched route without the prefix set by
+// RoutePrefix, so /api/v1/auth/register and its unversioned alias
+// /auth/register share one identity.
+func RouteID(c *gin.Context) string {
+	return strings.TrimPrefix(c.FullPath(), c.GetString(routePrefixContextKey))
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultTimeout bounds a whole outbound request, including reading the body.
+const DefaultTimeout = 10 * time.Second
+
+const instrumentationName = "mysvelteapp/server_new/internal/platform/httpclient"
+
+// Options tunes the client built by New.
+type Options struct {
+	// Timeout caps each request; zero selects DefaultTimeout.
+	Timeout time.Duration
+	// Transport performs the actual round trip; nil selects http.DefaultTransport.
+	Transport http.RoundTripper
+	// Propagator injects trace context into outbound headers; nil selects the
+	// global propagator.
+	Propagator propagation.TextMapPropagator
+	// TracerProvider creates client spans; nil selects the global provider.
+	TracerProvider trace.TracerProvider
+}
+
+// New builds an *http.Client for calling external services. Every request is
+// recorded as a client span and carries the caller's trace context so the
+// upstream call joins the incoming request's trace.
+func New(options Options) *http.Client {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.Transport == nil {
+		options.Transport = http.DefaultTransport
+	}
+	return &http.Client{
+		Timeout: options.Timeout,
+		Transport: &tracingTransport{
+			base:           options.Transport,
+			propagator:     options.Propagator,
+			tracerProvider: options.TracerProvider,
+		},
+	}
+}
+
+type tracingTransport struct {
+	base           http.RoundTripper
+	propagator     propagation.TextMapPropagator
+	tracerProvider trace.TracerProvider
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	provider := t.tracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	propagator := t.propagator
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+
+	ctx, span := provider.Tracer(instrumentationName).Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.Redacted()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// RoundTrippers must not modify the caller's request.
+	outbound := req.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(outbound.Header))
+
+	resp, err := t.base.RoundTrip(outbound)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, fmt.Sprintf("upstream returned status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
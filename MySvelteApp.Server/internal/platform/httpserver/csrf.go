@@ -0,0 +1,72 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+const (
+	// CSRFCookieName is the cookie carrying the double-submit token. It is
+	// readable by scripts so the client can echo it in CSRFHeader.
+	CSRFCookieName = "csrf_token"
+	// CSRFHeader must repeat the CSRFCookieName value on unsafe requests.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// csrfMiddleware implements double-submit-cookie CSRF protection. Every
+// response without a token cookie gets one; unsafe methods must echo the
+// cookie in CSRFHeader. Requests carrying an Authorization or
+// httpapi.APIKeyHeader header are exempt because browsers never attach
+// either on their own.
+func csrfMiddleware(secureCookie bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" || c.GetHeader(httpapi.APIKeyHeader) != "" {
+			c.Next()
+			return
+		}
+
+		cookie, err := c.Cookie(CSRFCookieName)
+		if err != nil || cookie == "" {
+			cookie = ""
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     CSRFCookieName,
+				Value:    newCSRFToken(),
+				Path:     "/",
+				Secure:   secureCookie,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		header := c.GetHeader(CSRFHeader)
+		if cookie == "" || header == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			httpapi.AbortWithError(c, http.StatusForbidden, httpapi.CodeForbidden, "CSRF token missing or invalid.")
+			return
+		}
+		c.Next()
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,21 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// notFoundHandler answers requests that match no route with the standard
+// error envelope instead of gin's plain-text 404.
+func notFoundHandler(c *gin.Context) {
+	httpapi.WriteError(c, http.StatusNotFound, httpapi.CodeNotFound, "Route not found.")
+}
+
+// methodNotAllowedHandler answers requests whose path exists only under other
+// methods. gin has already listed those methods in the Allow header.
+func methodNotAllowedHandler(c *gin.Context) {
+	httpapi.WriteError(c, http.StatusMethodNotAllowed, httpapi.CodeMethodNotAllowed, "Method not allowed.")
+}
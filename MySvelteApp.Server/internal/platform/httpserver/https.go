@@ -0,0 +1,30 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// plainHTTPRoutes stay reachable over plain HTTP when HTTPS is forced:
+// orchestrator probes and metrics scrapers call the pod directly and do not
+// follow redirects.
+var plainHTTPRoutes = []string{"/health", "/readyz", "/metrics"}
+
+// forceHTTPSMiddleware redirects plain-HTTP requests to the same URL over
+// https with 308, which keeps the method and body. The scheme comes from
+// resolver, so X-Forwarded-Proto is only believed from trusted proxies.
+// Routes under plainHTTPRoutes are served as they arrive.
+func forceHTTPSMiddleware(resolver *httpapi.ClientIPResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if resolver.Scheme(c.Request) == "https" || skipRoute(c.FullPath(), plainHTTPRoutes) {
+			c.Next()
+			return
+		}
+		target := "https://" + c.Request.Host + c.Request.URL.RequestURI()
+		c.Redirect(http.StatusPermanentRedirect, target)
+		c.Abort()
+	}
+}
@@ -0,0 +1,72 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+const meterName = "mysvelteapp/server_new/internal/platform/httpserver"
+
+// recoveryMiddleware turns handler panics into a 500 error envelope. The panic
+// value and stack go to the request logger and the active span; clients only
+// see a generic message.
+func recoveryMiddleware() gin.HandlerFunc {
+	panics, err := otel.Meter(meterName).Int64Counter("http.server.panics",
+		metric.WithDescription("Handler panics recovered by the HTTP server"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler deliberately aborts the response; let
+			// net/http handle it quietly as it would without this middleware.
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			ctx := c.Request.Context()
+			panicErr := fmt.Errorf("panic: %v", recovered)
+
+			logging.FromContext(ctx).Error("panic recovered",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+
+			span := trace.SpanFromContext(ctx)
+			span.RecordError(panicErr, trace.WithStackTrace(true))
+			span.SetStatus(codes.Error, panicErr.Error())
+
+			if panics != nil {
+				panics.Add(ctx, 1, metric.WithAttributes(attribute.String("http.route", c.FullPath())))
+			}
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			httpapi.AbortWithError(c, http.StatusInternalServerError, httpapi.CodeInternal, "An unexpected error occurred.")
+		}()
+
+		c.Next()
+	}
+}
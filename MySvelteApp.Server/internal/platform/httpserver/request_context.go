@@ -0,0 +1,46 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+// RequestIDHeader carries the request ID in both directions. A caller-supplied
+// value is reused so IDs can be correlated across services.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// requestContextMiddleware stores a logger pre-populated with the request and
+// trace IDs in the request context; retrieve it with logging.FromContext. It
+// must run after the tracing middleware so the server span already exists.
+func requestContextMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		requestLogger := logger.With("request_id", requestID)
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			requestLogger = requestLogger.With("trace_id", spanContext.TraceID().String())
+		}
+
+		c.Request = c.Request.WithContext(logging.NewContext(ctx, requestLogger))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -1,39 +1,222 @@
 package httpserver
 
 import (
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 	otelgin "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/metric"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/logging"
 )
 
+// SwaggerPath is the route serving the Swagger UI when WithSwaggerUI is set.
+const SwaggerPath = "/swagger/*any"
+
+// Option customises the engine built by New.
+type Option func(*options)
+
+type options struct {
+	problemDetails bool
+	strictJSON     bool
+	indentJSON     bool
+	clientIP       *httpapi.ClientIPResolver
+
+	forceHTTPS       bool
+	csrf             bool
+	csrfSecureCookie bool
+
+	accessLogSampleRate int
+	accessLogSkipPaths  []string
+
+	swagger bool
+
+	meterProvider metric.MeterProvider
+}
+
+// WithProblemDetails lets clients that accept application/problem+json
+// receive RFC 7807 problem details instead of the standard error envelope.
+func WithProblemDetails() Option {
+	return func(o *options) {
+		o.problemDetails = true
+	}
+}
+
+// WithStrictJSON rejects request bodies with fields the handler's payload
+// type does not declare.
+func WithStrictJSON() Option {
+	return func(o *options) {
+		o.strictJSON = true
+	}
+}
+
+// WithIndentedJSON indents JSON responses, including errors, for readability
+// during development. Leave it off in production to keep payloads compact.
+func WithIndentedJSON() Option {
+	return func(o *options) {
+		o.indentJSON = true
+	}
+}
+
+// WithForceHTTPS redirects plain-HTTP requests to https with 308. Behind a
+// TLS-terminating proxy, configure WithClientIPResolver so the proxy's
+// X-Forwarded-Proto is trusted; otherwise every request looks like HTTP.
+// Health, readiness and metrics routes are never redirected.
+func WithForceHTTPS() Option {
+	return func(o *options) {
+		o.forceHTTPS = true
+	}
+}
+
+// WithCSRFProtection requires unsafe requests that do not use an
+// Authorization header to echo the CSRFCookieName cookie in CSRFHeader.
+// secureCookie marks the token cookie HTTPS-only.
+func WithCSRFProtection(secureCookie bool) Option {
+	return func(o *options) {
+		o.csrf = true
+		o.csrfSecureCookie = secureCookie
+	}
+}
+
+// WithAccessLogSampling logs one in every rate successful (2xx/3xx)
+// requests. Failed requests are always logged. A rate of 1 or less logs
+// every request.
+func WithAccessLogSampling(rate int) Option {
+	return func(o *options) {
+		o.accessLogSampleRate = rate
+	}
+}
+
+// WithAccessLogSkipPaths leaves requests whose matched route is one of
+// prefixes, or lies beneath one, out of the access log entirely, whatever
+// their status. Matching uses the registered route rather than the raw URL
+// and whole path segments, so "/metrics" skips "/metrics" and
+// "/metrics/raw" but not "/metricsfoo" or requests that match no route.
+func WithAccessLogSkipPaths(prefixes ...string) Option {
+	return func(o *options) {
+		for _, prefix := range prefixes {
+			if prefix = strings.TrimRight(prefix, "/"); prefix != "" {
+				o.accessLogSkipPaths = append(o.accessLogSkipPaths, prefix)
+			}
+		}
+	}
+}
+
+// WithSwaggerUI serves the Swagger UI and spec under SwaggerPath. Without it
+// the route is not registered at all.
+func WithSwaggerUI() Option {
+	return func(o *options) {
+		o.swagger = true
+	}
+}
+
+// WithMeterProvider records the OpenTelemetry HTTP server metrics on mp
+// instead of the global MeterProvider. Pass a no-op provider when the global
+// one cannot export the histograms those metrics use.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.meterProvider = mp
+	}
+}
+
+// WithClientIPResolver selects the proxies whose X-Forwarded-For headers are
+// honoured. Without it no proxy is trusted and the remote address is used.
+func WithClientIPResolver(resolver *httpapi.ClientIPResolver) Option {
+	return func(o *options) {
+		if resolver != nil {
+			o.clientIP = resolver
+		}
+	}
+}
+
 // New constructs a gin.Engine with the baseline middlewares configured.
-func New(logger *slog.Logger, serviceName string) *gin.Engine {
+func New(logger *slog.Logger, serviceName string, opts ...Option) *gin.Engine {
+	var settings options
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if settings.clientIP == nil {
+		settings.clientIP, _ = httpapi.NewClientIPResolver(nil, 0)
+	}
+
 	engine := gin.New()
-	engine.Use(gin.Recovery())
+	// Answer /path/ for /path (and vice versa) with a redirect rather than a
+	// 404, and a known path with the wrong method with 405 rather than 404.
+	engine.RedirectTrailingSlash = true
+	engine.HandleMethodNotAllowed = true
+	engine.NoRoute(notFoundHandler)
+	engine.NoMethod(methodNotAllowedHandler)
+	// gin trusts every proxy by default; align it with our resolver so
+	// c.ClientIP() cannot be spoofed either. The ranges were validated when
+	// the resolver was built.
+	_ = engine.SetTrustedProxies(settings.clientIP.TrustedProxies())
+	if settings.problemDetails {
+		// First, so every error written below honours the negotiated format.
+		engine.Use(httpapi.NegotiateProblemDetails())
+	}
+	if settings.indentJSON {
+		engine.Use(httpapi.IndentJSON())
+	}
+	engine.Use(httpapi.ResolveClientIP(settings.clientIP))
 
 	if serviceName == "" {
 		serviceName = "mysvelteapp-server"
 	}
-	engine.Use(otelgin.Middleware(serviceName))
+	var otelOptions []otelgin.Option
+	if settings.meterProvider != nil {
+		otelOptions = append(otelOptions, otelgin.WithMeterProvider(settings.meterProvider))
+	}
+	engine.Use(otelgin.Middleware(serviceName, otelOptions...))
 
 	if logger != nil {
-		engine.Use(loggingMiddleware(logger))
+		engine.Use(requestContextMiddleware(logger))
+		engine.Use(loggingMiddleware(settings.accessLogSampleRate, settings.accessLogSkipPaths))
+	}
+
+	// Recovery sits inside the span, request logger and access log so a
+	// panic is reported with request context and logged as a 500.
+	engine.Use(recoveryMiddleware())
+
+	if settings.forceHTTPS {
+		engine.Use(forceHTTPSMiddleware(settings.clientIP))
+	}
+	if settings.csrf {
+		engine.Use(csrfMiddleware(settings.csrfSecureCookie))
+	}
+
+	engine.Use(httpapi.RequireJSON())
+	if settings.strictJSON {
+		engine.Use(httpapi.DisallowUnknownFields())
+	}
+
+	if settings.swagger {
+		engine.GET(SwaggerPath, ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
 	return engine
 }
 
-func loggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
+func loggingMiddleware(sampleRate int, skipPaths []string) gin.HandlerFunc {
+	var successes atomic.Uint64
 	return func(c *gin.Context) {
+		if skipRoute(c.FullPath(), skipPaths) {
+			c.Next()
+			return
+		}
 		start := time.Now()
 
 		c.Next()
 
+		logger := logging.FromContext(c.Request.Context())
 		status := c.Writer.Status()
-		clientIP := c.ClientIP()
+		clientIP := httpapi.ClientIP(c)
 		latency := time.Since(start)
 
 		if len(c.Errors) > 0 {
@@ -63,20 +246,52 @@ func loggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
 			return
 		}
 
+		if sampleRate <= 1 {
+			logger.Info("request completed",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"status", status,
+				"duration_ms", latency.Milliseconds(),
+				"client_ip", clientIP,
+			)
+			return
+		}
+		// Log the first of every sampleRate successes; sample_rate lets log
+		// queries scale counts back up.
+		if (successes.Add(1)-1)%uint64(sampleRate) != 0 {
+			return
+		}
 		logger.Info("request completed",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", status,
 			"duration_ms", latency.Milliseconds(),
 			"client_ip", clientIP,
+			"sample_rate", sampleRate,
 		)
 	}
 }
 
+// skipRoute reports whether route equals a prefix or sits beneath it. An
+// empty route means no handler matched and is always logged.
+func skipRoute(route string, prefixes []string) bool {
+	if route == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if route == prefix || strings.HasPrefix(route, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 func getStatusInfo(statusCode int) (slog.Level, string) {
 	switch {
 	case statusCode >= 500:
 		return slog.LevelError, "server error"
+	case statusCode == httpapi.StatusClientClosedRequest:
+		return slog.LevelInfo, "client closed request"
 	case statusCode == 404:
 		return slog.LevelWarn, "not found"
 	case statusCode == 405:
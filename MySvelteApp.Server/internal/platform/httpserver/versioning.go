@@ -0,0 +1,31 @@
+package httpserver
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// APIVersionPrefix is the path prefix of the current API version.
+const APIVersionPrefix = "/api/v1"
+
+// MountAPI calls mount once for the versioned APIVersionPrefix group and once
+// for the engine root. Both mounts report the same httpapi.RouteID, so
+// per-route state such as idempotency keys is shared between them.
+//
+// Deprecated aliases: the unversioned paths (e.g. /auth/login) predate API
+// versioning and are kept only so existing clients keep working while they
+// move to /api/v1. Their responses carry a Deprecation header and a Link to
+// the versioned path. Remove the root mount once clients have migrated.
+func MountAPI(engine *gin.Engine, mount func(router gin.IRouter)) {
+	mount(engine.Group(APIVersionPrefix, httpapi.RoutePrefix(APIVersionPrefix)))
+	mount(engine.Group("", deprecatedAlias))
+}
+
+// deprecatedAlias marks a response as coming from an unversioned alias and
+// points the client at its versioned successor.
+func deprecatedAlias(c *gin.Context) {
+	c.Header("Deprecation", "true")
+	c.Header("Link", "<"+APIVersionPrefix+c.Request.URL.Path+`>; rel="successor-version"`)
+	c.Next()
+}
@@ -0,0 +1,113 @@
+// Package idempotency remembers the outcome of requests carrying an
+// idempotency key so that retries replay it instead of repeating side effects.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrInFlight is returned by Store.Begin while another request holds the key.
+var ErrInFlight = errors.New("idempotency: request with this key is still in progress")
+
+// Response is a stored response, replayed verbatim for a repeated key.
+// Fingerprint identifies the request that produced it.
+type Response struct {
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// Store records responses by key. Implementations must be safe for
+// concurrent use, and Begin must claim a key atomically.
+type Store interface {
+	// Begin claims key for ttl. It returns the stored response when key has
+	// completed, ErrInFlight when another request holds it, and nil when the
+	// caller now holds it and must call Complete or Release.
+	Begin(ctx context.Context, key string, ttl time.Duration) (*Response, error)
+	// Complete stores response for key, keeping it for ttl.
+	Complete(ctx context.Context, key string, response Response, ttl time.Duration) error
+	// Release gives up a claim without storing a response, so the key can be
+	// retried.
+	Release(ctx context.Context, key string) error
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore keeps responses in process memory, so replays only work
+// against the instance that served the original request.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	response  *Response
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// WithClock replaces time.Now when deciding whether a claim or stored response
+// has outlived its TTL, so tests can expire keys without sleeping.
+func (s *MemoryStore) WithClock(now func() time.Time) *MemoryStore {
+	s.now = now
+	return s
+}
+
+// Begin implements Store.
+func (s *MemoryStore) Begin(_ context.Context, key string, ttl time.Duration) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now, ttl)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.response == nil {
+			return nil, ErrInFlight
+		}
+		return entry.response, nil
+	}
+	s.entries[key] = memoryEntry{expiresAt: now.Add(ttl)}
+	return nil, nil
+}
+
+// Complete implements Store.
+func (s *MemoryStore) Complete(_ context.Context, key string, response Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{response: &response, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops expired entries at most once per ttl so memory stays bounded
+// by recently used keys.
+func (s *MemoryStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(s.lastSweep) < ttl {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
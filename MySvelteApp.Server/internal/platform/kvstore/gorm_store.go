@@ -0,0 +1,110 @@
+package kvstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// gormEntry is a row of the kv_entries table. Queries filter on it as a
+// struct so GORM quotes the key column, a reserved word in MySQL.
+type gormEntry struct {
+	Key       string    `gorm:"primaryKey;size:255"`
+	Value     []byte    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (gormEntry) TableName() string { return "kv_entries" }
+
+var _ Store = (*GormStore)(nil)
+
+// GormStore keeps values in the kv_entries table, so every server instance
+// sharing the database sees them. Expired rows are ignored by Get and removed
+// by DeleteExpired.
+type GormStore struct {
+	db    *gorm.DB
+	retry persistence.RetryOptions
+	now   func() time.Time
+}
+
+// NewGormStore constructs a store backed by GORM. Writes failing with
+// transient errors are retried according to retry.
+func NewGormStore(db *gorm.DB, retry persistence.RetryOptions) *GormStore {
+	return &GormStore{db: db, retry: retry, now: time.Now}
+}
+
+// WithClock sets the clock used for expires_at on Set and the expiry filter
+// on Get. It is read on this process, not the database server, so instances
+// need roughly synchronised clocks.
+func (s *GormStore) WithClock(now func() time.Time) *GormStore {
+	s.now = now
+	return s
+}
+
+// Set implements Store.
+func (s *GormStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := gormEntry{Key: key, Value: value, ExpiresAt: s.now().UTC().Add(ttl)}
+	return persistence.Retry(ctx, s.retry, func() error {
+		return persistence.Conn(ctx, s.db).
+			Clauses(clause.OnConflict{UpdateAll: true}).
+			Create(&entry).
+			Error
+	})
+}
+
+// Get implements Store.
+func (s *GormStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var entry gormEntry
+	err := persistence.Conn(ctx, s.db).
+		Where(&gormEntry{Key: key}).
+		Where("expires_at > ?", s.now().UTC()).
+		Take(&entry).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry.Value, nil
+}
+
+// Delete implements Store.
+func (s *GormStore) Delete(ctx context.Context, key string) error {
+	return persistence.Retry(ctx, s.retry, func() error {
+		return persistence.Conn(ctx, s.db).Where(&gormEntry{Key: key}).Delete(&gormEntry{}).Error
+	})
+}
+
+// DeleteExpired removes rows whose TTL has passed.
+func (s *GormStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := persistence.Retry(ctx, s.retry, func() error {
+		result := persistence.Conn(ctx, s.db).Where("expires_at <= ?", now.UTC()).Delete(&gormEntry{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// RunCleanup calls DeleteExpired every interval until ctx is done, passing
+// each failure to onError. It blocks, so run it in its own goroutine.
+func (s *GormStore) RunCleanup(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.DeleteExpired(ctx, now); err != nil && ctx.Err() == nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package lifecycle coordinates the orderly teardown of long-lived components.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Hook releases a component's resources. It should return promptly once ctx
+// is done.
+type Hook func(ctx context.Context) error
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Registry collects shutdown hooks and runs them in reverse registration
+// order, so components are torn down before the dependencies they were built on.
+type Registry struct {
+	mu    sync.Mutex
+	hooks []namedHook
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a hook to run on Shutdown. The name labels its error.
+func (r *Registry) Register(name string, hook Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, namedHook{name: name, hook: hook})
+}
+
+// Shutdown runs every registered hook in LIFO order, sharing ctx's deadline.
+// A failing hook does not stop the remaining ones; all failures are joined
+// into the returned error. Hooks run at most once.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	hooks := r.hooks
+	r.hooks = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].hook(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown %s: %w", hooks[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -23,6 +23,19 @@ type Config struct {
 	Format    string // "json" or "text"
 	Output    string // "stdout", "stderr", or file path
 	AddSource bool
+
+	// Rotation settings apply only when Output is a file path. A zero
+	// MaxSizeMB disables rotation; zero MaxBackups or MaxAgeDays keeps
+	// backups indefinitely.
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+
+	// TimeFormat is a time.Format layout for the "time" attribute, e.g.
+	// time.RFC3339. Empty keeps slog's default formatting.
+	TimeFormat string
+	// UTC converts timestamps to UTC before formatting.
+	UTC bool
 }
 
 // DefaultConfig returns the baseline logger setup.
@@ -36,19 +49,23 @@ func DefaultConfig() Config {
 }
 
 // NewLogger builds a slog.Logger configured according to the provided Config.
-func NewLogger(config Config) *slog.Logger {
+// The returned closer releases the log file when Output is a file path and is
+// a no-op for stdout and stderr; call it during shutdown.
+func NewLogger(config Config) (*slog.Logger, io.Closer) {
 	var writer io.Writer
+	var closer io.Closer = nopCloser{}
 	switch strings.ToLower(config.Output) {
 	case "stdout":
 		writer = os.Stdout
 	case "stderr":
 		writer = os.Stderr
 	default:
-		file, err := os.OpenFile(config.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+		file, err := openRotatingFile(config.Output, config)
 		if err != nil {
 			writer = os.Stdout
 		} else {
 			writer = file
+			closer = file
 		}
 	}
 
@@ -67,8 +84,9 @@ func NewLogger(config Config) *slog.Logger {
 	}
 
 	handlerOpts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: config.AddSource,
+		Level:       level,
+		AddSource:   config.AddSource,
+		ReplaceAttr: replaceTime(config.TimeFormat, config.UTC),
 	}
 
 	var handler slog.Handler
@@ -78,10 +96,36 @@ func NewLogger(config Config) *slog.Logger {
 		handler = slog.NewTextHandler(writer, handlerOpts)
 	}
 
-	return slog.New(handler)
+	return slog.New(handler), closer
 }
 
 // NewDefaultLogger returns a slog.Logger using the default configuration.
 func NewDefaultLogger() *slog.Logger {
-	return NewLogger(DefaultConfig())
+	logger, _ := NewLogger(DefaultConfig())
+	return logger
 }
+
+// replaceTime rewrites the top-level time attribute according to format and
+// utc. It returns nil when neither is set so slog keeps its default output.
+func replaceTime(format string, utc bool) func([]string, slog.Attr) slog.Attr {
+	if format == "" && !utc {
+		return nil
+	}
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		if len(groups) > 0 || attr.Key != slog.TimeKey || attr.Value.Kind() != slog.KindTime {
+			return attr
+		}
+		t := attr.Value.Time()
+		if utc {
+			t = t.UTC()
+		}
+		if format == "" {
+			return slog.Time(attr.Key, t)
+		}
+		return slog.String(attr.Key, t.Format(format))
+	}
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,192 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// Supported metrics exporters.
+const (
+	// ExporterNone discards OpenTelemetry measurements.
+	ExporterNone = "none"
+	// ExporterPrometheus records OpenTelemetry counters in the Registry so
+	// they are scraped from /metrics alongside the auth counters.
+	ExporterPrometheus = "prometheus"
+)
+
+// Provider manages the global OpenTelemetry MeterProvider.
+type Provider struct {
+	provider metric.MeterProvider
+}
+
+// New installs a MeterProvider for the given exporter as the global provider.
+// Instruments created earlier through otel.Meter are rebound to it.
+func New(exporter string, registry *Registry, logger *slog.Logger) (*Provider, error) {
+	var provider metric.MeterProvider
+	switch exporter {
+	case "", ExporterNone:
+		exporter = ExporterNone
+		provider = noop.NewMeterProvider()
+	case ExporterPrometheus:
+		if registry == nil {
+			return nil, fmt.Errorf("metrics exporter %q requires a registry", exporter)
+		}
+		provider = registryMeterProvider{registry: registry}
+	default:
+		return nil, fmt.Errorf("unsupported metrics exporter %q: expected %q or %q", exporter, ExporterNone, ExporterPrometheus)
+	}
+
+	otel.SetMeterProvider(provider)
+	logger.Info("using metrics exporter", "exporter", exporter)
+	return &Provider{provider: provider}, nil
+}
+
+// Meter returns a meter for the given instrumentation scope.
+func (p *Provider) Meter(name string) metric.Meter {
+	return p.provider.Meter(name)
+}
+
+// Shutdown releases the provider. Neither exporter buffers measurements, so
+// there is nothing to flush.
+func (p *Provider) Shutdown(context.Context) error {
+	return nil
+}
+
+// registryMeterProvider bridges OpenTelemetry Int64 counters into a Registry.
+// Creating any other instrument kind returns errUnsupportedInstrument along
+// with a no-op instrument, so callers that check the error notice the
+// measurements are not exported.
+type registryMeterProvider struct {
+	noop.MeterProvider
+	registry *Registry
+}
+
+func (p registryMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return registryMeter{registry: p.registry}
+}
+
+type registryMeter struct {
+	noop.Meter
+	registry *Registry
+}
+
+func (m registryMeter) Int64Counter(name string, options ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	config := metric.NewInt64CounterConfig(options...)
+	return registryCounter{vec: m.registry.counter(prometheusName(name), config.Description())}, nil
+}
+
+// errUnsupportedInstrument is returned for instrument kinds the prometheus
+// exporter cannot publish.
+var errUnsupportedInstrument = errors.New("instrument kind not supported by the prometheus metrics exporter; only Int64Counter is exported")
+
+func unsupportedInstrument(kind, name string) error {
+	return fmt.Errorf("%s %q: %w", kind, name, errUnsupportedInstrument)
+}
+
+func (registryMeter) Int64UpDownCounter(name string, _ ...metric.Int64UpDownCounterOption) (metric.Int64UpDownCounter, error) {
+	return noop.Int64UpDownCounter{}, unsupportedInstrument("Int64UpDownCounter", name)
+}
+
+func (registryMeter) Int64Histogram(name string, _ ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	return noop.Int64Histogram{}, unsupportedInstrument("Int64Histogram", name)
+}
+
+func (registryMeter) Int64Gauge(name string, _ ...metric.Int64GaugeOption) (metric.Int64Gauge, error) {
+	return noop.Int64Gauge{}, unsupportedInstrument("Int64Gauge", name)
+}
+
+func (registryMeter) Int64ObservableCounter(name string, _ ...metric.Int64ObservableCounterOption) (metric.Int64ObservableCounter, error) {
+	return noop.Int64ObservableCounter{}, unsupportedInstrument("Int64ObservableCounter", name)
+}
+
+func (registryMeter) Int64ObservableUpDownCounter(name string, _ ...metric.Int64ObservableUpDownCounterOption) (metric.Int64ObservableUpDownCounter, error) {
+	return noop.Int64ObservableUpDownCounter{}, unsupportedInstrument("Int64ObservableUpDownCounter", name)
+}
+
+func (registryMeter) Int64ObservableGauge(name string, _ ...metric.Int64ObservableGaugeOption) (metric.Int64ObservableGauge, error) {
+	return noop.Int64ObservableGauge{}, unsupportedInstrument("Int64ObservableGauge", name)
+}
+
+func (registryMeter) Float64Counter(name string, _ ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	return noop.Float64Counter{}, unsupportedInstrument("Float64Counter", name)
+}
+
+func (registryMeter) Float64UpDownCounter(name string, _ ...metric.Float64UpDownCounterOption) (metric.Float64UpDownCounter, error) {
+	return noop.Float64UpDownCounter{}, unsupportedInstrument("Float64UpDownCounter", name)
+}
+
+func (registryMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return noop.Float64Histogram{}, unsupportedInstrument("Float64Histogram", name)
+}
+
+func (registryMeter) Float64Gauge(name string, _ ...metric.Float64GaugeOption) (metric.Float64Gauge, error) {
+	return noop.Float64Gauge{}, unsupportedInstrument("Float64Gauge", name)
+}
+
+func (registryMeter) Float64ObservableCounter(name string, _ ...metric.Float64ObservableCounterOption) (metric.Float64ObservableCounter, error) {
+	return noop.Float64ObservableCounter{}, unsupportedInstrument("Float64ObservableCounter", name)
+}
+
+func (registryMeter) Float64ObservableUpDownCounter(name string, _ ...metric.Float64ObservableUpDownCounterOption) (metric.Float64ObservableUpDownCounter, error) {
+	return noop.Float64ObservableUpDownCounter{}, unsupportedInstrument("Float64ObservableUpDownCounter", name)
+}
+
+func (registryMeter) Float64ObservableGauge(name string, _ ...metric.Float64ObservableGaugeOption) (metric.Float64ObservableGauge, error) {
+	return noop.Float64ObservableGauge{}, unsupportedInstrument("Float64ObservableGauge", name)
+}
+
+// RegisterCallback fails because no observable instrument can be created.
+func (registryMeter) RegisterCallback(metric.Callback, ...metric.Observable) (metric.Registration, error) {
+	return noop.Registration{}, errUnsupportedInstrument
+}
+
+type registryCounter struct {
+	noop.Int64Counter
+	vec *CounterVec
+}
+
+func (c registryCounter) Add(_ context.Context, incr int64, options ...metric.AddOption) {
+	if incr <= 0 {
+		return
+	}
+	c.vec.add(renderAttributes(metric.NewAddConfig(options).Attributes()), uint64(incr))
+}
+
+// prometheusName maps an OpenTelemetry instrument name such as
+// "http.server.panics" to "http_server_panics_total".
+func prometheusName(name string) string {
+	name = sanitizeName(name)
+	if !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return name
+}
+
+// renderAttributes formats an attribute set as sorted Prometheus label pairs.
+func renderAttributes(set attribute.Set) string {
+	pairs := make([]string, 0, set.Len())
+	for iter := set.Iter(); iter.Next(); {
+		kv := iter.Attribute()
+		pairs = append(pairs, fmt.Sprintf("%s=%q", sanitizeName(string(kv.Key)), kv.Value.Emit()))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
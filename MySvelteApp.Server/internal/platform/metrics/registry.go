@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds labelled counters and gauges and serves them in the
+// Prometheus text exposition format.
+type Registry struct {
+	mu       sync.Mutex
+	counters map[string]*CounterVec
+	gauges   map[string]gaugeFunc
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{counters: make(map[string]*CounterVec), gauges: make(map[string]gaugeFunc)}
+}
+
+// family is one metric name with its HELP, TYPE and samples.
+type family interface {
+	familyName() string
+	writeTo(out *strings.Builder)
+}
+
+// gaugeFunc is a gauge whose value is read at scrape time.
+type gaugeFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+// GaugeFunc registers a gauge reporting value() on every scrape. Registering
+// the same name again replaces the callback.
+func (r *Registry) GaugeFunc(name, help string, value func() float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = gaugeFunc{name: name, help: help, value: value}
+}
+
+func (g gaugeFunc) familyName() string { return g.name }
+
+func (g gaugeFunc) writeTo(out *strings.Builder) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.name, g.help, g.name, g.name, strconv.FormatFloat(g.value(), 'g', -1, 64))
+}
+
+// CounterVec is a counter partitioned into series by label values.
+type CounterVec struct {
+	name  string
+	help  string
+	label string
+
+	mu     sync.Mutex
+	series map[string]uint64
+}
+
+// Counter returns the counter registered under name, creating it on first
+// use. name should follow Prometheus conventions, e.g. "auth_logins_total",
+// and label names the dimension passed to Inc.
+func (r *Registry) Counter(name, help, label string) *CounterVec {
+	counter := r.counter(name, help)
+	counter.mu.Lock()
+	counter.label = label
+	counter.mu.Unlock()
+	return counter
+}
+
+func (r *Registry) counter(name, help string) *CounterVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if counter, ok := r.counters[name]; ok {
+		return counter
+	}
+	counter := &CounterVec{name: name, help: help, series: make(map[string]uint64)}
+	r.counters[name] = counter
+	return counter
+}
+
+// Inc adds one to the series labelled value.
+func (c *CounterVec) Inc(value string) {
+	c.add(c.labelPair(value), 1)
+}
+
+// Value reports the current count for the series labelled value.
+func (c *CounterVec) Value(value string) uint64 {
+	labels := c.labelPair(value)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.series[labels]
+}
+
+func (c *CounterVec) labelPair(value string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("%s=%q", c.label, value)
+}
+
+// add increments the series identified by its rendered label pairs.
+func (c *CounterVec) add(labels string, n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.series[labels] += n
+}
+
+// ServeHTTP writes every metric in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	families := make([]family, 0, len(r.counters)+len(r.gauges))
+	for _, counter := range r.counters {
+		families = append(families, counter)
+	}
+	for _, gauge := range r.gauges {
+		families = append(families, gauge)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].familyName() < families[j].familyName() })
+
+	var out strings.Builder
+	for _, f := range families {
+		f.writeTo(&out)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(out.String()))
+}
+
+func (c *CounterVec) familyName() string { return c.name }
+
+func (c *CounterVec) writeTo(out *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	labels := make([]string, 0, len(c.series))
+	for label := range c.series {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		if label == "" {
+			fmt.Fprintf(out, "%s %d\n", c.name, c.series[label])
+			continue
+		}
+		fmt.Fprintf(out, "%s{%s} %d\n", c.name, label, c.series[label])
+	}
+}
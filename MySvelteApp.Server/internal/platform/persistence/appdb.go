@@ -1,11 +1,14 @@
 package persistence
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
-	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	"mysvelteapp/server_new/internal/platform/metrics"
 )
 
 // AppDB wraps gorm.DB to keep persistence wiring centralised.
@@ -13,17 +16,80 @@ type AppDB struct {
 	DB *gorm.DB
 }
 
-// NewAppDB constructs an AppDB given a prepared gorm dialector and configuration.
-func NewAppDB(dialector gorm.Dialector, config *gorm.Config) (*AppDB, error) {
+// PoolOptions configures the connection pool of the underlying sql.DB.
+// Values are passed straight to database/sql, so zero means unlimited open
+// connections, no idle connections, and no lifetime limit respectively.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// connectHint follows connection failures, which otherwise surface as driver
+// internals such as SQLite's "unable to open database file".
+const connectHint = "check the DSN and, for SQLite, that the file's directory exists and is writable"
+
+// NewAppDB constructs an AppDB given a prepared gorm dialector, configuration, and pool settings.
+// It pings the database before returning, so an unusable DSN fails here with
+// a descriptive error rather than on the first query during migrations.
+func NewAppDB(dialector gorm.Dialector, config *gorm.Config, pool PoolOptions) (*AppDB, error) {
 	db, err := gorm.Open(dialector, config)
 	if err != nil {
-		return nil, fmt.Errorf("open database: %w", err)
+		return nil, fmt.Errorf("open %s database: %w (%s)", dialector.Name(), err, connectHint)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("access sql.DB: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("connect to %s database: %w (%s)", dialector.Name(), err, connectHint)
 	}
 
 	return &AppDB{DB: db}, nil
 }
 
-// AutoMigrate applies the schema required for the modules currently in use.
-func (a *AppDB) AutoMigrate() error {
-	return a.DB.AutoMigrate(&authdomain.User{})
+// Ping verifies the database is reachable.
+func (a *AppDB) Ping(ctx context.Context) error {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return fmt.Errorf("access sql.DB: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats reports connection pool statistics. It returns zero values when the
+// underlying sql.DB is unavailable.
+func (a *AppDB) Stats() sql.DBStats {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+	return sqlDB.Stats()
+}
+
+// RegisterPoolMetrics publishes the connection pool statistics as gauges
+// read on every scrape.
+func (a *AppDB) RegisterPoolMetrics(registry *metrics.Registry) {
+	gauges := []struct {
+		name  string
+		help  string
+		value func(sql.DBStats) float64
+	}{
+		{"db_pool_max_open_connections", "Maximum number of open connections to the database.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+		{"db_pool_open_connections", "Established connections, both in use and idle.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+		{"db_pool_in_use_connections", "Connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+		{"db_pool_idle_connections", "Idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+		{"db_pool_wait_count", "Total connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+		{"db_pool_wait_duration_seconds", "Total time blocked waiting for a new connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+	}
+	for _, gauge := range gauges {
+		value := gauge.value
+		registry.GaugeFunc(gauge.name, gauge.help, func() float64 { return value(a.Stats()) })
+	}
 }
@@ -0,0 +1,27 @@
+package persistence
+
+// RepositoryError records which repository operation failed, so handlers can
+// log it while clients only see a generic error. It wraps the cause, so
+// errors.Is and errors.As still find sentinels such as
+// context.DeadlineExceeded beneath it.
+type RepositoryError struct {
+	Op  string
+	Err error
+}
+
+func (e *RepositoryError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *RepositoryError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError annotates err with the repository operation op. A nil err stays
+// nil so calls can wrap every return value.
+func WrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RepositoryError{Op: op, Err: err}
+}
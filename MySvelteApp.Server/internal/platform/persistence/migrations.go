@@ -0,0 +1,342 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a single, ordered schema change. Up runs inside a transaction
+// and must be idempotent so databases created by the former AutoMigrate call
+// are recognised as already migrated.
+type Migration struct {
+	Version string
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+type schemaMigration struct {
+	Version   string    `gorm:"primaryKey;size:64"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies any pending migrations from Migrations, then reconciles
+// rows written by releases that predate them.
+func (a *AppDB) Migrate(ctx context.Context) error {
+	db := a.DB.WithContext(ctx)
+	if err := RunMigrations(db, Migrations()); err != nil {
+		return err
+	}
+	return ReconcileLegacyRows(db)
+}
+
+// ReconcileLegacyRows fills columns that older releases, which share the
+// users table but not its newer columns, leave at their zero value. Unlike a
+// Migration it runs on every start, so rows inserted by an old binary during
+// a rolling deploy are repaired too.
+func ReconcileLegacyRows(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&userV4{}).
+			Where("normalized_username = ?", "").
+			Update("normalized_username", gorm.Expr("LOWER(username)")).
+			Error; err != nil {
+			return fmt.Errorf("backfill normalized usernames: %w", err)
+		}
+		if err := tx.Model(&userV4{}).
+			Where("role = ? OR role IS NULL", "").
+			Update("role", "user").
+			Error; err != nil {
+			return fmt.Errorf("backfill user roles: %w", err)
+		}
+		return nil
+	})
+}
+
+// uniqueNormalizedUsernameIndex backs EnforceUniqueNormalizedUsernames.
+const uniqueNormalizedUsernameIndex = "idx_users_normalized_username_unique"
+
+// EnforceUniqueNormalizedUsernames adds a unique index on normalized_username
+// so that two concurrent registrations differing only in case cannot both
+// insert. It is not a Migration because only deployments with case-insensitive
+// usernames want it: case-sensitive ones may hold both "Alice" and "alice".
+// It fails while such case variants exist; resolve them first.
+func (a *AppDB) EnforceUniqueNormalizedUsernames(ctx context.Context) error {
+	db := a.DB.WithContext(ctx)
+	if db.Migrator().HasIndex(&userV2{}, uniqueNormalizedUsernameIndex) {
+		return nil
+	}
+	err := db.Exec("CREATE UNIQUE INDEX " + uniqueNormalizedUsernameIndex + " ON users (normalized_username)").Error
+	if err != nil {
+		return fmt.Errorf("enforce unique normalized usernames (are there usernames differing only in case?): %w", err)
+	}
+	return nil
+}
+
+// RunMigrations applies the migrations not yet recorded in schema_migrations,
+// in slice order, each in its own transaction.
+func RunMigrations(db *gorm.DB, migrations []Migration) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var applied []schemaMigration
+	if err := db.Find(&applied).Error; err != nil {
+		return fmt.Errorf("load applied migrations: %w", err)
+	}
+	done := make(map[string]struct{}, len(applied))
+	for _, m := range applied {
+		done[m.Version] = struct{}{}
+	}
+
+	for _, migration := range migrations {
+		if _, ok := done[migration.Version]; ok {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %s (%s): %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Migrations lists the schema history. Append new entries; never edit or
+// reorder applied ones. Each migration uses its own snapshot structs so later
+// changes to domain types do not rewrite history.
+func Migrations() []Migration {
+	return []Migration{
+		{
+			Version: "0001",
+			Name:    "create_users",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&userV1{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&userV1{})
+			},
+		},
+		{
+			Version: "0002",
+			Name:    "add_users_normalized_username",
+			Up: func(tx *gorm.DB) error {
+				m := tx.Migrator()
+				if !m.HasColumn(&userV2{}, "NormalizedUsername") {
+					if err := m.AddColumn(&userV2{}, "NormalizedUsername"); err != nil {
+						return err
+					}
+				}
+				if !m.HasIndex(&userV2{}, "NormalizedUsername") {
+					if err := m.CreateIndex(&userV2{}, "NormalizedUsername"); err != nil {
+						return err
+					}
+				}
+				return tx.Model(&userV2{}).
+					Where("normalized_username = ?", "").
+					Update("normalized_username", gorm.Expr("LOWER(username)")).
+					Error
+			},
+		},
+		{
+			Version: "0003",
+			Name:    "create_favorite_pokemons",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&favoritePokemonV1{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&favoritePokemonV1{})
+			},
+		},
+		{
+			Version: "0004",
+			Name:    "add_users_role",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasColumn(&userV3{}, "Role") {
+					return nil
+				}
+				return tx.Migrator().AddColumn(&userV3{}, "Role")
+			},
+		},
+		{
+			Version: "0005",
+			Name:    "add_users_token_version",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasColumn(&userV4{}, "TokenVersion") {
+					return nil
+				}
+				return tx.Migrator().AddColumn(&userV4{}, "TokenVersion")
+			},
+		},
+		{
+			Version: "0006",
+			Name:    "create_login_attempts",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&loginAttemptV1{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&loginAttemptV1{})
+			},
+		},
+		{
+			Version: "0007",
+			Name:    "create_account_events",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&accountEventV1{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&accountEventV1{})
+			},
+		},
+		{
+			Version: "0008",
+			Name:    "add_users_pending_email",
+			Up: func(tx *gorm.DB) error {
+				m := tx.Migrator()
+				for _, column := range []string{"PendingEmail", "EmailChangeTokenHash", "EmailChangeExpiresAt"} {
+					if m.HasColumn(&userV5{}, column) {
+						continue
+					}
+					if err := m.AddColumn(&userV5{}, column); err != nil {
+						return err
+					}
+				}
+				if m.HasIndex(&userV5{}, "EmailChangeTokenHash") {
+					return nil
+				}
+				return m.CreateIndex(&userV5{}, "EmailChangeTokenHash")
+			},
+		},
+		{
+			Version: "0009",
+			Name:    "create_kv_entries",
+			Up: func(tx *gorm.DB) error {
+				if tx.Migrator().HasTable(&kvEntryV1{}) {
+					return nil
+				}
+				return tx.Migrator().CreateTable(&kvEntryV1{})
+			},
+		},
+		{
+			// Email change tokens moved to kv_entries.
+			Version: "0010",
+			Name:    "drop_users_email_change_token",
+			Up: func(tx *gorm.DB) error {
+				m := tx.Migrator()
+				if m.HasIndex(&userV5{}, "EmailChangeTokenHash") {
+					if err := m.DropIndex(&userV5{}, "EmailChangeTokenHash"); err != nil {
+						return err
+					}
+				}
+				// Migrator.DropColumn rebuilds SQLite tables and loses their
+				// unique indexes, so drop the columns in place instead.
+				for _, column := range []string{"email_change_token_hash", "email_change_expires_at"} {
+					if !m.HasColumn(&userV5{}, column) {
+						continue
+					}
+					if err := tx.Exec("ALTER TABLE users DROP COLUMN " + column).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+type userV1 struct {
+	ID           uint      `gorm:"primaryKey"`
+	Username     string    `gorm:"size:64;uniqueIndex;not null"`
+	Email        string    `gorm:"size:320;uniqueIndex;not null"`
+	PasswordHash string    `gorm:"size:512;not null"`
+	PasswordSalt string    `gorm:"size:256;not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type userV2 struct {
+	userV1
+	NormalizedUsername string `gorm:"size:64;index;not null;default:''"`
+}
+
+func (userV2) TableName() string { return "users" }
+
+type userV3 struct {
+	userV2
+	Role string `gorm:"size:32;not null;default:'user'"`
+}
+
+func (userV3) TableName() string { return "users" }
+
+type userV4 struct {
+	userV3
+	TokenVersion int `gorm:"not null;default:0"`
+}
+
+func (userV4) TableName() string { return "users" }
+
+type userV5 struct {
+	userV4
+	PendingEmail         string `gorm:"size:320;not null;default:''"`
+	EmailChangeTokenHash string `gorm:"size:64;index;not null;default:''"`
+	EmailChangeExpiresAt *time.Time
+}
+
+func (userV5) TableName() string { return "users" }
+
+type favoritePokemonV1 struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_favorite_pokemon_user_name"`
+	Name      string    `gorm:"size:128;not null;uniqueIndex:idx_favorite_pokemon_user_name"`
+	Type      string    `gorm:"size:256"`
+	Image     string    `gorm:"size:1024"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (favoritePokemonV1) TableName() string { return "favorite_pokemons" }
+
+type loginAttemptV1 struct {
+	Username    string    `gorm:"primaryKey;size:64"`
+	Failures    int       `gorm:"not null;default:0"`
+	WindowStart time.Time `gorm:"not null"`
+	LockedUntil time.Time
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (loginAttemptV1) TableName() string { return "login_attempts" }
+
+type accountEventV1 struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null;index"`
+	Type       string    `gorm:"size:32;not null"`
+	Reason     string    `gorm:"size:32"`
+	OccurredAt time.Time `gorm:"not null"`
+}
+
+func (accountEventV1) TableName() string { return "account_events" }
+
+type kvEntryV1 struct {
+	Key       string    `gorm:"primaryKey;size:255"`
+	Value     []byte    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (kvEntryV1) TableName() string { return "kv_entries" }
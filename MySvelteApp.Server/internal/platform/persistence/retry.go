@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetryOptions controls how transient write failures are retried.
+type RetryOptions struct {
+	// Attempts is the total number of tries, including the first. Values below
+	// one are treated as one (no retries).
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryOptions returns the baseline retry policy for writes.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{
+		Attempts:       3,
+		InitialBackoff: 25 * time.Millisecond,
+		MaxBackoff:     500 * time.Millisecond,
+	}
+}
+
+// transientErrorMarkers identify lock contention and serialization failures
+// that usually succeed when retried.
+var transientErrorMarkers = []string{
+	"database is locked",       // SQLite SQLITE_BUSY
+	"database table is locked", // SQLite SQLITE_LOCKED
+	"sqlite_busy",
+	"could not serialize access", // Postgres 40001
+	"sqlstate 40001",
+	"deadlock detected", // Postgres 40P01
+	"sqlstate 40p01",
+}
+
+// IsTransientError reports whether err is a known-transient database failure.
+// Constraint violations and other permanent errors return false.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range transientErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retry runs op, retrying with exponential backoff while it fails with a
+// transient error. The last error is returned once attempts are exhausted or
+// ctx is done.
+//
+// When ctx carries a transaction op runs once: a serialization failure or
+// deadlock aborts the whole transaction, so re-running one statement cannot
+// succeed. Retry the transaction itself instead, by calling Retry around
+// WithinTransaction.
+func Retry(ctx context.Context, options RetryOptions, op func() error) error {
+	attempts := max(options.Attempts, 1)
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		attempts = 1
+	}
+	backoff := options.InitialBackoff
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = op()
+		if err == nil || !IsTransientError(err) || attempt == attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if options.MaxBackoff > 0 && backoff > options.MaxBackoff {
+			backoff = options.MaxBackoff
+		}
+	}
+	return err
+}
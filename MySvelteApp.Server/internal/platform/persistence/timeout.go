@@ -0,0 +1,20 @@
+package persistence
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultQueryTimeout bounds a database operation whose context has no deadline.
+const DefaultQueryTimeout = 5 * time.Second
+
+// WithQueryTimeout derives a context that expires after timeout unless ctx
+// already carries a deadline, which is then left to govern the operation. A
+// non-positive timeout disables the bound. Callers must call the returned
+// cancel function.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// UnitOfWork runs callbacks inside a gorm transaction carried on the context.
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork constructs a UnitOfWork for the given database.
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// WithinTransaction runs fn inside a transaction; see WithinTransaction.
+func (u *UnitOfWork) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return WithinTransaction(ctx, u.db, fn)
+}
+
+// WithinTransaction runs fn with a context carrying a transaction, committing
+// when fn returns nil and rolling back otherwise. Calls nested inside an
+// existing transaction join it rather than opening a new one.
+func WithinTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// Conn returns the transaction carried by ctx, or db when there is none,
+// bound to ctx. Repositories should use it for every query.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// sqliteUniquePattern matches "UNIQUE constraint failed: users.email" and
+	// its multi-column form "t.a, t.b".
+	sqliteUniquePattern = regexp.MustCompile(`UNIQUE constraint failed: ([\w.]+(?:, [\w.]+)*)`)
+	// postgresUniquePattern matches SQLSTATE 23505 messages such as
+	// `duplicate key value violates unique constraint "idx_users_email"`.
+	postgresUniquePattern = regexp.MustCompile(`duplicate key value violates unique constraint "([^"]+)"`)
+	// postgresKeyPattern matches the detail line "Key (email)=(a@b.c) already exists."
+	postgresKeyPattern = regexp.MustCompile(`Key \(([^)]+)\)=`)
+)
+
+// UniqueViolation describes a write rejected by a unique index. Drivers report
+// different parts of it, so any field may be empty.
+type UniqueViolation struct {
+	Table      string
+	Columns    []string
+	Constraint string
+}
+
+// Involves reports whether column is part of the violated index. When the
+// driver named only the constraint, it falls back to GORM's idx_<table>_<column>
+// and Postgres' <table>_<column>_key naming.
+func (v UniqueViolation) Involves(column string) bool {
+	if len(v.Columns) > 0 {
+		return slices.Contains(v.Columns, column)
+	}
+	if v.Constraint == "" {
+		return false
+	}
+	return strings.HasSuffix(v.Constraint, "_"+column) || strings.HasSuffix(v.Constraint, "_"+column+"_key")
+}
+
+// AsUniqueViolation reports whether err is a unique constraint violation and,
+// as far as the SQLite or Postgres error says, which index it hit.
+func AsUniqueViolation(err error) (UniqueViolation, bool) {
+	if err == nil {
+		return UniqueViolation{}, false
+	}
+
+	message := err.Error()
+	if match := sqliteUniquePattern.FindStringSubmatch(message); match != nil {
+		var violation UniqueViolation
+		for _, qualified := range strings.Split(match[1], ", ") {
+			table, column, found := strings.Cut(qualified, ".")
+			if !found {
+				table, column = "", qualified
+			}
+			violation.Table = table
+			violation.Columns = append(violation.Columns, column)
+		}
+		return violation, true
+	}
+	if match := postgresUniquePattern.FindStringSubmatch(message); match != nil {
+		violation := UniqueViolation{Constraint: match[1]}
+		if key := postgresKeyPattern.FindStringSubmatch(message); key != nil {
+			for _, column := range strings.Split(key[1], ",") {
+				violation.Columns = append(violation.Columns, strings.TrimSpace(column))
+			}
+		}
+		return violation, true
+	}
+	if strings.Contains(message, "SQLSTATE 23505") || errors.Is(err, gorm.ErrDuplicatedKey) {
+		return UniqueViolation{}, true
+	}
+	return UniqueViolation{}, false
+}
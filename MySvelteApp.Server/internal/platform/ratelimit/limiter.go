@@ -0,0 +1,119 @@
+// Package ratelimit provides an in-memory, per-key token bucket limiter.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter allows up to limit events per interval for each key, refilling
+// continuously. State lives in process memory, so limits apply per instance.
+type Limiter struct {
+	mu        sync.Mutex
+	limit     float64
+	burst     float64
+	interval  time.Duration
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// New builds a Limiter allowing limit events per interval per key. A limit
+// below one disables limiting.
+func New(limit int, interval time.Duration) *Limiter {
+	return &Limiter{
+		limit:    float64(limit),
+		burst:    float64(limit),
+		interval: interval,
+		buckets:  make(map[string]*bucket),
+		now:      time.Now,
+	}
+}
+
+// WithClock overrides the limiter's time source; intended for tests.
+func (l *Limiter) WithClock(now func() time.Time) *Limiter {
+	l.now = now
+	return l
+}
+
+// WithBurst lets each key spend up to burst events at once while still
+// refilling at limit per interval, so short spikes from a shared address are
+// tolerated without raising the sustained rate. Values below the limit are
+// ignored.
+func (l *Limiter) WithBurst(burst int) *Limiter {
+	l.burst = max(l.limit, float64(burst))
+	return l
+}
+
+// Allow consumes one token for key, reporting whether the event may proceed
+// and, when it may not, how long until a token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	if l.limit < 1 || l.interval <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	perToken := l.interval / time.Duration(l.limit)
+	b.tokens = min(l.burst, b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Wait blocks until Allow lets an event for key proceed, or returns ctx's
+// error if it ends first. Waiters are not queued, so under contention any of
+// them may take the next token.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		allowed, retryAfter := l.Allow(key)
+		if allowed {
+			return nil
+		}
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// sweep drops buckets that have been idle long enough to be full again, at
+// most once per interval, so memory stays bounded by recently active keys.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.interval {
+		return
+	}
+	l.lastSweep = now
+	refill := time.Duration(float64(l.interval) * l.burst / l.limit)
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package validation provides composable checks for request fields. Rules for
+// a field run in order and stop at its first failure; failures accumulate
+// across fields so callers can report the first or all of them.
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes reported by the built-in rules.
+const (
+	CodeRequired      = "required"
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeInvalidFormat = "invalid_format"
+)
+
+// FieldError describes the rule a field broke.
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// Rule checks a single value, failing with its code and message.
+type Rule struct {
+	code    string
+	message string
+	valid   func(value string) bool
+}
+
+// Check builds a rule from a predicate, for checks the helpers below do not
+// cover.
+func Check(code, message string, valid func(value string) bool) Rule {
+	return Rule{code: code, message: message, valid: valid}
+}
+
+// Required rejects empty and whitespace-only values.
+func Required(message string) Rule {
+	return Check(CodeRequired, message, func(value string) bool {
+		return strings.TrimSpace(value) != ""
+	})
+}
+
+// MinLen rejects values shorter than n characters.
+func MinLen(n int, message string) Rule {
+	return Check(CodeTooShort, message, func(value string) bool {
+		return utf8.RuneCountInString(value) >= n
+	})
+}
+
+// MaxLen rejects values longer than n characters.
+func MaxLen(n int, message string) Rule {
+	return Check(CodeTooLong, message, func(value string) bool {
+		return utf8.RuneCountInString(value) <= n
+	})
+}
+
+// MinBytes rejects values shorter than n bytes.
+func MinBytes(n int, message string) Rule {
+	return Check(CodeTooShort, message, func(value string) bool {
+		return len(value) >= n
+	})
+}
+
+// MaxBytes rejects values longer than n bytes, e.g. to fit a column.
+func MaxBytes(n int, message string) Rule {
+	return Check(CodeTooLong, message, func(value string) bool {
+		return len(value) <= n
+	})
+}
+
+// Matches rejects values that pattern does not match.
+func Matches(pattern *regexp.Regexp, message string) Rule {
+	return Check(CodeInvalidFormat, message, pattern.MatchString)
+}
+
+// Email accepts a bare RFC 5322 addr-spec; display names and angle brackets
+// ("Bob <bob@example.com>") are rejected.
+func Email(message string) Rule {
+	return Check(CodeInvalidFormat, message, func(value string) bool {
+		if strings.ContainsAny(value, "<>") {
+			return false
+		}
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Name == ""
+	})
+}
+
+// Validator accumulates field errors. The zero value is ready to use.
+type Validator struct {
+	errs []FieldError
+}
+
+// Field runs rules against value in order, recording the first failure under
+// field. It reports whether every rule passed.
+func (v *Validator) Field(field, value string, rules ...Rule) bool {
+	for _, rule := range rules {
+		if !rule.valid(value) {
+			v.errs = append(v.errs, FieldError{Field: field, Code: rule.code, Message: rule.message})
+			return false
+		}
+	}
+	return true
+}
+
+// Errors returns the recorded failures in the order the fields were checked.
+func (v *Validator) Errors() []FieldError {
+	return v.errs
+}
+
+// First returns the earliest recorded failure, if any.
+func (v *Validator) First() (FieldError, bool) {
+	if len(v.errs) == 0 {
+		return FieldError{}, false
+	}
+	return v.errs[0], true
+}
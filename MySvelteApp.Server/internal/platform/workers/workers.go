@@ -0,0 +1,101 @@
+// Package workers runs background goroutines under one cancellable context so
+// shutdown can stop them and wait for them to finish.
+package workers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Worker is a long-running background task. It must return promptly once ctx
+// is cancelled.
+type Worker func(ctx context.Context)
+
+// Coordinator starts workers under a shared root context and tracks them with
+// a WaitGroup, so workers never manage Add and Done themselves.
+type Coordinator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// New creates a Coordinator whose workers run until Stop is called. A nil
+// logger discards worker panics.
+func New(logger *slog.Logger) *Coordinator {
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Coordinator{ctx: ctx, cancel: cancel, logger: logger, running: make(map[string]int)}
+}
+
+// Go starts worker in its own goroutine. The name labels log lines and the
+// error Stop returns for workers that overrun the grace period. A panicking
+// worker is logged and counted as finished rather than crashing the process.
+// Workers started after Stop receive an already-cancelled context.
+func (c *Coordinator) Go(name string, worker Worker) {
+	c.mu.Lock()
+	c.running[name]++
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer c.finish(name)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				c.logger.Error("background worker panicked", "worker", name, "panic", recovered)
+			}
+		}()
+		worker(c.ctx)
+	}()
+}
+
+func (c *Coordinator) finish(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running[name]--; c.running[name] <= 0 {
+		delete(c.running, name)
+	}
+}
+
+// Stop cancels every worker's context and waits for them to return. It gives
+// up when ctx is done, naming the workers still running in the error. Its
+// signature matches lifecycle.Hook.
+func (c *Coordinator) Stop(ctx context.Context) error {
+	c.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers still running (%s): %w", strings.Join(c.Running(), ", "), ctx.Err())
+	}
+}
+
+// Running lists the names of workers that have not returned yet, sorted.
+func (c *Coordinator) Running() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.running))
+	for name := range c.running {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
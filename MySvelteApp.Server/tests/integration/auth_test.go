@@ -0,0 +1,1134 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
+	authtoken "mysvelteapp/server_new/internal/modules/auth/infra/token"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpserver"
+	"mysvelteapp/server_new/internal/platform/idempotency"
+	"mysvelteapp/server_new/internal/platform/ratelimit"
+)
+
+// memoryUserRepository is a concurrency-safe in-memory UserRepository for
+// driving the real handlers and service without a database.
+type memoryUserRepository struct {
+	mu     sync.Mutex
+	users  []authdomain.User
+	nextID uint
+}
+
+func (m *memoryUserRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (m *memoryUserRepository) Add(_ context.Context, user *authdomain.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	user.ID = m.nextID
+	m.users = append(m.users, *user)
+	return nil
+}
+
+func (m *memoryUserRepository) find(match func(authdomain.User) bool) *authdomain.User {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if match(user) {
+			clone := user
+			return &clone
+		}
+	}
+	return nil
+}
+
+func (m *memoryUserRepository) GetByID(_ context.Context, id uint) (*authdomain.User, error) {
+	return m.find(func(u authdomain.User) bool { return u.ID == id }), nil
+}
+
+func (m *memoryUserRepository) IncrementTokenVersion(_ context.Context, id uint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].TokenVersion++
+		}
+	}
+	return nil
+}
+
+func (m *memoryUserRepository) GetByUsername(_ context.Context, username string) (*authdomain.User, error) {
+	return m.find(func(u authdomain.User) bool { return u.Username == username }), nil
+}
+
+func (m *memoryUserRepository) GetByNormalizedUsername(_ context.Context, normalizedUsername string) (*authdomain.User, error) {
+	return m.find(func(u authdomain.User) bool { return u.NormalizedUsername == normalizedUsername }), nil
+}
+
+func (m *memoryUserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	user, err := m.GetByUsername(ctx, username)
+	return user != nil, err
+}
+
+func (m *memoryUserRepository) NormalizedUsernameExists(ctx context.Context, normalizedUsername string) (bool, error) {
+	user, err := m.GetByNormalizedUsername(ctx, normalizedUsername)
+	return user != nil, err
+}
+
+func (m *memoryUserRepository) EmailExists(_ context.Context, email string) (bool, error) {
+	return m.find(func(u authdomain.User) bool { return u.Email == email }) != nil, nil
+}
+
+// availabilityLimit is the per-IP budget for /auth/availability in these tests.
+const availabilityLimit = 5
+
+// registerBurst is how many registrations one IP may make back to back in
+// these tests.
+const registerBurst = 3
+
+// setRole changes a stored user's role, standing in for an admin tool.
+func (m *memoryUserRepository) setRole(id uint, role string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.users {
+		if m.users[i].ID == id {
+			m.users[i].Role = role
+		}
+	}
+}
+
+// rememberMeLifetime is the token lifetime newAuthStack grants to logins
+// that set rememberMe; the default is one hour.
+const rememberMeLifetime = 24 * time.Hour
+
+// introspectionAPIKey is the shared key newAuthStack accepts on /auth/introspect.
+var introspectionAPIKey = strings.Repeat("i", 32)
+
+// integrationJWTOptions configures the token generator behind newAuthStack.
+func integrationJWTOptions() authtoken.JWTOptions {
+	return authtoken.JWTOptions{
+		Key:                      strings.Repeat("k", 32),
+		Issuer:                   "integration-tests",
+		Audiences:                []string{"integration-tests"},
+		AccessTokenLifetimeHours: 1,
+	}
+}
+
+// newAuthEngine assembles the auth stack the way cmd/server does, swapping the
+// GORM repository for the in-memory one.
+func newAuthEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	engine, _ := newAuthStack(t)
+	return engine
+}
+
+// newAuthStack is newAuthEngine that also returns the repository for tests
+// that need to adjust stored users. opts are applied after the registration
+// limits.
+func newAuthStack(t *testing.T, opts ...authapi.HandlersOption) (*gin.Engine, *memoryUserRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	tokens, err := authtoken.NewJWTTokenGenerator(integrationJWTOptions())
+	if err != nil {
+		t.Fatalf("expected token generator, got %v", err)
+	}
+
+	users := &memoryUserRepository{}
+	service := authapp.NewService(users, authsecurity.NewHMACPasswordHasher(), tokens, nil, authapp.Options{
+		RememberMeLifetime: rememberMeLifetime,
+	})
+	engine := httpserver.New(slog.New(slog.NewTextHandler(io.Discard, nil)), "integration-tests")
+	limiter := ratelimit.New(availabilityLimit, time.Minute)
+	authenticator := authapp.NewTokenAuthenticator(tokens, users)
+	requireAuth := authapi.RequireAuth(authenticator)
+	handlers := authapi.NewHandlers(service, append([]authapi.HandlersOption{authapi.WithRegistrationLimits(
+		ratelimit.New(1, time.Hour).WithBurst(registerBurst),
+		ratelimit.New(1, time.Hour).WithBurst(registerBurst),
+	)}, opts...)...)
+	events := authevents.NewHub(authevents.DefaultSubscriberBuffer)
+	t.Cleanup(events.Close)
+	idempotent := httpapi.Idempotency(idempotency.NewMemoryStore(), time.Hour)
+	httpserver.MountAPI(engine, func(router gin.IRouter) {
+		authapi.RegisterRoutes(router, handlers, httpapi.RateLimitByClientIP(limiter), idempotent, requireAuth)
+		authapi.RegisterAdminRoutes(router,
+			authapi.NewAdminHandlers(service),
+			authapi.NewEventsHandlers(events, authapi.DefaultEventsHeartbeat),
+			idempotent,
+			requireAuth,
+		)
+		authapi.RegisterIntrospectionRoutes(router, authapi.NewIntrospectionHandlers(authenticator), authapi.RequireAPIKeyOrAdmin(introspectionAPIKey, authenticator))
+	})
+	return engine, users
+}
+
+func postJSON(t *testing.T, engine *gin.Engine, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("expected request body to marshal, got %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func decodeBody[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	t.Helper()
+	var body T
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON body, got %q (%v)", recorder.Body.String(), err)
+	}
+	return body
+}
+
+var validRegistration = authapi.RegisterRequest{
+	Username: "ash_ketchum",
+	Email:    "ash@example.com",
+	Password: "Pikachu123",
+}
+
+// TestRegisterThenLogin drives the happy path through the full gin stack.
+// Arrange: build the engine and register a user.
+// Act: log in with the same credentials.
+// Assert: expect 200 responses carrying a token and the same user ID.
+func TestRegisterThenLogin(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	registerRecorder := postJSON(t, engine, "/auth/register", validRegistration)
+	if registerRecorder.Code != http.StatusOK {
+		t.Fatalf("expected register status 200, got %d: %s", registerRecorder.Code, registerRecorder.Body.String())
+	}
+	registered := decodeBody[authapi.AuthSuccessResponse](t, registerRecorder)
+
+	// Act
+	loginRecorder := postJSON(t, engine, "/auth/login", authapi.LoginRequest{
+		Username: validRegistration.Username,
+		Password: validRegistration.Password,
+	})
+
+	// Assert
+	if loginRecorder.Code != http.StatusOK {
+		t.Fatalf("expected login status 200, got %d: %s", loginRecorder.Code, loginRecorder.Body.String())
+	}
+	loggedIn := decodeBody[authapi.AuthSuccessResponse](t, loginRecorder)
+	if loggedIn.Token == "" || registered.Token == "" {
+		t.Fatalf("expected tokens to be issued, got register=%q login=%q", registered.Token, loggedIn.Token)
+	}
+	if loggedIn.UserID != registered.UserID || loggedIn.Username != validRegistration.Username {
+		t.Fatalf("expected login to resolve user %d %q, got %d %q", registered.UserID, validRegistration.Username, loggedIn.UserID, loggedIn.Username)
+	}
+}
+
+// TestLoginServedOnVersionedAndDeprecatedPaths keeps old clients working during the move to /api/v1.
+// Arrange: register a user through the versioned API.
+// Act: log in through /api/v1/auth/login and the unversioned /auth/login.
+// Assert: expect both to succeed, with only the unversioned response marked deprecated.
+func TestLoginServedOnVersionedAndDeprecatedPaths(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	if recorder := postJSON(t, engine, httpserver.APIVersionPrefix+"/auth/register", validRegistration); recorder.Code != http.StatusOK {
+		t.Fatalf("expected register status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	credentials := authapi.LoginRequest{Username: validRegistration.Username, Password: validRegistration.Password}
+
+	// Act
+	versioned := postJSON(t, engine, httpserver.APIVersionPrefix+"/auth/login", credentials)
+	legacy := postJSON(t, engine, "/auth/login", credentials)
+
+	// Assert
+	if versioned.Code != http.StatusOK || legacy.Code != http.StatusOK {
+		t.Fatalf("expected both logins to succeed, got versioned=%d legacy=%d", versioned.Code, legacy.Code)
+	}
+	if versioned.Header().Get("Deprecation") != "" {
+		t.Fatalf("expected no Deprecation header on the versioned path, got %q", versioned.Header().Get("Deprecation"))
+	}
+	if legacy.Header().Get("Deprecation") != "true" || legacy.Header().Get("Link") != `</api/v1/auth/login>; rel="successor-version"` {
+		t.Fatalf("expected the unversioned path to point at its successor, got Deprecation=%q Link=%q", legacy.Header().Get("Deprecation"), legacy.Header().Get("Link"))
+	}
+}
+
+// TestRegisterDuplicateUsernameReturnsConflict maps ConflictError to 409.
+// Arrange: register a user once.
+// Act: register the same username with a different email.
+// Assert: expect 409 with the username-taken message.
+func TestRegisterDuplicateUsernameReturnsConflict(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	postJSON(t, engine, "/auth/register", validRegistration)
+	duplicate := validRegistration
+	duplicate.Email = "other@example.com"
+
+	// Act
+	recorder := postJSON(t, engine, "/auth/register", duplicate)
+
+	// Assert
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", recorder.Code)
+	}
+	body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+	if body.Code != httpapi.CodeConflict || !strings.Contains(body.Message, "username is already taken") {
+		t.Fatalf("expected username conflict, got %+v", body)
+	}
+}
+
+// TestRegisterValidationFailureReturnsBadRequest surfaces field and code.
+// Arrange: build a registration with a weak password.
+// Act: post it to /auth/register.
+// Assert: expect 400 naming the password field and the too_weak reason.
+func TestRegisterValidationFailureReturnsBadRequest(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	weak := validRegistration
+	weak.Password = "alllowercase"
+
+	// Act
+	recorder := postJSON(t, engine, "/auth/register", weak)
+
+	// Assert
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", recorder.Code)
+	}
+	body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+	if body.Code != httpapi.CodeValidation || body.Field != authapp.FieldPassword || body.Reason != authapp.CodeTooWeak {
+		t.Fatalf("expected password/%s validation error, got %+v", authapp.CodeTooWeak, body)
+	}
+}
+
+// TestRegisterMalformedPayloadReturnsBadRequest rejects bodies that fail binding.
+// Arrange: build a registration whose username exceeds the binding size cap.
+// Act: post it to /auth/register.
+// Assert: expect 400 with the generic invalid payload message.
+func TestRegisterMalformedPayloadReturnsBadRequest(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	oversize := validRegistration
+	oversize.Username = strings.Repeat("a", 257)
+
+	// Act
+	recorder := postJSON(t, engine, "/auth/register", oversize)
+
+	// Assert
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", recorder.Code)
+	}
+	body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+	if body.Code != httpapi.CodeInvalidRequest || body.Message != "Invalid request payload." {
+		t.Fatalf("expected invalid payload error, got %+v", body)
+	}
+}
+
+// TestAuthMissingFieldNamesField lets the service report which field is missing.
+// Arrange: table-drive register and login payloads that each omit one field.
+// Act: post each to its route.
+// Assert: expect 400 naming the missing field with the required reason.
+func TestAuthMissingFieldNamesField(t *testing.T) {
+	testCases := []struct {
+		name  string
+		path  string
+		body  map[string]string
+		field string
+	}{
+		{name: "register without username", path: "/auth/register", body: map[string]string{"email": "ash@example.com", "password": "Pikachu123"}, field: authapp.FieldUsername},
+		{name: "register without email", path: "/auth/register", body: map[string]string{"username": "ash", "password": "Pikachu123"}, field: authapp.FieldEmail},
+		{name: "login without password", path: "/auth/login", body: map[string]string{"username": "ash"}, field: authapp.FieldPassword},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newAuthEngine(t)
+
+			// Act
+			recorder := postJSON(t, engine, tc.path, tc.body)
+
+			// Assert
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", recorder.Code)
+			}
+			body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+			if body.Code != httpapi.CodeValidation || body.Field != tc.field || body.Reason != authapp.CodeRequired {
+				t.Fatalf("expected %s/%s validation error, got %+v", tc.field, authapp.CodeRequired, body)
+			}
+		})
+	}
+}
+
+// TestLoginWrongPasswordReturnsUnauthorized maps UnauthorizedError to 401.
+// Arrange: register a user.
+// Act: log in with the wrong password.
+// Assert: expect 401 with the invalid credentials message.
+func TestLoginWrongPasswordReturnsUnauthorized(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	postJSON(t, engine, "/auth/register", validRegistration)
+
+	// Act
+	recorder := postJSON(t, engine, "/auth/login", authapi.LoginRequest{
+		Username: validRegistration.Username,
+		Password: "WrongPassword1",
+	})
+
+	// Assert
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", recorder.Code)
+	}
+	body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+	if body.Code != httpapi.CodeUnauthorized || !strings.Contains(body.Message, "Invalid username or password") {
+		t.Fatalf("expected invalid credentials error, got %+v", body)
+	}
+}
+
+// TestRegisterFormEncodedReturnsUnsupportedMediaType rejects non-JSON bodies up front.
+// Arrange: build a form-encoded registration request.
+// Act: post it to /auth/register.
+// Assert: expect 415 with the UNSUPPORTED_MEDIA_TYPE code.
+func TestRegisterFormEncodedReturnsUnsupportedMediaType(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader("username=ash&email=ash@example.com&password=Pikachu123"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415, got %d", recorder.Code)
+	}
+	body := decodeBody[httpapi.ErrorResponse](t, recorder).Error
+	if body.Code != httpapi.CodeUnsupportedMediaType {
+		t.Fatalf("expected %s, got %+v", httpapi.CodeUnsupportedMediaType, body)
+	}
+}
+
+func getAvailability(engine *gin.Engine, rawQuery string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/auth/availability?"+rawQuery, nil))
+	return recorder
+}
+
+// TestAvailabilityReportsTakenAndFreeValues mirrors registration normalisation.
+// Arrange: register a user.
+// Act: check the taken username, a free username, and the email with an uppercase domain.
+// Assert: expect available=false, true, false respectively.
+func TestAvailabilityReportsTakenAndFreeValues(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	postJSON(t, engine, "/auth/register", validRegistration)
+	testCases := []struct {
+		query     string
+		available bool
+	}{
+		{query: "username=ash_ketchum", available: false},
+		{query: "username=gary_oak", available: true},
+		{query: "email=ash@EXAMPLE.com", available: false},
+	}
+
+	for _, tc := range testCases {
+		// Act
+		recorder := getAvailability(engine, tc.query)
+
+		// Assert
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d", tc.query, recorder.Code)
+		}
+		if body := decodeBody[authapi.AvailabilityResponse](t, recorder); body.Available != tc.available {
+			t.Fatalf("expected available=%t for %s, got %t", tc.available, tc.query, body.Available)
+		}
+	}
+}
+
+// TestAvailabilityRejectsInvalidInput validates before querying.
+// Arrange: build the engine.
+// Act: send a malformed email, then both parameters, then neither.
+// Assert: expect 400 for every request.
+func TestAvailabilityRejectsInvalidInput(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+
+	for _, query := range []string{"email=not-an-email", "username=ash&email=ash@example.com", ""} {
+		// Act
+		recorder := getAvailability(engine, query)
+
+		// Assert
+		if recorder.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400 for %q, got %d", query, recorder.Code)
+		}
+	}
+}
+
+// TestAvailabilityIsRateLimited throttles enumeration attempts per client.
+// Arrange: build the engine with a small per-IP budget.
+// Act: exceed the budget from a single client.
+// Assert: expect 429 with a Retry-After header once the budget is spent.
+func TestAvailabilityIsRateLimited(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	for i := 0; i < availabilityLimit; i++ {
+		if recorder := getAvailability(engine, "username=gary_oak"); recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200 within budget, got %d", recorder.Code)
+		}
+	}
+
+	// Act
+	recorder := getAvailability(engine, "username=gary_oak")
+
+	// Assert
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", recorder.Code)
+	}
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected Retry-After header")
+	}
+}
+
+func postLogoutAll(engine *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestLogoutAllRevokesEarlierTokens forces every existing session to sign in again.
+// Arrange: register a user and keep the issued token.
+// Act: call /auth/logout-all, reuse the old token, then log in again.
+// Assert: expect 204, then 401 for the old token, and a working fresh token.
+func TestLogoutAllRevokesEarlierTokens(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	registered := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+
+	// Act
+	logoutRecorder := postLogoutAll(engine, registered.Token)
+	staleRecorder := postLogoutAll(engine, registered.Token)
+	loggedIn := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/login", authapi.LoginRequest{
+		Username: validRegistration.Username,
+		Password: validRegistration.Password,
+	}))
+	freshRecorder := postLogoutAll(engine, loggedIn.Token)
+
+	// Assert
+	if logoutRecorder.Code != http.StatusNoContent {
+		t.Fatalf("expected logout-all status 204, got %d", logoutRecorder.Code)
+	}
+	if staleRecorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the old token to be rejected with 401, got %d", staleRecorder.Code)
+	}
+	if freshRecorder.Code != http.StatusNoContent {
+		t.Fatalf("expected a token issued after rotation to work, got %d", freshRecorder.Code)
+	}
+}
+
+// TestAvailabilityRateLimitIgnoresSpoofedForwardedFor stops IP rotation via headers.
+// Arrange: exhaust the budget while claiming a new X-Forwarded-For address each time.
+// Act: send one more request with yet another forwarded address.
+// Assert: expect 429 because no proxy is trusted and the socket address is shared.
+func TestAvailabilityRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	spoofed := func(i int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/auth/availability?username=gary_oak", nil)
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	for i := 0; i < availabilityLimit; i++ {
+		if recorder := spoofed(i); recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200 within budget, got %d", recorder.Code)
+		}
+	}
+
+	// Act
+	recorder := spoofed(availabilityLimit)
+
+	// Assert
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", recorder.Code)
+	}
+}
+
+func getAdminUser(engine *gin.Engine, token, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/"+id, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestAdminGetUser lets administrators inspect an account by ID.
+// Arrange: register an admin and a regular user.
+// Act: fetch users by ID as the admin and as the regular user.
+// Assert: expect the account without secrets, 404 for unknown IDs, 400 for bad IDs and 403 for non-admins.
+func TestAdminGetUser(t *testing.T) {
+	// Arrange
+	engine, users := newAuthStack(t)
+	admin := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+	users.setRole(admin.UserID, authdomain.RoleAdmin)
+	member := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", authapi.RegisterRequest{
+		Username: "misty",
+		Email:    "misty@example.com",
+		Password: "Starmie123",
+	}))
+	memberID := fmt.Sprint(member.UserID)
+
+	testCases := []struct {
+		name   string
+		token  string
+		id     string
+		status int
+		code   string
+	}{
+		{name: "found", token: admin.Token, id: memberID, status: http.StatusOK},
+		{name: "not found", token: admin.Token, id: "999", status: http.StatusNotFound, code: httpapi.CodeNotFound},
+		{name: "non-numeric id", token: admin.Token, id: "abc", status: http.StatusBadRequest, code: httpapi.CodeValidation},
+		{name: "zero id", token: admin.Token, id: "0", status: http.StatusBadRequest, code: httpapi.CodeValidation},
+		{name: "not an admin", token: member.Token, id: memberID, status: http.StatusForbidden, code: httpapi.CodeForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			recorder := getAdminUser(engine, tc.token, tc.id)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, recorder.Code, recorder.Body.String())
+			}
+			if tc.code != "" {
+				if body := decodeBody[httpapi.ErrorResponse](t, recorder).Error; body.Code != tc.code {
+					t.Fatalf("expected error code %s, got %+v", tc.code, body)
+				}
+				return
+			}
+			user := decodeBody[authapi.AdminUserResponse](t, recorder)
+			if user.ID != member.UserID || user.Username != "misty" || user.Email != "misty@example.com" || user.Role != authdomain.RoleUser {
+				t.Fatalf("expected misty's account, got %+v", user)
+			}
+			for _, secret := range []string{"password", "salt", "hash", "tokenVersion"} {
+				if strings.Contains(strings.ToLower(recorder.Body.String()), strings.ToLower(secret)) {
+					t.Fatalf("expected no %s in the response, got %s", secret, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func postRegisterFrom(t *testing.T, engine *gin.Engine, remoteAddr string, body authapi.RegisterRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("expected request body to marshal, got %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = remoteAddr
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func registrationFor(i int, domain string) authapi.RegisterRequest {
+	return authapi.RegisterRequest{
+		Username: fmt.Sprintf("trainer_%d", i),
+		Email:    fmt.Sprintf("trainer%d@%s", i, domain),
+		Password: "Pikachu123",
+	}
+}
+
+// TestRegisterIsRateLimitedPerIP curbs scripted sign-ups from one address.
+// Arrange: spend the registration burst from one IP.
+// Act: register once more from that IP and once from another.
+// Assert: expect 429 with Retry-After for the first and success for the other IP.
+func TestRegisterIsRateLimitedPerIP(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	for i := 0; i < registerBurst; i++ {
+		if recorder := postRegisterFrom(t, engine, "198.51.100.7:1234", registrationFor(i, fmt.Sprintf("d%d.example", i))); recorder.Code != http.StatusOK {
+			t.Fatalf("expected registration %d within the burst to succeed, got %d", i+1, recorder.Code)
+		}
+	}
+
+	// Act
+	limited := postRegisterFrom(t, engine, "198.51.100.7:1234", registrationFor(registerBurst, "late.example"))
+	otherIP := postRegisterFrom(t, engine, "198.51.100.8:1234", registrationFor(registerBurst+1, "other.example"))
+
+	// Assert
+	if limited.Code != http.StatusTooManyRequests || limited.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d", limited.Code)
+	}
+	if body := decodeBody[httpapi.ErrorResponse](t, limited).Error; body.Code != httpapi.CodeRateLimited {
+		t.Fatalf("expected %s, got %+v", httpapi.CodeRateLimited, body)
+	}
+	if otherIP.Code != http.StatusOK {
+		t.Fatalf("expected another IP to register, got %d", otherIP.Code)
+	}
+}
+
+// TestRegisterIsRateLimitedPerEmailDomain curbs sign-ups spread across IPs.
+// Arrange: spend the burst for one email domain from different IPs.
+// Act: register once more at that domain, in different casing, from a fresh IP.
+// Assert: expect 429.
+func TestRegisterIsRateLimitedPerEmailDomain(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	for i := 0; i < registerBurst; i++ {
+		if recorder := postRegisterFrom(t, engine, fmt.Sprintf("203.0.113.%d:1234", i+1), registrationFor(i, "spam.example")); recorder.Code != http.StatusOK {
+			t.Fatalf("expected registration %d within the burst to succeed, got %d", i+1, recorder.Code)
+		}
+	}
+
+	// Act
+	recorder := postRegisterFrom(t, engine, "203.0.113.99:1234", registrationFor(registerBurst, "SPAM.example"))
+
+	// Assert
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status 429, got %d", recorder.Code)
+	}
+}
+
+func authCookie(recorder *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == authapi.AuthCookieName {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// TestCookieModeIssuesHttpOnlyCookie keeps the token away from page scripts.
+// Arrange: enable cookie-only mode.
+// Act: register, then log in.
+// Assert: expect hardened access_token cookies and no token in either JSON body.
+func TestCookieModeIssuesHttpOnlyCookie(t *testing.T) {
+	// Arrange
+	engine, _ := newAuthStack(t, authapi.WithAuthCookie(authapi.AuthCookie{MaxAge: time.Hour, OmitBodyToken: true}))
+
+	// Act
+	registered := postJSON(t, engine, "/auth/register", validRegistration)
+	loggedIn := postJSON(t, engine, "/auth/login", authapi.LoginRequest{
+		Username: validRegistration.Username,
+		Password: validRegistration.Password,
+	})
+
+	// Assert
+	for name, recorder := range map[string]*httptest.ResponseRecorder{"register": registered, "login": loggedIn} {
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", name, recorder.Code)
+		}
+		cookie := authCookie(recorder)
+		if cookie == nil || cookie.Value == "" {
+			t.Fatalf("%s: expected an %s cookie, got %v", name, authapi.AuthCookieName, recorder.Result().Cookies())
+		}
+		if !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode || cookie.MaxAge != 3600 {
+			t.Fatalf("%s: expected an HttpOnly, Secure, SameSite=Strict cookie for an hour, got %+v", name, cookie)
+		}
+		if body := decodeBody[authapi.AuthSuccessResponse](t, recorder); body.Token != "" || body.Username != validRegistration.Username {
+			t.Fatalf("%s: expected the user without a token in the body, got %+v", name, body)
+		}
+	}
+}
+
+// TestCookieAuthenticatesRequestsUntilLogout lets browsers authenticate without headers.
+// Arrange: register in cookie mode and keep the cookie.
+// Act: call a protected route with only the cookie, then log out.
+// Assert: expect the route to accept the cookie and logout to expire it.
+func TestCookieAuthenticatesRequestsUntilLogout(t *testing.T) {
+	// Arrange
+	engine, _ := newAuthStack(t, authapi.WithAuthCookie(authapi.AuthCookie{MaxAge: time.Hour}))
+	registered := postJSON(t, engine, "/auth/register", validRegistration)
+	cookie := authCookie(registered)
+	if cookie == nil {
+		t.Fatalf("expected an %s cookie on register", authapi.AuthCookieName)
+	}
+	if body := decodeBody[authapi.AuthSuccessResponse](t, registered); body.Token != cookie.Value {
+		t.Fatalf("expected the body to carry the cookie's token in both mode, got %+v", body)
+	}
+
+	// Act
+	protected := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+	protected.AddCookie(cookie)
+	protectedRecorder := httptest.NewRecorder()
+	engine.ServeHTTP(protectedRecorder, protected)
+	logoutRecorder := httptest.NewRecorder()
+	engine.ServeHTTP(logoutRecorder, httptest.NewRequest(http.MethodPost, "/auth/logout", nil))
+
+	// Assert
+	if protectedRecorder.Code != http.StatusNoContent {
+		t.Fatalf("expected the cookie to authenticate, got %d", protectedRecorder.Code)
+	}
+	if logoutRecorder.Code != http.StatusNoContent {
+		t.Fatalf("expected logout status 204, got %d", logoutRecorder.Code)
+	}
+	if cleared := authCookie(logoutRecorder); cleared == nil || cleared.MaxAge >= 0 || cleared.Value != "" {
+		t.Fatalf("expected logout to expire the cookie, got %+v", cleared)
+	}
+}
+
+// TestLoginWithCancelledContextReturnsClientClosedRequest treats disconnects as client-side.
+// Arrange: register a user and build a login request whose context is already cancelled.
+// Act: send the login.
+// Assert: expect 499 with CLIENT_CLOSED_REQUEST instead of a 500.
+func TestLoginWithCancelledContextReturnsClientClosedRequest(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	postJSON(t, engine, "/auth/register", validRegistration)
+	payload, _ := json.Marshal(authapi.LoginRequest{Username: validRegistration.Username, Password: validRegistration.Password})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(payload)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != httpapi.StatusClientClosedRequest {
+		t.Fatalf("expected status %d, got %d", httpapi.StatusClientClosedRequest, recorder.Code)
+	}
+	if body := decodeBody[httpapi.ErrorResponse](t, recorder).Error; body.Code != httpapi.CodeClientClosedRequest {
+		t.Fatalf("expected %s, got %+v", httpapi.CodeClientClosedRequest, body)
+	}
+}
+
+// TestRegisterReplaysResponseForRepeatedIdempotencyKey lets clients retry registration safely.
+// Arrange: register a user with an Idempotency-Key.
+// Act: send the identical request with the same key, then with a new key.
+// Assert: expect the retry to replay the original 200 and the new key to reach the handler and conflict.
+func TestRegisterReplaysResponseForRepeatedIdempotencyKey(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	register := func(key string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(validRegistration)
+		req := httptest.NewRequest(http.MethodPost, httpserver.APIVersionPrefix+"/auth/register", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(httpapi.IdempotencyKeyHeader, key)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	first := register("signup-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected register status 200, got %d: %s", first.Code, first.Body.String())
+	}
+
+	// Act
+	retry := register("signup-1")
+	fresh := register("signup-2")
+
+	// Assert
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the original response to be replayed, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(httpapi.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected the retry to be marked as replayed, got headers %v", retry.Header())
+	}
+	if fresh.Code != http.StatusConflict {
+		t.Fatalf("expected a new key to run the handler and conflict, got %d: %s", fresh.Code, fresh.Body.String())
+	}
+}
+
+// TestRegisterIdempotencyKeySpansVersionedAndAliasPaths replays across the deprecated alias.
+// Arrange: register a user through /api/v1 with an Idempotency-Key.
+// Act: retry the same request with the same key through the unversioned alias.
+// Assert: expect the original 200 to be replayed instead of a conflict.
+func TestRegisterIdempotencyKeySpansVersionedAndAliasPaths(t *testing.T) {
+	// Arrange
+	engine := newAuthEngine(t)
+	register := func(path string) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(validRegistration)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(httpapi.IdempotencyKeyHeader, "signup-1")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	first := register(httpserver.APIVersionPrefix + "/auth/register")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected register status 200, got %d: %s", first.Code, first.Body.String())
+	}
+
+	// Act
+	retry := register("/auth/register")
+
+	// Assert
+	if retry.Header().Get(httpapi.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected the alias retry to be replayed, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the original response, got %d: %s", retry.Code, retry.Body.String())
+	}
+}
+
+// TestAdminImportUsers migrates accounts in bulk with per-row results.
+// Arrange: register an admin and misty, and hash a password the way the server does.
+// Act: import a batch with a new user and a row conflicting with misty, then log in as the imported user.
+// Assert: expect 200 with one created and one failed row, and the imported credentials to work.
+func TestAdminImportUsers(t *testing.T) {
+	// Arrange
+	engine, users := newAuthStack(t)
+	admin := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+	users.setRole(admin.UserID, authdomain.RoleAdmin)
+	postJSON(t, engine, "/auth/register", authapi.RegisterRequest{Username: "misty", Email: "misty@example.com", Password: "Starmie123"})
+	hash, salt, err := authsecurity.NewHMACPasswordHasher().HashPassword("Onix12345")
+	if err != nil {
+		t.Fatalf("expected password hash, got %v", err)
+	}
+	payload, _ := json.Marshal([]authapi.ImportUserRequest{
+		{Username: "brock", Email: "brock@example.com", PasswordHash: hash, PasswordSalt: salt},
+		{Username: "misty", Email: "other@example.com", PasswordHash: hash, PasswordSalt: salt},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/import", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+admin.Token)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+	login := postJSON(t, engine, "/auth/login", authapi.LoginRequest{Username: "brock", Password: "Onix12345"})
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+	body := decodeBody[authapi.ImportUsersResponse](t, recorder)
+	if body.Created != 1 || body.Failed != 1 || len(body.Results) != 2 {
+		t.Fatalf("expected one created and one failed row, got %+v", body)
+	}
+	if body.Results[0].Status != authapi.ImportStatusCreated || body.Results[0].UserID == 0 {
+		t.Fatalf("expected brock to be created, got %+v", body.Results[0])
+	}
+	if failed := body.Results[1]; failed.Status != authapi.ImportStatusFailed || failed.Error == nil || failed.Error.Code != httpapi.CodeConflict {
+		t.Fatalf("expected misty's row to conflict, got %+v", failed)
+	}
+	if login.Code != http.StatusOK {
+		t.Fatalf("expected the imported user to log in, got %d: %s", login.Code, login.Body.String())
+	}
+}
+
+// TestAdminImportUsersReplaysRetriedBatch imports a batch once per Idempotency-Key.
+// Arrange: register an admin and hash a password the way the server does.
+// Act: post the same import twice with one Idempotency-Key.
+// Assert: expect the retry to replay the created row instead of reporting a conflict.
+func TestAdminImportUsersReplaysRetriedBatch(t *testing.T) {
+	// Arrange
+	engine, users := newAuthStack(t)
+	admin := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+	users.setRole(admin.UserID, authdomain.RoleAdmin)
+	hash, salt, err := authsecurity.NewHMACPasswordHasher().HashPassword("Onix12345")
+	if err != nil {
+		t.Fatalf("expected password hash, got %v", err)
+	}
+	payload, _ := json.Marshal([]authapi.ImportUserRequest{
+		{Username: "brock", Email: "brock@example.com", PasswordHash: hash, PasswordSalt: salt},
+	})
+	importBatch := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/import", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+admin.Token)
+		req.Header.Set(httpapi.IdempotencyKeyHeader, "import-batch-1")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	first := importBatch()
+	retry := importBatch()
+
+	// Assert
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, first.Code, first.Body.String())
+	}
+	if retry.Header().Get(httpapi.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected the retry to be replayed, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the replayed body %s, got %s", first.Body.String(), retry.Body.String())
+	}
+	if body := decodeBody[authapi.ImportUsersResponse](t, retry); body.Created != 1 || body.Failed != 0 {
+		t.Fatalf("expected the replay to report brock as created, got %+v", body)
+	}
+}
+
+// TestLoginRememberMeExtendsSession issues a longer token and cookie on request.
+// Arrange: register a user on a stack whose remember-me lifetime is 24 hours.
+// Act: log in once normally and once with rememberMe set.
+// Assert: expect the remembered token to expire later and its cookie to last 24 hours.
+func TestLoginRememberMeExtendsSession(t *testing.T) {
+	// Arrange
+	engine, _ := newAuthStack(t, authapi.WithAuthCookie(authapi.AuthCookie{MaxAge: time.Hour}))
+	postJSON(t, engine, "/auth/register", validRegistration)
+	credentials := authapi.LoginRequest{Username: validRegistration.Username, Password: validRegistration.Password}
+
+	// Act
+	standard := postJSON(t, engine, "/auth/login", credentials)
+	credentials.RememberMe = true
+	remembered := postJSON(t, engine, "/auth/login", credentials)
+
+	// Assert
+	if standard.Code != http.StatusOK || remembered.Code != http.StatusOK {
+		t.Fatalf("expected both logins to succeed, got %d and %d", standard.Code, remembered.Code)
+	}
+	standardExpiry := tokenExpiry(t, decodeBody[authapi.AuthSuccessResponse](t, standard).Token)
+	rememberedExpiry := tokenExpiry(t, decodeBody[authapi.AuthSuccessResponse](t, remembered).Token)
+	if !rememberedExpiry.After(standardExpiry) {
+		t.Fatalf("expected the remembered token to expire after %s, got %s", standardExpiry, rememberedExpiry)
+	}
+	cookies := remembered.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge != int(rememberMeLifetime/time.Second) {
+		t.Fatalf("expected a cookie lasting %s, got %+v", rememberMeLifetime, cookies)
+	}
+}
+
+func tokenExpiry(t *testing.T, signed string) time.Time {
+	t.Helper()
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(signed, &claims); err != nil || claims.ExpiresAt == nil {
+		t.Fatalf("expected a token with an expiry, got %v", err)
+	}
+	return claims.ExpiresAt.Time
+}
+
+func introspect(t *testing.T, engine *gin.Engine, token string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, _ := json.Marshal(authapi.IntrospectRequest{Token: token})
+	req := httptest.NewRequest(http.MethodPost, "/auth/introspect", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestIntrospectReportsTokenState checks tokens on behalf of trusted callers.
+// Arrange: register a user and mint a current, an expired and a tampered token.
+// Act: introspect each token with the API key.
+// Assert: expect 200 throughout, active with the user's details only for the current token.
+func TestIntrospectReportsTokenState(t *testing.T) {
+	engine, _ := newAuthStack(t)
+	registered := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+	user := &authdomain.User{ID: registered.UserID, Username: registered.Username}
+	expiredTokens, err := authtoken.NewJWTTokenGenerator(integrationJWTOptions(), authtoken.WithClock(func() time.Time {
+		return time.Now().Add(-2 * time.Hour)
+	}))
+	if err != nil {
+		t.Fatalf("expected token generator, got %v", err)
+	}
+	expired, err := expiredTokens.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("expected expired token, got %v", err)
+	}
+	header, payload, _ := strings.Cut(registered.Token, ".")
+	claims, signature, _ := strings.Cut(payload, ".")
+	tampered := header + "." + claims + "." + strings.Repeat("A", len(signature))
+
+	testCases := []struct {
+		name   string
+		token  string
+		active bool
+	}{
+		{name: "active", token: registered.Token, active: true},
+		{name: "expired", token: expired},
+		{name: "tampered", token: tampered},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			headers := map[string]string{httpapi.APIKeyHeader: introspectionAPIKey}
+
+			// Act
+			recorder := introspect(t, engine, tc.token, headers)
+
+			// Assert
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+			}
+			body := decodeBody[authapi.IntrospectResponse](t, recorder)
+			if body.Active != tc.active {
+				t.Fatalf("expected active=%v, got %+v", tc.active, body)
+			}
+			if !tc.active {
+				if body != (authapi.IntrospectResponse{}) {
+					t.Fatalf("expected only active=false, got %+v", body)
+				}
+				return
+			}
+			if body.UserID != registered.UserID || body.Username != registered.Username || body.Exp <= time.Now().Unix() {
+				t.Fatalf("expected user %d %q with a future exp, got %+v", registered.UserID, registered.Username, body)
+			}
+		})
+	}
+}
+
+// TestIntrospectRequiresTrustedCaller admits the API key or an admin token only.
+// Arrange: register an admin and a regular user.
+// Act: introspect with no credentials, a wrong key, the user's token, and the admin's token.
+// Assert: expect 401, 401, 403 and 200 respectively.
+func TestIntrospectRequiresTrustedCaller(t *testing.T) {
+	engine, users := newAuthStack(t)
+	admin := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", validRegistration))
+	users.setRole(admin.UserID, authdomain.RoleAdmin)
+	regular := decodeBody[authapi.AuthSuccessResponse](t, postJSON(t, engine, "/auth/register", authapi.RegisterRequest{
+		Username: "misty",
+		Email:    "misty@example.com",
+		Password: "Starmie123",
+	}))
+
+	testCases := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{name: "anonymous", status: http.StatusUnauthorized},
+		{name: "wrong key", headers: map[string]string{httpapi.APIKeyHeader: strings.Repeat("x", 32)}, status: http.StatusUnauthorized},
+		{name: "regular user", headers: map[string]string{"Authorization": "Bearer " + regular.Token}, status: http.StatusForbidden},
+		{name: "admin", headers: map[string]string{"Authorization": "Bearer " + admin.Token}, status: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			recorder := introspect(t, engine, regular.Token, tc.headers)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
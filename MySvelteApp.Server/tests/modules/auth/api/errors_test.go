@@ -0,0 +1,80 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authpersistence "mysvelteapp/server_new/internal/modules/auth/infra/persistence"
+	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/logging"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// TestRepositoryFailureLogsOperation keeps database details out of responses.
+// Arrange: build the auth routes on a database that is then closed, logging
+// to a buffer.
+// Act: log in.
+// Assert: expect a generic 500 body and a log entry naming GetByUsername.
+func TestRepositoryFailureLogsOperation(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	service := authapp.NewService(authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions()),
+		authsecurity.NewHMACPasswordHasher(), staticTokens{}, nil, authapp.Options{})
+	sqlDB, err := appDB.DB.DB()
+	if err != nil {
+		t.Fatalf("expected sql.DB, got %v", err)
+	}
+	_ = sqlDB.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
+		c.Next()
+	})
+	next := func(c *gin.Context) { c.Next() }
+	authapi.RegisterRoutes(engine, authapi.NewHandlers(service), next, next, next)
+
+	// Act
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"ash","password":"Password123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response httpapi.ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("expected an error body, got %v", err)
+	}
+	body := response.Error
+	if body.Code != httpapi.CodeInternal || strings.Contains(body.Message, "GetByUsername") || strings.Contains(body.Message, "sql") {
+		t.Fatalf("expected a generic internal error, got %+v", body)
+	}
+	if !strings.Contains(logs.String(), `"operation":"GetByUsername"`) {
+		t.Fatalf("expected the failed operation to be logged, got %s", logs.String())
+	}
+}
@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+)
+
+// roleAuthenticator treats the bearer token as the caller's role.
+type roleAuthenticator struct{}
+
+func (roleAuthenticator) Authenticate(_ context.Context, token string) (*authapp.Principal, error) {
+	return &authapp.Principal{UserID: 1, Username: "caller", Role: token}, nil
+}
+
+func newEventsServer(t *testing.T, heartbeat time.Duration) (*httptest.Server, *authevents.Hub) {
+	t.Helper()
+	hub := authevents.NewHub(4)
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	authapi.RegisterAdminRoutes(engine, authapi.NewAdminHandlers(nil), authapi.NewEventsHandlers(hub, heartbeat), func(c *gin.Context) { c.Next() }, authapi.RequireAuth(roleAuthenticator{}))
+	server := httptest.NewServer(engine)
+	t.Cleanup(func() {
+		hub.Close()
+		server.Close()
+	})
+	return server, hub
+}
+
+func getEvents(t *testing.T, server *httptest.Server, role string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/admin/events", nil)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+role)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected request to complete, got %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// readUntil returns the stream up to and including the first line containing want.
+func readUntil(t *testing.T, reader *bufio.Reader, want string) string {
+	t.Helper()
+	var seen strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		seen.WriteString(line)
+		if strings.Contains(line, want) {
+			return seen.String()
+		}
+		if err != nil {
+			t.Fatalf("expected %q in the stream, got %q (%v)", want, seen.String(), err)
+		}
+	}
+}
+
+// TestStreamEventsRequiresAdminRole hides auth events from regular users.
+// Arrange: serve the events route with a caller holding the user role.
+// Act: request /admin/events.
+// Assert: expect 403 with the FORBIDDEN code.
+func TestStreamEventsRequiresAdminRole(t *testing.T) {
+	// Arrange
+	server, _ := newEventsServer(t, time.Hour)
+
+	// Act
+	resp := getEvents(t, server, authdomain.RoleUser)
+
+	// Assert
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusForbidden || !strings.Contains(string(body), "FORBIDDEN") {
+		t.Fatalf("expected 403 FORBIDDEN, got %d %s", resp.StatusCode, body)
+	}
+}
+
+// TestStreamEventsDeliversPublishedEvents streams hub events to administrators.
+// Arrange: open the stream as an admin with a short heartbeat.
+// Act: wait for a heartbeat, then publish a failed login.
+// Assert: expect a text/event-stream carrying the ping and the named event.
+func TestStreamEventsDeliversPublishedEvents(t *testing.T) {
+	// Arrange
+	server, hub := newEventsServer(t, 20*time.Millisecond)
+	resp := getEvents(t, server, authdomain.RoleAdmin)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	reader := bufio.NewReader(resp.Body)
+
+	// Act
+	readUntil(t, reader, ": ping")
+	hub.Publish(authapp.AuthEvent{Type: authapp.EventLoginFailed, Username: "mallory", OccurredAt: time.Now()})
+	stream := readUntil(t, reader, "data:")
+
+	// Assert
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/event-stream") {
+		t.Fatalf("expected text/event-stream, got %q", contentType)
+	}
+	if !strings.Contains(stream, "event:"+authapp.EventLoginFailed) || !strings.Contains(stream, `"username":"mallory"`) {
+		t.Fatalf("expected a login_failed event for mallory, got %q", stream)
+	}
+}
+
+// TestStreamEventsEndsWhenHubCloses lets server shutdown finish.
+// Arrange: open the stream as an admin.
+// Act: close the hub.
+// Assert: expect the response body to end.
+func TestStreamEventsEndsWhenHubCloses(t *testing.T) {
+	// Arrange
+	server, hub := newEventsServer(t, time.Hour)
+	resp := getEvents(t, server, authdomain.RoleAdmin)
+
+	// Act
+	hub.Close()
+
+	// Assert
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, resp.Body)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected the stream to end after the hub closed")
+	}
+}
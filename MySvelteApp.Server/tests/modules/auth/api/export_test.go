@@ -0,0 +1,183 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+	authpersistence "mysvelteapp/server_new/internal/modules/auth/infra/persistence"
+	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
+	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	pokemonpersistence "mysvelteapp/server_new/internal/modules/pokemon/infra/persistence"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// staticTokens issues the same token to everyone.
+type staticTokens struct{}
+
+func (staticTokens) GenerateToken(*authdomain.User) (string, error) { return "token", nil }
+func (staticTokens) GenerateTokenWithLifetime(*authdomain.User, time.Duration) (string, error) {
+	return "token", nil
+}
+
+// userAuthenticator authenticates every request as one user.
+type userAuthenticator struct{ userID uint }
+
+func (a userAuthenticator) Authenticate(context.Context, string) (*authapp.Principal, error) {
+	return &authapp.Principal{UserID: a.userID, Username: "exporter", Role: authdomain.RoleUser}, nil
+}
+
+type exportFixture struct {
+	db      *gorm.DB
+	service *authapp.Service
+	userID  uint
+}
+
+// newExportFixture registers a user against a migrated in-memory database,
+// recording auth events as production does, then fails one login.
+func newExportFixture(t *testing.T) exportFixture {
+	t.Helper()
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+
+	retry := platformpersistence.DefaultRetryOptions()
+	accountEvents := authpersistence.NewGormAccountEventLog(appDB.DB, retry)
+	bus := authevents.NewBus(func(_ authapp.DomainEvent, err error) { t.Errorf("expected event to be recorded, got %v", err) })
+	authevents.RecordAccountEvents(bus, accountEvents)
+	service := authapp.NewService(authpersistence.NewGormUserRepository(appDB.DB, retry), authsecurity.NewHMACPasswordHasher(), staticTokens{}, nil, authapp.Options{
+		Events:        bus,
+		AccountEvents: accountEvents,
+	})
+
+	registered, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "exporter",
+		Email:    "exporter@example.com",
+		Password: "Password123",
+	})
+	if err != nil {
+		t.Fatalf("expected registration to succeed, got %v", err)
+	}
+	if _, err := service.Login(context.Background(), authapp.LoginRequest{Username: "exporter", Password: "WrongPass123"}); !authapp.IsUnauthorizedError(err) {
+		t.Fatalf("expected wrong password to be rejected, got %v", err)
+	}
+	return exportFixture{db: appDB.DB, service: service, userID: registered.UserID}
+}
+
+func getExport(t *testing.T, fixture exportFixture, sections ...authapi.ExportSection) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	next := func(c *gin.Context) { c.Next() }
+	handlers := authapi.NewHandlers(fixture.service, authapi.WithExportSections(sections...))
+	authapi.RegisterRoutes(engine, handlers, next, next, authapi.RequireAuth(userAuthenticator{userID: fixture.userID}))
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/me/export", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestExportMeContainsProfileFavoritesAndHistoryWithoutCredentials serves a user their own data.
+// Arrange: register a user, fail a login and save a favorite through the repositories.
+// Act: download the export with the favorites section configured.
+// Assert: expect an attachment holding the profile, favorite and auth history, and no password fields.
+func TestExportMeContainsProfileFavoritesAndHistoryWithoutCredentials(t *testing.T) {
+	// Arrange
+	fixture := newExportFixture(t)
+	favorite, err := pokemondomain.NewFavoritePokemon(fixture.userID, "pikachu", "electric", "https://img.example/pikachu.png")
+	if err != nil {
+		t.Fatalf("expected favorite, got %v", err)
+	}
+	favorites := pokemonpersistence.NewGormFavoriteRepository(fixture.db)
+	if err := favorites.Add(context.Background(), favorite); err != nil {
+		t.Fatalf("expected favorite to be saved, got %v", err)
+	}
+	favoritesService := pokemonapp.NewFavoritesService(favorites, nil)
+
+	// Act
+	recorder := getExport(t, fixture, pokemonapi.FavoritesExportSection(favoritesService))
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment") {
+		t.Fatalf("expected an attachment, got Content-Disposition %q", disposition)
+	}
+	var stored authdomain.User
+	if err := fixture.db.First(&stored, fixture.userID).Error; err != nil {
+		t.Fatalf("expected stored user, got %v", err)
+	}
+	body := recorder.Body.String()
+	lower := strings.ToLower(body)
+	if strings.Contains(lower, `"password`) || strings.Contains(lower, `salt"`) ||
+		strings.Contains(body, stored.PasswordHash) || strings.Contains(body, stored.PasswordSalt) {
+		t.Fatalf("expected no credential fields or values, got %s", body)
+	}
+	var export struct {
+		Profile    authapi.ExportProfileResponse  `json:"profile"`
+		AuthEvents []authapi.AccountEventResponse `json:"authEvents"`
+		Favorites  []struct {
+			Name string `json:"name"`
+		} `json:"favorites"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &export); err != nil {
+		t.Fatalf("expected a JSON document, got %q (%v)", recorder.Body.String(), err)
+	}
+	if export.Profile.ID != fixture.userID || export.Profile.Username != "exporter" || export.Profile.Email != "exporter@example.com" {
+		t.Fatalf("expected the caller's profile, got %+v", export.Profile)
+	}
+	if len(export.Favorites) != 1 || export.Favorites[0].Name != "pikachu" {
+		t.Fatalf("expected pikachu as the only favorite, got %+v", export.Favorites)
+	}
+	if len(export.AuthEvents) != 2 ||
+		export.AuthEvents[0].Type != authapp.EventUserRegistered ||
+		export.AuthEvents[1].Type != authapp.EventLoginFailed || export.AuthEvents[1].Reason != authapp.LoginFailureWrongPassword {
+		t.Fatalf("expected registration then wrong-password failure, got %+v", export.AuthEvents)
+	}
+}
+
+// TestExportMeCutsDocumentShortWhenASectionFails never passes off a partial export as complete.
+// Arrange: register a user and configure a section that fails.
+// Act: download the export.
+// Assert: expect the document to be left as invalid JSON.
+func TestExportMeCutsDocumentShortWhenASectionFails(t *testing.T) {
+	// Arrange
+	fixture := newExportFixture(t)
+	failing := authapi.ExportSection{
+		Name: "broken",
+		Export: func(context.Context, uint, func(any) error) error {
+			return errors.New("storage offline")
+		},
+	}
+
+	// Act
+	recorder := getExport(t, fixture, failing)
+
+	// Assert
+	var document map[string]any
+	if err := json.Unmarshal(recorder.Body.Bytes(), &document); err == nil {
+		t.Fatalf("expected an unterminated document, got %s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,38 @@
+package api_test
+
+import (
+	"testing"
+
+	authapi "mysvelteapp/server_new/internal/modules/auth/api"
+)
+
+// TestBearerToken extracts tokens only from well-formed Bearer headers.
+// Arrange: table-drive header values.
+// Act: call BearerToken for each.
+// Assert: expect the token and ok flag to match.
+func TestBearerToken(t *testing.T) {
+	testCases := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{header: "Bearer abc.def.ghi", token: "abc.def.ghi", ok: true},
+		{header: "bearer   abc ", token: "abc", ok: true},
+		{header: "Basic dXNlcjpwYXNz", ok: false},
+		{header: "Bearer", ok: false},
+		{header: "Bearer   ", ok: false},
+		{header: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			// Act
+			token, ok := authapi.BearerToken(tc.header)
+
+			// Assert
+			if token != tc.token || ok != tc.ok {
+				t.Fatalf("expected (%q, %t), got (%q, %t)", tc.token, tc.ok, token, ok)
+			}
+		})
+	}
+}
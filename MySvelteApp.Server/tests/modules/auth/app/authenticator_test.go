@@ -0,0 +1,76 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// stubTokenValidator resolves every token to the configured principal.
+type stubTokenValidator struct {
+	principal *authapp.Principal
+	err       error
+}
+
+func (s stubTokenValidator) ValidateToken(string) (*authapp.Principal, error) {
+	return s.principal, s.err
+}
+
+// TestAuthenticateRejectsOutdatedTokenVersion enforces global logout.
+// Arrange: register a user and table-drive token versions and validator errors.
+// Act: bump the stored version once and authenticate.
+// Assert: expect only current-version tokens to authenticate.
+func TestAuthenticateRejectsOutdatedTokenVersion(t *testing.T) {
+	testCases := []struct {
+		name         string
+		tokenVersion int
+		validatorErr error
+		wantErr      bool
+	}{
+		{name: "current version", tokenVersion: 1},
+		{name: "version before rotation", tokenVersion: 0, wantErr: true},
+		{name: "invalid token", tokenVersion: 1, validatorErr: errors.New("token expired"), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			repo := newMemoryUserRepository()
+			result, err := newAuthService(repo).Register(context.Background(), authapp.RegisterRequest{
+				Username: "rotating_user",
+				Email:    "rotating@example.com",
+				Password: "Password123",
+			})
+			if err != nil {
+				t.Fatalf("registration failed: %v", err)
+			}
+			if err := newAuthService(repo).LogoutAll(context.Background(), result.UserID); err != nil {
+				t.Fatalf("expected logout-all to succeed, got %v", err)
+			}
+			authenticator := authapp.NewTokenAuthenticator(stubTokenValidator{
+				principal: &authapp.Principal{UserID: result.UserID, TokenVersion: tc.tokenVersion},
+				err:       tc.validatorErr,
+			}, repo)
+
+			// Act
+			principal, err := authenticator.Authenticate(context.Background(), "token")
+
+			// Assert
+			if tc.wantErr {
+				if !authapp.IsUnauthorizedError(err) {
+					t.Fatalf("expected an UnauthorizedError, got %v", err)
+				}
+				return
+			}
+			if err != nil || principal == nil || principal.UserID != result.UserID {
+				t.Fatalf("expected the caller to authenticate, got %+v and %v", principal, err)
+			}
+			if principal.Role != authdomain.RoleUser {
+				t.Fatalf("expected the stored role %q, got %q", authdomain.RoleUser, principal.Role)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	"mysvelteapp/server_new/internal/platform/kvstore"
+)
+
+// memoryEmailChangeStore keeps pending emails on the users held by a
+// memoryUserRepository.
+type memoryEmailChangeStore struct {
+	users *memoryUserRepository
+}
+
+func (m memoryEmailChangeStore) SetPendingEmail(ctx context.Context, userID uint, email string) error {
+	user, err := m.users.GetByID(ctx, userID)
+	if user != nil {
+		user.PendingEmail = email
+	}
+	return err
+}
+
+func (m memoryEmailChangeStore) ConfirmEmail(ctx context.Context, userID uint, email string) (bool, error) {
+	user, err := m.users.GetByID(ctx, userID)
+	if user == nil || user.PendingEmail != email {
+		return false, err
+	}
+	delete(m.users.usersByEmail, user.Email)
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	m.users.usersByEmail[user.Email] = user
+	return true, nil
+}
+
+// recordingMailer remembers the last verification email it was asked to send.
+type recordingMailer struct {
+	to    string
+	token string
+}
+
+func (m *recordingMailer) SendVerification(_ context.Context, to, _, token string, _ time.Time) error {
+	m.to, m.token = to, token
+	return nil
+}
+
+func (m *recordingMailer) SendPasswordReset(context.Context, string, string, string, time.Time) error {
+	return nil
+}
+
+// newEmailChangeService stores misty and brock and returns a service whose
+// clock reads *now, along with misty's ID.
+func newEmailChangeService(t *testing.T, mailer *recordingMailer, now *time.Time) (*authapp.Service, *memoryUserRepository, uint) {
+	t.Helper()
+	repo := newMemoryUserRepository()
+	misty, _ := authdomain.NewUser("misty", "misty@example.com", "hash", "salt")
+	brock, _ := authdomain.NewUser("brock", "brock@example.com", "hash", "salt")
+	for _, user := range []*authdomain.User{misty, brock} {
+		if err := repo.Add(context.Background(), user); err != nil {
+			t.Fatalf("expected seed user, got %v", err)
+		}
+	}
+	service := newAuthServiceWithOptions(repo, authapp.Options{
+		EmailChanges:   memoryEmailChangeStore{users: repo},
+		Tokens:         kvstore.NewMemoryStore().WithClock(func() time.Time { return *now }),
+		Mailer:         mailer,
+		EmailChangeTTL: time.Hour,
+		Now:            func() time.Time { return *now },
+	})
+	return service, repo, misty.ID
+}
+
+// TestChangeEmailWaitsForConfirmation keeps the old address until the new one is verified.
+// Arrange: store misty and build a service with a recording mailer.
+// Act: request a change to a new address, then confirm it with the emailed token.
+// Assert: expect the token sent to the new address, the old address in use
+// until confirmation and the new one afterwards; the token is single use.
+func TestChangeEmailWaitsForConfirmation(t *testing.T) {
+	// Arrange
+	mailer := &recordingMailer{}
+	now := time.Now()
+	service, repo, mistyID := newEmailChangeService(t, mailer, &now)
+
+	// Act
+	changeErr := service.ChangeEmail(context.Background(), mistyID, "misty@Cerulean.example")
+	pending, _ := repo.GetByID(context.Background(), mistyID)
+	pendingEmail := pending.Email
+	confirmErr := service.ConfirmEmailChange(context.Background(), mailer.token)
+	reuseErr := service.ConfirmEmailChange(context.Background(), mailer.token)
+
+	// Assert
+	if changeErr != nil {
+		t.Fatalf("expected change request to succeed, got %v", changeErr)
+	}
+	if mailer.to != "misty@cerulean.example" || mailer.token == "" {
+		t.Fatalf("expected a token mailed to misty@cerulean.example, got %q to %q", mailer.token, mailer.to)
+	}
+	if pendingEmail != "misty@example.com" {
+		t.Fatalf("expected the old email until confirmation, got %q", pendingEmail)
+	}
+	if confirmErr != nil {
+		t.Fatalf("expected confirmation to succeed, got %v", confirmErr)
+	}
+	if confirmed, _ := repo.GetByID(context.Background(), mistyID); confirmed.Email != "misty@cerulean.example" {
+		t.Fatalf("expected the new email after confirmation, got %q", confirmed.Email)
+	}
+	var validation authapp.ValidationError
+	if !errors.As(reuseErr, &validation) || validation.Code != authapp.CodeInvalidToken {
+		t.Fatalf("expected a reused token to be invalid, got %v", reuseErr)
+	}
+}
+
+// TestChangeEmailRejectsTakenAddress refuses addresses held by another account.
+// Arrange: store misty and brock.
+// Act: ask to move misty to brock's address with an upper-case domain.
+// Assert: expect a ConflictError and no email sent.
+func TestChangeEmailRejectsTakenAddress(t *testing.T) {
+	// Arrange
+	mailer := &recordingMailer{}
+	now := time.Now()
+	service, _, mistyID := newEmailChangeService(t, mailer, &now)
+
+	// Act
+	err := service.ChangeEmail(context.Background(), mistyID, "brock@EXAMPLE.com")
+
+	// Assert
+	if !authapp.IsConflictError(err) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if mailer.token != "" {
+		t.Fatalf("expected no email to be sent, got one to %q", mailer.to)
+	}
+}
+
+// TestConfirmEmailChangeRejectsBadTokens only accepts live tokens for free addresses.
+// Arrange: request a change for misty, then let the token expire, replace
+// the pending address or have another account claim it.
+// Act: confirm with the emailed token, or with an unknown one.
+// Assert: expect an invalid token error or a conflict, and misty's old email kept.
+func TestConfirmEmailChangeRejectsBadTokens(t *testing.T) {
+	testCases := []struct {
+		name     string
+		prepare  func(repo *memoryUserRepository, now *time.Time)
+		token    func(mailed string) string
+		conflict bool
+	}{
+		{name: "unknown", token: func(string) string { return "not-a-token" }},
+		{name: "expired", prepare: func(_ *memoryUserRepository, now *time.Time) { *now = now.Add(2 * time.Hour) }},
+		{name: "superseded", prepare: func(repo *memoryUserRepository, _ *time.Time) {
+			repo.usersByUsername["misty"].PendingEmail = "misty@pewter.example"
+		}},
+		{name: "claimed", conflict: true, prepare: func(repo *memoryUserRepository, _ *time.Time) {
+			gary, _ := authdomain.NewUser("gary", "misty@cerulean.example", "hash", "salt")
+			_ = repo.Add(context.Background(), gary)
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			mailer := &recordingMailer{}
+			now := time.Now()
+			service, repo, mistyID := newEmailChangeService(t, mailer, &now)
+			if err := service.ChangeEmail(context.Background(), mistyID, "misty@cerulean.example"); err != nil {
+				t.Fatalf("expected change request to succeed, got %v", err)
+			}
+			if tc.prepare != nil {
+				tc.prepare(repo, &now)
+			}
+			token := mailer.token
+			if tc.token != nil {
+				token = tc.token(token)
+			}
+
+			// Act
+			err := service.ConfirmEmailChange(context.Background(), token)
+
+			// Assert
+			var validation authapp.ValidationError
+			switch {
+			case tc.conflict && !authapp.IsConflictError(err):
+				t.Fatalf("expected a conflict, got %v", err)
+			case !tc.conflict && (!errors.As(err, &validation) || validation.Code != authapp.CodeInvalidToken):
+				t.Fatalf("expected an invalid token error, got %v", err)
+			}
+			if misty, _ := repo.GetByID(context.Background(), mistyID); misty.Email != "misty@example.com" {
+				t.Fatalf("expected misty to keep the old email, got %q", misty.Email)
+			}
+		})
+	}
+}
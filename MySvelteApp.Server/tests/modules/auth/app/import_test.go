@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
+)
+
+// TestImportUsersReportsEachRow skips bad rows without failing the batch.
+// Arrange: store misty, then build a batch with a new user, misty again, a
+// repeat of the new user's email and an unknown role.
+// Act: import the batch.
+// Assert: expect only the first row created, inside a transaction, and a
+// conflict or validation error for every other row.
+func TestImportUsersReportsEachRow(t *testing.T) {
+	// Arrange
+	repo := &transactionalUserRepository{memoryUserRepository: newMemoryUserRepository()}
+	service := authapp.NewService(repo, authsecurity.NewHMACPasswordHasher(), stubTokenGenerator{}, nil, authapp.Options{})
+	existing, _ := authdomain.NewUser("misty", "misty@example.com", "hash", "salt")
+	if err := repo.memoryUserRepository.Add(context.Background(), existing); err != nil {
+		t.Fatalf("expected seed user, got %v", err)
+	}
+	rows := []authapp.ImportUserRequest{
+		{Username: " brock ", Email: "brock@example.com", PasswordHash: "hash", PasswordSalt: "salt", Role: authdomain.RoleAdmin},
+		{Username: "misty", Email: "misty2@example.com", PasswordHash: "hash", PasswordSalt: "salt"},
+		{Username: "brock_two", Email: "brock@example.com", PasswordHash: "hash", PasswordSalt: "salt"},
+		{Username: "gary", Email: "gary@example.com", PasswordHash: "hash", PasswordSalt: "salt", Role: "superuser"},
+	}
+
+	// Act
+	results, err := service.ImportUsers(context.Background(), rows)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no batch error, got %v", err)
+	}
+	if len(results) != len(rows) {
+		t.Fatalf("expected %d results, got %d", len(rows), len(results))
+	}
+	if results[0].Err != nil || results[0].UserID == 0 || results[0].Username != "brock" {
+		t.Fatalf("expected brock to be created, got %+v", results[0])
+	}
+	if brock, _ := repo.GetByUsername(context.Background(), "brock"); brock == nil || brock.Role != authdomain.RoleAdmin || brock.PasswordHash != "hash" {
+		t.Fatalf("expected brock stored as an admin with the given hash, got %+v", brock)
+	}
+	if !repo.addedInTx {
+		t.Fatalf("expected users to be added inside a transaction")
+	}
+	for _, i := range []int{1, 2} {
+		if !authapp.IsConflictError(results[i].Err) {
+			t.Fatalf("expected row %d to conflict, got %v", i, results[i].Err)
+		}
+	}
+	var validation authapp.ValidationError
+	if !errors.As(results[3].Err, &validation) || validation.Field != authapp.FieldRole {
+		t.Fatalf("expected a role validation error, got %v", results[3].Err)
+	}
+}
+
+// TestImportUsersRejectsEmptyBatch requires at least one row.
+// Arrange: use an empty repository.
+// Act: import no users.
+// Assert: expect a ValidationError for the whole request.
+func TestImportUsersRejectsEmptyBatch(t *testing.T) {
+	// Arrange
+	service := newAuthService(newMemoryUserRepository())
+
+	// Act
+	results, err := service.ImportUsers(context.Background(), nil)
+
+	// Assert
+	var validation authapp.ValidationError
+	if results != nil || !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error, got %v and %v", results, err)
+	}
+}
+
+// TestImportUsersFailsBatchOnInsertConflict rolls back when a row loses a race.
+// Arrange: use a repository whose Add reports a unique violation that the
+// existence checks did not foresee.
+// Act: import a single valid row.
+// Assert: expect a ConflictError for the batch and no per-row results.
+func TestImportUsersFailsBatchOnInsertConflict(t *testing.T) {
+	// Arrange
+	repo := &racingUserRepository{
+		memoryUserRepository: newMemoryUserRepository(),
+		addErr:               fmt.Errorf("%w: UNIQUE constraint failed: users.email", authapp.ErrDuplicateEmail),
+	}
+	service := authapp.NewService(repo, authsecurity.NewHMACPasswordHasher(), stubTokenGenerator{}, nil, authapp.Options{})
+	rows := []authapp.ImportUserRequest{{Username: "racer", Email: "racer@example.com", PasswordHash: "hash", PasswordSalt: "salt"}}
+
+	// Act
+	results, err := service.ImportUsers(context.Background(), rows)
+
+	// Assert
+	if !authapp.IsConflictError(err) {
+		t.Fatalf("expected a conflict for the batch, got %v", err)
+	}
+	if results != nil {
+		t.Fatalf("expected no results, got %+v", results)
+	}
+}
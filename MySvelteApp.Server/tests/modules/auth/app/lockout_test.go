@@ -0,0 +1,162 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+)
+
+// memoryLoginAttemptStore applies the lockout policy in memory.
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]authapp.LoginAttempts
+}
+
+func newMemoryLoginAttemptStore() *memoryLoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]authapp.LoginAttempts)}
+}
+
+func (m *memoryLoginAttemptStore) Get(_ context.Context, key string) (authapp.LoginAttempts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.attempts[key], nil
+}
+
+func (m *memoryLoginAttemptStore) RecordFailure(_ context.Context, key string, policy authapp.LockoutPolicy, now time.Time) (authapp.LoginAttempts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempts := m.attempts[key]
+	attempts.Failures++
+	if attempts.Failures >= policy.MaxFailures {
+		attempts = authapp.LoginAttempts{LockedUntil: now.Add(policy.Duration)}
+	}
+	m.attempts[key] = attempts
+	return attempts, nil
+}
+
+func (m *memoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.attempts, key)
+	return nil
+}
+
+func (m *memoryLoginAttemptStore) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newLockoutService(t *testing.T, store authapp.LoginAttemptStore, now *time.Time) *authapp.Service {
+	t.Helper()
+	return newLockoutServiceWithOptions(t, store, now, authapp.Options{}, "Lockout_User")
+}
+
+// newLockoutServiceWithOptions allows three failures per minute on top of
+// options and registers each of usernames with the password Password123.
+func newLockoutServiceWithOptions(t *testing.T, store authapp.LoginAttemptStore, now *time.Time, options authapp.Options, usernames ...string) *authapp.Service {
+	t.Helper()
+	options.LoginAttempts = store
+	options.Lockout = authapp.LockoutPolicy{MaxFailures: 3, Window: time.Minute, Duration: 10 * time.Minute}
+	options.Now = func() time.Time { return *now }
+	service := newAuthServiceWithOptions(newMemoryUserRepository(), options)
+	for i, username := range usernames {
+		_, err := service.Register(context.Background(), authapp.RegisterRequest{
+			Username: username,
+			Email:    fmt.Sprintf("lockout%d@example.com", i),
+			Password: "Password123",
+		})
+		if err != nil {
+			t.Fatalf("registration failed: %v", err)
+		}
+	}
+	return service
+}
+
+// TestLoginLocksAccountAfterRepeatedFailures stops password guessing.
+// Arrange: allow three failures and, with case-insensitive usernames, fail
+// three logins varying the username's case.
+// Act: log in with the correct password while locked and again once the lock ends.
+// Assert: expect a LockedError with the remaining time, then success.
+func TestLoginLocksAccountAfterRepeatedFailures(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newLockoutServiceWithOptions(t, newMemoryLoginAttemptStore(), &now, authapp.Options{UsernameCaseInsensitive: true}, "Lockout_User")
+	for _, username := range []string{"lockout_user", "LOCKOUT_USER", "Lockout_User"} {
+		_, err := service.Login(context.Background(), authapp.LoginRequest{Username: username, Password: "wrong-password"})
+		if !authapp.IsUnauthorizedError(err) {
+			t.Fatalf("expected UnauthorizedError for a wrong password, got %v", err)
+		}
+	}
+	correct := authapp.LoginRequest{Username: "Lockout_User", Password: "Password123"}
+
+	// Act
+	_, lockedErr := service.Login(context.Background(), correct)
+	now = now.Add(10 * time.Minute)
+	result, err := service.Login(context.Background(), correct)
+
+	// Assert
+	var locked authapp.LockedError
+	if !errors.As(lockedErr, &locked) || locked.RetryAfter != 10*time.Minute {
+		t.Fatalf("expected LockedError with 10m remaining, got %v", lockedErr)
+	}
+	if err != nil || result == nil {
+		t.Fatalf("expected login to succeed once the lock ended, got %v", err)
+	}
+}
+
+// TestLoginSuccessResetsFailures gives users a fresh count after logging in.
+// Arrange: fail two of three allowed logins, then log in successfully.
+// Act: fail two more logins.
+// Assert: expect the account to remain unlocked.
+func TestLoginSuccessResetsFailures(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryLoginAttemptStore()
+	service := newLockoutService(t, store, &now)
+	wrong := authapp.LoginRequest{Username: "Lockout_User", Password: "wrong-password"}
+	_, _ = service.Login(context.Background(), wrong)
+	_, _ = service.Login(context.Background(), wrong)
+	if _, err := service.Login(context.Background(), authapp.LoginRequest{Username: "Lockout_User", Password: "Password123"}); err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+
+	// Act
+	_, _ = service.Login(context.Background(), wrong)
+	_, _ = service.Login(context.Background(), wrong)
+
+	// Assert
+	attempts, _ := store.Get(context.Background(), "Lockout_User")
+	if attempts.Locked(now) || attempts.Failures != 2 {
+		t.Fatalf("expected 2 failures and no lock, got %+v", attempts)
+	}
+}
+
+// TestLoginLockoutSeparatesCaseVariantAccounts keeps one account's failures from locking another.
+// Arrange: with case-sensitive usernames, register Ash and ash.
+// Act: fail three logins as Ash, then log in as ash with the right password.
+// Assert: expect Ash locked and ash to sign in.
+func TestLoginLockoutSeparatesCaseVariantAccounts(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newLockoutServiceWithOptions(t, newMemoryLoginAttemptStore(), &now, authapp.Options{}, "Ash", "ash")
+	for i := 0; i < 3; i++ {
+		_, _ = service.Login(context.Background(), authapp.LoginRequest{Username: "Ash", Password: "wrong-password"})
+	}
+
+	// Act
+	_, lockedErr := service.Login(context.Background(), authapp.LoginRequest{Username: "Ash", Password: "Password123"})
+	result, err := service.Login(context.Background(), authapp.LoginRequest{Username: "ash", Password: "Password123"})
+
+	// Assert
+	var locked authapp.LockedError
+	if !errors.As(lockedErr, &locked) {
+		t.Fatalf("expected Ash to be locked, got %v", lockedErr)
+	}
+	if err != nil || result == nil || result.Username != "ash" {
+		t.Fatalf("expected ash to sign in, got %+v and %v", result, err)
+	}
+}
@@ -0,0 +1,73 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+)
+
+// TestSeedAdminIsIdempotent ensures re-running the seed leaves a single admin.
+// Arrange: create an auth service backed by an empty in-memory repository.
+// Act: seed the same admin account twice.
+// Assert: expect the first call to create an admin and the second to skip it.
+func TestSeedAdminIsIdempotent(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service := newAuthService(repo)
+	request := authapp.SeedAdminRequest{
+		Username: "admin",
+		Email:    "admin@localhost",
+		Password: "Password123",
+	}
+
+	// Act
+	first, firstErr := service.SeedAdmin(context.Background(), request)
+	second, secondErr := service.SeedAdmin(context.Background(), request)
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("expected no errors, got %v and %v", firstErr, secondErr)
+	}
+	if !first || second {
+		t.Fatalf("expected only the first seed to create a user, got %t and %t", first, second)
+	}
+	if len(repo.usersByUsername) != 1 {
+		t.Fatalf("expected exactly one user, got %d", len(repo.usersByUsername))
+	}
+	stored := repo.usersByUsername["admin"]
+	if stored.Role != authdomain.RoleAdmin {
+		t.Fatalf("expected role %q, got %q", authdomain.RoleAdmin, stored.Role)
+	}
+	if stored.PasswordHash == "" || stored.PasswordHash == request.Password {
+		t.Fatalf("expected the password to be hashed")
+	}
+}
+
+// TestSeedAdminRejectsWeakPassword applies the registration password rules.
+// Arrange: create an auth service backed by an empty in-memory repository.
+// Act: seed an admin with a password that fails validation.
+// Assert: expect a validation error and no stored user.
+func TestSeedAdminRejectsWeakPassword(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service := newAuthService(repo)
+
+	// Act
+	created, err := service.SeedAdmin(context.Background(), authapp.SeedAdminRequest{
+		Username: "admin",
+		Email:    "admin@localhost",
+		Password: "short",
+	})
+
+	// Assert
+	var validation authapp.ValidationError
+	if created || !errors.As(err, &validation) {
+		t.Fatalf("expected a validation error, got created=%t err=%v", created, err)
+	}
+	if len(repo.usersByUsername) != 0 {
+		t.Fatalf("expected no users, got %d", len(repo.usersByUsername))
+	}
+}
@@ -3,8 +3,10 @@ package app_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	authapp "mysvelteapp/server_new/internal/modules/auth/app"
 	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
@@ -25,18 +27,40 @@ func newMemoryUserRepository() *memoryUserRepository {
 	}
 }
 
+// WithinTransaction is a no-op: the in-memory store has nothing to roll back.
+func (m *memoryUserRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (m *memoryUserRepository) Add(_ context.Context, user *authdomain.User) error {
 	clone := *user
 	clone.ID = m.nextID
 	m.nextID++
 
 	m.usersByUsername[clone.Username] = &clone
-	m.usersByEmail[strings.ToLower(clone.Email)] = &clone
+	m.usersByEmail[clone.Email] = &clone
 
 	user.ID = clone.ID
 	return nil
 }
 
+func (m *memoryUserRepository) GetByID(_ context.Context, id uint) (*authdomain.User, error) {
+	for _, user := range m.usersByUsername {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryUserRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	user, err := m.GetByID(ctx, id)
+	if user != nil {
+		user.TokenVersion++
+	}
+	return err
+}
+
 func (m *memoryUserRepository) GetByUsername(_ context.Context, username string) (*authdomain.User, error) {
 	if user, ok := m.usersByUsername[username]; ok {
 		clone := *user
@@ -45,31 +69,83 @@ func (m *memoryUserRepository) GetByUsername(_ context.Context, username string)
 	return nil, nil
 }
 
+func (m *memoryUserRepository) GetByNormalizedUsername(_ context.Context, normalizedUsername string) (*authdomain.User, error) {
+	for _, user := range m.usersByUsername {
+		if user.NormalizedUsername == normalizedUsername {
+			clone := *user
+			return &clone, nil
+		}
+	}
+	return nil, nil
+}
+
 func (m *memoryUserRepository) UsernameExists(_ context.Context, username string) (bool, error) {
 	_, ok := m.usersByUsername[username]
 	return ok, nil
 }
 
+func (m *memoryUserRepository) NormalizedUsernameExists(ctx context.Context, normalizedUsername string) (bool, error) {
+	user, err := m.GetByNormalizedUsername(ctx, normalizedUsername)
+	return user != nil, err
+}
+
 func (m *memoryUserRepository) EmailExists(_ context.Context, email string) (bool, error) {
-	_, ok := m.usersByEmail[strings.ToLower(email)]
+	_, ok := m.usersByEmail[email]
 	return ok, nil
 }
 
+// racingUserRepository simulates a concurrent insert winning the race: the
+// existence checks pass but Add fails on the unique constraint.
+type racingUserRepository struct {
+	*memoryUserRepository
+	addErr error
+}
+
+func (r *racingUserRepository) Add(_ context.Context, _ *authdomain.User) error {
+	return r.addErr
+}
+
+// transactionalUserRepository records whether Add ran inside WithinTransaction.
+type transactionalUserRepository struct {
+	*memoryUserRepository
+	inTransaction bool
+	addedInTx     bool
+}
+
+func (r *transactionalUserRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.inTransaction = true
+	defer func() { r.inTransaction = false }()
+	return fn(ctx)
+}
+
+func (r *transactionalUserRepository) Add(ctx context.Context, user *authdomain.User) error {
+	r.addedInTx = r.inTransaction
+	return r.memoryUserRepository.Add(ctx, user)
+}
+
 type stubTokenGenerator struct{}
 
 func (stubTokenGenerator) GenerateToken(_ *authdomain.User) (string, error) {
 	return "token-123", nil
 }
 
+func (stubTokenGenerator) GenerateTokenWithLifetime(_ *authdomain.User, lifetime time.Duration) (string, error) {
+	return "token-" + lifetime.String(), nil
+}
+
 func newAuthService(repo *memoryUserRepository) *authapp.Service {
+	return newAuthServiceWithOptions(repo, authapp.Options{})
+}
+
+func newAuthServiceWithOptions(repo *memoryUserRepository, options authapp.Options) *authapp.Service {
 	hasher := authsecurity.NewHMACPasswordHasher()
-	return authapp.NewService(repo, hasher, stubTokenGenerator{})
+	return authapp.NewService(repo, hasher, stubTokenGenerator{}, nil, options)
 }
 
 // TestRegisterSuccess validates the happy-path registration flow.
 // Arrange: configure in-memory dependencies with a fresh auth service.
 // Act: call Register with valid, mixed-case input.
-// Assert: expect a token, persisted user ID, and normalised stored fields.
+// Assert: expect a token, persisted user ID, and a trimmed email with a lowercase domain.
 func TestRegisterSuccess(t *testing.T) {
 	// Arrange
 	repo := newMemoryUserRepository()
@@ -103,8 +179,8 @@ func TestRegisterSuccess(t *testing.T) {
 	if stored == nil {
 		t.Fatalf("expected user to be stored in repository")
 	}
-	if stored.Email != "new_user@example.com" {
-		t.Fatalf("expected email to be normalised, got %q", stored.Email)
+	if stored.Email != "NEW_user@example.com" {
+		t.Fatalf("expected email domain to be normalised, got %q", stored.Email)
 	}
 	if stored.PasswordHash == "Password123" {
 		t.Fatalf("expected password hash to differ from plain text")
@@ -154,8 +230,72 @@ func TestRegisterDuplicateUsername(t *testing.T) {
 	}
 }
 
-// TestRegisterDuplicateEmail ensures email uniqueness is enforced case-insensitively.
-// Arrange: seed a user whose email differs only by case.
+// TestRegisterUsernameCaseVariants covers both username normalisation modes.
+// Arrange: seed "Alice" with the case-insensitive flag on or off.
+// Act: register "alice" and log in using a different casing.
+// Assert: expect a conflict and a successful login only when the flag is on.
+func TestRegisterUsernameCaseVariants(t *testing.T) {
+	testCases := []struct {
+		name            string
+		caseInsensitive bool
+		expectConflict  bool
+	}{
+		{name: "case sensitive", caseInsensitive: false, expectConflict: false},
+		{name: "case insensitive", caseInsensitive: true, expectConflict: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			repo := newMemoryUserRepository()
+			service := newAuthServiceWithOptions(repo, authapp.Options{UsernameCaseInsensitive: tc.caseInsensitive})
+
+			_, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: "Alice",
+				Email:    "alice@example.com",
+				Password: "Password123",
+			})
+			if err != nil {
+				t.Fatalf("expected first registration to succeed, got %v", err)
+			}
+
+			// Act
+			_, registerErr := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: "alice",
+				Email:    "other@example.com",
+				Password: "Password123",
+			})
+			login, loginErr := service.Login(context.Background(), authapp.LoginRequest{
+				Username: "ALICE",
+				Password: "Password123",
+			})
+
+			// Assert
+			if tc.expectConflict {
+				if !authapp.IsConflictError(registerErr) {
+					t.Fatalf("expected conflict error, got %v", registerErr)
+				}
+				if loginErr != nil {
+					t.Fatalf("expected case-insensitive login to succeed, got %v", loginErr)
+				}
+				if login.Username != "Alice" {
+					t.Fatalf("expected display casing to be preserved, got %q", login.Username)
+				}
+				return
+			}
+
+			if registerErr != nil {
+				t.Fatalf("expected case variant to register, got %v", registerErr)
+			}
+			if !authapp.IsUnauthorizedError(loginErr) {
+				t.Fatalf("expected unauthorized error, got %v", loginErr)
+			}
+		})
+	}
+}
+
+// TestRegisterDuplicateEmail ensures email uniqueness ignores domain casing.
+// Arrange: seed a user whose email differs only by domain case.
 // Act: register another user with the same email.
 // Assert: expect a conflict error describing the duplicate email.
 func TestRegisterDuplicateEmail(t *testing.T) {
@@ -176,7 +316,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 	// Act
 	result, err := service.Register(context.Background(), authapp.RegisterRequest{
 		Username: "second_user",
-		Email:    "USER@example.com",
+		Email:    "user@EXAMPLE.com",
 		Password: "Password123",
 	})
 	// Assert
@@ -197,6 +337,247 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 	}
 }
 
+// TestRegisterUniqueViolationOnInsert closes the check-then-insert race.
+// Arrange: use a repository whose Add returns a wrapped unique-constraint error.
+// Act: call Register with an otherwise valid payload.
+// Assert: expect a ConflictError naming the violated field.
+func TestRegisterUniqueViolationOnInsert(t *testing.T) {
+	testCases := []struct {
+		name    string
+		addErr  error
+		message string
+	}{
+		{
+			name:    "duplicate username",
+			addErr:  fmt.Errorf("%w: UNIQUE constraint failed: users.username", authapp.ErrDuplicateUsername),
+			message: "This username is already taken. Please choose a different one.",
+		},
+		{
+			name:    "duplicate email",
+			addErr:  fmt.Errorf("%w: UNIQUE constraint failed: users.email", authapp.ErrDuplicateEmail),
+			message: "This email is already registered. Please use a different email address.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			repo := &racingUserRepository{memoryUserRepository: newMemoryUserRepository(), addErr: tc.addErr}
+			service := authapp.NewService(repo, authsecurity.NewHMACPasswordHasher(), stubTokenGenerator{}, nil, authapp.Options{})
+
+			// Act
+			result, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: "racer",
+				Email:    "racer@example.com",
+				Password: "Password123",
+			})
+
+			// Assert
+			if err == nil {
+				t.Fatalf("expected conflict error, got result %+v", result)
+			}
+			var conflict authapp.ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("expected ConflictError, got %v", err)
+			}
+			if conflict.Message != tc.message {
+				t.Fatalf("expected conflict message %q, got %q", tc.message, conflict.Message)
+			}
+		})
+	}
+}
+
+// TestRegisterRunsInTransaction ensures the existence checks and insert are atomic.
+// Arrange: use a repository that tracks transaction boundaries.
+// Act: register a new user.
+// Assert: expect the insert to happen inside WithinTransaction.
+func TestRegisterRunsInTransaction(t *testing.T) {
+	// Arrange
+	repo := &transactionalUserRepository{memoryUserRepository: newMemoryUserRepository()}
+	service := authapp.NewService(repo, authsecurity.NewHMACPasswordHasher(), stubTokenGenerator{}, nil, authapp.Options{})
+
+	// Act
+	_, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "atomic_user",
+		Email:    "atomic@example.com",
+		Password: "Password123",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected registration to succeed, got %v", err)
+	}
+	if !repo.addedInTx {
+		t.Fatalf("expected user to be added inside a transaction")
+	}
+}
+
+// TestRegisterLowercaseWholeEmail verifies the legacy normalisation flag.
+// Arrange: enable LowercaseWholeEmail and seed a lowercase address.
+// Act: register another user whose email differs only by local-part case.
+// Assert: expect the stored email to be lowercase and the duplicate rejected.
+func TestRegisterLowercaseWholeEmail(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service := newAuthServiceWithOptions(repo, authapp.Options{LowercaseWholeEmail: true})
+
+	_, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "first_user",
+		Email:    "Mixed.Case@Example.com",
+		Password: "Password123",
+	})
+	if err != nil {
+		t.Fatalf("expected first registration to succeed, got %v", err)
+	}
+
+	// Act
+	_, err = service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "second_user",
+		Email:    "MIXED.CASE@example.com",
+		Password: "Password123",
+	})
+
+	// Assert
+	if stored := repo.usersByUsername["first_user"]; stored == nil || stored.Email != "mixed.case@example.com" {
+		t.Fatalf("expected email to be lowercased, got %+v", stored)
+	}
+	if !authapp.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+// TestRegisterAcceptsEmailFormats covers addresses the legacy regex rejected.
+// Arrange: table-drive RFC-valid email addresses.
+// Act: register a user with each address.
+// Assert: expect success and the local part to keep its casing.
+func TestRegisterAcceptsEmailFormats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{name: "plus addressing", email: "Jane+news@example.com", expected: "Jane+news@example.com"},
+		{name: "subdomains", email: "user@mail.eu.Example.co.uk", expected: "user@mail.eu.example.co.uk"},
+		{name: "internationalized domain", email: "user@Bücher.de", expected: "user@bücher.de"},
+		{name: "quoted local part", email: `"john doe"@example.com`, expected: `"john doe"@example.com`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			repo := newMemoryUserRepository()
+			service := newAuthService(repo)
+
+			// Act
+			_, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: "valid_user",
+				Email:    tc.email,
+				Password: "Password123",
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected registration to succeed, got %v", err)
+			}
+			if stored := repo.usersByUsername["valid_user"]; stored.Email != tc.expected {
+				t.Fatalf("expected stored email %q, got %q", tc.expected, stored.Email)
+			}
+		})
+	}
+}
+
+// TestRegisterAllowedEmailDomains restricts registration to configured domains.
+// Arrange: table-drive addresses against an allow-list of "Corp.example".
+// Act: register a user with each address.
+// Assert: expect the domain and its subdomains to pass and others to fail validation.
+func TestRegisterAllowedEmailDomains(t *testing.T) {
+	testCases := []struct {
+		name    string
+		email   string
+		allowed bool
+	}{
+		{name: "exact domain", email: "jane@corp.example", allowed: true},
+		{name: "mixed case domain", email: "jane@CORP.Example", allowed: true},
+		{name: "subdomain", email: "jane@eu.corp.example", allowed: true},
+		{name: "other domain", email: "jane@gmail.com", allowed: false},
+		{name: "suffix without dot", email: "jane@evilcorp.example", allowed: false},
+		{name: "domain as subdomain of another", email: "jane@corp.example.net", allowed: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			service := newAuthServiceWithOptions(newMemoryUserRepository(), authapp.Options{
+				AllowedEmailDomains: []string{"Corp.example"},
+			})
+
+			// Act
+			_, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: "domain_user",
+				Email:    tc.email,
+				Password: "Password123",
+			})
+
+			// Assert
+			if tc.allowed {
+				if err != nil {
+					t.Fatalf("expected registration to succeed, got %v", err)
+				}
+				return
+			}
+			var validationErr authapp.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Code != authapp.CodeDomainNotAllowed || validationErr.Field != authapp.FieldEmail {
+				t.Fatalf("expected a %s validation error on email, got %v", authapp.CodeDomainNotAllowed, err)
+			}
+		})
+	}
+}
+
+// TestRegisterReservedUsernames keeps system names out of user hands.
+// Arrange: table-drive usernames against a reserved list of "admin" and "support".
+// Act: register a user with each username.
+// Assert: expect reserved names in any casing to fail validation and others to pass.
+func TestRegisterReservedUsernames(t *testing.T) {
+	testCases := []struct {
+		username string
+		allowed  bool
+	}{
+		{username: "admin", allowed: false},
+		{username: "ADMIN", allowed: false},
+		{username: "SupPort", allowed: false},
+		{username: "admin_fan", allowed: true},
+		{username: "brock", allowed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.username, func(t *testing.T) {
+			// Arrange
+			service := newAuthServiceWithOptions(newMemoryUserRepository(), authapp.Options{
+				ReservedUsernames: []string{"admin", "support"},
+			})
+
+			// Act
+			_, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: tc.username,
+				Email:    "reserved@example.com",
+				Password: "Password123",
+			})
+
+			// Assert
+			if tc.allowed {
+				if err != nil {
+					t.Fatalf("expected registration to succeed, got %v", err)
+				}
+				return
+			}
+			var validationErr authapp.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Code != authapp.CodeReserved || validationErr.Field != authapp.FieldUsername {
+				t.Fatalf("expected a %s validation error on username, got %v", authapp.CodeReserved, err)
+			}
+		})
+	}
+}
+
 // TestRegisterValidationErrors covers validation failures for the register command.
 // Arrange: table-drive invalid payloads.
 // Act: invoke Register for each case.
@@ -209,6 +590,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 	testCases := []struct {
 		name    string
 		payload authapp.RegisterRequest
+		field   string
 		message string
 	}{
 		{
@@ -218,6 +600,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "Password123",
 			},
+			field:   authapp.FieldUsername,
 			message: "Username is required.",
 		},
 		{
@@ -227,6 +610,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "Password123",
 			},
+			field:   authapp.FieldUsername,
 			message: "Username must be at least 3 characters long.",
 		},
 		{
@@ -236,6 +620,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "Password123",
 			},
+			field:   authapp.FieldUsername,
 			message: "Username must not exceed 64 characters.",
 		},
 		{
@@ -245,6 +630,47 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "Password123",
 			},
+			field:   authapp.FieldUsername,
+			message: "Username can only contain letters, numbers, and underscores.",
+		},
+		{
+			name: "short multi-byte username",
+			payload: authapp.RegisterRequest{
+				Username: "日本",
+				Email:    "user@example.com",
+				Password: "Password123",
+			},
+			field:   authapp.FieldUsername,
+			message: "Username must be at least 3 characters long.",
+		},
+		{
+			name: "long multi-byte username",
+			payload: authapp.RegisterRequest{
+				Username: strings.Repeat("é", authdomain.MaxUsernameLength+1),
+				Email:    "user@example.com",
+				Password: "Password123",
+			},
+			field:   authapp.FieldUsername,
+			message: "Username must not exceed 64 characters.",
+		},
+		{
+			name: "emoji in username",
+			payload: authapp.RegisterRequest{
+				Username: "ash🔥",
+				Email:    "user@example.com",
+				Password: "Password123",
+			},
+			field:   authapp.FieldUsername,
+			message: "Username can only contain letters, numbers, and underscores.",
+		},
+		{
+			name: "combining mark after a digit",
+			payload: authapp.RegisterRequest{
+				Username: "user_1\u093e",
+				Email:    "user@example.com",
+				Password: "Password123",
+			},
+			field:   authapp.FieldUsername,
 			message: "Username can only contain letters, numbers, and underscores.",
 		},
 		{
@@ -254,6 +680,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "   ",
 				Password: "Password123",
 			},
+			field:   authapp.FieldEmail,
 			message: "Email is required.",
 		},
 		{
@@ -263,6 +690,17 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@@example.com",
 				Password: "Password123",
 			},
+			field:   authapp.FieldEmail,
+			message: "Please enter a valid email address.",
+		},
+		{
+			name: "email with display name",
+			payload: authapp.RegisterRequest{
+				Username: "valid_user",
+				Email:    "Bob <bob@example.com>",
+				Password: "Password123",
+			},
+			field:   authapp.FieldEmail,
 			message: "Please enter a valid email address.",
 		},
 		{
@@ -272,6 +710,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "   ",
 			},
+			field:   authapp.FieldPassword,
 			message: "Password is required.",
 		},
 		{
@@ -281,6 +720,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "Abc123",
 			},
+			field:   authapp.FieldPassword,
 			message: "Password must be at least 8 characters long.",
 		},
 		{
@@ -290,6 +730,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: "alllowercase",
 			},
+			field:   authapp.FieldPassword,
 			message: "Password must contain at least one uppercase letter, one lowercase letter, and one number.",
 		},
 		{
@@ -299,6 +740,7 @@ func TestRegisterValidationErrors(t *testing.T) {
 				Email:    "user@example.com",
 				Password: strings.Repeat("A", 513),
 			},
+			field:   authapp.FieldPassword,
 			message: "Password must not exceed 512 characters.",
 		},
 	}
@@ -326,6 +768,9 @@ func TestRegisterValidationErrors(t *testing.T) {
 			if validation.Message != tc.message {
 				t.Fatalf("expected message %q, got %q", tc.message, validation.Message)
 			}
+			if validation.Field != tc.field {
+				t.Fatalf("expected field %q, got %q", tc.field, validation.Field)
+			}
 		})
 	}
 }
@@ -367,6 +812,85 @@ func TestLoginSuccess(t *testing.T) {
 	}
 }
 
+// TestLoginUsesRoleTokenLifetime issues longer tokens to service accounts.
+// Arrange: configure a 72 hour lifetime for the service role and register two users.
+// Act: log in as the regular user and as the service account.
+// Assert: expect the default token for the user and the override for the service account.
+func TestLoginUsesRoleTokenLifetime(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service := newAuthServiceWithOptions(repo, authapp.Options{
+		TokenLifetimes: map[string]time.Duration{authdomain.RoleService: 72 * time.Hour},
+	})
+	for _, username := range []string{"regular_user", "service_user"} {
+		if _, err := service.Register(context.Background(), authapp.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "Password123",
+		}); err != nil {
+			t.Fatalf("registration failed: %v", err)
+		}
+	}
+	repo.usersByUsername["service_user"].Role = authdomain.RoleService
+
+	// Act
+	regular, regularErr := service.Login(context.Background(), authapp.LoginRequest{Username: "regular_user", Password: "Password123"})
+	account, accountErr := service.Login(context.Background(), authapp.LoginRequest{Username: "service_user", Password: "Password123"})
+
+	// Assert
+	if regularErr != nil || accountErr != nil {
+		t.Fatalf("expected logins to succeed, got %v and %v", regularErr, accountErr)
+	}
+	if regular.Token != "token-123" {
+		t.Fatalf("expected the default token, got %q", regular.Token)
+	}
+	if account.Token != "token-72h0m0s" {
+		t.Fatalf("expected a 72h token, got %q", account.Token)
+	}
+}
+
+// TestLoginRememberMeLifetime grants the remember-me lifetime only on request.
+// Arrange: configure a 96 hour remember-me lifetime and a 120 hour service lifetime.
+// Act: log in as a regular user with and without rememberMe, and as the service account with it.
+// Assert: expect the default, then 96h, and the service account to keep its longer lifetime.
+func TestLoginRememberMeLifetime(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service := newAuthServiceWithOptions(repo, authapp.Options{
+		TokenLifetimes:     map[string]time.Duration{authdomain.RoleService: 120 * time.Hour},
+		RememberMeLifetime: 96 * time.Hour,
+	})
+	for _, username := range []string{"regular_user", "service_user"} {
+		if _, err := service.Register(context.Background(), authapp.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "Password123",
+		}); err != nil {
+			t.Fatalf("registration failed: %v", err)
+		}
+	}
+	repo.usersByUsername["service_user"].Role = authdomain.RoleService
+
+	// Act
+	standard, standardErr := service.Login(context.Background(), authapp.LoginRequest{Username: "regular_user", Password: "Password123"})
+	remembered, rememberedErr := service.Login(context.Background(), authapp.LoginRequest{Username: "regular_user", Password: "Password123", RememberMe: true})
+	account, accountErr := service.Login(context.Background(), authapp.LoginRequest{Username: "service_user", Password: "Password123", RememberMe: true})
+
+	// Assert
+	if standardErr != nil || rememberedErr != nil || accountErr != nil {
+		t.Fatalf("expected logins to succeed, got %v, %v and %v", standardErr, rememberedErr, accountErr)
+	}
+	if standard.Token != "token-123" || standard.Lifetime != 0 {
+		t.Fatalf("expected the default token, got %q (%s)", standard.Token, standard.Lifetime)
+	}
+	if remembered.Token != "token-96h0m0s" || remembered.Lifetime != 96*time.Hour {
+		t.Fatalf("expected a 96h token, got %q (%s)", remembered.Token, remembered.Lifetime)
+	}
+	if account.Token != "token-120h0m0s" {
+		t.Fatalf("expected the service account to keep its 120h token, got %q", account.Token)
+	}
+}
+
 // TestLoginInvalidPassword ensures incorrect passwords fail authentication.
 // Arrange: register a known user.
 // Act: Login with the wrong password.
@@ -434,6 +958,133 @@ func TestLoginUnknownUser(t *testing.T) {
 	}
 }
 
+type recordingEventBus struct {
+	events []authapp.DomainEvent
+}
+
+func (b *recordingEventBus) Publish(_ context.Context, event authapp.DomainEvent) {
+	b.events = append(b.events, event)
+}
+
+// TestServicePublishesDomainEvents reports each use-case outcome as a typed event.
+// Arrange: configure a recording bus and a fixed clock.
+// Act: register, log in with a wrong password, an unknown username and the right password, then log out everywhere.
+// Assert: expect one event per step, in order, with the matching payload and timestamp.
+func TestServicePublishesDomainEvents(t *testing.T) {
+	// Arrange
+	bus := &recordingEventBus{}
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	service := newAuthServiceWithOptions(newMemoryUserRepository(), authapp.Options{
+		Events: bus,
+		Now:    func() time.Time { return now },
+	})
+	ctx := context.Background()
+
+	// Act
+	registered, err := service.Register(ctx, authapp.RegisterRequest{
+		Username: "event_user",
+		Email:    "event@example.com",
+		Password: "Password123",
+	})
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	_, _ = service.Login(ctx, authapp.LoginRequest{Username: "event_user", Password: "WrongPass123"})
+	_, _ = service.Login(ctx, authapp.LoginRequest{Username: "nobody", Password: "Password123"})
+	_, _ = service.Login(ctx, authapp.LoginRequest{Username: "event_user", Password: "Password123"})
+	if err := service.LogoutAll(ctx, registered.UserID); err != nil {
+		t.Fatalf("logout failed: %v", err)
+	}
+
+	// Assert
+	id := registered.UserID
+	expected := []authapp.DomainEvent{
+		authapp.UserRegistered{UserID: id, Username: "event_user", Email: "event@example.com", OccurredAt: now},
+		authapp.LoginFailed{UserID: id, Username: "event_user", Reason: authapp.LoginFailureWrongPassword, OccurredAt: now},
+		authapp.LoginFailed{Username: "nobody", Reason: authapp.LoginFailureUnknownUser, OccurredAt: now},
+		authapp.UserLoggedIn{UserID: id, Username: "event_user", OccurredAt: now},
+		authapp.UserLoggedOutEverywhere{UserID: id, OccurredAt: now},
+	}
+	if len(bus.events) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), bus.events)
+	}
+	for i, want := range expected {
+		if got := bus.events[i]; got != want {
+			t.Fatalf("expected event %d to be %+v, got %+v", i+1, want, got)
+		}
+	}
+}
+
+// TestRegisterPublishesNothingOnFailure keeps rejected registrations off the bus.
+// Arrange: register a user, then configure a recording bus.
+// Act: register the same username again.
+// Assert: expect a conflict and no events.
+func TestRegisterPublishesNothingOnFailure(t *testing.T) {
+	// Arrange
+	bus := &recordingEventBus{}
+	service := newAuthServiceWithOptions(newMemoryUserRepository(), authapp.Options{Events: bus})
+	request := authapp.RegisterRequest{Username: "taken_user", Email: "taken@example.com", Password: "Password123"}
+	if _, err := service.Register(context.Background(), request); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	bus.events = nil
+
+	// Act
+	_, err := service.Register(context.Background(), request)
+
+	// Assert
+	if !authapp.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if len(bus.events) != 0 {
+		t.Fatalf("expected no events, got %+v", bus.events)
+	}
+}
+
+type recordingMetrics struct {
+	logins        map[string]int
+	registrations map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{logins: map[string]int{}, registrations: map[string]int{}}
+}
+
+func (m *recordingMetrics) RecordLogin(_ context.Context, outcome string) {
+	m.logins[outcome]++
+}
+
+func (m *recordingMetrics) RecordRegistration(_ context.Context, outcome string) {
+	m.registrations[outcome]++
+}
+
+// TestServiceRecordsAuthOutcomeMetrics counts logins and registrations by outcome.
+// Arrange: configure a recording metrics recorder and register a user.
+// Act: register the same user again, fail a login, then log in correctly.
+// Assert: expect success and conflict registrations, and one failed and one successful login.
+func TestServiceRecordsAuthOutcomeMetrics(t *testing.T) {
+	// Arrange
+	metrics := newRecordingMetrics()
+	service := newAuthServiceWithOptions(newMemoryUserRepository(), authapp.Options{Metrics: metrics})
+	registration := authapp.RegisterRequest{Username: "metric_user", Email: "metric@example.com", Password: "Password123"}
+	if _, err := service.Register(context.Background(), registration); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+
+	// Act
+	_, _ = service.Register(context.Background(), registration)
+	_, _ = service.Login(context.Background(), authapp.LoginRequest{Username: "metric_user", Password: "WrongPass123"})
+	_, _ = service.Login(context.Background(), authapp.LoginRequest{Username: "metric_user", Password: "Password123"})
+
+	// Assert
+	if metrics.registrations[authapp.OutcomeSuccess] != 1 || metrics.registrations[authapp.OutcomeConflict] != 1 {
+		t.Fatalf("expected one successful and one conflicting registration, got %v", metrics.registrations)
+	}
+	if metrics.logins[authapp.OutcomeFailure] != 1 || metrics.logins[authapp.OutcomeSuccess] != 1 {
+		t.Fatalf("expected one failed and one successful login, got %v", metrics.logins)
+	}
+}
+
 // TestLoginValidationErrors ensures login input validation mirrors production rules.
 // Arrange: define invalid login payloads.
 // Act: call Login for each case.
@@ -446,6 +1097,7 @@ func TestLoginValidationErrors(t *testing.T) {
 	testCases := []struct {
 		name    string
 		payload authapp.LoginRequest
+		field   string
 		message string
 	}{
 		{
@@ -454,6 +1106,7 @@ func TestLoginValidationErrors(t *testing.T) {
 				Username: "   ",
 				Password: "Password123",
 			},
+			field:   authapp.FieldUsername,
 			message: "Username is required.",
 		},
 		{
@@ -462,6 +1115,7 @@ func TestLoginValidationErrors(t *testing.T) {
 				Username: "valid",
 				Password: "   ",
 			},
+			field:   authapp.FieldPassword,
 			message: "Password is required.",
 		},
 	}
@@ -489,6 +1143,140 @@ func TestLoginValidationErrors(t *testing.T) {
 			if validation.Message != tc.message {
 				t.Fatalf("expected message %q, got %q", tc.message, validation.Message)
 			}
+			if validation.Field != tc.field {
+				t.Fatalf("expected field %q, got %q", tc.field, validation.Field)
+			}
+		})
+	}
+}
+
+// countingPasswordHasher records how often VerifyPassword runs.
+type countingPasswordHasher struct {
+	authapp.PasswordHasher
+	verifications int
+}
+
+func (h *countingPasswordHasher) VerifyPassword(password, hash, salt string) (bool, error) {
+	h.verifications++
+	return h.PasswordHasher.VerifyPassword(password, hash, salt)
+}
+
+// TestLoginUnknownUserStillVerifiesPassword equalises timing for unknown usernames.
+// Arrange: wire the service with a counting hasher and no users.
+// Act: log in as a username that does not exist.
+// Assert: expect an unauthorized error after one password verification.
+func TestLoginUnknownUserStillVerifiesPassword(t *testing.T) {
+	// Arrange
+	hasher := &countingPasswordHasher{PasswordHasher: authsecurity.NewHMACPasswordHasher()}
+	service := authapp.NewService(newMemoryUserRepository(), hasher, stubTokenGenerator{}, nil, authapp.Options{})
+
+	// Act
+	_, err := service.Login(context.Background(), authapp.LoginRequest{Username: "ghost", Password: "Password123"})
+
+	// Assert
+	if !authapp.IsUnauthorizedError(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if hasher.verifications != 1 {
+		t.Fatalf("expected 1 password verification, got %d", hasher.verifications)
+	}
+}
+
+func benchmarkLogin(b *testing.B, username string) {
+	repo := newMemoryUserRepository()
+	service := newAuthService(repo)
+	if _, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "known_user",
+		Email:    "known@example.com",
+		Password: "Password123",
+	}); err != nil {
+		b.Fatalf("expected registration to succeed, got %v", err)
+	}
+	cmd := authapp.LoginRequest{Username: username, Password: "WrongPassword1"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.Login(context.Background(), cmd); !authapp.IsUnauthorizedError(err) {
+			b.Fatalf("expected unauthorized error, got %v", err)
+		}
+	}
+}
+
+// BenchmarkLoginWrongPassword measures a failed login for an existing user.
+// Compare with BenchmarkLoginUnknownUser: the two should be close.
+func BenchmarkLoginWrongPassword(b *testing.B) {
+	benchmarkLogin(b, "known_user")
+}
+
+// BenchmarkLoginUnknownUser measures a failed login for a missing user.
+func BenchmarkLoginUnknownUser(b *testing.B) {
+	benchmarkLogin(b, "unknown_user")
+}
+
+// TestRegisterAcceptsUnicodeUsernamesAtLengthBoundaries counts characters rather than bytes.
+// Arrange: table-drive usernames of multi-byte letters at the minimum and maximum lengths,
+// including Devanagari names whose vowel signs are combining marks.
+// Act: register each one.
+// Assert: expect every registration to succeed and keep the username as typed.
+func TestRegisterAcceptsUnicodeUsernamesAtLengthBoundaries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		username string
+	}{
+		{name: "three cjk characters", username: "日本語"},
+		{name: "sixty-four accented letters", username: strings.Repeat("é", authdomain.MaxUsernameLength)},
+		{name: "mixed scripts with digits", username: "Zoë_Ωμέγα_42"},
+		{name: "four-byte letters", username: "𐐷𐐷𐐷"},
+		{name: "devanagari vowel signs", username: "राहुल"},
+		{name: "devanagari virama", username: "नमस्ते"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			service := newAuthService(newMemoryUserRepository())
+
+			// Act
+			result, err := service.Register(context.Background(), authapp.RegisterRequest{
+				Username: tc.username,
+				Email:    fmt.Sprintf("unicode%d@example.com", i),
+				Password: "Password123",
+			})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected %q to register, got %v", tc.username, err)
+			}
+			if result.Username != tc.username {
+				t.Fatalf("expected username %q, got %q", tc.username, result.Username)
+			}
 		})
 	}
 }
+
+// TestLoginMatchesUsernameWhateverAccentComposition signs in however the client composed accents.
+// Arrange: register José with a combining accent.
+// Act: log in with the precomposed spelling.
+// Assert: expect success and the stored, precomposed username.
+func TestLoginMatchesUsernameWhateverAccentComposition(t *testing.T) {
+	// Arrange
+	service := newAuthService(newMemoryUserRepository())
+	if _, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "Jose\u0301",
+		Email:    "jose@example.com",
+		Password: "Password123",
+	}); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+
+	// Act
+	result, err := service.Login(context.Background(), authapp.LoginRequest{Username: "Jos\u00e9", Password: "Password123"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+	if result.Username != "Jos\u00e9" {
+		t.Fatalf("expected precomposed username %q, got %q", "Jos\u00e9", result.Username)
+	}
+}
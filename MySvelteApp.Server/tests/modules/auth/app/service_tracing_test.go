@@ -0,0 +1,126 @@
+package app_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
+)
+
+func newTracedAuthService(repo *memoryUserRepository) (*authapp.Service, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	hasher := authsecurity.NewHMACPasswordHasher()
+	service := authapp.NewService(repo, hasher, stubTokenGenerator{}, provider.Tracer("auth-test"), authapp.Options{})
+	return service, exporter
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+// TestRegisterRecordsSpans verifies the registration flow emits child spans.
+// Arrange: wire the service to an in-memory span exporter.
+// Act: register a new user.
+// Assert: expect spans for each dependency call and existence attributes on the root span.
+func TestRegisterRecordsSpans(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service, exporter := newTracedAuthService(repo)
+
+	// Act
+	_, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "traced_user",
+		Email:    "traced@example.com",
+		Password: "Password123",
+	})
+	if err != nil {
+		t.Fatalf("expected registration to succeed, got %v", err)
+	}
+
+	// Assert
+	spans := exporter.GetSpans()
+	root := findSpan(spans, "auth.Register")
+	if root == nil {
+		t.Fatalf("expected auth.Register span to be recorded")
+	}
+	for _, name := range []string{
+		"auth.UserRepository.UsernameExists",
+		"auth.UserRepository.EmailExists",
+		"auth.PasswordHasher.HashPassword",
+		"auth.UserRepository.Add",
+		"auth.TokenGenerator.GenerateToken",
+	} {
+		child := findSpan(spans, name)
+		if child == nil {
+			t.Fatalf("expected span %q to be recorded", name)
+		}
+		if child.Parent.SpanID() != root.SpanContext.SpanID() {
+			t.Fatalf("expected span %q to be a child of auth.Register", name)
+		}
+	}
+
+	attrs := attribute.NewSet(root.Attributes...)
+	if value, ok := attrs.Value("auth.username_exists"); !ok || value.AsBool() {
+		t.Fatalf("expected auth.username_exists=false, got %v", value)
+	}
+	if value, ok := attrs.Value("auth.email_exists"); !ok || value.AsBool() {
+		t.Fatalf("expected auth.email_exists=false, got %v", value)
+	}
+}
+
+// TestLoginFailureMarksSpanError ensures failures surface as span errors.
+// Arrange: register a user and reset the exporter.
+// Act: log in with the wrong password.
+// Assert: expect an error status on auth.Login and no password in recorded events.
+func TestLoginFailureMarksSpanError(t *testing.T) {
+	// Arrange
+	repo := newMemoryUserRepository()
+	service, exporter := newTracedAuthService(repo)
+	_, err := service.Register(context.Background(), authapp.RegisterRequest{
+		Username: "traced_user",
+		Email:    "traced@example.com",
+		Password: "Password123",
+	})
+	if err != nil {
+		t.Fatalf("expected registration to succeed, got %v", err)
+	}
+	exporter.Reset()
+
+	// Act
+	_, err = service.Login(context.Background(), authapp.LoginRequest{
+		Username: "traced_user",
+		Password: "SecretGuess1",
+	})
+	if !authapp.IsUnauthorizedError(err) {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+
+	// Assert
+	root := findSpan(exporter.GetSpans(), "auth.Login")
+	if root == nil {
+		t.Fatalf("expected auth.Login span to be recorded")
+	}
+	if root.Status.Code != codes.Error {
+		t.Fatalf("expected error status, got %v", root.Status.Code)
+	}
+	for _, event := range root.Events {
+		for _, attr := range event.Attributes {
+			if strings.Contains(attr.Value.Emit(), "SecretGuess1") {
+				t.Fatalf("expected password to be absent from span events")
+			}
+		}
+	}
+}
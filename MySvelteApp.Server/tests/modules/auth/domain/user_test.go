@@ -31,10 +31,10 @@ func TestNewUserValid(t *testing.T) {
 	}
 }
 
-// TestNewUserTrimsAndNormalizesFields checks trimming and lowercasing logic.
+// TestNewUserTrimsAndNormalizesFields checks trimming and domain lowercasing logic.
 // Arrange: provide padded username and mixed-case email.
 // Act: call NewUser with the noisy inputs.
-// Assert: expect trimmed username and lowercase email in the result.
+// Assert: expect trimmed username and a lowercase email domain in the result.
 func TestNewUserTrimsAndNormalizesFields(t *testing.T) {
 	// Arrange
 	username := "  spaced  "
@@ -50,8 +50,11 @@ func TestNewUserTrimsAndNormalizesFields(t *testing.T) {
 	if user.Username != "spaced" {
 		t.Fatalf("expected username to be trimmed, got %q", user.Username)
 	}
-	if user.Email != "mixedcase@example.com" {
-		t.Fatalf("expected email to be lowercased, got %q", user.Email)
+	if user.NormalizedUsername != "spaced" {
+		t.Fatalf("expected normalized username to be populated, got %q", user.NormalizedUsername)
+	}
+	if user.Email != "MixedCase@example.com" {
+		t.Fatalf("expected email domain to be lowercased, got %q", user.Email)
 	}
 }
 
@@ -145,6 +148,47 @@ func TestNewUserUsernameTooLong(t *testing.T) {
 	}
 }
 
+// TestNewUserCountsUsernameLengthInCharacters lets multi-byte names reach the limit.
+// Arrange: build names of MaxUsernameLength and one more two-byte characters.
+// Act: call NewUser with each.
+// Assert: expect the limit name accepted and the longer one rejected.
+func TestNewUserCountsUsernameLengthInCharacters(t *testing.T) {
+	// Arrange
+	atLimit := strings.Repeat("é", authdomain.MaxUsernameLength)
+	overLimit := atLimit + "é"
+
+	// Act
+	user, atLimitErr := authdomain.NewUser(atLimit, "user@example.com", "hash", "salt")
+	_, overLimitErr := authdomain.NewUser(overLimit, "user@example.com", "hash", "salt")
+
+	// Assert
+	if atLimitErr != nil || user.Username != atLimit {
+		t.Fatalf("expected a %d-character username to be accepted, got %v", authdomain.MaxUsernameLength, atLimitErr)
+	}
+	if overLimitErr == nil {
+		t.Fatalf("expected error for a %d-character username", authdomain.MaxUsernameLength+1)
+	}
+}
+
+// TestNormalizeUsernameComposesAccents matches names however their accents were typed.
+// Arrange: spell José with a precomposed é and with e plus a combining acute accent.
+// Act: normalize both.
+// Assert: expect the same lowercase, precomposed form.
+func TestNormalizeUsernameComposesAccents(t *testing.T) {
+	// Arrange
+	precomposed := "Jos\u00e9"
+	decomposed := "Jose\u0301"
+
+	// Act
+	first := authdomain.NormalizeUsername(precomposed)
+	second := authdomain.NormalizeUsername(decomposed)
+
+	// Assert
+	if first != "jos\u00e9" || second != first {
+		t.Fatalf("expected both spellings to normalize to %q, got %q and %q", "jos\u00e9", first, second)
+	}
+}
+
 // TestNewUserEmailTooLong guards the maximum email length.
 // Arrange: compose an email longer than MaxEmailLength.
 // Act: attempt to create the user.
@@ -0,0 +1,111 @@
+package email_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	authemail "mysvelteapp/server_new/internal/modules/auth/infra/email"
+	platformemail "mysvelteapp/server_new/internal/platform/email"
+)
+
+// capturingSender records every message instead of delivering it.
+type capturingSender struct {
+	messages []platformemail.Message
+	err      error
+}
+
+func (s *capturingSender) Send(_ context.Context, msg platformemail.Message) error {
+	s.messages = append(s.messages, msg)
+	return s.err
+}
+
+func newMailer(t *testing.T, sender platformemail.Sender) *authemail.Mailer {
+	t.Helper()
+	templates, err := authemail.NewTemplates("")
+	if err != nil {
+		t.Fatalf("expected default templates to parse, got %v", err)
+	}
+	baseURL, _ := url.Parse("https://app.example.com/base")
+	return authemail.NewMailer(sender, templates, baseURL)
+}
+
+// TestMailerRendersAccountEmails links each email to the frontend with the issued token.
+// Arrange: table-drive the verification and password reset emails.
+// Act: send each through a capturing sender with a token valid for a day.
+// Assert: expect one HTML message addressed to the user carrying the link under the base URL and the expiry.
+func TestMailerRendersAccountEmails(t *testing.T) {
+	expiresAt := time.Now().Add(24 * time.Hour)
+	testCases := []struct {
+		name    string
+		send    func(*authemail.Mailer) error
+		subject string
+		link    string
+	}{
+		{
+			name: "verification",
+			send: func(m *authemail.Mailer) error {
+				return m.SendVerification(context.Background(), "ash@example.com", "ash", "abc123", expiresAt)
+			},
+			subject: "Confirm your email address",
+			link:    "https://app.example.com/base/verify-email?token=abc123",
+		},
+		{
+			name: "password reset",
+			send: func(m *authemail.Mailer) error {
+				return m.SendPasswordReset(context.Background(), "ash@example.com", "ash", "abc123", expiresAt)
+			},
+			subject: "Reset your password",
+			link:    "https://app.example.com/base/reset-password?token=abc123",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			sender := &capturingSender{}
+			mailer := newMailer(t, sender)
+
+			// Act
+			err := tc.send(mailer)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected email to be sent, got %v", err)
+			}
+			if len(sender.messages) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(sender.messages))
+			}
+			msg := sender.messages[0]
+			if msg.To != "ash@example.com" || msg.Subject != tc.subject || !msg.HTML {
+				t.Fatalf("expected HTML %q to ash@example.com, got %+v", tc.subject, msg)
+			}
+			for _, want := range []string{"Hi ash,", tc.link, "expires in 24 hours"} {
+				if !strings.Contains(msg.Body, want) {
+					t.Fatalf("expected %q in body, got %q", want, msg.Body)
+				}
+			}
+		})
+	}
+}
+
+// TestMailerReturnsSenderFailures lets callers decide whether a failed send matters.
+// Arrange: use a sender that fails.
+// Act: send a verification email.
+// Assert: expect the sender's error.
+func TestMailerReturnsSenderFailures(t *testing.T) {
+	// Arrange
+	errRelayDown := errors.New("relay down")
+	mailer := newMailer(t, &capturingSender{err: errRelayDown})
+
+	// Act
+	err := mailer.SendVerification(context.Background(), "ash@example.com", "ash", "abc123", time.Now().Add(time.Hour))
+
+	// Assert
+	if !errors.Is(err, errRelayDown) {
+		t.Fatalf("expected sender error, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package email_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	authemail "mysvelteapp/server_new/internal/modules/auth/infra/email"
+)
+
+// TestTemplatesFillEveryPlaceholder renders the built-in templates.
+// Arrange: load the defaults and build data with a username, link and expiry.
+// Act: render each template.
+// Assert: expect a subject and a body containing every value and no unfilled actions.
+func TestTemplatesFillEveryPlaceholder(t *testing.T) {
+	templates, err := authemail.NewTemplates("")
+	if err != nil {
+		t.Fatalf("expected default templates to parse, got %v", err)
+	}
+	data := authemail.TemplateData{
+		Username:  "misty",
+		Link:      "https://app.example.com/flow?token=t0k3n",
+		ExpiresAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		ExpiresIn: "2 hours",
+	}
+
+	for _, name := range []string{authemail.TemplateVerification, authemail.TemplatePasswordReset} {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			rendered, err := templates.Render(name, data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected template to render, got %v", err)
+			}
+			if rendered.Subject == "" {
+				t.Fatalf("expected a subject, got none")
+			}
+			for _, want := range []string{data.Username, data.Link, data.ExpiresIn} {
+				if !strings.Contains(rendered.HTML, want) {
+					t.Fatalf("expected %q in body, got %q", want, rendered.HTML)
+				}
+			}
+			if strings.Contains(rendered.HTML, "{{") {
+				t.Fatalf("expected no template actions left, got %q", rendered.HTML)
+			}
+		})
+	}
+}
+
+// TestTemplatesEscapeUserInput keeps usernames from injecting markup.
+// Arrange: load the defaults and use a username containing HTML.
+// Act: render the verification email.
+// Assert: expect the username escaped in the body.
+func TestTemplatesEscapeUserInput(t *testing.T) {
+	// Arrange
+	templates, err := authemail.NewTemplates("")
+	if err != nil {
+		t.Fatalf("expected default templates to parse, got %v", err)
+	}
+
+	// Act
+	rendered, err := templates.Render(authemail.TemplateVerification, authemail.TemplateData{
+		Username: "<b>ash</b>",
+		Link:     "https://app.example.com/verify-email?token=abc",
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected template to render, got %v", err)
+	}
+	if strings.Contains(rendered.HTML, "<b>ash</b>") || !strings.Contains(rendered.HTML, "&lt;b&gt;ash&lt;/b&gt;") {
+		t.Fatalf("expected escaped username, got %q", rendered.HTML)
+	}
+}
+
+// TestTemplatesPreferOverrideDirectory lets deployments replace individual templates.
+// Arrange: write a verification override and leave password reset to the default.
+// Act: render both templates.
+// Assert: expect the override for verification and the built-in reset email.
+func TestTemplatesPreferOverrideDirectory(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	override := `{{define "subject"}}Welcome & verify{{end}}{{define "body"}}<p>Bienvenue {{.Username}}: {{.Link}}</p>{{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "verification.html"), []byte(override), 0o600); err != nil {
+		t.Fatalf("expected override to be written, got %v", err)
+	}
+	templates, err := authemail.NewTemplates(dir)
+	if err != nil {
+		t.Fatalf("expected templates to parse, got %v", err)
+	}
+	data := authemail.TemplateData{Username: "brock", Link: "https://app.example.com/x"}
+
+	// Act
+	verification, verifyErr := templates.Render(authemail.TemplateVerification, data)
+	reset, resetErr := templates.Render(authemail.TemplatePasswordReset, data)
+
+	// Assert
+	if verifyErr != nil || resetErr != nil {
+		t.Fatalf("expected both templates to render, got %v and %v", verifyErr, resetErr)
+	}
+	if verification.Subject != "Welcome & verify" || !strings.Contains(verification.HTML, "Bienvenue brock") {
+		t.Fatalf("expected override verification email, got %+v", verification)
+	}
+	if reset.Subject != "Reset your password" {
+		t.Fatalf("expected default reset subject, got %q", reset.Subject)
+	}
+}
+
+// TestTemplatesRejectIncompleteOverride fails at startup rather than on first send.
+// Arrange: write a password reset override without a subject.
+// Act: load the templates.
+// Assert: expect an error naming the missing definition.
+func TestTemplatesRejectIncompleteOverride(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "password_reset.html"), []byte(`{{define "body"}}reset{{end}}`), 0o600); err != nil {
+		t.Fatalf("expected override to be written, got %v", err)
+	}
+
+	// Act
+	_, err := authemail.NewTemplates(dir)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), `"subject"`) {
+		t.Fatalf("expected missing subject error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+)
+
+// TestBusDeliversToSubscribedHandlersInOrder routes events by name.
+// Arrange: subscribe two handlers to logins and one to registrations.
+// Act: publish a login.
+// Assert: expect only the login handlers to run, in subscription order, with the event.
+func TestBusDeliversToSubscribedHandlersInOrder(t *testing.T) {
+	// Arrange
+	bus := authevents.NewBus(nil)
+	var calls []string
+	record := func(label string) authevents.Handler {
+		return func(_ context.Context, event authapp.DomainEvent) error {
+			login, ok := event.(authapp.UserLoggedIn)
+			if !ok || login.Username != "alice" {
+				t.Fatalf("expected alice's login for %s, got %+v", label, event)
+			}
+			calls = append(calls, label)
+			return nil
+		}
+	}
+	bus.Subscribe(record("audit"), authapp.EventLoginSucceeded)
+	bus.Subscribe(record("metrics"), authapp.EventLoginSucceeded, authapp.EventLoginFailed)
+	bus.Subscribe(record("email"), authapp.EventUserRegistered)
+
+	// Act
+	bus.Publish(context.Background(), authapp.UserLoggedIn{UserID: 1, Username: "alice"})
+
+	// Assert
+	if strings.Join(calls, ",") != "audit,metrics" {
+		t.Fatalf("expected audit then metrics, got %v", calls)
+	}
+}
+
+// TestBusReportsHandlerFailuresWithoutStopping isolates subscribers from each other.
+// Arrange: subscribe a failing handler, a panicking handler and a working one, with an error callback.
+// Act: publish a registration.
+// Assert: expect both failures reported and the last handler still called.
+func TestBusReportsHandlerFailuresWithoutStopping(t *testing.T) {
+	// Arrange
+	var reported []error
+	bus := authevents.NewBus(func(event authapp.DomainEvent, err error) {
+		if event.EventName() != authapp.EventUserRegistered {
+			t.Fatalf("expected the registration to be reported, got %+v", event)
+		}
+		reported = append(reported, err)
+	})
+	errMailDown := errors.New("mail down")
+	bus.Subscribe(func(context.Context, authapp.DomainEvent) error { return errMailDown }, authapp.EventUserRegistered)
+	bus.Subscribe(func(context.Context, authapp.DomainEvent) error { panic("boom") }, authapp.EventUserRegistered)
+	delivered := false
+	bus.Subscribe(func(context.Context, authapp.DomainEvent) error {
+		delivered = true
+		return nil
+	}, authapp.EventUserRegistered)
+
+	// Act
+	bus.Publish(context.Background(), authapp.UserRegistered{UserID: 1, Username: "alice"})
+
+	// Assert
+	if len(reported) != 2 || !errors.Is(reported[0], errMailDown) || !strings.Contains(reported[1].Error(), "boom") {
+		t.Fatalf("expected mail and panic failures, got %v", reported)
+	}
+	if !delivered {
+		t.Fatalf("expected the remaining handler to run")
+	}
+}
+
+// TestHubStreamsLoginEventsFromBus feeds the admin event stream from the bus.
+// Arrange: attach a hub to a bus and subscribe to the hub.
+// Act: publish a registration, a failed login and a logout.
+// Assert: expect only the failed login and logout to be streamed, with their payloads.
+func TestHubStreamsLoginEventsFromBus(t *testing.T) {
+	// Arrange
+	bus := authevents.NewBus(nil)
+	hub := authevents.NewHub(4)
+	hub.StreamFrom(bus)
+	events, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Act
+	bus.Publish(context.Background(), authapp.UserRegistered{UserID: 1, Username: "alice", OccurredAt: at})
+	bus.Publish(context.Background(), authapp.LoginFailed{Username: "mallory", Reason: authapp.LoginFailureUnknownUser, OccurredAt: at})
+	bus.Publish(context.Background(), authapp.UserLoggedOutEverywhere{UserID: 1, OccurredAt: at})
+
+	// Assert
+	expected := []authapp.AuthEvent{
+		{Type: authapp.EventLoginFailed, Username: "mallory", OccurredAt: at},
+		{Type: authapp.EventLogoutAll, UserID: 1, OccurredAt: at},
+	}
+	for i, want := range expected {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("expected streamed event %d to be %+v, got %+v", i+1, want, got)
+			}
+		default:
+			t.Fatalf("expected streamed event %d, got none", i+1)
+		}
+	}
+	select {
+	case extra := <-events:
+		t.Fatalf("expected no further events, got %+v", extra)
+	default:
+	}
+}
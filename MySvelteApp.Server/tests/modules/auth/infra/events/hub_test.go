@@ -0,0 +1,85 @@
+package events_test
+
+import (
+	"testing"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authevents "mysvelteapp/server_new/internal/modules/auth/infra/events"
+)
+
+// TestHubFansOutToSubscribers delivers each event to every subscriber.
+// Arrange: subscribe twice to a hub.
+// Act: publish one event.
+// Assert: expect both subscribers to receive it.
+func TestHubFansOutToSubscribers(t *testing.T) {
+	// Arrange
+	hub := authevents.NewHub(4)
+	first, unsubscribeFirst := hub.Subscribe()
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := hub.Subscribe()
+	defer unsubscribeSecond()
+
+	// Act
+	hub.Publish(authapp.AuthEvent{Type: authapp.EventLoginSucceeded, Username: "alice"})
+
+	// Assert
+	for i, ch := range []<-chan authapp.AuthEvent{first, second} {
+		select {
+		case event := <-ch:
+			if event.Username != "alice" {
+				t.Fatalf("expected alice for subscriber %d, got %+v", i+1, event)
+			}
+		default:
+			t.Fatalf("expected subscriber %d to receive the event", i+1)
+		}
+	}
+}
+
+// TestHubDropsEventsForSlowSubscribers never blocks the publisher.
+// Arrange: subscribe with a one-event buffer and never read.
+// Act: publish three events.
+// Assert: expect the first event queued and the other two counted as dropped.
+func TestHubDropsEventsForSlowSubscribers(t *testing.T) {
+	// Arrange
+	hub := authevents.NewHub(1)
+	events, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	// Act
+	for _, username := range []string{"first", "second", "third"} {
+		hub.Publish(authapp.AuthEvent{Type: authapp.EventLoginFailed, Username: username})
+	}
+
+	// Assert
+	if event := <-events; event.Username != "first" {
+		t.Fatalf("expected the first event to be queued, got %+v", event)
+	}
+	if dropped := hub.Dropped(); dropped != 2 {
+		t.Fatalf("expected 2 dropped events, got %d", dropped)
+	}
+}
+
+// TestHubUnsubscribeAndClose close subscriber channels.
+// Arrange: subscribe twice, then unsubscribe one of them.
+// Act: close the hub and subscribe again.
+// Assert: expect every channel closed and publishing after Close to be harmless.
+func TestHubUnsubscribeAndClose(t *testing.T) {
+	// Arrange
+	hub := authevents.NewHub(1)
+	left, unsubscribe := hub.Subscribe()
+	stayed, _ := hub.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	// Act
+	hub.Close()
+	late, _ := hub.Subscribe()
+	hub.Publish(authapp.AuthEvent{Type: authapp.EventLoginSucceeded})
+
+	// Assert
+	for name, ch := range map[string]<-chan authapp.AuthEvent{"unsubscribed": left, "open": stayed, "late": late} {
+		if _, ok := <-ch; ok {
+			t.Fatalf("expected the %s channel to be closed", name)
+		}
+	}
+}
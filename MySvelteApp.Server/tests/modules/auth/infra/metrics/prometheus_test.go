@@ -0,0 +1,45 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authmetrics "mysvelteapp/server_new/internal/modules/auth/infra/metrics"
+	platformmetrics "mysvelteapp/server_new/internal/platform/metrics"
+)
+
+// TestPrometheusRecorderExposesFailedLogins publishes outcome counters on /metrics.
+// Arrange: build a recorder on a fresh registry.
+// Act: record two failed logins and a conflicting registration, then scrape the registry.
+// Assert: expect both counters in the Prometheus text format with their outcome labels.
+func TestPrometheusRecorderExposesFailedLogins(t *testing.T) {
+	// Arrange
+	registry := platformmetrics.NewRegistry()
+	recorder := authmetrics.NewPrometheusRecorder(registry)
+
+	// Act
+	recorder.RecordLogin(context.Background(), authapp.OutcomeFailure)
+	recorder.RecordLogin(context.Background(), authapp.OutcomeFailure)
+	recorder.RecordRegistration(context.Background(), authapp.OutcomeConflict)
+	response := httptest.NewRecorder()
+	registry.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	body := response.Body.String()
+	for _, want := range []string{
+		"# TYPE auth_logins_total counter",
+		`auth_logins_total{outcome="failure"} 2`,
+		`auth_registrations_total{outcome="conflict"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the scrape, got %q", want, body)
+		}
+	}
+	if contentType := response.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Fatalf("expected the Prometheus text content type, got %q", contentType)
+	}
+}
@@ -0,0 +1,109 @@
+package persistence_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authpersistence "mysvelteapp/server_new/internal/modules/auth/infra/persistence"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// openSharedDB opens the database file at path the way one server instance
+// would, with its own connection pool.
+func openSharedDB(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file:"+path+"?_busy_timeout=5000"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	sqlDB, err := appDB.DB.DB()
+	if err != nil {
+		t.Fatalf("expected sql.DB, got %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return appDB.DB
+}
+
+// TestLoginAttemptStoreCountsConcurrentFailuresAcrossInstances shares lockout state between replicas.
+// Arrange: open one database file through two independent connection pools.
+// Act: record 20 failures for one account concurrently, half through each instance.
+// Assert: expect every failure counted once.
+func TestLoginAttemptStoreCountsConcurrentFailuresAcrossInstances(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "lockout.db")
+	stores := []*authpersistence.GormLoginAttemptStore{
+		authpersistence.NewGormLoginAttemptStore(openSharedDB(t, path), platformpersistence.DefaultRetryOptions()),
+		authpersistence.NewGormLoginAttemptStore(openSharedDB(t, path), platformpersistence.DefaultRetryOptions()),
+	}
+	policy := authapp.LockoutPolicy{MaxFailures: 100, Window: time.Hour, Duration: time.Hour}
+	now := time.Now()
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(store *authpersistence.GormLoginAttemptStore) {
+			defer wg.Done()
+			if _, err := store.RecordFailure(context.Background(), "ash", policy, now); err != nil {
+				errs <- err
+			}
+		}(stores[i%2])
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		t.Fatalf("expected every failure to be recorded, got %v", err)
+	}
+	attempts, err := stores[0].Get(context.Background(), "ash")
+	if err != nil {
+		t.Fatalf("expected attempts, got %v", err)
+	}
+	if attempts.Failures != 20 {
+		t.Fatalf("expected 20 failures, got %d", attempts.Failures)
+	}
+}
+
+// TestLoginAttemptStoreLocksAndExpires applies the policy and forgets stale records.
+// Arrange: allow two failures per minute with a five minute lock.
+// Act: fail twice, then delete expired records before and after the lock ends.
+// Assert: expect the second failure to lock the account and the record to survive only until the lock ends.
+func TestLoginAttemptStoreLocksAndExpires(t *testing.T) {
+	// Arrange
+	store := authpersistence.NewGormLoginAttemptStore(openSharedDB(t, filepath.Join(t.TempDir(), "lockout.db")), platformpersistence.DefaultRetryOptions())
+	policy := authapp.LockoutPolicy{MaxFailures: 2, Window: time.Minute, Duration: 5 * time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	// Act
+	first, _ := store.RecordFailure(ctx, "ash", policy, now)
+	second, err := store.RecordFailure(ctx, "ash", policy, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("expected failure to be recorded, got %v", err)
+	}
+	keptWhileLocked, _ := store.DeleteExpired(ctx, now.Add(2*time.Minute))
+	deletedAfterLock, _ := store.DeleteExpired(ctx, now.Add(6*time.Minute))
+
+	// Assert
+	if first.Failures != 1 || first.Locked(now) {
+		t.Fatalf("expected one failure and no lock, got %+v", first)
+	}
+	if !second.Locked(now.Add(4 * time.Minute)) {
+		t.Fatalf("expected the second failure to lock the account, got %+v", second)
+	}
+	if keptWhileLocked != 0 || deletedAfterLock != 1 {
+		t.Fatalf("expected the record kept while locked and deleted after, got %d then %d", keptWhileLocked, deletedAfterLock)
+	}
+}
@@ -0,0 +1,305 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	authapp "mysvelteapp/server_new/internal/modules/auth/app"
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authpersistence "mysvelteapp/server_new/internal/modules/auth/infra/persistence"
+	platformpersistence "mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// newHangingDB opens a migrated in-memory database whose queries block until
+// their context ends, standing in for a hung database.
+func newHangingDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	err = appDB.DB.Callback().Query().Before("gorm:query").Register("test:hang", func(db *gorm.DB) {
+		<-db.Statement.Context.Done()
+		_ = db.AddError(db.Statement.Context.Err())
+	})
+	if err != nil {
+		t.Fatalf("expected callback to register, got %v", err)
+	}
+	return appDB.DB
+}
+
+// TestRepositoryBoundsQueriesWithoutDeadline stops hung queries from blocking forever.
+// Arrange: use a hanging database and a repository with a short query timeout.
+// Act: look up a user with a context that has no deadline.
+// Assert: expect context.DeadlineExceeded shortly after the timeout.
+func TestRepositoryBoundsQueriesWithoutDeadline(t *testing.T) {
+	// Arrange
+	repository := authpersistence.NewGormUserRepository(newHangingDB(t), platformpersistence.DefaultRetryOptions(),
+		authpersistence.WithQueryTimeout(20*time.Millisecond))
+	start := time.Now()
+
+	// Act
+	_, err := repository.GetByUsername(context.Background(), "ash")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the query to be cancelled promptly, took %s", elapsed)
+	}
+}
+
+// TestRepositoryKeepsCallerDeadline lets an existing deadline govern the query.
+// Arrange: use a hanging database, a long query timeout and a caller deadline.
+// Act: check a username with the caller's context.
+// Assert: expect the caller's deadline, not the default timeout, to end the query.
+func TestRepositoryKeepsCallerDeadline(t *testing.T) {
+	// Arrange
+	repository := authpersistence.NewGormUserRepository(newHangingDB(t), platformpersistence.DefaultRetryOptions(),
+		authpersistence.WithQueryTimeout(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := repository.UsernameExists(ctx, "ash")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's deadline to apply, got %v", err)
+	}
+}
+
+// legacyUsersSchema is the users table as created by releases that predate
+// the migration history: no normalized username, role or token version.
+const legacyUsersSchema = `CREATE TABLE users (
+	id integer PRIMARY KEY AUTOINCREMENT,
+	username varchar(64) NOT NULL UNIQUE,
+	email varchar(320) NOT NULL UNIQUE,
+	password_hash varchar(512) NOT NULL,
+	password_salt varchar(256) NOT NULL,
+	created_at datetime,
+	updated_at datetime
+)`
+
+// TestRepositoryReadsDatabaseCreatedByLegacySchema keeps old deployments working after an upgrade.
+// Arrange: create the legacy users table and insert a user the way the old code did.
+// Act: run the migrations and look the user up through the repository.
+// Assert: expect the stored fields intact and the new columns filled with their defaults.
+func TestRepositoryReadsDatabaseCreatedByLegacySchema(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.DB.Exec(legacyUsersSchema).Error; err != nil {
+		t.Fatalf("expected legacy schema, got %v", err)
+	}
+	if err := appDB.DB.Exec(
+		"INSERT INTO users (username, email, password_hash, password_salt, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		"AshKetchum", "ash@example.com", "legacy-hash", "legacy-salt", time.Now(), time.Now(),
+	).Error; err != nil {
+		t.Fatalf("expected legacy insert, got %v", err)
+	}
+	repository := authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions())
+
+	// Act
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to upgrade the legacy schema, got %v", err)
+	}
+	user, err := repository.GetByNormalizedUsername(context.Background(), "ashketchum")
+
+	// Assert
+	if err != nil || user == nil {
+		t.Fatalf("expected the legacy user to load, got %v, %v", user, err)
+	}
+	if user.Username != "AshKetchum" || user.Email != "ash@example.com" || user.PasswordHash != "legacy-hash" || user.PasswordSalt != "legacy-salt" {
+		t.Fatalf("expected stored fields to survive the upgrade, got %+v", user)
+	}
+	if user.Role != authdomain.RoleUser || user.TokenVersion != 0 {
+		t.Fatalf("expected default role and token version, got %q and %d", user.Role, user.TokenVersion)
+	}
+}
+
+// TestRepositoryAddReportsWhichColumnIsDuplicated lets Register explain a lost race.
+// Arrange: store a user in a migrated database.
+// Act: add users reusing its username and then its email.
+// Assert: expect ErrDuplicateUsername and ErrDuplicateEmail respectively.
+func TestRepositoryAddReportsWhichColumnIsDuplicated(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	repository := authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions())
+	newUser := func(username, email string) *authdomain.User {
+		user, err := authdomain.NewUser(username, email, "hash", "salt")
+		if err != nil {
+			t.Fatalf("expected valid user, got %v", err)
+		}
+		return user
+	}
+	if err := repository.Add(context.Background(), newUser("ash", "ash@example.com")); err != nil {
+		t.Fatalf("expected first user to be stored, got %v", err)
+	}
+
+	// Act
+	usernameErr := repository.Add(context.Background(), newUser("ash", "misty@example.com"))
+	emailErr := repository.Add(context.Background(), newUser("misty", "ash@example.com"))
+
+	// Assert
+	if !errors.Is(usernameErr, authapp.ErrDuplicateUsername) {
+		t.Fatalf("expected ErrDuplicateUsername, got %v", usernameErr)
+	}
+	if !errors.Is(emailErr, authapp.ErrDuplicateEmail) {
+		t.Fatalf("expected ErrDuplicateEmail, got %v", emailErr)
+	}
+}
+
+// TestRepositoryConfirmEmailMovesPendingAddress swaps the email only on confirmation.
+// Arrange: store ash and misty, then give both the same pending address.
+// Act: confirm ash's change with a stale and then the pending address, then
+// confirm misty's.
+// Assert: expect only the matching confirmation to move ash, with the pending
+// change cleared, and ErrDuplicateEmail for misty, who keeps the old address.
+func TestRepositoryConfirmEmailMovesPendingAddress(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	repository := authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions())
+	ctx := context.Background()
+	ash, _ := authdomain.NewUser("ash", "ash@example.com", "hash", "salt")
+	misty, _ := authdomain.NewUser("misty", "misty@example.com", "hash", "salt")
+	for _, user := range []*authdomain.User{ash, misty} {
+		if err := repository.Add(ctx, user); err != nil {
+			t.Fatalf("expected user to be stored, got %v", err)
+		}
+		if err := repository.SetPendingEmail(ctx, user.ID, "ash@pallet.example"); err != nil {
+			t.Fatalf("expected pending email to be stored, got %v", err)
+		}
+	}
+
+	// Act
+	stale, staleErr := repository.ConfirmEmail(ctx, ash.ID, "ash@viridian.example")
+	confirmed, ashErr := repository.ConfirmEmail(ctx, ash.ID, "ash@pallet.example")
+	_, mistyErr := repository.ConfirmEmail(ctx, misty.ID, "ash@pallet.example")
+
+	// Assert
+	if stale || staleErr != nil {
+		t.Fatalf("expected a stale address not to be confirmed, got %v (%v)", stale, staleErr)
+	}
+	if !confirmed || ashErr != nil {
+		t.Fatalf("expected ash's change to be confirmed, got %v (%v)", confirmed, ashErr)
+	}
+	if stored, _ := repository.GetByID(ctx, ash.ID); stored.Email != "ash@pallet.example" || stored.PendingEmail != "" {
+		t.Fatalf("expected ash on the new address with the change cleared, got %+v", stored)
+	}
+	if !errors.Is(mistyErr, authapp.ErrDuplicateEmail) {
+		t.Fatalf("expected ErrDuplicateEmail, got %v", mistyErr)
+	}
+	if stored, _ := repository.GetByID(ctx, misty.ID); stored.Email != "misty@example.com" {
+		t.Fatalf("expected misty to keep the old address, got %q", stored.Email)
+	}
+}
+
+// TestRepositoryErrorsNameTheOperation tells handlers which call failed.
+// Arrange: store ash, and use a hanging database with a short query timeout.
+// Act: add a user reusing ash's email, then look a user up on the hanging database.
+// Assert: expect RepositoryErrors for Add and GetByUsername that still match
+// ErrDuplicateEmail and context.DeadlineExceeded.
+func TestRepositoryErrorsNameTheOperation(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	repository := authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions())
+	ash, _ := authdomain.NewUser("ash", "ash@example.com", "hash", "salt")
+	if err := repository.Add(context.Background(), ash); err != nil {
+		t.Fatalf("expected first user to be stored, got %v", err)
+	}
+	duplicate, _ := authdomain.NewUser("misty", "ash@example.com", "hash", "salt")
+	hanging := authpersistence.NewGormUserRepository(newHangingDB(t), platformpersistence.DefaultRetryOptions(),
+		authpersistence.WithQueryTimeout(20*time.Millisecond))
+
+	// Act
+	addErr := repository.Add(context.Background(), duplicate)
+	_, getErr := hanging.GetByUsername(context.Background(), "ash")
+
+	// Assert
+	testCases := []struct {
+		err   error
+		op    string
+		cause error
+	}{
+		{err: addErr, op: "Add", cause: authapp.ErrDuplicateEmail},
+		{err: getErr, op: "GetByUsername", cause: context.DeadlineExceeded},
+	}
+	for _, tc := range testCases {
+		var repoErr *platformpersistence.RepositoryError
+		if !errors.As(tc.err, &repoErr) || repoErr.Op != tc.op {
+			t.Fatalf("expected a RepositoryError for %s, got %v", tc.op, tc.err)
+		}
+		if !errors.Is(tc.err, tc.cause) {
+			t.Fatalf("expected %s to wrap %v, got %v", tc.op, tc.cause, tc.err)
+		}
+	}
+}
+
+// TestRepositoryRejectsCaseVariantUsernames closes the registration race for
+// case-insensitive usernames.
+// Arrange: enforce unique normalized usernames and store Ash.
+// Act: add ash, which only differs in case, under a different email.
+// Assert: expect ErrDuplicateUsername from the normalized_username index.
+func TestRepositoryRejectsCaseVariantUsernames(t *testing.T) {
+	// Arrange
+	appDB, err := platformpersistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, platformpersistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	for range 2 {
+		if err := appDB.EnforceUniqueNormalizedUsernames(context.Background()); err != nil {
+			t.Fatalf("expected the unique index to be created idempotently, got %v", err)
+		}
+	}
+	repository := authpersistence.NewGormUserRepository(appDB.DB, platformpersistence.DefaultRetryOptions())
+	upper, _ := authdomain.NewUser("Ash", "ash@example.com", "hash", "salt")
+	if err := repository.Add(context.Background(), upper); err != nil {
+		t.Fatalf("expected first user to be stored, got %v", err)
+	}
+	lower, _ := authdomain.NewUser("ash", "ash2@example.com", "hash", "salt")
+
+	// Act
+	err = repository.Add(context.Background(), lower)
+
+	// Assert
+	violation, ok := platformpersistence.AsUniqueViolation(err)
+	if !ok || !violation.Involves("normalized_username") {
+		t.Fatalf("expected the normalized_username index to reject the insert, got %v", err)
+	}
+	if !errors.Is(err, authapp.ErrDuplicateUsername) {
+		t.Fatalf("expected ErrDuplicateUsername, got %v", err)
+	}
+}
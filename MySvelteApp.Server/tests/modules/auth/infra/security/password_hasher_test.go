@@ -1,6 +1,8 @@
 package security_test
 
 import (
+	"encoding/base64"
+	"fmt"
 	"testing"
 
 	authsecurity "mysvelteapp/server_new/internal/modules/auth/infra/security"
@@ -33,3 +35,107 @@ func TestHashAndVerifyPassword(t *testing.T) {
 		t.Fatalf("expected verification to fail for incorrect password")
 	}
 }
+
+// TestHashAndVerifyWithCustomSaltSizes keeps hashes portable across salt sizes.
+// Arrange: table-drive hashers with minimum, default and large salt sizes.
+// Act: hash with each and verify with a default hasher.
+// Assert: expect the requested salt length and a successful round trip.
+func TestHashAndVerifyWithCustomSaltSizes(t *testing.T) {
+	for _, size := range []int{authsecurity.MinSaltSize, 64, 128} {
+		t.Run(fmt.Sprintf("%d bytes", size), func(t *testing.T) {
+			// Arrange
+			hasher, err := authsecurity.NewHMACPasswordHasherWithSaltSize(size)
+			if err != nil {
+				t.Fatalf("expected hasher, got %v", err)
+			}
+
+			// Act
+			hash, salt, err := hasher.HashPassword("Password123")
+			if err != nil {
+				t.Fatalf("expected no error hashing password, got %v", err)
+			}
+			verified, verifyErr := authsecurity.NewHMACPasswordHasher().VerifyPassword("Password123", hash, salt)
+
+			// Assert
+			decoded, err := base64.StdEncoding.DecodeString(salt)
+			if err != nil || len(decoded) != size {
+				t.Fatalf("expected a %d byte salt, got %d (%v)", size, len(decoded), err)
+			}
+			if verifyErr != nil || !verified {
+				t.Fatalf("expected password to verify, got %t and %v", verified, verifyErr)
+			}
+		})
+	}
+}
+
+// TestNewHMACPasswordHasherWithSaltSizeRejectsSmallSalts enforces the minimum.
+// Arrange: pick a salt size below the minimum.
+// Act: construct a hasher.
+// Assert: expect an error.
+func TestNewHMACPasswordHasherWithSaltSizeRejectsSmallSalts(t *testing.T) {
+	// Arrange
+	size := authsecurity.MinSaltSize - 1
+
+	// Act
+	_, err := authsecurity.NewHMACPasswordHasherWithSaltSize(size)
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected salt size %d to be rejected", size)
+	}
+}
+
+// TestVerifyPasswordRejectsTamperedHashes compares the full stored hash.
+// Arrange: hash a password and derive altered copies of the stored hash.
+// Act: verify the correct password against each copy.
+// Assert: expect a flipped final byte and a truncated hash to both fail.
+func TestVerifyPasswordRejectsTamperedHashes(t *testing.T) {
+	// Arrange
+	hasher := authsecurity.NewHMACPasswordHasher()
+	hash, salt, err := hasher.HashPassword("Password123")
+	if err != nil {
+		t.Fatalf("expected no error hashing password, got %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(hash)
+	flipped := append([]byte(nil), raw...)
+	flipped[len(flipped)-1] ^= 0x01
+	tampered := map[string]string{
+		"flipped last byte": base64.StdEncoding.EncodeToString(flipped),
+		"truncated":         base64.StdEncoding.EncodeToString(raw[:len(raw)-1]),
+	}
+
+	for name, storedHash := range tampered {
+		// Act
+		verified, err := hasher.VerifyPassword("Password123", storedHash, salt)
+
+		// Assert
+		if err != nil || verified {
+			t.Fatalf("expected %s hash to be rejected, got %t and %v", name, verified, err)
+		}
+	}
+}
+
+func BenchmarkHashPassword(b *testing.B) {
+	hasher := authsecurity.NewHMACPasswordHasher()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := hasher.HashPassword("Password123"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerifyPassword(b *testing.B) {
+	hasher := authsecurity.NewHMACPasswordHasher()
+	hash, salt, err := hasher.HashPassword("Password123")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := hasher.VerifyPassword("Password123", hash, salt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package token_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authtoken "mysvelteapp/server_new/internal/modules/auth/infra/token"
+)
+
+// TestGenerateTokenLifetimes stamps exp from the default or overridden lifetime.
+// Arrange: build a generator whose default lifetime is one hour.
+// Act: issue tokens with the default and with a 72 hour override.
+// Assert: expect exp minus iat to equal the requested lifetime.
+func TestGenerateTokenLifetimes(t *testing.T) {
+	generator, err := authtoken.NewJWTTokenGenerator(testOptions())
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	verifier := newVerifier(t)
+	user := &authdomain.User{ID: 7, Username: "misty"}
+
+	testCases := []struct {
+		name     string
+		generate func() (string, error)
+		lifetime time.Duration
+	}{
+		{name: "default", generate: func() (string, error) { return generator.GenerateToken(user) }, lifetime: time.Hour},
+		{name: "override", generate: func() (string, error) { return generator.GenerateTokenWithLifetime(user, 72*time.Hour) }, lifetime: 72 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			signed, err := tc.generate()
+			if err != nil {
+				t.Fatalf("expected token, got %v", err)
+			}
+
+			// Act
+			claims, err := verifier.Parse(signed)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected token to verify, got %v", err)
+			}
+			if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != tc.lifetime {
+				t.Fatalf("expected lifetime %s, got %s", tc.lifetime, got)
+			}
+		})
+	}
+}
+
+// TestGenerateTokenWithLifetimeRejectsOutOfPolicy keeps overrides within policy.
+// Arrange: build a generator.
+// Act: request tokens with zero and above-maximum lifetimes.
+// Assert: expect both requests to fail.
+func TestGenerateTokenWithLifetimeRejectsOutOfPolicy(t *testing.T) {
+	// Arrange
+	generator, err := authtoken.NewJWTTokenGenerator(testOptions())
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	user := &authdomain.User{ID: 7, Username: "misty"}
+
+	// Act
+	_, zeroErr := generator.GenerateTokenWithLifetime(user, 0)
+	_, longErr := generator.GenerateTokenWithLifetime(user, authtoken.MaxAccessTokenLifetime+time.Hour)
+
+	// Assert
+	if zeroErr == nil || longErr == nil {
+		t.Fatalf("expected out-of-policy lifetimes to be rejected, got %v and %v", zeroErr, longErr)
+	}
+}
+
+// TestGeneratedTokenRoundTripsCustomClaims exposes custom claims to verifiers.
+// Arrange: build generators with the default and a custom claims function.
+// Act: issue a token for an admin from each and parse it.
+// Assert: expect the role by default and every custom claim when configured.
+func TestGeneratedTokenRoundTripsCustomClaims(t *testing.T) {
+	verified := true
+	custom := func(user *authdomain.User) authtoken.CustomClaims {
+		return authtoken.CustomClaims{Role: user.Role, TenantID: "tenant-9", EmailVerified: &verified}
+	}
+
+	testCases := []struct {
+		name string
+		opts []authtoken.Option
+		want authtoken.CustomClaims
+	}{
+		{name: "default", want: authtoken.CustomClaims{Role: authdomain.RoleAdmin}},
+		{name: "custom", opts: []authtoken.Option{authtoken.WithCustomClaims(custom)}, want: custom(&authdomain.User{Role: authdomain.RoleAdmin})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			generator, err := authtoken.NewJWTTokenGenerator(testOptions(), tc.opts...)
+			if err != nil {
+				t.Fatalf("expected generator, got %v", err)
+			}
+			signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty", Role: authdomain.RoleAdmin})
+			if err != nil {
+				t.Fatalf("expected token, got %v", err)
+			}
+
+			// Act
+			claims, err := newVerifier(t).Parse(signed)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected token to verify, got %v", err)
+			}
+			got := claims.CustomClaims
+			if got.Role != tc.want.Role || got.TenantID != tc.want.TenantID {
+				t.Fatalf("expected claims %+v, got %+v", tc.want, got)
+			}
+			if (got.EmailVerified == nil) != (tc.want.EmailVerified == nil) ||
+				(got.EmailVerified != nil && *got.EmailVerified != *tc.want.EmailVerified) {
+				t.Fatalf("expected email_verified %v, got %v", tc.want.EmailVerified, got.EmailVerified)
+			}
+			if claims.Username != "misty" || claims.NameID != "7" {
+				t.Fatalf("expected name and nameid to be kept, got %q and %q", claims.Username, claims.NameID)
+			}
+		})
+	}
+}
+
+// TestGenerateTokenClaimNames issues the legacy, standard or both claim sets.
+// Arrange: table-drive each ClaimNames setting.
+// Act: issue a token for user 7 and decode its raw payload.
+// Assert: expect sub in every token, only the selected claim keys, and
+// ValidateToken to recover the username either way.
+func TestGenerateTokenClaimNames(t *testing.T) {
+	testCases := []struct {
+		name       string
+		claimNames string
+		present    []string
+		absent     []string
+	}{
+		{name: "default", present: []string{"name", "nameid"}, absent: []string{"username", "userId"}},
+		{name: "standard", claimNames: authtoken.ClaimNamesStandard, present: []string{"username", "userId"}, absent: []string{"name", "nameid"}},
+		{name: "both", claimNames: authtoken.ClaimNamesBoth, present: []string{"name", "nameid", "username", "userId"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			options := testOptions()
+			options.ClaimNames = tc.claimNames
+			generator, err := authtoken.NewJWTTokenGenerator(options)
+			if err != nil {
+				t.Fatalf("expected generator, got %v", err)
+			}
+
+			// Act
+			signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty"})
+			if err != nil {
+				t.Fatalf("expected token, got %v", err)
+			}
+			payload := jwt.MapClaims{}
+			if _, _, err := jwt.NewParser().ParseUnverified(signed, payload); err != nil {
+				t.Fatalf("expected token to decode, got %v", err)
+			}
+			principal, err := generator.ValidateToken(signed)
+
+			// Assert
+			if err != nil || principal.Username != "misty" || principal.UserID != 7 {
+				t.Fatalf("expected principal 7 misty, got %+v (%v)", principal, err)
+			}
+			if payload["sub"] != "7" {
+				t.Fatalf("expected sub 7, got %v", payload["sub"])
+			}
+			for _, key := range tc.present {
+				if _, ok := payload[key]; !ok {
+					t.Fatalf("expected claim %q in %v", key, payload)
+				}
+			}
+			for _, key := range tc.absent {
+				if _, ok := payload[key]; ok {
+					t.Fatalf("expected no claim %q in %v", key, payload)
+				}
+			}
+		})
+	}
+}
+
+// TestOptionsRejectUnknownClaimNames refuses claim name sets it cannot issue.
+// Arrange: set ClaimNames to an unsupported value.
+// Act: validate the options.
+// Assert: expect an error.
+func TestOptionsRejectUnknownClaimNames(t *testing.T) {
+	// Arrange
+	options := testOptions()
+	options.ClaimNames = "dotnet"
+
+	// Act
+	err := options.Validate()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected an error for unknown claim names")
+	}
+}
@@ -0,0 +1,443 @@
+package token_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	authtoken "mysvelteapp/server_new/internal/modules/auth/infra/token"
+)
+
+var testKey = strings.Repeat("k", 32)
+
+func testOptions() authtoken.JWTOptions {
+	return authtoken.JWTOptions{
+		Key:                      testKey,
+		Issuer:                   "test-issuer",
+		Audiences:                []string{"test-audience"},
+		AccessTokenLifetimeHours: 1,
+	}
+}
+
+func newVerifier(t *testing.T) *authtoken.Verifier {
+	t.Helper()
+	verifier, err := authtoken.NewVerifier(testOptions())
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+	return verifier
+}
+
+// signClaims signs arbitrary claims with key so tests can craft edge cases.
+func signClaims(t *testing.T, key string, claims authtoken.Claims) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	if err != nil {
+		t.Fatalf("expected token to sign, got %v", err)
+	}
+	return signed
+}
+
+func validClaims() authtoken.Claims {
+	now := time.Now()
+	return authtoken.Claims{
+		Username: "ash",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "42",
+			Issuer:    "test-issuer",
+			Audience:  jwt.ClaimStrings{"test-audience"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+// TestVerifierParsesGeneratedToken round-trips a token from the generator.
+// Arrange: generate a token for a stored user.
+// Act: parse it with a verifier built from the same options.
+// Assert: expect the user ID and username to be exposed.
+func TestVerifierParsesGeneratedToken(t *testing.T) {
+	// Arrange
+	generator, err := authtoken.NewJWTTokenGenerator(testOptions())
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	user := &authdomain.User{ID: 7, Username: "misty"}
+	signed, err := generator.GenerateToken(user)
+	if err != nil {
+		t.Fatalf("expected token, got %v", err)
+	}
+
+	// Act
+	claims, err := newVerifier(t).Parse(signed)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	userID, err := claims.UserID()
+	if err != nil || userID != 7 || claims.Username != "misty" {
+		t.Fatalf("expected user 7 misty, got %d %q (%v)", userID, claims.Username, err)
+	}
+}
+
+// TestVerifierRejectsInvalidTokens covers expiry, issuer, audience and signature checks.
+// Arrange: table-drive tokens each breaking one rule.
+// Act: parse each token.
+// Assert: expect the matching jwt sentinel error.
+func TestVerifierRejectsInvalidTokens(t *testing.T) {
+	testCases := []struct {
+		name   string
+		token  func(t *testing.T) string
+		target error
+	}{
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return signClaims(t, testKey, claims)
+			},
+			target: jwt.ErrTokenExpired,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Issuer = "someone-else"
+				return signClaims(t, testKey, claims)
+			},
+			target: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "wrong audience",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims.Audience = jwt.ClaimStrings{"other-audience"}
+				return signClaims(t, testKey, claims)
+			},
+			target: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name: "signed with another key",
+			token: func(t *testing.T) string {
+				return signClaims(t, strings.Repeat("x", 32), validClaims())
+			},
+			target: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name: "tampered payload",
+			token: func(t *testing.T) string {
+				parts := strings.Split(signClaims(t, testKey, validClaims()), ".")
+				forged := validClaims()
+				forged.Subject = "1"
+				forgedParts := strings.Split(signClaims(t, strings.Repeat("x", 32), forged), ".")
+				return parts[0] + "." + forgedParts[1] + "." + parts[2]
+			},
+			target: jwt.ErrTokenSignatureInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			verifier := newVerifier(t)
+			signed := tc.token(t)
+
+			// Act
+			_, err := verifier.Parse(signed)
+
+			// Assert
+			if !errors.Is(err, tc.target) {
+				t.Fatalf("expected %v, got %v", tc.target, err)
+			}
+		})
+	}
+}
+
+// TestVerifierRejectsNonNumericSubject requires a user ID subject.
+// Arrange: sign otherwise valid claims with a non-numeric subject.
+// Act: parse the token.
+// Assert: expect an error.
+func TestVerifierRejectsNonNumericSubject(t *testing.T) {
+	// Arrange
+	claims := validClaims()
+	claims.Subject = "ash"
+	signed := signClaims(t, testKey, claims)
+
+	// Act
+	_, err := newVerifier(t).Parse(signed)
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected error for non-numeric subject")
+	}
+}
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.current }
+
+func (c *fakeClock) Advance(d time.Duration) { c.current = c.current.Add(d) }
+
+// TestGeneratedTokenExpiresAfterLifetime uses an injected clock instead of sleeping.
+// Arrange: generate a one-hour token with a fake clock.
+// Act: validate it just before and just after the lifetime elapses.
+// Assert: expect success first, then jwt.ErrTokenExpired.
+func TestGeneratedTokenExpiresAfterLifetime(t *testing.T) {
+	// Arrange
+	clock := &fakeClock{current: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)}
+	generator, err := authtoken.NewJWTTokenGenerator(testOptions(), authtoken.WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty"})
+	if err != nil {
+		t.Fatalf("expected token, got %v", err)
+	}
+
+	// Act
+	clock.Advance(59 * time.Minute)
+	_, beforeExpiry := generator.ValidateToken(signed)
+	clock.Advance(2 * time.Minute)
+	_, afterExpiry := generator.ValidateToken(signed)
+
+	// Assert
+	if beforeExpiry != nil {
+		t.Fatalf("expected token to be valid before expiry, got %v", beforeExpiry)
+	}
+	if !errors.Is(afterExpiry, jwt.ErrTokenExpired) {
+		t.Fatalf("expected expired token error, got %v", afterExpiry)
+	}
+}
+
+// TestVerifierAppliesNotBeforeLeeway tolerates small clock skew only.
+// Arrange: configure a 30s leeway and sign tokens with nbf 10s and 60s ahead.
+// Act: parse both tokens.
+// Assert: expect the first accepted and the second rejected as not yet valid.
+func TestVerifierAppliesNotBeforeLeeway(t *testing.T) {
+	// Arrange
+	options := testOptions()
+	options.Leeway = 30 * time.Second
+	verifier, err := authtoken.NewVerifier(options)
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+	withinLeeway := validClaims()
+	withinLeeway.NotBefore = jwt.NewNumericDate(time.Now().Add(10 * time.Second))
+	beyondLeeway := validClaims()
+	beyondLeeway.NotBefore = jwt.NewNumericDate(time.Now().Add(60 * time.Second))
+
+	// Act
+	_, withinErr := verifier.Parse(signClaims(t, testKey, withinLeeway))
+	_, beyondErr := verifier.Parse(signClaims(t, testKey, beyondLeeway))
+
+	// Assert
+	if withinErr != nil {
+		t.Fatalf("expected token within leeway to be accepted, got %v", withinErr)
+	}
+	if !errors.Is(beyondErr, jwt.ErrTokenNotValidYet) {
+		t.Fatalf("expected not-yet-valid error, got %v", beyondErr)
+	}
+}
+
+// TestGeneratedTokenCarriesNotBefore stamps nbf at issuance.
+// Arrange: generate a token with a fixed clock.
+// Act: parse it with the same clock.
+// Assert: expect nbf to equal the issue time.
+func TestGeneratedTokenCarriesNotBefore(t *testing.T) {
+	// Arrange
+	clock := &fakeClock{current: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)}
+	generator, err := authtoken.NewJWTTokenGenerator(testOptions(), authtoken.WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty"})
+	if err != nil {
+		t.Fatalf("expected token, got %v", err)
+	}
+	verifier, err := authtoken.NewVerifier(testOptions(), authtoken.WithClock(clock.Now))
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+
+	// Act
+	claims, err := verifier.Parse(signed)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims.NotBefore == nil || !claims.NotBefore.Equal(clock.current) {
+		t.Fatalf("expected nbf %v, got %v", clock.current, claims.NotBefore)
+	}
+}
+
+// TestVerifierAcceptsAnyConfiguredAudience checks audience intersection.
+// Arrange: allow two audiences and table-drive token audience lists.
+// Act: parse each token.
+// Assert: expect acceptance only when the lists intersect.
+func TestVerifierAcceptsAnyConfiguredAudience(t *testing.T) {
+	testCases := []struct {
+		name      string
+		audiences jwt.ClaimStrings
+		accepted  bool
+	}{
+		{name: "primary audience", audiences: jwt.ClaimStrings{"web"}, accepted: true},
+		{name: "secondary audience", audiences: jwt.ClaimStrings{"mobile"}, accepted: true},
+		{name: "multiple with one match", audiences: jwt.ClaimStrings{"partner", "mobile"}, accepted: true},
+		{name: "no match", audiences: jwt.ClaimStrings{"partner"}, accepted: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			options := testOptions()
+			options.Audiences = []string{"web", "mobile"}
+			verifier, err := authtoken.NewVerifier(options)
+			if err != nil {
+				t.Fatalf("expected verifier, got %v", err)
+			}
+			claims := validClaims()
+			claims.Audience = tc.audiences
+
+			// Act
+			_, err = verifier.Parse(signClaims(t, testKey, claims))
+
+			// Assert
+			if tc.accepted && err != nil {
+				t.Fatalf("expected token to be accepted, got %v", err)
+			}
+			if !tc.accepted && !errors.Is(err, jwt.ErrTokenInvalidAudience) {
+				t.Fatalf("expected invalid audience error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestGeneratedTokenCarriesPrimaryAudience emits only the first audience.
+// Arrange: configure two audiences on the generator.
+// Act: generate and parse a token.
+// Assert: expect the audience claim to contain just the primary audience.
+func TestGeneratedTokenCarriesPrimaryAudience(t *testing.T) {
+	// Arrange
+	options := testOptions()
+	options.Audiences = []string{"web", "mobile"}
+	generator, err := authtoken.NewJWTTokenGenerator(options)
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	verifier, err := authtoken.NewVerifier(options)
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+
+	// Act
+	signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty"})
+	if err != nil {
+		t.Fatalf("expected token, got %v", err)
+	}
+	claims, err := verifier.Parse(signed)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != "web" {
+		t.Fatalf("expected audience [web], got %v", claims.Audience)
+	}
+}
+
+// TestVerifierAcceptsPreviousKeys keeps sessions alive across a key rotation.
+// Arrange: configure a new current key with the old key listed as previous.
+// Act: parse tokens signed with the old key, the new key and an unknown key.
+// Assert: expect the old and new keys to validate and the unknown key to be rejected.
+func TestVerifierAcceptsPreviousKeys(t *testing.T) {
+	// Arrange
+	currentKey := strings.Repeat("n", 32)
+	options := testOptions()
+	options.Key = currentKey
+	options.PreviousKeys = []string{testKey}
+	verifier, err := authtoken.NewVerifier(options)
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+
+	// Act
+	_, previousErr := verifier.Parse(signClaims(t, testKey, validClaims()))
+	_, currentErr := verifier.Parse(signClaims(t, currentKey, validClaims()))
+	_, unknownErr := verifier.Parse(signClaims(t, strings.Repeat("u", 32), validClaims()))
+
+	// Assert
+	if previousErr != nil {
+		t.Fatalf("expected a token signed with a previous key to validate, got %v", previousErr)
+	}
+	if currentErr != nil {
+		t.Fatalf("expected a token signed with the current key to validate, got %v", currentErr)
+	}
+	if !errors.Is(unknownErr, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("expected jwt.ErrTokenSignatureInvalid for an unknown key, got %v", unknownErr)
+	}
+}
+
+// TestGeneratorSignsWithCurrentKey never issues tokens under a retired key.
+// Arrange: configure a new current key with the old key listed as previous.
+// Act: generate a token and parse it with a verifier that only knows the old key.
+// Assert: expect the old-key verifier to reject it and a current-key verifier to accept it.
+func TestGeneratorSignsWithCurrentKey(t *testing.T) {
+	// Arrange
+	options := testOptions()
+	options.Key = strings.Repeat("n", 32)
+	options.PreviousKeys = []string{testKey}
+	generator, err := authtoken.NewJWTTokenGenerator(options)
+	if err != nil {
+		t.Fatalf("expected generator, got %v", err)
+	}
+	currentOnly := options
+	currentOnly.PreviousKeys = nil
+	currentVerifier, err := authtoken.NewVerifier(currentOnly)
+	if err != nil {
+		t.Fatalf("expected verifier, got %v", err)
+	}
+
+	// Act
+	signed, err := generator.GenerateToken(&authdomain.User{ID: 7, Username: "misty"})
+	if err != nil {
+		t.Fatalf("expected token, got %v", err)
+	}
+	_, oldErr := newVerifier(t).Parse(signed)
+	_, currentErr := currentVerifier.Parse(signed)
+
+	// Assert
+	if !errors.Is(oldErr, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("expected the previous key not to sign new tokens, got %v", oldErr)
+	}
+	if currentErr != nil {
+		t.Fatalf("expected the current key to sign new tokens, got %v", currentErr)
+	}
+}
+
+// TestOptionsRejectWeakPreviousKey applies the key-length rule to retired keys.
+// Arrange: list a previous key shorter than 32 bytes.
+// Act: validate the options.
+// Assert: expect an error naming the previous key.
+func TestOptionsRejectWeakPreviousKey(t *testing.T) {
+	// Arrange
+	options := testOptions()
+	options.PreviousKeys = []string{"short"}
+
+	// Act
+	err := options.Validate()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "previous key 1") {
+		t.Fatalf("expected a previous key error, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+)
+
+type stubCountRefresher struct {
+	count int
+	err   error
+}
+
+func (s stubCountRefresher) RefreshCount(context.Context) (int, error) {
+	return s.count, s.err
+}
+
+func newAdminEngine(counts pokemonapp.CountRefresher) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	allow := func(c *gin.Context) { c.Next() }
+	pokemonapi.RegisterAdminRoutes(engine, pokemonapi.NewAdminHandlers(counts), allow, allow)
+	return engine
+}
+
+// TestRefreshCountResponses reports the new count or why it could not be fetched.
+// Arrange: table-drive refreshers returning a count, an upstream error and an internal error.
+// Act: POST /admin/pokemon/refresh-count.
+// Assert: expect 200 with the count, 502 and 500 respectively.
+func TestRefreshCountResponses(t *testing.T) {
+	testCases := []struct {
+		name    string
+		counts  stubCountRefresher
+		status  int
+		payload string
+	}{
+		{name: "refreshed", counts: stubCountRefresher{count: 1025}, status: http.StatusOK, payload: `{"count":1025}`},
+		{name: "upstream unavailable", counts: stubCountRefresher{err: pokemonapp.UpstreamError{Err: errors.New("down")}}, status: http.StatusBadGateway},
+		{name: "internal failure", counts: stubCountRefresher{err: errors.New("bad count payload")}, status: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newAdminEngine(tc.counts)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/pokemon/refresh-count", nil))
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+			if tc.payload != "" && recorder.Body.String() != tc.payload {
+				t.Fatalf("expected body %s, got %s", tc.payload, recorder.Body.String())
+			}
+		})
+	}
+}
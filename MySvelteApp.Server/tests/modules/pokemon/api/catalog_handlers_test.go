@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+type stubListPort struct {
+	offset, limit int
+	err           error
+}
+
+func (s *stubListPort) ListPokemon(_ context.Context, offset, limit int) ([]pokemondomain.PokemonSummary, int64, error) {
+	s.offset, s.limit = offset, limit
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return []pokemondomain.PokemonSummary{{Name: "pikachu"}}, 45, nil
+}
+
+func newCatalogEngine(port pokemonapp.PokemonListPort) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	pokemonapi.RegisterCatalogRoutes(engine, pokemonapi.NewCatalogHandlers(pokemonapp.NewCatalogService(port)))
+	return engine
+}
+
+// TestListPokemonReturnsPagedEnvelope translates page numbers into offsets.
+// Arrange: stub the list port.
+// Act: request the third page of ten.
+// Assert: expect offset 20 upstream and the paged envelope with the upstream total.
+func TestListPokemonReturnsPagedEnvelope(t *testing.T) {
+	// Arrange
+	port := &stubListPort{}
+	engine := newCatalogEngine(port)
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/pokemon?page=3&pageSize=10", nil)
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if port.offset != 20 || port.limit != 10 {
+		t.Fatalf("expected offset 20 and limit 10, got %d and %d", port.offset, port.limit)
+	}
+	var body httpapi.PagedResponse[pokemonapi.PokemonSummaryResponse]
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected paged JSON, got %q", recorder.Body.String())
+	}
+	if body.Page != 3 || body.Total != 45 || body.TotalPages != 5 || len(body.Items) != 1 {
+		t.Fatalf("expected page 3 of 5 with 45 total, got %+v", body)
+	}
+}
+
+// TestListPokemonErrorStatus validates input and maps upstream failures.
+// Arrange: table-drive oversized pages and failing ports.
+// Act: request /pokemon.
+// Assert: expect 400 for invalid paging and 502 for upstream failures.
+func TestListPokemonErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{name: "page size above cap", query: "?pageSize=101", status: http.StatusBadRequest},
+		{name: "non-numeric page", query: "?page=abc", status: http.StatusBadRequest},
+		{name: "upstream unavailable", err: pokemonapp.UpstreamError{Err: errors.New("down")}, status: http.StatusBadGateway},
+		{name: "internal failure", err: errors.New("decode failed"), status: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newCatalogEngine(&stubListPort{err: tc.err})
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/pokemon"+tc.query, nil)
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+		})
+	}
+}
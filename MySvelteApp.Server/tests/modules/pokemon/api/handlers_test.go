@@ -0,0 +1,223 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+type stubRandomPokemonPort struct {
+	pokemon *pokemondomain.RandomPokemon
+	err     error
+}
+
+func (s stubRandomPokemonPort) GetRandomPokemon(_ context.Context) (*pokemondomain.RandomPokemon, error) {
+	return s.pokemon, s.err
+}
+
+func (s stubRandomPokemonPort) GetRandomPokemonByType(_ context.Context, _ string) (*pokemondomain.RandomPokemon, error) {
+	return s.pokemon, s.err
+}
+
+// contextPort fails the way the PokeAPI adapter does when its context ends.
+type contextPort struct{}
+
+func (contextPort) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
+	<-ctx.Done()
+	return nil, pokemonapp.UpstreamError{Err: fmt.Errorf("failed to get Pokemon count: %w", ctx.Err())}
+}
+
+func (p contextPort) GetRandomPokemonByType(ctx context.Context, _ string) (*pokemondomain.RandomPokemon, error) {
+	return p.GetRandomPokemon(ctx)
+}
+
+func newPokemonEngine(port pokemonapp.RandomPokemonPort) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	pokemonapi.RegisterRoutes(engine, pokemonapi.NewHandlers(pokemonapp.NewService(port)))
+	return engine
+}
+
+// TestGetRandomPokemonErrorStatus maps port failures onto HTTP statuses.
+// Arrange: table-drive stub ports returning upstream and internal errors.
+// Act: request /RandomPokemon.
+// Assert: expect 502 for upstream failures and 500 for everything else.
+func TestGetRandomPokemonErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{
+			name:   "upstream unavailable",
+			err:    pokemonapp.UpstreamError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("Pokemon API returned status 503")},
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "wrapped upstream network failure",
+			err:    errors.Join(errors.New("failed to get Pokemon count"), pokemonapp.UpstreamError{Err: errors.New("connection refused")}),
+			status: http.StatusBadGateway,
+		},
+		{
+			name:   "internal failure",
+			err:    errors.New("failed to deserialize Pokemon data"),
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newPokemonEngine(stubRandomPokemonPort{err: tc.err})
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/RandomPokemon", nil)
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+		})
+	}
+}
+
+// TestGetRandomPokemonUpstreamErrorEnvelope returns the standard error envelope.
+// Arrange: stub the port with an upstream failure.
+// Act: request /RandomPokemon.
+// Assert: expect the UPSTREAM_UNAVAILABLE code and a message in the envelope.
+func TestGetRandomPokemonUpstreamErrorEnvelope(t *testing.T) {
+	// Arrange
+	engine := newPokemonEngine(stubRandomPokemonPort{err: pokemonapp.UpstreamError{StatusCode: http.StatusServiceUnavailable, Err: errors.New("down")}})
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/RandomPokemon", nil)
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	var body httpapi.ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected JSON error envelope, got %q", recorder.Body.String())
+	}
+	if body.Error.Code != httpapi.CodeUpstreamUnavailable || body.Error.Message == "" {
+		t.Fatalf("expected %s error with a message, got %+v", httpapi.CodeUpstreamUnavailable, body.Error)
+	}
+}
+
+// TestGetRandomPokemonContextErrors keeps abandoned and timed-out requests out of the 5xx range.
+// Arrange: table-drive request contexts that are cancelled or past their deadline.
+// Act: request /RandomPokemon through a port that waits for the context.
+// Assert: expect 499 for cancellation and 408 for deadlines, with matching codes.
+func TestGetRandomPokemonContextErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ctx    func() (context.Context, context.CancelFunc)
+		status int
+		code   string
+	}{
+		{
+			name: "client disconnected",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			status: httpapi.StatusClientClosedRequest,
+			code:   httpapi.CodeClientClosedRequest,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Millisecond)
+			},
+			status: http.StatusRequestTimeout,
+			code:   httpapi.CodeRequestTimeout,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newPokemonEngine(contextPort{})
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/RandomPokemon", nil).WithContext(ctx)
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			var body httpapi.ErrorResponse
+			_ = json.Unmarshal(recorder.Body.Bytes(), &body)
+			if recorder.Code != tc.status || body.Error.Code != tc.code {
+				t.Fatalf("expected %d %s, got %d %+v", tc.status, tc.code, recorder.Code, body.Error)
+			}
+		})
+	}
+}
+
+// TestGetRandomPokemonUpstreamTimeoutStaysBadGateway blames the upstream for its own timeouts.
+// Arrange: stub the port with a deadline error while the request context is live.
+// Act: request /RandomPokemon.
+// Assert: expect 502.
+func TestGetRandomPokemonUpstreamTimeoutStaysBadGateway(t *testing.T) {
+	// Arrange
+	engine := newPokemonEngine(stubRandomPokemonPort{err: pokemonapp.UpstreamError{Err: fmt.Errorf("failed to get Pokemon count: %w", context.DeadlineExceeded)}})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/RandomPokemon", nil))
+
+	// Assert
+	if recorder.Code != http.StatusBadGateway {
+		t.Fatalf("expected status %d, got %d", http.StatusBadGateway, recorder.Code)
+	}
+}
+
+// TestGetRandomPokemonTypeFilterStatus maps type-filter failures onto HTTP statuses.
+// Arrange: table-drive an invalid type and a port reporting an unknown type.
+// Act: request /RandomPokemon with a type query parameter.
+// Assert: expect 400 for a malformed type and 404 for an unknown one.
+func TestGetRandomPokemonTypeFilterStatus(t *testing.T) {
+	testCases := []struct {
+		name   string
+		query  string
+		err    error
+		status int
+	}{
+		{name: "malformed type", query: "fire%2F..", status: http.StatusBadRequest},
+		{name: "unknown type", query: "plasma", err: pokemonapp.NotFoundError{Message: "Pokemon type not found."}, status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newPokemonEngine(stubRandomPokemonPort{err: tc.err})
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/RandomPokemon?type="+tc.query, nil)
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+		})
+	}
+}
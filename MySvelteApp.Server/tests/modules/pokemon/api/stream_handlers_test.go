@@ -0,0 +1,154 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	pokemonapi "mysvelteapp/server_new/internal/modules/pokemon/api"
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+func newStreamServer(t *testing.T, opts pokemonapi.StreamOptions) (*httptest.Server, *pokemonapi.StreamHandlers) {
+	t.Helper()
+	name, kind := "pikachu", "electric"
+	port := stubRandomPokemonPort{pokemon: &pokemondomain.RandomPokemon{Name: &name, Type: &kind}}
+	handlers := pokemonapi.NewStreamHandlers(pokemonapp.NewService(port), opts)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	pokemonapi.RegisterStreamRoutes(engine, handlers)
+	server := httptest.NewServer(engine)
+	t.Cleanup(func() {
+		handlers.Close()
+		server.Close()
+	})
+	return server, handlers
+}
+
+func dialStream(server *httptest.Server) (*websocket.Conn, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/RandomPokemon/stream"
+	return websocket.Dial(url, "", server.URL)
+}
+
+// TestStreamRandomPokemonPushesAtInterval sends Pokemon until the client leaves.
+// Arrange: serve the stream with a short interval and a stub port.
+// Act: connect with a WebSocket client and read two messages.
+// Assert: expect both messages to carry the stubbed Pokemon.
+func TestStreamRandomPokemonPushesAtInterval(t *testing.T) {
+	// Arrange
+	server, _ := newStreamServer(t, pokemonapi.StreamOptions{Interval: 10 * time.Millisecond})
+	conn, err := dialStream(server)
+	if err != nil {
+		t.Fatalf("expected websocket dial to succeed, got %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	// Act
+	var messages []pokemonapi.RandomPokemonResponse
+	for i := 0; i < 2; i++ {
+		var message pokemonapi.RandomPokemonResponse
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			t.Fatalf("expected message %d, got %v", i+1, err)
+		}
+		messages = append(messages, message)
+	}
+
+	// Assert
+	for i, message := range messages {
+		if message.Name == nil || *message.Name != "pikachu" || message.Type == nil || *message.Type != "electric" {
+			t.Fatalf("expected pikachu in message %d, got %+v", i+1, message)
+		}
+	}
+}
+
+// TestStreamRandomPokemonBoundsClients rejects streams beyond the client cap.
+// Arrange: serve the stream with room for one client and occupy it.
+// Act: request a second stream.
+// Assert: expect 503 Service Unavailable.
+func TestStreamRandomPokemonBoundsClients(t *testing.T) {
+	// Arrange
+	server, _ := newStreamServer(t, pokemonapi.StreamOptions{Interval: time.Hour, MaxClients: 1})
+	conn, err := dialStream(server)
+	if err != nil {
+		t.Fatalf("expected first websocket dial to succeed, got %v", err)
+	}
+	defer conn.Close()
+
+	// Act
+	resp, err := http.Get(server.URL + "/RandomPokemon/stream")
+	if err != nil {
+		t.Fatalf("expected request to complete, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Assert
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
+
+// TestStreamRandomPokemonReleasesSlotOnDisconnect frees capacity when a client leaves.
+// Arrange: serve the stream with room for one client.
+// Act: connect, disconnect, then connect again.
+// Assert: expect the second connection to be accepted once the first is cleaned up.
+func TestStreamRandomPokemonReleasesSlotOnDisconnect(t *testing.T) {
+	// Arrange
+	server, _ := newStreamServer(t, pokemonapi.StreamOptions{Interval: time.Hour, MaxClients: 1})
+	first, err := dialStream(server)
+	if err != nil {
+		t.Fatalf("expected first websocket dial to succeed, got %v", err)
+	}
+
+	// Act
+	_ = first.Close()
+	var second *websocket.Conn
+	deadline := time.Now().Add(2 * time.Second)
+	for second == nil && time.Now().Before(deadline) {
+		if second, err = dialStream(server); err != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// Assert
+	if second == nil {
+		t.Fatalf("expected the slot to be released after disconnect, got %v", err)
+	}
+	_ = second.Close()
+}
+
+// TestStreamRandomPokemonCloseEndsStreams ends open streams on shutdown.
+// Arrange: open a stream with a long interval and read its first message.
+// Act: close the handlers.
+// Assert: expect the client to observe the connection closing.
+func TestStreamRandomPokemonCloseEndsStreams(t *testing.T) {
+	// Arrange
+	server, handlers := newStreamServer(t, pokemonapi.StreamOptions{Interval: time.Hour})
+	conn, err := dialStream(server)
+	if err != nil {
+		t.Fatalf("expected websocket dial to succeed, got %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var message pokemonapi.RandomPokemonResponse
+	if err := websocket.JSON.Receive(conn, &message); err != nil {
+		t.Fatalf("expected first message, got %v", err)
+	}
+
+	// Act
+	handlers.Close()
+
+	// Assert
+	if err := websocket.JSON.Receive(conn, &message); err == nil {
+		t.Fatalf("expected the stream to end, got %+v", message)
+	} else if strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected the server to close the stream, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+type memoryFavoriteRepository struct {
+	favorites []pokemondomain.FavoritePokemon
+}
+
+func (m *memoryFavoriteRepository) Add(_ context.Context, favorite *pokemondomain.FavoritePokemon) error {
+	for _, existing := range m.favorites {
+		if existing.UserID == favorite.UserID && existing.Name == favorite.Name {
+			return pokemonapp.ErrDuplicateFavorite
+		}
+	}
+	favorite.ID = uint(len(m.favorites) + 1)
+	m.favorites = append(m.favorites, *favorite)
+	return nil
+}
+
+func (m *memoryFavoriteRepository) ListByUser(_ context.Context, userID uint, offset, limit int) ([]pokemondomain.FavoritePokemon, int64, error) {
+	var result []pokemondomain.FavoritePokemon
+	for _, favorite := range m.favorites {
+		if favorite.UserID == userID {
+			result = append(result, favorite)
+		}
+	}
+	total := int64(len(result))
+	result = result[min(offset, len(result)):]
+	return result[:min(limit, len(result))], total, nil
+}
+
+func (m *memoryFavoriteRepository) Delete(_ context.Context, userID uint, name string) (bool, error) {
+	for i, favorite := range m.favorites {
+		if favorite.UserID == userID && favorite.Name == name {
+			m.favorites = append(m.favorites[:i], m.favorites[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type stubPokemonLookup struct{}
+
+func (stubPokemonLookup) GetPokemonByName(_ context.Context, name string) (*pokemondomain.RandomPokemon, error) {
+	if name != "pikachu" {
+		return nil, pokemonapp.NotFoundError{Message: "Pokemon not found."}
+	}
+	pokemonType := "electric"
+	image := "https://example.com/pikachu.png"
+	return &pokemondomain.RandomPokemon{Name: &name, Type: &pokemonType, Image: &image}, nil
+}
+
+// TestAddFavoriteCapturesDetails validates the happy-path favorite flow.
+// Arrange: wire the service with in-memory storage and a stub lookup.
+// Act: add a favorite using mixed-case input.
+// Assert: expect the normalised name with type and image captured.
+func TestAddFavoriteCapturesDetails(t *testing.T) {
+	// Arrange
+	repo := &memoryFavoriteRepository{}
+	service := pokemonapp.NewFavoritesService(repo, stubPokemonLookup{})
+
+	// Act
+	favorite, err := service.Add(context.Background(), 7, " Pikachu ")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if favorite.Name != "pikachu" || favorite.Type != "electric" || favorite.Image == "" {
+		t.Fatalf("expected captured pokemon details, got %+v", favorite)
+	}
+	if favorites, _, _ := service.List(context.Background(), 7, 0, 10); len(favorites) != 1 {
+		t.Fatalf("expected one stored favorite, got %d", len(favorites))
+	}
+}
+
+// TestAddFavoriteDuplicate ensures a user cannot save the same Pokemon twice.
+// Arrange: seed a favorite for the user.
+// Act: add the same Pokemon again.
+// Assert: expect a ConflictError.
+func TestAddFavoriteDuplicate(t *testing.T) {
+	// Arrange
+	repo := &memoryFavoriteRepository{}
+	service := pokemonapp.NewFavoritesService(repo, stubPokemonLookup{})
+	if _, err := service.Add(context.Background(), 7, "pikachu"); err != nil {
+		t.Fatalf("expected first favorite to succeed, got %v", err)
+	}
+
+	// Act
+	_, err := service.Add(context.Background(), 7, "PIKACHU")
+
+	// Assert
+	if !pokemonapp.IsConflictError(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
+
+// TestFavoriteErrors covers validation, unknown Pokemon, and missing favorites.
+// Arrange: wire the service with empty storage.
+// Act: add a blank name, add an unknown Pokemon, and remove a missing favorite.
+// Assert: expect validation, not-found, and not-found errors respectively.
+func TestFavoriteErrors(t *testing.T) {
+	// Arrange
+	service := pokemonapp.NewFavoritesService(&memoryFavoriteRepository{}, stubPokemonLookup{})
+
+	// Act
+	_, blankErr := service.Add(context.Background(), 7, "  ")
+	_, unknownErr := service.Add(context.Background(), 7, "missingno")
+	removeErr := service.Remove(context.Background(), 7, "pikachu")
+
+	// Assert
+	if !pokemonapp.IsValidationError(blankErr) {
+		t.Fatalf("expected validation error, got %v", blankErr)
+	}
+	if !pokemonapp.IsNotFoundError(unknownErr) {
+		t.Fatalf("expected not found error, got %v", unknownErr)
+	}
+	var notFound pokemonapp.NotFoundError
+	if !errors.As(removeErr, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", removeErr)
+	}
+}
@@ -0,0 +1,224 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemondomain "mysvelteapp/server_new/internal/modules/pokemon/domain"
+)
+
+// sequencePokemonPort hands out names from a fixed cycle so duplicates are predictable.
+type sequencePokemonPort struct {
+	mu    sync.Mutex
+	names []string
+	next  int
+	err   error
+}
+
+func (s *sequencePokemonPort) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.mu.Lock()
+	name := s.names[s.next%len(s.names)]
+	s.next++
+	s.mu.Unlock()
+
+	return &pokemondomain.RandomPokemon{Name: &name}, nil
+}
+
+func (s *sequencePokemonPort) GetRandomPokemonByType(ctx context.Context, _ string) (*pokemondomain.RandomPokemon, error) {
+	return s.GetRandomPokemon(ctx)
+}
+
+// TestGetRandomPokemonBatchDistinct ensures batches contain distinct Pokemon.
+// Arrange: use a port that repeats each name twice in a row.
+// Act: request a batch of five.
+// Assert: expect five distinct names.
+func TestGetRandomPokemonBatchDistinct(t *testing.T) {
+	// Arrange
+	var names []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("pokemon-%d", i)
+		names = append(names, name, name)
+	}
+	service := pokemonapp.NewService(&sequencePokemonPort{names: names})
+
+	// Act
+	batch, err := service.GetRandomPokemonBatch(context.Background(), 5)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(batch) != 5 {
+		t.Fatalf("expected 5 pokemon, got %d", len(batch))
+	}
+	seen := make(map[string]bool)
+	for _, pokemon := range batch {
+		if seen[*pokemon.Name] {
+			t.Fatalf("expected distinct pokemon, got duplicate %q", *pokemon.Name)
+		}
+		seen[*pokemon.Name] = true
+	}
+}
+
+// TestGetRandomPokemonBatchLimitedSource returns what a small source can supply.
+// Arrange: use a port that only knows two Pokemon.
+// Act: request a batch of five.
+// Assert: expect the two distinct Pokemon without error.
+func TestGetRandomPokemonBatchLimitedSource(t *testing.T) {
+	// Arrange
+	service := pokemonapp.NewService(&sequencePokemonPort{names: []string{"pikachu", "eevee"}})
+
+	// Act
+	batch, err := service.GetRandomPokemonBatch(context.Background(), 5)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(batch) != 2 {
+		t.Fatalf("expected 2 pokemon, got %d", len(batch))
+	}
+}
+
+// TestGetRandomPokemonBatchErrors covers count validation, port failures, and cancellation.
+// Arrange: table-drive counts, port errors, and contexts.
+// Act: request a batch for each case.
+// Assert: expect the matching error.
+func TestGetRandomPokemonBatchErrors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	upstream := pokemonapp.UpstreamError{Err: errors.New("connection refused")}
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		count int
+		err   error
+		check func(error) bool
+	}{
+		{name: "count too small", ctx: context.Background(), count: 0, check: pokemonapp.IsValidationError},
+		{name: "count too large", ctx: context.Background(), count: pokemonapp.MaxBatchSize + 1, check: pokemonapp.IsValidationError},
+		{name: "port failure", ctx: context.Background(), count: 3, err: upstream, check: pokemonapp.IsUpstreamError},
+		{name: "cancelled context", ctx: cancelled, count: 3, check: func(err error) bool { return errors.Is(err, context.Canceled) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			service := pokemonapp.NewService(&sequencePokemonPort{names: []string{"pikachu"}, err: tc.err})
+
+			// Act
+			_, err := service.GetRandomPokemonBatch(tc.ctx, tc.count)
+
+			// Assert
+			if !tc.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+// randomSourcePokemonPort picks names with whatever source the context carries.
+type randomSourcePokemonPort struct {
+	names []string
+}
+
+func (r randomSourcePokemonPort) GetRandomPokemon(ctx context.Context) (*pokemondomain.RandomPokemon, error) {
+	name := r.names[pokemonapp.RandomSourceFrom(ctx, nil).Intn(len(r.names))]
+	return &pokemondomain.RandomPokemon{Name: &name}, nil
+}
+
+func (r randomSourcePokemonPort) GetRandomPokemonByType(ctx context.Context, _ string) (*pokemondomain.RandomPokemon, error) {
+	return r.GetRandomPokemon(ctx)
+}
+
+// TestGetSeededRandomPokemonRepeatsSequence replays the same picks for the same seed.
+// Arrange: use a port that draws from the request's random source.
+// Act: request eight Pokemon twice with seed 99.
+// Assert: expect both sequences to match in order.
+func TestGetSeededRandomPokemonRepeatsSequence(t *testing.T) {
+	// Arrange
+	service := pokemonapp.NewService(randomSourcePokemonPort{
+		names: []string{"pikachu", "bulbasaur", "charmander", "squirtle", "eevee", "snorlax"},
+	})
+	names := func(batch []pokemondomain.RandomPokemon) []string {
+		out := make([]string, 0, len(batch))
+		for _, pokemon := range batch {
+			out = append(out, *pokemon.Name)
+		}
+		return out
+	}
+
+	// Act
+	first, firstErr := service.GetSeededRandomPokemon(context.Background(), 99, 8)
+	second, secondErr := service.GetSeededRandomPokemon(context.Background(), 99, 8)
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("expected no errors, got %v and %v", firstErr, secondErr)
+	}
+	if len(first) != 8 || fmt.Sprint(names(first)) != fmt.Sprint(names(second)) {
+		t.Fatalf("expected the same eight picks, got %v and %v", names(first), names(second))
+	}
+}
+
+// TestGetSeededRandomPokemonValidatesCount applies the batch bounds to seeded draws.
+// Arrange: use a port that draws from the request's random source.
+// Act: request zero Pokemon.
+// Assert: expect a validation error.
+func TestGetSeededRandomPokemonValidatesCount(t *testing.T) {
+	// Arrange
+	service := pokemonapp.NewService(randomSourcePokemonPort{names: []string{"pikachu"}})
+
+	// Act
+	_, err := service.GetSeededRandomPokemon(context.Background(), 1, 0)
+
+	// Assert
+	if !pokemonapp.IsValidationError(err) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
+
+// typeRecordingPort remembers the type it was asked for.
+type typeRecordingPort struct {
+	sequencePokemonPort
+	typeName string
+}
+
+func (p *typeRecordingPort) GetRandomPokemonByType(ctx context.Context, typeName string) (*pokemondomain.RandomPokemon, error) {
+	p.typeName = typeName
+	return p.GetRandomPokemon(ctx)
+}
+
+// TestGetRandomPokemonByTypeNormalizesAndValidates passes clean type names to the port.
+// Arrange: use a port that records the requested type.
+// Act: request " Fire " and then a name with a path separator.
+// Assert: expect "fire" forwarded and the second request rejected as a validation error.
+func TestGetRandomPokemonByTypeNormalizesAndValidates(t *testing.T) {
+	// Arrange
+	port := &typeRecordingPort{sequencePokemonPort: sequencePokemonPort{names: []string{"charmander"}}}
+	service := pokemonapp.NewService(port)
+
+	// Act
+	_, validErr := service.GetRandomPokemonByType(context.Background(), " Fire ")
+	_, invalidErr := service.GetRandomPokemonByType(context.Background(), "fire/../pokemon")
+
+	// Assert
+	if validErr != nil || port.typeName != "fire" {
+		t.Fatalf("expected fire to be forwarded, got %q and %v", port.typeName, validErr)
+	}
+	if !pokemonapp.IsValidationError(invalidErr) {
+		t.Fatalf("expected a validation error, got %v", invalidErr)
+	}
+}
@@ -0,0 +1,131 @@
+package offline_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemonoffline "mysvelteapp/server_new/internal/modules/pokemon/infra/offline"
+)
+
+// TestGetRandomPokemonFromEmbeddedDataset serves Pokemon without network access.
+// Arrange: load the embedded dataset.
+// Act: fetch a random Pokemon.
+// Assert: expect every field to be populated.
+func TestGetRandomPokemonFromEmbeddedDataset(t *testing.T) {
+	// Arrange
+	adapter, err := pokemonoffline.NewAdapter()
+	if err != nil {
+		t.Fatalf("expected dataset to load, got %v", err)
+	}
+
+	// Act
+	pokemon, err := adapter.GetRandomPokemon(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pokemon.Name == nil || *pokemon.Name == "" {
+		t.Fatalf("expected name to be populated")
+	}
+	if pokemon.Type == nil || *pokemon.Type == "" {
+		t.Fatalf("expected type to be populated")
+	}
+	if pokemon.Image == nil || *pokemon.Image == "" {
+		t.Fatalf("expected image to be populated")
+	}
+}
+
+// TestListPokemonPagesEmbeddedDataset pages through the dataset in order.
+// Arrange: load the embedded dataset.
+// Act: list the first page and a page past the end.
+// Assert: expect bulbasaur first, the dataset size as total, and an empty tail page.
+func TestListPokemonPagesEmbeddedDataset(t *testing.T) {
+	// Arrange
+	adapter, err := pokemonoffline.NewAdapter()
+	if err != nil {
+		t.Fatalf("expected dataset to load, got %v", err)
+	}
+
+	// Act
+	first, total, err := adapter.ListPokemon(context.Background(), 0, 2)
+	beyond, _, beyondErr := adapter.ListPokemon(context.Background(), int(total), 2)
+
+	// Assert
+	if err != nil || beyondErr != nil {
+		t.Fatalf("expected no errors, got %v and %v", err, beyondErr)
+	}
+	if len(first) != 2 || first[0].Name != "bulbasaur" {
+		t.Fatalf("expected a two-item page starting with bulbasaur, got %+v", first)
+	}
+	if total < 2 {
+		t.Fatalf("expected the dataset size as total, got %d", total)
+	}
+	if len(beyond) != 0 {
+		t.Fatalf("expected an empty page past the end, got %+v", beyond)
+	}
+}
+
+// drawNames picks count random Pokemon from adapter and returns their names.
+func drawNames(t *testing.T, ctx context.Context, adapter *pokemonoffline.Adapter, count int) []string {
+	t.Helper()
+	names := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		pokemon, err := adapter.GetRandomPokemon(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		names = append(names, *pokemon.Name)
+	}
+	return names
+}
+
+// TestSeededAdaptersRepeatTheSameSequence makes demos and load tests reproducible.
+// Arrange: load two adapters seeded with the same value.
+// Act: draw ten Pokemon from each.
+// Assert: expect identical sequences.
+func TestSeededAdaptersRepeatTheSameSequence(t *testing.T) {
+	// Arrange
+	first, err := pokemonoffline.NewAdapter(pokemonoffline.WithRandomSource(pokemonapp.NewSeededSource(42)))
+	if err != nil {
+		t.Fatalf("expected dataset to load, got %v", err)
+	}
+	second, err := pokemonoffline.NewAdapter(pokemonoffline.WithRandomSource(pokemonapp.NewSeededSource(42)))
+	if err != nil {
+		t.Fatalf("expected dataset to load, got %v", err)
+	}
+
+	// Act
+	firstNames := drawNames(t, context.Background(), first, 10)
+	secondNames := drawNames(t, context.Background(), second, 10)
+
+	// Assert
+	if !slices.Equal(firstNames, secondNames) {
+		t.Fatalf("expected the same sequence, got %v and %v", firstNames, secondNames)
+	}
+}
+
+// TestRequestScopedSourceOverridesAdapterSource lets one request choose its own seed.
+// Arrange: load an unseeded adapter and scope a seeded source to two contexts.
+// Act: draw ten Pokemon with each context.
+// Assert: expect both contexts to yield the same sequence.
+func TestRequestScopedSourceOverridesAdapterSource(t *testing.T) {
+	// Arrange
+	adapter, err := pokemonoffline.NewAdapter()
+	if err != nil {
+		t.Fatalf("expected dataset to load, got %v", err)
+	}
+	firstCtx := pokemonapp.WithRandomSource(context.Background(), pokemonapp.NewSeededSource(7))
+	secondCtx := pokemonapp.WithRandomSource(context.Background(), pokemonapp.NewSeededSource(7))
+
+	// Act
+	firstNames := drawNames(t, firstCtx, adapter, 10)
+	secondNames := drawNames(t, secondCtx, adapter, 10)
+
+	// Assert
+	if !slices.Equal(firstNames, secondNames) {
+		t.Fatalf("expected the same sequence, got %v and %v", firstNames, secondNames)
+	}
+}
@@ -0,0 +1,351 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// roundTripFunc serves canned PokeAPI responses without network access.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func newTestAdapter(pokemonBody string, opts ...pokemoninfra.Option) *pokemoninfra.Adapter {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "pokemon-species") {
+			return jsonResponse(http.StatusOK, `{"count": 1}`), nil
+		}
+		return jsonResponse(http.StatusOK, pokemonBody), nil
+	})}
+	opts = append([]pokemoninfra.Option{pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer(""))}, opts...)
+	return pokemoninfra.NewAdapter(client, opts...)
+}
+
+// TestGetRandomPokemonDeduplicatesTypes keeps the Type field clean and stable.
+// Arrange: serve a crafted response listing a type twice.
+// Act: fetch a random Pokemon.
+// Assert: expect each type once, in first-seen order.
+func TestGetRandomPokemonDeduplicatesTypes(t *testing.T) {
+	// Arrange
+	adapter := newTestAdapter(`{
+		"name": "bulbasaur",
+		"types": [
+			{"type": {"name": "grass"}},
+			{"type": {"name": "poison"}},
+			{"type": {"name": "grass"}}
+		],
+		"sprites": {"front_default": null}
+	}`)
+
+	// Act
+	pokemon, err := adapter.GetRandomPokemon(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *pokemon.Type != "grass, poison" {
+		t.Fatalf("expected deduplicated types, got %q", *pokemon.Type)
+	}
+}
+
+// TestGetRandomPokemonImageFallbacks avoids broken images when sprites are null.
+// Arrange: table-drive sprite payloads with and without a placeholder configured.
+// Act: fetch a random Pokemon.
+// Assert: expect the front sprite, then the official artwork, then the placeholder.
+func TestGetRandomPokemonImageFallbacks(t *testing.T) {
+	const placeholder = "https://example.com/placeholder.png"
+	testCases := []struct {
+		name        string
+		sprites     string
+		placeholder string
+		image       string
+	}{
+		{
+			name:    "front sprite present",
+			sprites: `{"front_default": "https://img/front.png", "other": {"official-artwork": {"front_default": "https://img/art.png"}}}`,
+			image:   "https://img/front.png",
+		},
+		{
+			name:    "null front sprite uses official artwork",
+			sprites: `{"front_default": null, "other": {"official-artwork": {"front_default": "https://img/art.png"}}}`,
+			image:   "https://img/art.png",
+		},
+		{
+			name:        "all null uses placeholder",
+			sprites:     `{"front_default": null, "other": {"official-artwork": {"front_default": null}}}`,
+			placeholder: placeholder,
+			image:       placeholder,
+		},
+		{
+			name:    "all null without placeholder",
+			sprites: `{"front_default": null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			adapter := newTestAdapter(`{"name": "missingno", "types": [{"type": {"name": "normal"}}], "sprites": `+tc.sprites+`}`,
+				pokemoninfra.WithPlaceholderImage(tc.placeholder))
+
+			// Act
+			pokemon, err := adapter.GetRandomPokemon(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tc.image == "" {
+				if pokemon.Image != nil {
+					t.Fatalf("expected no image, got %q", *pokemon.Image)
+				}
+				return
+			}
+			if pokemon.Image == nil || *pokemon.Image != tc.image {
+				t.Fatalf("expected image %q, got %v", tc.image, pokemon.Image)
+			}
+		})
+	}
+}
+
+// TestGetRandomPokemonDefaultsEmptyTypes reports "unknown" instead of an empty type.
+// Arrange: serve a response with an empty types array.
+// Act: fetch a random Pokemon.
+// Assert: expect the type to be "unknown".
+func TestGetRandomPokemonDefaultsEmptyTypes(t *testing.T) {
+	// Arrange
+	adapter := newTestAdapter(`{"name": "missingno", "types": [], "sprites": {"front_default": null}}`)
+
+	// Act
+	pokemon, err := adapter.GetRandomPokemon(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pokemon.Type == nil || *pokemon.Type != "unknown" {
+		t.Fatalf("expected type %q, got %v", "unknown", pokemon.Type)
+	}
+}
+
+// TestListPokemonForwardsPagingAndTotal maps the PokeAPI index onto summaries.
+// Arrange: serve a list response and capture the request query.
+// Act: list Pokemon with an offset and limit.
+// Assert: expect the paging parameters upstream and the reported total returned.
+func TestListPokemonForwardsPagingAndTotal(t *testing.T) {
+	// Arrange
+	var query url.Values
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return jsonResponse(http.StatusOK, `{"count": 1302, "results": [{"name": "ivysaur", "url": "https://pokeapi.co/api/v2/pokemon/2/"}]}`), nil
+	})}
+	adapter := pokemoninfra.NewAdapter(client, pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+
+	// Act
+	summaries, total, err := adapter.ListPokemon(context.Background(), 1, 1)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if query.Get("offset") != "1" || query.Get("limit") != "1" {
+		t.Fatalf("expected offset=1 and limit=1 upstream, got %v", query)
+	}
+	if total != 1302 {
+		t.Fatalf("expected total 1302, got %d", total)
+	}
+	if len(summaries) != 1 || summaries[0].Name != "ivysaur" {
+		t.Fatalf("expected ivysaur, got %+v", summaries)
+	}
+}
+
+// TestListPokemonUpstreamFailure reports upstream failures as UpstreamError.
+// Arrange: serve a 503 from the list endpoint.
+// Act: list Pokemon.
+// Assert: expect an UpstreamError carrying the status code.
+func TestListPokemonUpstreamFailure(t *testing.T) {
+	// Arrange
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusServiceUnavailable, `{}`), nil
+	})}
+	adapter := pokemoninfra.NewAdapter(client, pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+
+	// Act
+	_, _, err := adapter.ListPokemon(context.Background(), 0, 20)
+
+	// Assert
+	var upstream pokemonapp.UpstreamError
+	if !errors.As(err, &upstream) || upstream.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected upstream error with status 503, got %v", err)
+	}
+}
+
+// TestGetRandomPokemonRejectsNonPositiveCount avoids panicking on bad count data.
+// Arrange: table-drive count responses of zero, negative and missing values.
+// Act: fetch a random Pokemon.
+// Assert: expect an UpstreamError instead of a panic.
+func TestGetRandomPokemonRejectsNonPositiveCount(t *testing.T) {
+	for _, body := range []string{`{"count": 0}`, `{"count": -3}`, `{}`} {
+		t.Run(body, func(t *testing.T) {
+			// Arrange
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				if !strings.Contains(req.URL.Path, "pokemon-species") {
+					t.Fatalf("expected no Pokemon lookup, got %s", req.URL)
+				}
+				return jsonResponse(http.StatusOK, body), nil
+			})}
+			adapter := pokemoninfra.NewAdapter(client, pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+
+			// Act
+			pokemon, err := adapter.GetRandomPokemon(context.Background())
+
+			// Assert
+			if pokemon != nil || !pokemonapp.IsUpstreamError(err) {
+				t.Fatalf("expected an upstream error, got %v and %v", pokemon, err)
+			}
+		})
+	}
+}
+
+// TestRefreshCountUpdatesCachedCount picks up new Pokemon before the count expires.
+// Arrange: cache a count of 1, then have PokeAPI report 2.
+// Act: refresh the count and pick another random Pokemon.
+// Assert: expect the refreshed count returned, and the next pick served from the updated cache.
+func TestRefreshCountUpdatesCachedCount(t *testing.T) {
+	// Arrange
+	upstreamCount, countRequests := 1, 0
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "pokemon-species") {
+			countRequests++
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"count": %d}`, upstreamCount)), nil
+		}
+		return jsonResponse(http.StatusOK, `{"name": "bulbasaur", "types": [{"type": {"name": "grass"}}]}`), nil
+	})}
+	adapter := pokemoninfra.NewAdapter(client,
+		pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+		pokemoninfra.WithCountCacheTTL(time.Hour),
+	)
+	if _, err := adapter.GetRandomPokemon(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	upstreamCount = 2
+
+	// Act
+	count, err := adapter.RefreshCount(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := adapter.GetRandomPokemon(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// Assert
+	if count != 2 {
+		t.Fatalf("expected refreshed count 2, got %d", count)
+	}
+	if countRequests != 2 {
+		t.Fatalf("expected only the initial fetch and the refresh to reach PokeAPI, got %d count requests", countRequests)
+	}
+}
+
+// typeStubClient serves a stubbed /type/fire listing and the Pokemon it names.
+func typeStubClient(t *testing.T, typeStatus int, typeBody string) *http.Client {
+	t.Helper()
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/api/v2/type/"):
+			return jsonResponse(typeStatus, typeBody), nil
+		case strings.HasPrefix(req.URL.Path, "/api/v2/pokemon/"):
+			name := strings.TrimPrefix(req.URL.Path, "/api/v2/pokemon/")
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"name": %q, "types": [{"type": {"name": "fire"}}]}`, name)), nil
+		}
+		t.Fatalf("unexpected request %s", req.URL)
+		return nil, nil
+	})}
+}
+
+// TestGetRandomPokemonByTypePicksFromTypeListing draws candidates from /type/{name}.
+// Arrange: stub the fire type listing and scope a seeded random source.
+// Act: pick a random fire Pokemon.
+// Assert: expect a Pokemon from the listing, fetched by name.
+func TestGetRandomPokemonByTypePicksFromTypeListing(t *testing.T) {
+	// Arrange
+	adapter := pokemoninfra.NewAdapter(typeStubClient(t, http.StatusOK, `{
+		"name": "fire",
+		"pokemon": [
+			{"pokemon": {"name": "charmander", "url": "https://pokeapi.co/api/v2/pokemon/4/"}, "slot": 1},
+			{"pokemon": {"name": "vulpix", "url": "https://pokeapi.co/api/v2/pokemon/37/"}, "slot": 1}
+		]
+	}`), pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+	ctx := pokemonapp.WithRandomSource(context.Background(), pokemonapp.NewSeededSource(1))
+
+	// Act
+	pokemon, err := adapter.GetRandomPokemonByType(ctx, "fire")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *pokemon.Name != "charmander" && *pokemon.Name != "vulpix" {
+		t.Fatalf("expected a fire Pokemon from the listing, got %q", *pokemon.Name)
+	}
+	if *pokemon.Type != "fire" {
+		t.Fatalf("expected type fire, got %q", *pokemon.Type)
+	}
+}
+
+// TestGetRandomPokemonByTypeNotFound maps unknown or empty types onto NotFoundError.
+// Arrange: table-drive a 404 from the type endpoint and a type without Pokemon.
+// Act: pick a random Pokemon of that type.
+// Assert: expect a NotFoundError.
+func TestGetRandomPokemonByTypeNotFound(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "unknown type", status: http.StatusNotFound, body: `Not Found`},
+		{name: "type without pokemon", status: http.StatusOK, body: `{"name": "shadow", "pokemon": []}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			adapter := pokemoninfra.NewAdapter(typeStubClient(t, tc.status, tc.body),
+				pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+
+			// Act
+			pokemon, err := adapter.GetRandomPokemonByType(context.Background(), "shadow")
+
+			// Assert
+			if pokemon != nil || !pokemonapp.IsNotFoundError(err) {
+				t.Fatalf("expected a NotFoundError, got %v and %v", pokemon, err)
+			}
+		})
+	}
+}
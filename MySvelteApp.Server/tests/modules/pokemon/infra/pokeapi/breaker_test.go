@@ -0,0 +1,180 @@
+package pokeapi_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// switchableUpstream answers with 503 until healthy is set, counting calls.
+type switchableUpstream struct {
+	healthy atomic.Bool
+	calls   atomic.Int32
+}
+
+func (u *switchableUpstream) client() *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		u.calls.Add(1)
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		if !u.healthy.Load() {
+			return jsonResponse(http.StatusServiceUnavailable, `{}`), nil
+		}
+		return jsonResponse(http.StatusOK, `{"name": "pikachu", "types": [], "sprites": {}}`), nil
+	})}
+}
+
+func newBreakerAdapter(upstream *switchableUpstream, cooldown time.Duration) *pokemoninfra.Adapter {
+	return pokemoninfra.NewAdapter(upstream.client(),
+		pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+		pokemoninfra.WithCircuitBreaker(3, cooldown),
+		pokemoninfra.WithLookupCache(0, 0),
+	)
+}
+
+// TestCircuitBreakerOpensAfterConsecutiveFailures stops hammering a failing upstream.
+// Arrange: point the adapter at an upstream that always returns 503.
+// Act: make more calls than the failure threshold.
+// Assert: expect UpstreamErrors throughout but only threshold calls to reach upstream.
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	adapter := newBreakerAdapter(upstream, time.Hour)
+
+	// Act
+	var errs []error
+	for i := 0; i < 5; i++ {
+		_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+		errs = append(errs, err)
+	}
+
+	// Assert
+	for i, err := range errs {
+		if !pokemonapp.IsUpstreamError(err) {
+			t.Fatalf("expected upstream error on call %d, got %v", i+1, err)
+		}
+	}
+	if calls := upstream.calls.Load(); calls != 3 {
+		t.Fatalf("expected 3 upstream calls before the breaker opened, got %d", calls)
+	}
+}
+
+// TestCircuitBreakerRecoversAfterCooldown lets a trial call through once cooled down.
+// Arrange: trip the breaker, then heal the upstream.
+// Act: wait out the cooldown and call again.
+// Assert: expect the trial call to succeed and the breaker to stay closed.
+func TestCircuitBreakerRecoversAfterCooldown(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	adapter := newBreakerAdapter(upstream, 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+	}
+	upstream.healthy.Store(true)
+	if _, err := adapter.GetPokemonByName(context.Background(), "pikachu"); !pokemonapp.IsUpstreamError(err) {
+		t.Fatalf("expected open breaker to reject the call, got %v", err)
+	}
+
+	// Act
+	time.Sleep(30 * time.Millisecond)
+	_, trialErr := adapter.GetPokemonByName(context.Background(), "pikachu")
+	_, nextErr := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	if trialErr != nil || nextErr != nil {
+		t.Fatalf("expected calls to succeed after cooldown, got %v and %v", trialErr, nextErr)
+	}
+	if calls := upstream.calls.Load(); calls != 5 {
+		t.Fatalf("expected 5 upstream calls, got %d", calls)
+	}
+}
+
+// TestCircuitBreakerReopensWhenTrialFails keeps rejecting while upstream stays down.
+// Arrange: trip the breaker against a permanently failing upstream.
+// Act: wait out the cooldown, make a trial call, then call again immediately.
+// Assert: expect only the trial call to reach upstream.
+func TestCircuitBreakerReopensWhenTrialFails(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	adapter := newBreakerAdapter(upstream, 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+	}
+
+	// Act
+	time.Sleep(30 * time.Millisecond)
+	_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+	_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	if !pokemonapp.IsUpstreamError(err) {
+		t.Fatalf("expected upstream error, got %v", err)
+	}
+	if calls := upstream.calls.Load(); calls != 4 {
+		t.Fatalf("expected 4 upstream calls, got %d", calls)
+	}
+}
+
+// TestCircuitBreakerIgnoresCallerCancellation does not blame upstream for callers giving up.
+// Arrange: build a breaker adapter and a context that is already cancelled.
+// Act: make more cancelled calls than the failure threshold, then a live call.
+// Assert: expect every call to reach upstream and the live call to succeed.
+func TestCircuitBreakerIgnoresCallerCancellation(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	upstream.healthy.Store(true)
+	adapter := newBreakerAdapter(upstream, time.Hour)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	for i := 0; i < 4; i++ {
+		_, _ = adapter.GetPokemonByName(cancelled, "pikachu")
+	}
+	_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the breaker to stay closed, got %v", err)
+	}
+	if calls := upstream.calls.Load(); calls != 5 {
+		t.Fatalf("expected 5 upstream calls, got %d", calls)
+	}
+}
+
+// TestCircuitBreakerReleasesCancelledTrial lets another call be the trial.
+// Arrange: trip the breaker, heal the upstream and wait out the cooldown.
+// Act: make the trial call with a cancelled context, then a live call.
+// Assert: expect the live call to be allowed through and succeed.
+func TestCircuitBreakerReleasesCancelledTrial(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	adapter := newBreakerAdapter(upstream, 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+	}
+	upstream.healthy.Store(true)
+	time.Sleep(30 * time.Millisecond)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	_, _ = adapter.GetPokemonByName(cancelled, "pikachu")
+	_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected the next call to become the trial, got %v", err)
+	}
+	if calls := upstream.calls.Load(); calls != 5 {
+		t.Fatalf("expected 5 upstream calls, got %d", calls)
+	}
+}
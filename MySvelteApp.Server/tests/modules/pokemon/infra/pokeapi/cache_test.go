@@ -0,0 +1,141 @@
+package pokeapi_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// newCountingAdapter serves every lookup successfully and counts HTTP calls.
+func newCountingAdapter(calls *atomic.Int64, opts ...pokemoninfra.Option) *pokemoninfra.Adapter {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		name := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		return jsonResponse(http.StatusOK, `{"name": "`+name+`", "types": [{"type": {"name": "electric"}}], "sprites": {}}`), nil
+	})}
+	opts = append([]pokemoninfra.Option{pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer(""))}, opts...)
+	return pokemoninfra.NewAdapter(client, opts...)
+}
+
+// TestGetPokemonByNameServesRepeatLookupsFromCache spares PokeAPI repeat requests.
+// Arrange: build an adapter with a lookup cache and a counting client.
+// Act: look the same Pokemon up twice, the second time with different casing.
+// Assert: expect one HTTP call and the same Pokemon both times.
+func TestGetPokemonByNameServesRepeatLookupsFromCache(t *testing.T) {
+	// Arrange
+	var calls atomic.Int64
+	adapter := newCountingAdapter(&calls, pokemoninfra.WithLookupCache(8, time.Minute))
+
+	// Act
+	first, firstErr := adapter.GetPokemonByName(context.Background(), "pikachu")
+	second, secondErr := adapter.GetPokemonByName(context.Background(), "Pikachu")
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("expected no errors, got %v and %v", firstErr, secondErr)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected 1 HTTP call, got %d", got)
+	}
+	if *second.Name != *first.Name || *second.Type != "electric" {
+		t.Fatalf("expected the cached Pokemon, got %+v", second)
+	}
+}
+
+// TestGetPokemonByNameCacheExpiryAndEviction refetches stale or evicted entries.
+// Arrange: table-drive a short TTL and a single-entry cache.
+// Act: look Pokemon up around the expiry or eviction.
+// Assert: expect the affected lookup to reach the HTTP client again.
+func TestGetPokemonByNameCacheExpiryAndEviction(t *testing.T) {
+	testCases := []struct {
+		name    string
+		option  pokemoninfra.Option
+		lookups func(adapter *pokemoninfra.Adapter)
+		calls   int64
+	}{
+		{
+			name:   "expired entry",
+			option: pokemoninfra.WithLookupCache(8, time.Millisecond),
+			lookups: func(adapter *pokemoninfra.Adapter) {
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+				time.Sleep(5 * time.Millisecond)
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+			},
+			calls: 2,
+		},
+		{
+			name:   "least recently used evicted",
+			option: pokemoninfra.WithLookupCache(1, time.Minute),
+			lookups: func(adapter *pokemoninfra.Adapter) {
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+				_, _ = adapter.GetPokemonByName(context.Background(), "eevee")
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+			},
+			calls: 3,
+		},
+		{
+			name:   "disabled cache",
+			option: pokemoninfra.WithLookupCache(0, time.Minute),
+			lookups: func(adapter *pokemoninfra.Adapter) {
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+				_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+			},
+			calls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var calls atomic.Int64
+			adapter := newCountingAdapter(&calls, tc.option)
+
+			// Act
+			tc.lookups(adapter)
+
+			// Assert
+			if got := calls.Load(); got != tc.calls {
+				t.Fatalf("expected %d HTTP calls, got %d", tc.calls, got)
+			}
+		})
+	}
+}
+
+// TestGetPokemonByNameCacheIsConcurrencySafe exercises the cache from many goroutines.
+// Arrange: build an adapter with a small cache.
+// Act: look up a handful of names concurrently.
+// Assert: expect every lookup to succeed (run with -race to check for data races).
+func TestGetPokemonByNameCacheIsConcurrencySafe(t *testing.T) {
+	// Arrange
+	var calls atomic.Int64
+	adapter := newCountingAdapter(&calls, pokemoninfra.WithLookupCache(2, time.Minute))
+	names := []string{"pikachu", "eevee", "snorlax"}
+
+	// Act
+	var wg sync.WaitGroup
+	errs := make(chan error, 60)
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, err := adapter.GetPokemonByName(context.Background(), name); err != nil {
+				errs <- err
+			}
+		}(names[i%len(names)])
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	for err := range errs {
+		t.Fatalf("expected concurrent lookups to succeed, got %v", err)
+	}
+}
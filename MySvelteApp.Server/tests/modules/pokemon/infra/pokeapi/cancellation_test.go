@@ -0,0 +1,88 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// serverClient sends every PokeAPI request to server instead, keeping the path.
+func serverClient(server *httptest.Server) *http.Client {
+	target, _ := url.Parse(server.URL)
+	transport := server.Client().Transport
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		redirected := req.Clone(req.Context())
+		redirected.URL.Scheme, redirected.URL.Host = target.Scheme, target.Host
+		return transport.RoundTrip(redirected)
+	})}
+}
+
+// slowResponse is how long the stalled request takes to be answered.
+const slowResponse = 2 * time.Second
+
+// TestGetRandomPokemonHonorsCancellation stops waiting on PokeAPI once the caller gives up.
+// Arrange: table-drive a slow server that stalls either the count or the detail
+// request for slowResponse.
+// Act: fetch a random Pokemon and cancel the context once the stalled request arrives.
+// Assert: expect context.Canceled well before the server would have answered.
+func TestGetRandomPokemonHonorsCancellation(t *testing.T) {
+	testCases := []struct {
+		name  string
+		stall string
+	}{
+		{name: "count", stall: "pokemon-species"},
+		{name: "detail", stall: "/pokemon/"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			stalled := make(chan struct{})
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !strings.Contains(r.URL.Path, tc.stall) {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"count": 1}`))
+					return
+				}
+				close(stalled)
+				// Answer late rather than never, so an adapter that ignores
+				// cancellation fails the timing check instead of hanging.
+				select {
+				case <-release:
+				case <-time.After(slowResponse):
+				}
+			}))
+			t.Cleanup(server.Close)
+			t.Cleanup(func() { close(release) })
+			adapter := pokemoninfra.NewAdapter(serverClient(server), pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-stalled
+				cancel()
+			}()
+			start := time.Now()
+
+			// Act
+			pokemon, err := adapter.GetRandomPokemon(ctx)
+
+			// Assert
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %+v and %v", pokemon, err)
+			}
+			if elapsed := time.Since(start); elapsed >= slowResponse/2 {
+				t.Fatalf("expected the call to return promptly after cancellation, took %s", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// fakeDoer answers every request with the configured response or error.
+type fakeDoer struct {
+	resp *http.Response
+	err  error
+}
+
+func (f fakeDoer) Do(*http.Request) (*http.Response, error) {
+	return f.resp, f.err
+}
+
+// TestGetPokemonByNameWithFakeDoer maps doer results onto adapter errors.
+// Arrange: table-drive a fake doer returning malformed JSON or a transport error.
+// Act: look a Pokemon up by name.
+// Assert: expect a decode error for bad JSON and an UpstreamError wrapping the transport error.
+func TestGetPokemonByNameWithFakeDoer(t *testing.T) {
+	errConnReset := errors.New("connection reset by peer")
+	testCases := []struct {
+		name     string
+		doer     fakeDoer
+		upstream bool
+		message  string
+	}{
+		{
+			name:    "json decode failure",
+			doer:    fakeDoer{resp: jsonResponse(http.StatusOK, `{"name": `)},
+			message: "failed to deserialize Pokemon data",
+		},
+		{
+			name:     "transport error",
+			doer:     fakeDoer{err: errConnReset},
+			upstream: true,
+			message:  errConnReset.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			adapter := pokemoninfra.NewAdapter(tc.doer,
+				pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+				pokemoninfra.WithLookupCache(0, 0),
+			)
+
+			// Act
+			pokemon, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+			// Assert
+			if err == nil {
+				t.Fatalf("expected an error, got %+v", pokemon)
+			}
+			if pokemonapp.IsUpstreamError(err) != tc.upstream {
+				t.Fatalf("expected upstream error %t, got %v", tc.upstream, err)
+			}
+			if !strings.Contains(err.Error(), tc.message) {
+				t.Fatalf("expected error mentioning %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace/noop"
+
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// recordingClient answers every request with a Pokemon and records when each
+// one was sent.
+type recordingClient struct {
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+func (c *recordingClient) Do(*http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.sent = append(c.sent, time.Now())
+	c.mu.Unlock()
+	return jsonResponse(http.StatusOK, `{"name": "pikachu", "types": [], "sprites": {}}`), nil
+}
+
+// TestRateLimitSpacesBursts keeps concurrent lookups within the configured rate.
+// Arrange: limit the adapter to 20 requests per second.
+// Act: look up 25 Pokemon concurrently.
+// Assert: expect the first 20 sent at once and each later one at least 50ms
+// (one token) after the one before it.
+func TestRateLimitSpacesBursts(t *testing.T) {
+	// Arrange
+	const rate, calls = 20, 25
+	interval := time.Second / rate
+	client := &recordingClient{}
+	adapter := pokemoninfra.NewAdapter(client,
+		pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+		pokemoninfra.WithLookupCache(0, 0),
+		pokemoninfra.WithRateLimit(rate),
+	)
+
+	// Act
+	var wg sync.WaitGroup
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := adapter.GetPokemonByName(context.Background(), "pikachu"); err != nil {
+				t.Errorf("expected lookup to succeed, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	sent := slices.SortedFunc(slices.Values(client.sent), time.Time.Compare)
+	if len(sent) != calls {
+		t.Fatalf("expected %d requests, got %d", calls, len(sent))
+	}
+	if burst := sent[rate-1].Sub(sent[0]); burst >= interval {
+		t.Fatalf("expected the first %d requests to go out together, spread over %s", rate, burst)
+	}
+	// Allow for timer and clock granularity when comparing gaps.
+	const slack = 5 * time.Millisecond
+	for i := rate; i < calls; i++ {
+		if gap := sent[i].Sub(sent[i-1]); gap < interval-slack {
+			t.Fatalf("expected request %d at least %s after the previous one, got %s", i+1, interval, gap)
+		}
+	}
+}
+
+// TestRateLimitWaitHonorsCancellation gives up waiting for a token with the caller.
+// Arrange: limit the adapter to 1 request per second and spend the token.
+// Act: look up another Pokemon with a context that ends after 20ms.
+// Assert: expect context.DeadlineExceeded promptly and no second request sent.
+func TestRateLimitWaitHonorsCancellation(t *testing.T) {
+	// Arrange
+	client := &recordingClient{}
+	adapter := pokemoninfra.NewAdapter(client,
+		pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+		pokemoninfra.WithLookupCache(0, 0),
+		pokemoninfra.WithRateLimit(1),
+	)
+	if _, err := adapter.GetPokemonByName(context.Background(), "pikachu"); err != nil {
+		t.Fatalf("expected the first lookup to succeed, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+
+	// Act
+	_, err := adapter.GetPokemonByName(ctx, "pikachu")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 500*time.Millisecond {
+		t.Fatalf("expected the wait to end with the context, took %s", elapsed)
+	}
+	if len(client.sent) != 1 {
+		t.Fatalf("expected only the first request to be sent, got %d", len(client.sent))
+	}
+}
+
+// TestRateLimitCancelledWaitKeepsBreakerTrial does not strand the breaker half-open.
+// Arrange: trip a one-failure breaker under a 1 request per second limit,
+// heal the upstream and wait out the cooldown.
+// Act: give up waiting for a token, then look up again without a deadline.
+// Assert: expect the second lookup to reach the healed upstream and succeed.
+func TestRateLimitCancelledWaitKeepsBreakerTrial(t *testing.T) {
+	// Arrange
+	upstream := &switchableUpstream{}
+	adapter := pokemoninfra.NewAdapter(upstream.client(),
+		pokemoninfra.WithTracer(noop.NewTracerProvider().Tracer("")),
+		pokemoninfra.WithLookupCache(0, 0),
+		pokemoninfra.WithCircuitBreaker(1, 10*time.Millisecond),
+		pokemoninfra.WithRateLimit(1),
+	)
+	_, _ = adapter.GetPokemonByName(context.Background(), "pikachu")
+	upstream.healthy.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, waitErr := adapter.GetPokemonByName(ctx, "pikachu")
+	_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	if !errors.Is(waitErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the first lookup to give up waiting, got %v", waitErr)
+	}
+	if err != nil {
+		t.Fatalf("expected the breaker to allow a trial call, got %v", err)
+	}
+	if calls := upstream.calls.Load(); calls != 2 {
+		t.Fatalf("expected 2 upstream calls, got %d", calls)
+	}
+}
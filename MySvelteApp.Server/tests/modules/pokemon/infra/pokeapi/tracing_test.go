@@ -0,0 +1,61 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	pokemonapp "mysvelteapp/server_new/internal/modules/pokemon/app"
+	pokemoninfra "mysvelteapp/server_new/internal/modules/pokemon/infra/pokeapi"
+)
+
+// TestGetPokemonByNameRecordsUpstreamFailureOnSpan traces failed PokeAPI calls.
+// Arrange: record spans from an adapter whose upstream answers 503.
+// Act: look up a Pokemon by name.
+// Assert: expect one client span carrying the URL and status code, an error status and a recorded exception.
+func TestGetPokemonByNameRecordsUpstreamFailureOnSpan(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(http.StatusServiceUnavailable, `{}`), nil
+	})}
+	adapter := pokemoninfra.NewAdapter(client, pokemoninfra.WithTracer(provider.Tracer("pokeapi-test")))
+
+	// Act
+	_, err := adapter.GetPokemonByName(context.Background(), "pikachu")
+
+	// Assert
+	var upstream pokemonapp.UpstreamError
+	if !errors.As(err, &upstream) || upstream.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected an upstream 503 error, got %v", err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "pokeapi.GetPokemon" {
+		t.Fatalf("expected span pokeapi.GetPokemon, got %q", span.Name())
+	}
+	attrs := attribute.NewSet(span.Attributes()...)
+	if value, ok := attrs.Value("url.full"); !ok || value.AsString() != "https://pokeapi.co/api/v2/pokemon/pikachu" {
+		t.Fatalf("expected url.full to name the pikachu endpoint, got %v", value.Emit())
+	}
+	if value, ok := attrs.Value("http.response.status_code"); !ok || value.AsInt64() != http.StatusServiceUnavailable {
+		t.Fatalf("expected http.response.status_code=503, got %v", value.Emit())
+	}
+	if span.Status().Code != codes.Error {
+		t.Fatalf("expected error status, got %v", span.Status().Code)
+	}
+	events := span.Events()
+	if len(events) != 1 || events[0].Name != "exception" {
+		t.Fatalf("expected the error to be recorded as an exception event, got %+v", events)
+	}
+}
@@ -0,0 +1,587 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/config"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.env")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("expected config file to be written, got %v", err)
+	}
+	return path
+}
+
+// TestLoadReadsValuesFromConfigFile applies file values over defaults.
+// Arrange: point CONFIG_FILE at a dotenv file setting the port and issuer.
+// Act: load the configuration.
+// Assert: expect the file values to be used.
+func TestLoadReadsValuesFromConfigFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "# local overrides\nSERVER_PORT=9090\nexport JWT_ISSUER=\"file-issuer\"\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SERVER_PORT", "")
+	t.Setenv("JWT_ISSUER", "")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Port != "9090" {
+		t.Fatalf("expected port from file, got %q", cfg.Port)
+	}
+	if cfg.JWTIssuer != "file-issuer" {
+		t.Fatalf("expected unquoted issuer from file, got %q", cfg.JWTIssuer)
+	}
+}
+
+// TestLoadPrefersEnvironmentOverConfigFile keeps env vars authoritative.
+// Arrange: set the port in both the file and the environment.
+// Act: load the configuration.
+// Assert: expect the environment value to win.
+func TestLoadPrefersEnvironmentOverConfigFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "SERVER_PORT=9090\nJWT_ACCESS_TOKEN_LIFETIME_HOURS=2\n")
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("SERVER_PORT", "7070")
+	t.Setenv("JWT_ACCESS_TOKEN_LIFETIME_HOURS", "")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Port != "7070" {
+		t.Fatalf("expected port from environment, got %q", cfg.Port)
+	}
+	if cfg.JWTAccessLifetimeHours != 2 {
+		t.Fatalf("expected lifetime from file, got %d", cfg.JWTAccessLifetimeHours)
+	}
+}
+
+// TestLoadFailsWhenConfigFileMissing surfaces a mistyped CONFIG_FILE.
+// Arrange: point CONFIG_FILE at a path that does not exist.
+// Act: load the configuration.
+// Assert: expect an error.
+func TestLoadFailsWhenConfigFileMissing(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.env"))
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected error for missing config file")
+	}
+}
+
+// TestLoadRejectsMalformedConfigFile reports lines without a separator.
+// Arrange: write a file containing a line with no "=".
+// Act: load the configuration.
+// Assert: expect an error.
+func TestLoadRejectsMalformedConfigFile(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, "SERVER_PORT\n"))
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected error for malformed config file")
+	}
+}
+
+func validServer() config.Server {
+	return config.Server{
+		Port:                   "8080",
+		DatabaseDSN:            "file::memory:",
+		JWTKey:                 "secret",
+		JWTIssuer:              "issuer",
+		JWTAudiences:           []string{"audience"},
+		JWTAccessLifetimeHours: 1,
+		JWTClaimNames:          "legacy",
+		Environment:            config.EnvironmentDevelopment,
+		PokemonSource:          config.PokemonSourceOffline,
+		DBWriteRetryAttempts:   1,
+		AppBaseURL:             "http://localhost:5173",
+		IdempotencyTTL:         time.Hour,
+		EmailChangeTTL:         24 * time.Hour,
+	}
+}
+
+// TestServerValidateAcceptsValidConfig passes a fully populated config.
+// Arrange: build a valid server configuration.
+// Act: validate it.
+// Assert: expect no error.
+func TestServerValidateAcceptsValidConfig(t *testing.T) {
+	// Arrange
+	cfg := validServer()
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+// TestServerValidateReportsEveryProblem aggregates all invalid fields.
+// Arrange: break the port, DSN, lifetimes, environment, app base URL and PokeAPI
+// rate together.
+// Act: validate the configuration.
+// Assert: expect each problem to be named in the error.
+func TestServerValidateReportsEveryProblem(t *testing.T) {
+	// Arrange
+	cfg := validServer()
+	cfg.Port = "http"
+	cfg.DatabaseDSN = " "
+	cfg.JWTAccessLifetimeHours = 0
+	cfg.JWTRememberMeHours = 200
+	cfg.JWTClaimNames = "dotnet"
+	cfg.Environment = "prod"
+	cfg.AppBaseURL = "app.example.com"
+	cfg.PokeAPIRequestsPerSec = -1
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	for _, key := range []string{"SERVER_PORT", "DATABASE_DSN", "JWT_ACCESS_TOKEN_LIFETIME_HOURS", "JWT_REMEMBER_ME_LIFETIME_HOURS", "JWT_CLAIM_NAMES", "ENVIRONMENT", "APP_BASE_URL", "POKEAPI_REQUESTS_PER_SECOND"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Fatalf("expected error to mention %s, got %v", key, err)
+		}
+	}
+}
+
+// TestLoadFailsFastOnInvalidPort validates configuration during Load.
+// Arrange: set a non-numeric SERVER_PORT.
+// Act: load the configuration.
+// Assert: expect an error naming the port.
+func TestLoadFailsFastOnInvalidPort(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("SERVER_PORT", "eighty")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "SERVER_PORT") {
+		t.Fatalf("expected SERVER_PORT error, got %v", err)
+	}
+}
+
+// TestLoadRefusesDefaultJWTKeyInProduction blocks the sample key in production.
+// Arrange: select production without overriding JWT_KEY.
+// Act: load the configuration.
+// Assert: expect an error telling the operator to set JWT_KEY.
+func TestLoadRefusesDefaultJWTKeyInProduction(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("ENVIRONMENT", config.EnvironmentProduction)
+	t.Setenv("JWT_KEY", "")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "JWT_KEY") {
+		t.Fatalf("expected JWT_KEY error, got %v", err)
+	}
+}
+
+// TestLoadWarnsAboutDefaultJWTKeyInDevelopment allows the sample key locally.
+// Arrange: select development without overriding JWT_KEY.
+// Act: load the configuration and collect its warnings.
+// Assert: expect success with a warning about JWT_KEY.
+func TestLoadWarnsAboutDefaultJWTKeyInDevelopment(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("ENVIRONMENT", config.EnvironmentDevelopment)
+	t.Setenv("JWT_KEY", "")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "JWT_KEY") {
+		t.Fatalf("expected a JWT_KEY warning, got %v", warnings)
+	}
+}
+
+// TestServerWarningsEmptyForCustomJWTKey stays quiet once a key is set.
+// Arrange: build a production config with a custom key.
+// Act: validate it and collect warnings.
+// Assert: expect no error and no warnings.
+func TestServerWarningsEmptyForCustomJWTKey(t *testing.T) {
+	// Arrange
+	cfg := validServer()
+	cfg.Environment = config.EnvironmentProduction
+
+	// Act
+	err := cfg.Validate()
+	warnings := cfg.Warnings()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", warnings)
+	}
+}
+
+// TestLoadSplitsAudienceList reads comma-separated audiences.
+// Arrange: set JWT_AUDIENCE to a padded list with a blank entry.
+// Act: load the configuration.
+// Assert: expect the trimmed, non-empty audiences in order.
+func TestLoadSplitsAudienceList(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("JWT_AUDIENCE", " web, mobile ,,")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Join(cfg.JWTAudiences, "|") != "web|mobile" {
+		t.Fatalf("expected [web mobile], got %v", cfg.JWTAudiences)
+	}
+}
+
+// TestLogEffectiveMasksSecrets shows the boot configuration without leaking credentials.
+// Arrange: configure current and previous JWT keys, a seed password and DSN password, and capture JSON logs.
+// Act: call LogEffective.
+// Assert: expect plain settings to be logged and none of the secrets to appear verbatim.
+func TestLogEffectiveMasksSecrets(t *testing.T) {
+	// Arrange
+	cfg := config.Server{
+		Port:              "9090",
+		JWTKey:            "super-secret-signing-key-value-123",
+		JWTPreviousKeys:   []string{"retired-signing-key-value-456"},
+		SeedAdminPassword: "Seed-Password-1",
+		DatabaseDSN:       "file:app.db?_auth&_auth_user=admin&_auth_pass=dsn-password-9",
+		JWTLeeway:         30 * time.Second,
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	// Act
+	cfg.LogEffective(logger)
+
+	// Assert
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected one JSON log entry, got %q (%v)", buf.String(), err)
+	}
+	output := buf.String()
+	for _, secret := range []string{cfg.JWTKey, cfg.JWTPreviousKeys[0], cfg.SeedAdminPassword, "dsn-password-9"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("expected %q to be masked, got %s", secret, output)
+		}
+	}
+	if entry["port"] != "9090" || entry["jwt_leeway"] != "30s" {
+		t.Fatalf("expected plain settings to be logged, got %v", entry)
+	}
+	if dsn, _ := entry["database_dsn"].(string); !strings.Contains(dsn, "_auth_user=admin") {
+		t.Fatalf("expected the DSN with only its password masked, got %q", dsn)
+	}
+	if key, _ := entry["jwt_key"].(string); !strings.Contains(key, "34 chars") {
+		t.Fatalf("expected the JWT key length to be reported, got %q", key)
+	}
+}
+
+// TestLoadMergesReservedUsernamesFromFile combines the inline list with a file.
+// Arrange: set AUTH_RESERVED_USERNAMES and point AUTH_RESERVED_USERNAMES_FILE at a list with comments.
+// Act: load the configuration.
+// Assert: expect lowercase entries from both sources.
+func TestLoadMergesReservedUsernamesFromFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "# staff\nOwner\n\nbilling\n")
+	t.Setenv("AUTH_RESERVED_USERNAMES", "Root, admin")
+	t.Setenv("AUTH_RESERVED_USERNAMES_FILE", path)
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got, want := strings.Join(cfg.ReservedUsernames, ","), "root,admin,owner,billing"; got != want {
+		t.Fatalf("expected reserved usernames %q, got %q", want, got)
+	}
+}
+
+// TestLoadSwaggerDefaultsByEnvironment hides API docs in production unless opted in.
+// Arrange: table-drive environments with and without ENABLE_SWAGGER.
+// Act: load the configuration.
+// Assert: expect Swagger off only for production without an explicit opt-in.
+func TestLoadSwaggerDefaultsByEnvironment(t *testing.T) {
+	testCases := []struct {
+		name        string
+		environment string
+		flag        string
+		enabled     bool
+	}{
+		{name: "development default", environment: config.EnvironmentDevelopment, enabled: true},
+		{name: "production default", environment: config.EnvironmentProduction},
+		{name: "production opt-in", environment: config.EnvironmentProduction, flag: "true", enabled: true},
+		{name: "development opt-out", environment: config.EnvironmentDevelopment, flag: "false"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+			t.Setenv("ENVIRONMENT", tc.environment)
+			t.Setenv("JWT_KEY", strings.Repeat("k", 32))
+			t.Setenv("ENABLE_SWAGGER", tc.flag)
+
+			// Act
+			cfg, err := config.Load()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("expected config to load, got %v", err)
+			}
+			if cfg.SwaggerEnabled != tc.enabled {
+				t.Fatalf("expected SwaggerEnabled %t, got %t", tc.enabled, cfg.SwaggerEnabled)
+			}
+		})
+	}
+}
+
+// TestLoadReadsEmailSettings trims the app base URL and validates the template directory.
+// Arrange: set APP_BASE_URL with a trailing slash and EMAIL_TEMPLATES_DIR to an existing, then a missing, directory.
+// Act: load the configuration.
+// Assert: expect the slash dropped and only the missing directory rejected.
+func TestLoadReadsEmailSettings(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("APP_BASE_URL", "https://app.example.com/")
+	t.Setenv("EMAIL_TEMPLATES_DIR", dir)
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected config to load, got %v", err)
+	}
+	if cfg.AppBaseURL != "https://app.example.com" || cfg.EmailTemplatesDir != dir {
+		t.Fatalf("expected trimmed base URL and %q, got %q and %q", dir, cfg.AppBaseURL, cfg.EmailTemplatesDir)
+	}
+
+	// Arrange
+	t.Setenv("EMAIL_TEMPLATES_DIR", filepath.Join(dir, "missing"))
+
+	// Act
+	_, err = config.Load()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "EMAIL_TEMPLATES_DIR") {
+		t.Fatalf("expected EMAIL_TEMPLATES_DIR error, got %v", err)
+	}
+}
+
+// TestServerValidateRefusesSeededRandomInProduction keeps random picks unpredictable in production.
+// Arrange: enable POKEMON_SEEDED_RANDOM in production and, separately, in development.
+// Act: validate both configurations.
+// Assert: expect production to be refused and development to pass.
+func TestServerValidateRefusesSeededRandomInProduction(t *testing.T) {
+	// Arrange
+	production := validServer()
+	production.JWTKey = strings.Repeat("k", 32)
+	production.Environment = config.EnvironmentProduction
+	production.PokemonSeededRandom = true
+	development := validServer()
+	development.PokemonSeededRandom = true
+
+	// Act
+	productionErr := production.Validate()
+	developmentErr := development.Validate()
+
+	// Assert
+	if productionErr == nil || !strings.Contains(productionErr.Error(), "POKEMON_SEEDED_RANDOM") {
+		t.Fatalf("expected POKEMON_SEEDED_RANDOM error, got %v", productionErr)
+	}
+	if developmentErr != nil {
+		t.Fatalf("expected seeded random to be allowed in development, got %v", developmentErr)
+	}
+}
+
+// TestIndentsJSONOnlyInDevelopment keeps production responses compact.
+// Arrange: enable JSON_INDENT in development and in production.
+// Act: ask each configuration whether to indent.
+// Assert: expect indentation in development only, with a warning in production.
+func TestIndentsJSONOnlyInDevelopment(t *testing.T) {
+	// Arrange
+	development := validServer()
+	development.JSONIndent = true
+	production := validServer()
+	production.JSONIndent = true
+	production.Environment = config.EnvironmentProduction
+
+	// Act
+	developmentIndents := development.IndentsJSON()
+	productionIndents := production.IndentsJSON()
+
+	// Assert
+	if !developmentIndents {
+		t.Fatalf("expected JSON_INDENT to apply in development")
+	}
+	if productionIndents {
+		t.Fatalf("expected JSON_INDENT to be ignored in production")
+	}
+	if warnings := production.Warnings(); !strings.Contains(strings.Join(warnings, " "), "JSON_INDENT") {
+		t.Fatalf("expected a JSON_INDENT warning, got %v", warnings)
+	}
+}
+
+// TestServerValidateRequiresTrustedProxiesForForceHTTPS prevents redirect loops.
+// Arrange: enable FORCE_HTTPS without any trusted proxy.
+// Act: validate the configuration.
+// Assert: expect an error naming FORCE_HTTPS and TRUSTED_PROXIES.
+func TestServerValidateRequiresTrustedProxiesForForceHTTPS(t *testing.T) {
+	// Arrange
+	cfg := validServer()
+	cfg.ForceHTTPS = true
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "FORCE_HTTPS") || !strings.Contains(err.Error(), "TRUSTED_PROXIES") {
+		t.Fatalf("expected FORCE_HTTPS error, got %v", err)
+	}
+}
+
+// TestLoadReadsLogTimeSettings resolves layout names and rejects layouts without a time element.
+// Arrange: set LOG_TIME_FORMAT to a layout name and LOG_UTC to true, then to plain text.
+// Act: load the configuration.
+// Assert: expect the RFC3339 layout and UTC enabled, then a LOG_TIME_FORMAT error.
+func TestLoadReadsLogTimeSettings(t *testing.T) {
+	// Arrange
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, ""))
+	t.Setenv("LOG_TIME_FORMAT", "rfc3339")
+	t.Setenv("LOG_UTC", "true")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected config to load, got %v", err)
+	}
+	if cfg.LogTimeFormat != time.RFC3339 || !cfg.LogUTC {
+		t.Fatalf("expected RFC3339 in UTC, got %q and %t", cfg.LogTimeFormat, cfg.LogUTC)
+	}
+
+	// Arrange
+	t.Setenv("LOG_TIME_FORMAT", "iso")
+
+	// Act
+	_, err = config.Load()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "LOG_TIME_FORMAT") {
+		t.Fatalf("expected LOG_TIME_FORMAT error, got %v", err)
+	}
+}
+
+// TestServerValidateChecksSMTPSettings only requires SMTP settings once a host is set.
+// Arrange: table-drive SMTP settings with and without SMTP_HOST.
+// Act: validate each configuration.
+// Assert: expect complete settings to pass and each gap to be named.
+func TestServerValidateChecksSMTPSettings(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(cfg *config.Server)
+		wantKey string
+	}{
+		{name: "unset", modify: func(*config.Server) {}},
+		{name: "complete", modify: func(cfg *config.Server) {
+			cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom = "smtp.example.com", 587, "MySvelteApp <no-reply@example.com>"
+			cfg.SMTPUsername, cfg.SMTPPassword = "mailer", "secret"
+		}},
+		{name: "missing from", wantKey: "SMTP_FROM", modify: func(cfg *config.Server) {
+			cfg.SMTPHost, cfg.SMTPPort = "smtp.example.com", 587
+		}},
+		{name: "bad port", wantKey: "SMTP_PORT", modify: func(cfg *config.Server) {
+			cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom = "smtp.example.com", 0, "no-reply@example.com"
+		}},
+		{name: "username without password", wantKey: "SMTP_PASSWORD", modify: func(cfg *config.Server) {
+			cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom, cfg.SMTPUsername = "smtp.example.com", 587, "no-reply@example.com", "mailer"
+		}},
+		{name: "from without host", wantKey: "SMTP_HOST", modify: func(cfg *config.Server) {
+			cfg.SMTPFrom = "no-reply@example.com"
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			cfg := validServer()
+			tc.modify(&cfg)
+
+			// Act
+			err := cfg.Validate()
+
+			// Assert
+			if tc.wantKey == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tc.wantKey != "" && (err == nil || !strings.Contains(err.Error(), tc.wantKey)) {
+				t.Fatalf("expected error to mention %s, got %v", tc.wantKey, err)
+			}
+		})
+	}
+}
+
+// TestServerValidateExplainsMissingMetricsExporters points OTLP users at /metrics.
+// Arrange: set OTEL_METRICS_EXPORTER to otlp.
+// Act: validate the configuration.
+// Assert: expect an error naming the setting and the prometheus alternative.
+func TestServerValidateExplainsMissingMetricsExporters(t *testing.T) {
+	// Arrange
+	cfg := validServer()
+	cfg.MetricsExporter = "otlp"
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "OTEL_METRICS_EXPORTER") || !strings.Contains(err.Error(), config.MetricsExporterPrometheus) {
+		t.Fatalf("expected OTEL_METRICS_EXPORTER error suggesting prometheus, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package email_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/email"
+)
+
+// TestMessageValidateRejectsHeaderInjection keeps callers from adding headers.
+// Arrange: table-drive messages with and without line breaks in their headers.
+// Act: validate each.
+// Assert: expect only the clean message to pass.
+func TestMessageValidateRejectsHeaderInjection(t *testing.T) {
+	testCases := []struct {
+		name  string
+		msg   email.Message
+		valid bool
+	}{
+		{name: "clean", msg: email.Message{To: "ash@example.com", Subject: "Hello"}, valid: true},
+		{name: "missing recipient", msg: email.Message{Subject: "Hello"}},
+		{name: "injected recipient", msg: email.Message{To: "ash@example.com\r\nBcc: eve@example.com", Subject: "Hello"}},
+		{name: "injected subject", msg: email.Message{To: "ash@example.com", Subject: "Hello\nBcc: eve@example.com"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := tc.msg.Validate()
+
+			// Assert
+			if (err == nil) != tc.valid {
+				t.Fatalf("expected valid %t, got %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+// TestLogSenderLogsMessage notes development emails in the log without their body.
+// Arrange: build a LogSender writing to a buffer.
+// Act: send a message whose body carries a token.
+// Assert: expect the recipient and subject in the log, but not the token.
+func TestLogSenderLogsMessage(t *testing.T) {
+	// Arrange
+	var logs bytes.Buffer
+	sender := email.NewLogSender(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	// Act
+	err := sender.Send(context.Background(), email.Message{To: "ash@example.com", Subject: "Hello", Body: "token=abc123"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected message to be logged, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "to=ash@example.com") || !strings.Contains(logs.String(), "subject=Hello") {
+		t.Fatalf("expected recipient and subject in log, got %q", logs.String())
+	}
+	if strings.Contains(logs.String(), "abc123") {
+		t.Fatalf("expected the body to stay out of the log, got %q", logs.String())
+	}
+}
+
+// serveFakeSMTP accepts one SMTP session and returns the DATA it received.
+func serveFakeSMTP(t *testing.T) (port int, received <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected listener, got %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	data := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+			case "EHLO", "HELO":
+				_ = text.PrintfLine("250 fake")
+			case "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				body, _ := text.ReadDotBytes()
+				data <- string(body)
+				_ = text.PrintfLine("250 queued")
+			case "QUIT":
+				_ = text.PrintfLine("221 bye")
+				return
+			default:
+				_ = text.PrintfLine("250 ok")
+			}
+		}
+	}()
+	_, portText, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ = strconv.Atoi(portText)
+	return port, data
+}
+
+// TestSMTPSenderDeliversMessage speaks SMTP to the configured relay.
+// Arrange: start a fake SMTP server and point a sender at it.
+// Act: send a message.
+// Assert: expect the relay to receive the headers and body.
+func TestSMTPSenderDeliversMessage(t *testing.T) {
+	// Arrange
+	port, received := serveFakeSMTP(t)
+	sender, err := email.NewSMTPSender(email.SMTPConfig{Host: "127.0.0.1", Port: port, From: "noreply@example.com"})
+	if err != nil {
+		t.Fatalf("expected sender, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Act
+	err = sender.Send(ctx, email.Message{To: "ash@example.com", Subject: "Reset your password", Body: "Open https://app.example.com/reset"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected message to be delivered, got %v", err)
+	}
+	message, err := textproto.NewReader(bufio.NewReader(strings.NewReader(<-received))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("expected message headers, got %v", err)
+	}
+	if message.Get("From") != "noreply@example.com" || message.Get("To") != "ash@example.com" || message.Get("Subject") != "Reset your password" {
+		t.Fatalf("expected From, To and Subject headers, got %v", message)
+	}
+}
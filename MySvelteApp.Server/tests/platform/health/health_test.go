@@ -0,0 +1,166 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/health"
+)
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("unreachable") }
+
+func serveHealth(t *testing.T, checker *health.Checker) (*httptest.ResponseRecorder, health.Report) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/health", health.Handler(checker))
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var report health.Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON report, got %q", recorder.Body.String())
+	}
+	return recorder, report
+}
+
+// TestHealthHandlerReportsHealthyComponents lists every passing check.
+// Arrange: build a checker with two passing critical checks.
+// Act: request /health.
+// Assert: expect 200 and both components up.
+func TestHealthHandlerReportsHealthyComponents(t *testing.T) {
+	// Arrange
+	checker := health.NewChecker(
+		health.Check{Name: "database", Critical: true, Run: passing},
+		health.Check{Name: "token_key", Critical: true, Run: passing},
+	)
+
+	// Act
+	recorder, report := serveHealth(t, checker)
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if report.Status != health.StatusUp || len(report.Components) != 2 {
+		t.Fatalf("expected 2 healthy components, got %+v", report)
+	}
+}
+
+// TestHealthHandlerFailsOnCriticalCheck flips readiness for critical failures.
+// Arrange: build a checker whose database check fails.
+// Act: request /health.
+// Assert: expect 503 with the database component down and its error reported.
+func TestHealthHandlerFailsOnCriticalCheck(t *testing.T) {
+	// Arrange
+	checker := health.NewChecker(health.Check{Name: "database", Critical: true, Run: failing})
+
+	// Act
+	recorder, report := serveHealth(t, checker)
+
+	// Assert
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", recorder.Code)
+	}
+	if report.Components[0].Status != health.StatusDown || report.Components[0].Error == "" {
+		t.Fatalf("expected database down with an error, got %+v", report.Components[0])
+	}
+}
+
+// TestHealthHandlerToleratesNonCriticalFailure keeps upstream outages from flipping readiness.
+// Arrange: build a checker with a passing database and a failing pokeapi check.
+// Act: request /health.
+// Assert: expect 200 while still reporting pokeapi as down.
+func TestHealthHandlerToleratesNonCriticalFailure(t *testing.T) {
+	// Arrange
+	checker := health.NewChecker(
+		health.Check{Name: "database", Critical: true, Run: passing},
+		health.Check{Name: "pokeapi", Run: failing},
+	)
+
+	// Act
+	recorder, report := serveHealth(t, checker)
+
+	// Assert
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if report.Components[1].Name != "pokeapi" || report.Components[1].Status != health.StatusDown {
+		t.Fatalf("expected pokeapi down, got %+v", report.Components[1])
+	}
+}
+
+// TestCachedCheckDoesNotBlockOnProbe serves cached results while refreshing.
+// Arrange: wrap a probe that counts calls in a CachedCheck with a long TTL.
+// Act: run the check until the first background refresh lands, then run again.
+// Assert: expect pending before the first result and a single probe call overall.
+func TestCachedCheckDoesNotBlockOnProbe(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	check := health.NewCachedCheck(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}, time.Hour)
+
+	// Act
+	first := check.Run(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() == 0 || check.Run(context.Background()) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected background refresh to complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Assert
+	if !errors.Is(first, health.ErrCheckPending) {
+		t.Fatalf("expected pending result before the first probe, got %v", first)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single probe within the TTL, got %d", calls.Load())
+	}
+}
+
+// TestReadyHandlerFlipsWhenDraining tells load balancers to stop routing as shutdown begins.
+// Arrange: build a readiness flag and a checker whose dependencies all pass.
+// Act: probe /readyz before and after draining starts.
+// Assert: expect 200 first, then 503 with the draining status.
+func TestReadyHandlerFlipsWhenDraining(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	readiness := health.NewReadiness()
+	engine := gin.New()
+	engine.GET("/readyz", health.ReadyHandler(readiness, health.NewChecker(health.Check{Name: "database", Critical: true, Run: passing})))
+	probe := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return recorder
+	}
+	before := probe()
+
+	// Act
+	readiness.StartDraining()
+	after := probe()
+
+	// Assert
+	if before.Code != http.StatusOK {
+		t.Fatalf("expected status 200 before draining, got %d", before.Code)
+	}
+	var report health.Report
+	if err := json.Unmarshal(after.Body.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON report, got %q", after.Body.String())
+	}
+	if after.Code != http.StatusServiceUnavailable || report.Status != health.StatusDraining {
+		t.Fatalf("expected 503 %s once draining, got %d %+v", health.StatusDraining, after.Code, report)
+	}
+}
@@ -0,0 +1,73 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// TestClientIPResolver honours forwarded headers only from trusted proxies.
+// Arrange: table-drive trusted ranges, proxy counts, remote addresses and X-Forwarded-For chains.
+// Act: resolve the client address.
+// Assert: expect spoofed headers from untrusted peers to be ignored.
+func TestClientIPResolver(t *testing.T) {
+	testCases := []struct {
+		name       string
+		trusted    []string
+		proxyCount int
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "spoofed header from untrusted peer", remoteAddr: "203.0.113.7:5000", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "spoofed header from peer outside trusted range", trusted: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:5000", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "trusted proxy", trusted: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:5000", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "client prepends spoofed hop", trusted: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:5000", forwarded: "1.1.1.1, 198.51.100.1, 10.0.0.3", want: "198.51.100.1"},
+		{name: "proxy count picks fixed hop", trusted: []string{"10.0.0.2"}, proxyCount: 2, remoteAddr: "10.0.0.2:5000", forwarded: "1.1.1.1, 198.51.100.1, 192.0.2.9", want: "198.51.100.1"},
+		{name: "chain shorter than proxy count", trusted: []string{"10.0.0.2"}, proxyCount: 3, remoteAddr: "10.0.0.2:5000", forwarded: "198.51.100.1", want: "10.0.0.2"},
+		{name: "ipv6 proxy and client", trusted: []string{"2001:db8::/32"}, remoteAddr: "[2001:db8::1]:443", forwarded: "[2001:db8:ffff::5]:1234, 2001:db8::2", want: "2001:db8:ffff::5"},
+		{name: "ipv4-mapped remote", remoteAddr: "[::ffff:203.0.113.7]:5000", forwarded: "198.51.100.1", want: "203.0.113.7"},
+		{name: "malformed chain", trusted: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:5000", forwarded: "not-an-ip", want: "10.0.0.2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			resolver, err := httpapi.NewClientIPResolver(tc.trusted, tc.proxyCount)
+			if err != nil {
+				t.Fatalf("expected resolver, got %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			req.Header.Set("X-Forwarded-For", tc.forwarded)
+
+			// Act
+			got := resolver.Resolve(req)
+
+			// Assert
+			if got != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestNewClientIPResolverRejectsInvalidProxies fails fast on bad configuration.
+// Arrange: pick an unparsable proxy and a negative count.
+// Act: build resolvers with each.
+// Assert: expect both to fail.
+func TestNewClientIPResolverRejectsInvalidProxies(t *testing.T) {
+	// Arrange
+	invalidProxy, negativeCount := []string{"proxy.internal"}, -1
+
+	// Act
+	_, proxyErr := httpapi.NewClientIPResolver(invalidProxy, 0)
+	_, countErr := httpapi.NewClientIPResolver(nil, negativeCount)
+
+	// Assert
+	if proxyErr == nil || countErr == nil {
+		t.Fatalf("expected invalid settings to be rejected, got %v and %v", proxyErr, countErr)
+	}
+}
@@ -0,0 +1,70 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+func newJSONOnlyEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(httpapi.RequireJSON())
+	engine.POST("/items", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	engine.GET("/items", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return engine
+}
+
+// TestRequireJSON enforces JSON bodies on write requests.
+// Arrange: table-drive methods, bodies and content types.
+// Act: send each request through the middleware.
+// Assert: expect 415 only for non-empty non-JSON write requests.
+func TestRequireJSON(t *testing.T) {
+	testCases := []struct {
+		name        string
+		method      string
+		body        string
+		contentType string
+		status      int
+	}{
+		{name: "json", method: http.MethodPost, body: `{"a":1}`, contentType: "application/json", status: http.StatusNoContent},
+		{name: "json with charset", method: http.MethodPost, body: `{"a":1}`, contentType: "application/json; charset=utf-8", status: http.StatusNoContent},
+		{name: "problem json", method: http.MethodPost, body: `{"a":1}`, contentType: "application/problem+json", status: http.StatusNoContent},
+		{name: "form encoded", method: http.MethodPost, body: "a=1", contentType: "application/x-www-form-urlencoded", status: http.StatusUnsupportedMediaType},
+		{name: "missing content type", method: http.MethodPost, body: `{"a":1}`, contentType: "", status: http.StatusUnsupportedMediaType},
+		{name: "empty body", method: http.MethodPost, body: "", contentType: "", status: http.StatusNoContent},
+		{name: "get is ignored", method: http.MethodGet, body: "", contentType: "text/plain", status: http.StatusNoContent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newJSONOnlyEngine()
+			req := httptest.NewRequest(tc.method, "/items", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+			if tc.status == http.StatusUnsupportedMediaType {
+				var body httpapi.ErrorResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error.Code != httpapi.CodeUnsupportedMediaType {
+					t.Fatalf("expected %s envelope, got %q", httpapi.CodeUnsupportedMediaType, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+type decodePayload struct {
+	Name  string `json:"name" binding:"required"`
+	Count int    `json:"count"`
+}
+
+func newDecodeEngine(strict bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	if strict {
+		engine.Use(httpapi.DisallowUnknownFields())
+	}
+	engine.POST("/items", func(c *gin.Context) {
+		var payload decodePayload
+		if !httpapi.BindJSON(c, &payload) {
+			return
+		}
+		c.JSON(http.StatusOK, payload)
+	})
+	return engine
+}
+
+// TestBindJSONDescribesDecodeFailures tells clients what was wrong with the body.
+// Arrange: table-drive bodies with lenient and strict decoding.
+// Act: post each body to a handler using BindJSON.
+// Assert: expect 200 for valid bodies and 400 with a specific message and field otherwise.
+func TestBindJSONDescribesDecodeFailures(t *testing.T) {
+	testCases := []struct {
+		name    string
+		strict  bool
+		body    string
+		status  int
+		message string
+		field   string
+	}{
+		{name: "valid", body: `{"name": "pikachu", "count": 2}`, status: http.StatusOK},
+		{name: "empty body", body: "", status: http.StatusBadRequest, message: "Request body is required."},
+		{name: "syntax error", body: `{"name": "pikachu",}`, status: http.StatusBadRequest, message: "Request body is not valid JSON."},
+		{name: "truncated", body: `{"name": "pika`, status: http.StatusBadRequest, message: "Request body is not valid JSON."},
+		{name: "trailing value", body: `{"name": "a"} {"name": "b"}`, status: http.StatusBadRequest, message: "Request body must contain a single JSON value."},
+		{name: "type mismatch", body: `{"name": "pikachu", "count": "two"}`, status: http.StatusBadRequest, message: `Field "count" must be a number.`, field: "count"},
+		{name: "wrong top-level type", body: `["pikachu"]`, status: http.StatusBadRequest, message: "Request body must be a JSON object."},
+		{name: "missing required field", body: `{"count": 2}`, status: http.StatusBadRequest, message: "Invalid request payload."},
+		{name: "unknown field allowed by default", body: `{"name": "pikachu", "nmae": "x"}`, status: http.StatusOK},
+		{name: "unknown field rejected when strict", strict: true, body: `{"name": "pikachu", "nmae": "x"}`, status: http.StatusBadRequest, message: `Unknown field "nmae".`, field: "nmae"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newDecodeEngine(tc.strict)
+			req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, recorder.Code, recorder.Body.String())
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			var body httpapi.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected an error envelope, got %q", recorder.Body.String())
+			}
+			if body.Error.Code != httpapi.CodeInvalidRequest || body.Error.Message != tc.message || body.Error.Field != tc.field {
+				t.Fatalf("expected %s %q on field %q, got %+v", httpapi.CodeInvalidRequest, tc.message, tc.field, body.Error)
+			}
+		})
+	}
+}
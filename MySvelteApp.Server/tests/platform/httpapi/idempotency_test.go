@@ -0,0 +1,165 @@
+package httpapi_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/idempotency"
+)
+
+// newIdempotentEngine mounts a counting handler that answers with status
+// behind the idempotency middleware and a per-request header set before it.
+func newIdempotentEngine(status int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	requests := 0
+	engine.Use(func(c *gin.Context) {
+		requests++
+		c.Header("X-Request-ID", strings.Repeat("r", requests))
+		c.Next()
+	})
+	engine.POST("/items", httpapi.Idempotency(idempotency.NewMemoryStore(), time.Hour), func(c *gin.Context) {
+		*calls++
+		c.Header("Location", "/items/1")
+		c.JSON(status, gin.H{"call": *calls})
+	})
+	return engine
+}
+
+func postWithKey(engine *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(httpapi.IdempotencyKeyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestIdempotencyReplaysWithoutRerunningHandler returns the first response to a retry.
+// Arrange: send a request with an Idempotency-Key.
+// Act: repeat it with the same key and body.
+// Assert: expect one handler call, the same status, body and handler headers, and this request's own request ID.
+func TestIdempotencyReplaysWithoutRerunningHandler(t *testing.T) {
+	// Arrange
+	calls := 0
+	engine := newIdempotentEngine(http.StatusCreated, &calls)
+	first := postWithKey(engine, "abc", `{"name":"pikachu"}`)
+
+	// Act
+	retry := postWithKey(engine, "abc", `{"name":"pikachu"}`)
+
+	// Assert
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, got %d", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected replay of %d %s, got %d %s", first.Code, first.Body.String(), retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Location") != "/items/1" || retry.Header().Get(httpapi.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected handler headers and replay marker, got %v", retry.Header())
+	}
+	if retry.Header().Get("X-Request-ID") != "rr" {
+		t.Fatalf("expected the retry's own request ID, got %q", retry.Header().Get("X-Request-ID"))
+	}
+}
+
+// TestIdempotencyOnlyAppliesToMatchingRequests leaves other requests to the handler.
+// Arrange: table-drive follow-up requests after one keyed request.
+// Act: send each follow-up.
+// Assert: expect the handler to run or a rejection, as the key and body dictate.
+func TestIdempotencyOnlyAppliesToMatchingRequests(t *testing.T) {
+	testCases := []struct {
+		name   string
+		key    string
+		body   string
+		status int
+		calls  int
+	}{
+		{name: "no key", key: "", body: `{"name":"pikachu"}`, status: http.StatusCreated, calls: 2},
+		{name: "different key", key: "other", body: `{"name":"pikachu"}`, status: http.StatusCreated, calls: 2},
+		{name: "same key different body", key: "abc", body: `{"name":"eevee"}`, status: http.StatusUnprocessableEntity, calls: 1},
+		{name: "key too long", key: strings.Repeat("k", 256), body: `{}`, status: http.StatusBadRequest, calls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			calls := 0
+			engine := newIdempotentEngine(http.StatusCreated, &calls)
+			postWithKey(engine, "abc", `{"name":"pikachu"}`)
+
+			// Act
+			recorder := postWithKey(engine, tc.key, tc.body)
+
+			// Assert
+			if recorder.Code != tc.status || calls != tc.calls {
+				t.Fatalf("expected status %d after %d calls, got %d after %d", tc.status, tc.calls, recorder.Code, calls)
+			}
+		})
+	}
+}
+
+// TestIdempotencyDoesNotStoreServerErrors lets clients retry failures.
+// Arrange: use a handler that fails with 503.
+// Act: send the same keyed request twice.
+// Assert: expect the handler to run both times.
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	engine := newIdempotentEngine(http.StatusServiceUnavailable, &calls)
+
+	// Act
+	postWithKey(engine, "abc", `{}`)
+	retry := postWithKey(engine, "abc", `{}`)
+
+	// Assert
+	if calls != 2 || retry.Header().Get(httpapi.IdempotentReplayedHeader) != "" {
+		t.Fatalf("expected the retry to run the handler again, got %d calls and headers %v", calls, retry.Header())
+	}
+}
+
+// failingBody is a request body whose reads fail partway through.
+type failingBody struct{}
+
+func (failingBody) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+// TestIdempotencyReportsBodyReadErrors tells oversize bodies apart from broken ones.
+// Arrange: build one keyed request over the body limit and one whose body fails to read.
+// Act: send both.
+// Assert: expect 413 for the oversize body, 400 for the failed read, and no handler calls.
+func TestIdempotencyReportsBodyReadErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	engine := newIdempotentEngine(http.StatusCreated, &calls)
+	send := func(req *http.Request) *httptest.ResponseRecorder {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(httpapi.IdempotencyKeyHeader, "abc")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	tooLarge := send(httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(strings.Repeat("x", 1<<20+1))))
+	broken := send(httptest.NewRequest(http.MethodPost, "/items", failingBody{}))
+
+	// Assert
+	if tooLarge.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d for an oversize body, got %d: %s", http.StatusRequestEntityTooLarge, tooLarge.Code, tooLarge.Body.String())
+	}
+	if broken.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for a failed read, got %d: %s", http.StatusBadRequest, broken.Code, broken.Body.String())
+	}
+	if calls != 0 {
+		t.Fatalf("expected the handler not to run, got %d calls", calls)
+	}
+}
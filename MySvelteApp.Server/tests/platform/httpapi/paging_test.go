@@ -0,0 +1,105 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+// TestNewPagedResponseComputesPages covers first, middle, last and empty pages.
+// Arrange: table-drive offsets, limits and totals.
+// Act: build the envelope for each case.
+// Assert: expect the page number, total pages and a non-nil item slice.
+func TestNewPagedResponseComputesPages(t *testing.T) {
+	testCases := []struct {
+		name       string
+		items      []string
+		offset     int
+		limit      int
+		total      int64
+		page       int
+		totalPages int
+	}{
+		{name: "empty result", items: nil, offset: 0, limit: 20, total: 0, page: 1, totalPages: 0},
+		{name: "single partial page", items: []string{"a", "b"}, offset: 0, limit: 20, total: 2, page: 1, totalPages: 1},
+		{name: "exact multiple", items: []string{"c", "d"}, offset: 2, limit: 2, total: 4, page: 2, totalPages: 2},
+		{name: "partial last page", items: []string{"e"}, offset: 4, limit: 2, total: 5, page: 3, totalPages: 3},
+		{name: "offset past the end", items: nil, offset: 40, limit: 20, total: 5, page: 3, totalPages: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			items := tc.items
+
+			// Act
+			response := httpapi.NewPagedResponse(items, tc.offset, tc.limit, tc.total)
+
+			// Assert
+			if response.Items == nil {
+				t.Fatalf("expected non-nil items so JSON encodes []")
+			}
+			if response.Page != tc.page {
+				t.Fatalf("expected page %d, got %d", tc.page, response.Page)
+			}
+			if response.TotalPages != tc.totalPages {
+				t.Fatalf("expected %d total pages, got %d", tc.totalPages, response.TotalPages)
+			}
+			if response.PageSize != tc.limit || response.Total != tc.total {
+				t.Fatalf("expected pageSize %d and total %d, got %d and %d", tc.limit, tc.total, response.PageSize, response.Total)
+			}
+		})
+	}
+}
+
+func newQueryContext(rawQuery string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/items?"+rawQuery, nil)
+	return c
+}
+
+// TestParsePageQueryDefaultsAndOffsets applies defaults and converts pages to offsets.
+// Arrange: build a request asking for page 3 without a page size.
+// Act: parse the page query.
+// Assert: expect the default page size and the matching offset.
+func TestParsePageQueryDefaultsAndOffsets(t *testing.T) {
+	// Arrange
+	c := newQueryContext("page=3")
+
+	// Act
+	query, err := httpapi.ParsePageQuery(c, 10, 50)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if query.Limit() != 10 || query.Offset() != 20 {
+		t.Fatalf("expected limit 10 and offset 20, got %d and %d", query.Limit(), query.Offset())
+	}
+}
+
+// TestParsePageQueryRejectsInvalidValues guards against bad or oversized input.
+// Arrange: table-drive malformed page and pageSize values.
+// Act: parse each query.
+// Assert: expect an error for every case.
+func TestParsePageQueryRejectsInvalidValues(t *testing.T) {
+	for _, rawQuery := range []string{"page=0", "page=abc", "pageSize=0", "pageSize=51", "pageSize=-1"} {
+		t.Run(rawQuery, func(t *testing.T) {
+			// Arrange
+			c := newQueryContext(rawQuery)
+
+			// Act
+			_, err := httpapi.ParsePageQuery(c, 10, 50)
+
+			// Assert
+			if err == nil {
+				t.Fatalf("expected error for %q", rawQuery)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package httpapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+func newProblemEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(httpapi.NegotiateProblemDetails())
+	engine.GET("/invalid", func(c *gin.Context) {
+		httpapi.WriteErrorBody(c, http.StatusBadRequest, httpapi.ErrorBody{
+			Code:    httpapi.CodeValidation,
+			Message: "Username is required.",
+			Field:   "username",
+			Reason:  "REQUIRED",
+		})
+	})
+	engine.GET("/conflict", func(c *gin.Context) {
+		httpapi.AbortWithError(c, http.StatusConflict, httpapi.CodeConflict, "Username is already taken.")
+	})
+	return engine
+}
+
+// TestErrorFormatNegotiation serves problem+json only to clients that ask for it.
+// Arrange: table-drive 400 and 409 routes with and without a problem+json Accept header.
+// Act: request each route.
+// Assert: expect problem details when negotiated and the standard envelope otherwise.
+func TestErrorFormatNegotiation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		accept  string
+		status  int
+		code    string
+		message string
+	}{
+		{name: "400 envelope", path: "/invalid", accept: "application/json", status: http.StatusBadRequest, code: httpapi.CodeValidation, message: "Username is required."},
+		{name: "400 problem", path: "/invalid", accept: "application/problem+json", status: http.StatusBadRequest, code: httpapi.CodeValidation, message: "Username is required."},
+		{name: "409 envelope", path: "/conflict", status: http.StatusConflict, code: httpapi.CodeConflict, message: "Username is already taken."},
+		{name: "409 problem", path: "/conflict", accept: "application/json, application/problem+json;q=0.9", status: http.StatusConflict, code: httpapi.CodeConflict, message: "Username is already taken."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newProblemEngine()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, rec.Code)
+			}
+			wantsProblem := tc.accept != "" && tc.accept != "application/json"
+			if !wantsProblem {
+				var body httpapi.ErrorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error.Code != tc.code || body.Error.Message != tc.message {
+					t.Fatalf("expected %s envelope, got %q", tc.code, rec.Body.String())
+				}
+				return
+			}
+
+			if got := rec.Header().Get("Content-Type"); got != httpapi.ProblemContentType {
+				t.Fatalf("expected content type %q, got %q", httpapi.ProblemContentType, got)
+			}
+			var problem httpapi.ProblemDetails
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("expected problem details, got %q", rec.Body.String())
+			}
+			want := httpapi.ProblemDetails{Type: "about:blank", Title: http.StatusText(tc.status), Status: tc.status, Detail: tc.message, Code: tc.code}
+			if tc.status == http.StatusBadRequest {
+				want.Field, want.Reason = "username", "REQUIRED"
+			}
+			if problem != want {
+				t.Fatalf("expected %+v, got %+v", want, problem)
+			}
+		})
+	}
+}
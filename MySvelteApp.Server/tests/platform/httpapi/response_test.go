@@ -0,0 +1,62 @@
+package httpapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+)
+
+func newResponseEngine(indent bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	if indent {
+		engine.Use(httpapi.IndentJSON())
+	}
+	engine.GET("/item", func(c *gin.Context) {
+		httpapi.WriteJSON(c, http.StatusOK, gin.H{"name": "pikachu"})
+	})
+	engine.GET("/missing", func(c *gin.Context) {
+		httpapi.WriteError(c, http.StatusNotFound, httpapi.CodeNotFound, "Not found.")
+	})
+	return engine
+}
+
+// TestWriteJSONIndentsWhenEnabled makes development responses readable.
+// Arrange: table-drive engines with and without IndentJSON.
+// Act: request a success and an error response from each.
+// Assert: expect indented bodies only when IndentJSON is active.
+func TestWriteJSONIndentsWhenEnabled(t *testing.T) {
+	testCases := []struct {
+		name     string
+		indent   bool
+		path     string
+		expected string
+	}{
+		{name: "indented success", indent: true, path: "/item", expected: "{\n    \"name\": \"pikachu\"\n}"},
+		{name: "compact success", indent: false, path: "/item", expected: `{"name":"pikachu"}`},
+		{name: "indented error", indent: true, path: "/missing", expected: "{\n    \"error\": {\n        \"code\": \"NOT_FOUND\""},
+		{name: "compact error", indent: false, path: "/missing", expected: `{"error":{"code":"NOT_FOUND"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newResponseEngine(tc.indent)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if !strings.HasPrefix(recorder.Body.String(), tc.expected) {
+				t.Fatalf("expected body starting %q, got %q", tc.expected, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"mysvelteapp/server_new/internal/platform/httpclient"
+)
+
+// TestClientInjectsTraceContext propagates the caller's trace to the upstream.
+// Arrange: start a parent span and a server capturing the traceparent header.
+// Act: send a request through the instrumented client.
+// Assert: expect a client span in the parent's trace whose ID reaches the server.
+func TestClientInjectsTraceContext(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	client := httpclient.New(httpclient.Options{
+		Propagator:     propagation.TraceContext{},
+		TracerProvider: provider,
+	})
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("expected request to build, got %v", err)
+	}
+
+	// Act
+	resp, err := client.Do(req)
+	parent.End()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+	if req.Header.Get("traceparent") != "" {
+		t.Fatalf("expected the caller's request headers to be left untouched")
+	}
+
+	var clientSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "HTTP GET" {
+			clientSpan = span
+		}
+	}
+	if clientSpan == nil {
+		t.Fatalf("expected an HTTP GET client span to be recorded")
+	}
+	spanContext := clientSpan.SpanContext()
+	if spanContext.TraceID() != parent.SpanContext().TraceID() {
+		t.Fatalf("expected client span in trace %s, got %s", parent.SpanContext().TraceID(), spanContext.TraceID())
+	}
+	want := "00-" + spanContext.TraceID().String() + "-" + spanContext.SpanID().String() + "-01"
+	if traceparent != want {
+		t.Fatalf("expected traceparent %q, got %q", want, traceparent)
+	}
+}
+
+// TestClientAppliesDefaultTimeout guards against unbounded outbound calls.
+// Arrange: build a client without an explicit timeout.
+// Act: inspect the client.
+// Assert: expect the default timeout.
+func TestClientAppliesDefaultTimeout(t *testing.T) {
+	// Arrange
+	options := httpclient.Options{}
+
+	// Act
+	client := httpclient.New(options)
+
+	// Assert
+	if client.Timeout != httpclient.DefaultTimeout {
+		t.Fatalf("expected timeout %s, got %s", httpclient.DefaultTimeout, client.Timeout)
+	}
+	if httpclient.New(httpclient.Options{Timeout: time.Second}).Timeout != time.Second {
+		t.Fatalf("expected an explicit timeout to be kept")
+	}
+}
@@ -0,0 +1,125 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+func newAccessLogEngine(opts ...httpserver.Option) (*gin.Engine, *bytes.Buffer) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	engine := httpserver.New(slog.New(slog.NewTextHandler(&buf, nil)), "test-service", opts...)
+	engine.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	engine.GET("/health", func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) })
+	engine.GET("/metrics/raw", func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET("/metricsfoo", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return engine, &buf
+}
+
+func serveTimes(engine *gin.Engine, path string, times int) {
+	for i := 0; i < times; i++ {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+}
+
+func countAccessLogs(logs *bytes.Buffer, path string) int {
+	count := 0
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(line, `msg="request completed"`) && strings.Contains(line, "path="+path+" ") {
+			count++
+		}
+	}
+	return count
+}
+
+// TestAccessLogSamplesSuccessfulRequests cuts log volume for healthy traffic.
+// Arrange: sample one in four successful requests.
+// Act: serve ten successful requests.
+// Assert: expect three entries, each carrying the sample rate.
+func TestAccessLogSamplesSuccessfulRequests(t *testing.T) {
+	// Arrange
+	engine, logs := newAccessLogEngine(httpserver.WithAccessLogSampling(4))
+
+	// Act
+	serveTimes(engine, "/ok", 10)
+
+	// Assert
+	if got := countAccessLogs(logs, "/ok"); got != 3 {
+		t.Fatalf("expected 3 sampled entries, got %d in %q", got, logs.String())
+	}
+	if !strings.Contains(logs.String(), "sample_rate=4") {
+		t.Fatalf("expected sampled entries to record the rate, got %q", logs.String())
+	}
+}
+
+// TestAccessLogAlwaysLogsFailures keeps every error visible despite sampling.
+// Arrange: sample one in a hundred successful requests.
+// Act: serve five failing requests.
+// Assert: expect five entries.
+func TestAccessLogAlwaysLogsFailures(t *testing.T) {
+	// Arrange
+	engine, logs := newAccessLogEngine(httpserver.WithAccessLogSampling(100))
+
+	// Act
+	serveTimes(engine, "/fail", 5)
+
+	// Assert
+	if got := countAccessLogs(logs, "/fail"); got != 5 {
+		t.Fatalf("expected every failure to be logged, got %d", got)
+	}
+}
+
+// TestAccessLogSkipsConfiguredPaths keeps health probes out of the log.
+// Arrange: skip /health.
+// Act: probe /health, even while it is failing, and serve one other request.
+// Assert: expect no /health entries but the other request logged.
+func TestAccessLogSkipsConfiguredPaths(t *testing.T) {
+	// Arrange
+	engine, logs := newAccessLogEngine(httpserver.WithAccessLogSkipPaths("/health"))
+
+	// Act
+	serveTimes(engine, "/health", 3)
+	serveTimes(engine, "/ok", 1)
+
+	// Assert
+	if got := countAccessLogs(logs, "/health"); got != 0 {
+		t.Fatalf("expected /health to be skipped, got %d entries", got)
+	}
+	if got := countAccessLogs(logs, "/ok"); got != 1 {
+		t.Fatalf("expected /ok to be logged, got %d entries", got)
+	}
+}
+
+// TestAccessLogSkipsOnlyMatchedRoutes cannot be used to hide real traffic.
+// Arrange: skip the /metrics prefix.
+// Act: request a route beneath it, a route sharing its text, and an unmatched path beneath it.
+// Assert: expect only the route beneath the prefix to go unlogged.
+func TestAccessLogSkipsOnlyMatchedRoutes(t *testing.T) {
+	// Arrange
+	engine, logs := newAccessLogEngine(httpserver.WithAccessLogSkipPaths("/metrics"))
+
+	// Act
+	serveTimes(engine, "/metrics/raw", 1)
+	serveTimes(engine, "/metricsfoo", 1)
+	serveTimes(engine, "/metrics/missing", 1)
+
+	// Assert
+	if got := countAccessLogs(logs, "/metrics/raw"); got != 0 {
+		t.Fatalf("expected /metrics/raw to be skipped, got %d entries", got)
+	}
+	if got := countAccessLogs(logs, "/metricsfoo"); got != 1 {
+		t.Fatalf("expected /metricsfoo to be logged, got %d entries", got)
+	}
+	if got := countAccessLogs(logs, "/metrics/missing"); got != 1 {
+		t.Fatalf("expected unmatched /metrics/missing to be logged, got %d entries", got)
+	}
+}
@@ -0,0 +1,115 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+func newCSRFEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := httpserver.New(nil, "test", httpserver.WithCSRFProtection(false))
+	engine.GET("/session", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	engine.POST("/session", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return engine
+}
+
+// issueCSRFCookie performs a safe request and returns the token cookie set on it.
+func issueCSRFCookie(t *testing.T, engine *gin.Engine) *http.Cookie {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/session", nil))
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == httpserver.CSRFCookieName {
+			return cookie
+		}
+	}
+	t.Fatalf("expected a %s cookie on a safe request", httpserver.CSRFCookieName)
+	return nil
+}
+
+// TestCSRFAcceptsMatchingToken lets browsers that echo the cookie through.
+// Arrange: obtain a token cookie from a GET.
+// Act: POST with the cookie and the same value in X-CSRF-Token.
+// Assert: expect the handler to run.
+func TestCSRFAcceptsMatchingToken(t *testing.T) {
+	// Arrange
+	engine := newCSRFEngine()
+	cookie := issueCSRFCookie(t, engine)
+	req := httptest.NewRequest(http.MethodPost, "/session", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(httpserver.CSRFHeader, cookie.Value)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+	if cookie.SameSite != http.SameSiteStrictMode || cookie.HttpOnly {
+		t.Fatalf("expected a script-readable SameSite=Strict cookie, got %+v", cookie)
+	}
+}
+
+// TestCSRFRejectsMissingOrMismatchedToken blocks forged cross-site posts.
+// Arrange: obtain a token cookie from a GET.
+// Act: POST without the header, with a wrong header, and with no cookie at all.
+// Assert: expect 403 FORBIDDEN each time.
+func TestCSRFRejectsMissingOrMismatchedToken(t *testing.T) {
+	// Arrange
+	engine := newCSRFEngine()
+	cookie := issueCSRFCookie(t, engine)
+	cases := map[string]func(*http.Request){
+		"missing header": func(req *http.Request) { req.AddCookie(cookie) },
+		"wrong header": func(req *http.Request) {
+			req.AddCookie(cookie)
+			req.Header.Set(httpserver.CSRFHeader, "forged")
+		},
+		"missing cookie": func(req *http.Request) { req.Header.Set(httpserver.CSRFHeader, cookie.Value) },
+	}
+
+	for name, prepare := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/session", nil)
+		prepare(req)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		engine.ServeHTTP(recorder, req)
+
+		// Assert
+		if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), httpapi.CodeForbidden) {
+			t.Fatalf("%s: expected 403 %s, got %d %s", name, httpapi.CodeForbidden, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+// TestCSRFSkipsBearerRequests keeps API clients working without cookies.
+// Arrange: build a POST carrying only an Authorization header.
+// Act: send it.
+// Assert: expect the handler to run and no token cookie to be issued.
+func TestCSRFSkipsBearerRequests(t *testing.T) {
+	// Arrange
+	engine := newCSRFEngine()
+	req := httptest.NewRequest(http.MethodPost, "/session", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+	if cookies := recorder.Result().Cookies(); len(cookies) != 0 {
+		t.Fatalf("expected no cookies, got %+v", cookies)
+	}
+}
@@ -0,0 +1,84 @@
+package httpserver_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+func newFallbackEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := httpserver.New(nil, "test")
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	engine.GET("/api/v1/pokemon", ok)
+	engine.POST("/api/v1/pokemon", ok)
+	return engine
+}
+
+// TestUnmatchedRoutesUseErrorEnvelope replaces gin's plain-text fallbacks.
+// Arrange: register GET and POST on one path.
+// Act: send DELETE to that path and GET to an unknown path.
+// Assert: expect 405 with an Allow header and 404, both as JSON error envelopes.
+func TestUnmatchedRoutesUseErrorEnvelope(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string
+		allow  string
+	}{
+		{name: "wrong method", method: http.MethodDelete, path: "/api/v1/pokemon", status: http.StatusMethodNotAllowed, code: httpapi.CodeMethodNotAllowed, allow: "GET, POST"},
+		{name: "unknown path", method: http.MethodGet, path: "/api/v1/missing", status: http.StatusNotFound, code: httpapi.CodeNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newFallbackEngine()
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, httptest.NewRequest(tc.method, tc.path, nil))
+
+			// Assert
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, recorder.Code)
+			}
+			if allow := recorder.Header().Get("Allow"); allow != tc.allow {
+				t.Fatalf("expected Allow %q, got %q", tc.allow, allow)
+			}
+			var body httpapi.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error.Code != tc.code {
+				t.Fatalf("expected error code %s, got %q (%v)", tc.code, recorder.Body.String(), err)
+			}
+		})
+	}
+}
+
+// TestTrailingSlashRedirects points clients at the registered path.
+// Arrange: register GET and POST without a trailing slash.
+// Act: request the path with a trailing slash.
+// Assert: expect a redirect to the path without it.
+func TestTrailingSlashRedirects(t *testing.T) {
+	// Arrange
+	engine := newFallbackEngine()
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/pokemon/", nil))
+
+	// Assert
+	if recorder.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected status %d, got %d", http.StatusMovedPermanently, recorder.Code)
+	}
+	if location := recorder.Header().Get("Location"); location != "/api/v1/pokemon" {
+		t.Fatalf("expected redirect to /api/v1/pokemon, got %q", location)
+	}
+}
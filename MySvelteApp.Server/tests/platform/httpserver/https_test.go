@@ -0,0 +1,114 @@
+package httpserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+func newForceHTTPSEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	resolver, err := httpapi.NewClientIPResolver([]string{"10.0.0.0/8"}, 0)
+	if err != nil {
+		t.Fatalf("expected resolver, got %v", err)
+	}
+	engine := httpserver.New(nil, "test", httpserver.WithClientIPResolver(resolver), httpserver.WithForceHTTPS())
+	engine.POST("/api/v1/auth/login", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	for _, path := range []string{"/health", "/readyz", "/metrics"} {
+		engine.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	return engine
+}
+
+// TestForceHTTPSRedirectsPlainRequests sends HTTP clients to the https URL.
+// Arrange: table-drive plain requests from a trusted proxy and from an untrusted
+// client spoofing X-Forwarded-Proto.
+// Act: send each request through an engine with WithForceHTTPS.
+// Assert: expect a 308 to the same host, path and query over https.
+func TestForceHTTPSRedirectsPlainRequests(t *testing.T) {
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		proto      string
+	}{
+		{name: "trusted proxy forwarding http", remoteAddr: "10.0.0.5:4000", proto: "http"},
+		{name: "trusted proxy without header", remoteAddr: "10.0.0.5:4000"},
+		{name: "untrusted client claiming https", remoteAddr: "203.0.113.9:4000", proto: "https"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			engine := newForceHTTPSEngine(t)
+			req := httptest.NewRequest(http.MethodPost, "http://app.example.com/api/v1/auth/login?next=%2Fhome", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tc.proto)
+			}
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != http.StatusPermanentRedirect {
+				t.Fatalf("expected status %d, got %d", http.StatusPermanentRedirect, recorder.Code)
+			}
+			if location, want := recorder.Header().Get("Location"), "https://app.example.com/api/v1/auth/login?next=%2Fhome"; location != want {
+				t.Fatalf("expected Location %q, got %q", want, location)
+			}
+		})
+	}
+}
+
+// TestForceHTTPSPassesHTTPSRequestsThrough serves requests the proxy already terminated.
+// Arrange: send a request from a trusted proxy with X-Forwarded-Proto: https.
+// Act: serve it through an engine with WithForceHTTPS.
+// Assert: expect the handler to run without a redirect.
+func TestForceHTTPSPassesHTTPSRequestsThrough(t *testing.T) {
+	// Arrange
+	engine := newForceHTTPSEngine(t)
+	req := httptest.NewRequest(http.MethodPost, "http://app.example.com/api/v1/auth/login", nil)
+	req.RemoteAddr = "10.0.0.5:4000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	recorder := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(recorder, req)
+
+	// Assert
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, recorder.Code)
+	}
+}
+
+// TestForceHTTPSServesProbesOverPlainHTTP keeps health checks and scrapes working.
+// Arrange: table-drive plain requests to the probe and metrics routes from a
+// client that is not a proxy.
+// Act: serve each through an engine with WithForceHTTPS.
+// Assert: expect 200 instead of a redirect.
+func TestForceHTTPSServesProbesOverPlainHTTP(t *testing.T) {
+	for _, path := range []string{"/health", "/readyz", "/metrics"} {
+		t.Run(path, func(t *testing.T) {
+			// Arrange
+			engine := newForceHTTPSEngine(t)
+			req := httptest.NewRequest(http.MethodGet, "http://10.1.2.3:8080"+path, nil)
+			req.RemoteAddr = "192.0.2.10:4000"
+			recorder := httptest.NewRecorder()
+
+			// Act
+			engine.ServeHTTP(recorder, req)
+
+			// Assert
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package httpserver_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"mysvelteapp/server_new/internal/platform/httpserver"
+	"mysvelteapp/server_new/internal/platform/metrics"
+)
+
+// TestWithMeterProviderKeepsHistogramsOffThePrometheusBridge avoids startup errors.
+// Arrange: install the prometheus bridge globally and count OpenTelemetry errors.
+// Act: build engines with and without a no-op meter provider and serve a request.
+// Assert: expect errors only from the engine using the global provider.
+func TestWithMeterProviderKeepsHistogramsOffThePrometheusBridge(t *testing.T) {
+	// Arrange
+	previousProvider, previousHandler := otel.GetMeterProvider(), otel.GetErrorHandler()
+	t.Cleanup(func() {
+		otel.SetMeterProvider(previousProvider)
+		otel.SetErrorHandler(previousHandler)
+	})
+	if _, err := metrics.New(metrics.ExporterPrometheus, metrics.NewRegistry(), slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("expected provider, got %v", err)
+	}
+	var otelErrors atomic.Int32
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(error) { otelErrors.Add(1) }))
+	gin.SetMode(gin.TestMode)
+	serve := func(options ...httpserver.Option) int32 {
+		otelErrors.Store(0)
+		engine := httpserver.New(nil, "test", options...)
+		engine.GET("/ping", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+		return otelErrors.Load()
+	}
+
+	// Act
+	withGlobal := serve()
+	withNoop := serve(httpserver.WithMeterProvider(noop.NewMeterProvider()))
+
+	// Assert
+	if withGlobal == 0 {
+		t.Fatalf("expected the bridge to reject otelgin's histograms")
+	}
+	if withNoop != 0 {
+		t.Fatalf("expected no OpenTelemetry errors with a no-op meter provider, got %d", withNoop)
+	}
+}
@@ -0,0 +1,99 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"mysvelteapp/server_new/internal/platform/httpapi"
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+// countingMeterProvider hands out counters that tally every Add.
+type countingMeterProvider struct {
+	noop.MeterProvider
+	total *atomic.Int64
+}
+
+func (p countingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return countingMeter{total: p.total}
+}
+
+type countingMeter struct {
+	noop.Meter
+	total *atomic.Int64
+}
+
+func (m countingMeter) Int64Counter(string, ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return countingCounter{total: m.total}, nil
+}
+
+type countingCounter struct {
+	noop.Int64Counter
+	total *atomic.Int64
+}
+
+func (c countingCounter) Add(_ context.Context, incr int64, _ ...metric.AddOption) {
+	c.total.Add(incr)
+}
+
+// TestRecoveryReportsPanicsSafely keeps panics observable but out of responses.
+// Arrange: install recording tracer and meter providers and a panicking handler.
+// Act: request the handler.
+// Assert: expect a 500 envelope without the stack, a logged stack, an errored span and one counted panic.
+func TestRecoveryReportsPanicsSafely(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	spans := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	var panics atomic.Int64
+	otel.SetMeterProvider(countingMeterProvider{total: &panics})
+
+	var logs bytes.Buffer
+	engine := httpserver.New(slog.New(slog.NewTextHandler(&logs, nil)), "test-service")
+	engine.GET("/boom", func(*gin.Context) {
+		panic("secret internal detail")
+	})
+	rec := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	// Assert
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", rec.Code)
+	}
+	var body httpapi.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error.Code != httpapi.CodeInternal {
+		t.Fatalf("expected %s error envelope, got %q", httpapi.CodeInternal, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), "secret internal detail") || strings.Contains(rec.Body.String(), "goroutine") {
+		t.Fatalf("expected panic details to stay out of the response, got %q", rec.Body.String())
+	}
+	if !strings.Contains(logs.String(), "panic recovered") || !strings.Contains(logs.String(), "secret internal detail") || !strings.Contains(logs.String(), "runtime/debug.Stack") {
+		t.Fatalf("expected the panic value and stack to be logged, got %q", logs.String())
+	}
+	ended := spans.Ended()
+	if len(ended) != 1 || ended[0].Status().Code != codes.Error || len(ended[0].Events()) == 0 {
+		t.Fatalf("expected one errored span with a recorded exception, got %+v", ended)
+	}
+	if got := panics.Load(); got != 1 {
+		t.Fatalf("expected one counted panic, got %d", got)
+	}
+}
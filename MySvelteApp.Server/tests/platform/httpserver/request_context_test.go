@@ -0,0 +1,111 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"mysvelteapp/server_new/internal/platform/httpserver"
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+func newEngineWithLogBuffer(t *testing.T) (*gin.Engine, *bytes.Buffer) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	engine := httpserver.New(logger, "test-service")
+	engine.GET("/ping", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("handling ping")
+		c.Status(http.StatusNoContent)
+	})
+	return engine, &buf
+}
+
+// TestRequestLoggerReusesIncomingRequestID correlates handler logs with the caller.
+// Arrange: build the engine and a request carrying X-Request-ID.
+// Act: serve the request.
+// Assert: expect the ID echoed in the response and attached to handler logs.
+func TestRequestLoggerReusesIncomingRequestID(t *testing.T) {
+	// Arrange
+	engine, logs := newEngineWithLogBuffer(t)
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(httpserver.RequestIDHeader, "abc123")
+	rec := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(rec, req)
+
+	// Assert
+	if got := rec.Header().Get(httpserver.RequestIDHeader); got != "abc123" {
+		t.Fatalf("expected request ID header abc123, got %q", got)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if !strings.Contains(line, "request_id=abc123") {
+			t.Fatalf("expected request_id on every entry, got %q", line)
+		}
+		if !strings.Contains(line, "trace_id=") {
+			t.Fatalf("expected trace_id on every entry, got %q", line)
+		}
+	}
+}
+
+// TestRequestLoggerGeneratesRequestID assigns an ID when the caller sends none.
+// Arrange: build the engine and a request without X-Request-ID.
+// Act: serve the request.
+// Assert: expect a generated ID in the response header and the logs.
+func TestRequestLoggerGeneratesRequestID(t *testing.T) {
+	// Arrange
+	engine, logs := newEngineWithLogBuffer(t)
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(rec, req)
+
+	// Assert
+	requestID := rec.Header().Get(httpserver.RequestIDHeader)
+	if requestID == "" {
+		t.Fatalf("expected a generated request ID")
+	}
+	if !strings.Contains(logs.String(), "handling ping") || !strings.Contains(logs.String(), "request_id="+requestID) {
+		t.Fatalf("expected handler log with request_id %s, got %q", requestID, logs.String())
+	}
+}
+
+// TestRequestLoggingNeverIncludesResponseBodies keeps issued tokens out of logs.
+// Arrange: register a handler that responds with a token and a password field.
+// Act: serve the request through the logging middleware.
+// Assert: expect neither secret to appear in the log output.
+func TestRequestLoggingNeverIncludesResponseBodies(t *testing.T) {
+	// Arrange
+	engine, logs := newEngineWithLogBuffer(t)
+	engine.POST("/login", func(c *gin.Context) {
+		c.JSON(http.StatusUnauthorized, gin.H{"token": "secret-token-value", "password": "hunter2"})
+	})
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	engine.ServeHTTP(rec, req)
+
+	// Assert
+	if !strings.Contains(rec.Body.String(), "secret-token-value") {
+		t.Fatalf("expected token in response body, got %q", rec.Body.String())
+	}
+	for _, secret := range []string{"secret-token-value", "hunter2"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Fatalf("expected %q to be absent from logs, got %q", secret, logs.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package httpserver_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"mysvelteapp/server_new/internal/platform/httpserver"
+)
+
+// TestSwaggerRouteRegisteredOnlyWhenEnabled keeps API docs off servers that do not opt in.
+// Arrange: table-drive engines built with and without WithSwaggerUI.
+// Act: list the registered routes.
+// Assert: expect the Swagger route only when the option is set.
+func TestSwaggerRouteRegisteredOnlyWhenEnabled(t *testing.T) {
+	testCases := []struct {
+		name    string
+		opts    []httpserver.Option
+		present bool
+	}{
+		{name: "enabled", opts: []httpserver.Option{httpserver.WithSwaggerUI()}, present: true},
+		{name: "disabled"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			engine := httpserver.New(nil, "test", tc.opts...)
+
+			// Act
+			routes := engine.Routes()
+
+			// Assert
+			present := slices.ContainsFunc(routes, func(route gin.RouteInfo) bool { return route.Path == httpserver.SwaggerPath })
+			if present != tc.present {
+				t.Fatalf("expected Swagger route present %t, got %t in %+v", tc.present, present, routes)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/idempotency"
+)
+
+// TestMemoryStoreClaimsCompletesAndExpiresKeys walks a key through its lifecycle.
+// Arrange: use a store with a controllable clock.
+// Act: begin a key twice, complete it, begin again, then advance past the TTL.
+// Assert: expect in-flight, then the stored response, then a fresh claim.
+func TestMemoryStoreClaimsCompletesAndExpiresKeys(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := idempotency.NewMemoryStore().WithClock(func() time.Time { return now })
+	ctx := context.Background()
+	response := idempotency.Response{Fingerprint: "abc", Status: http.StatusCreated, Body: []byte(`{"id":1}`)}
+
+	// Act
+	claimed, claimErr := store.Begin(ctx, "key", time.Hour)
+	_, inFlightErr := store.Begin(ctx, "key", time.Hour)
+	if err := store.Complete(ctx, "key", response, time.Hour); err != nil {
+		t.Fatalf("expected response to be stored, got %v", err)
+	}
+	stored, storedErr := store.Begin(ctx, "key", time.Hour)
+	now = now.Add(time.Hour)
+	expired, expiredErr := store.Begin(ctx, "key", time.Hour)
+
+	// Assert
+	if claimed != nil || claimErr != nil {
+		t.Fatalf("expected the first begin to claim the key, got %+v, %v", claimed, claimErr)
+	}
+	if !errors.Is(inFlightErr, idempotency.ErrInFlight) {
+		t.Fatalf("expected ErrInFlight while claimed, got %v", inFlightErr)
+	}
+	if storedErr != nil || stored == nil || stored.Status != http.StatusCreated || string(stored.Body) != `{"id":1}` {
+		t.Fatalf("expected the stored response, got %+v, %v", stored, storedErr)
+	}
+	if expired != nil || expiredErr != nil {
+		t.Fatalf("expected an expired key to be claimable again, got %+v, %v", expired, expiredErr)
+	}
+}
+
+// TestMemoryStoreReleaseFreesKey lets a failed request be retried.
+// Arrange: claim a key.
+// Act: release it and begin again.
+// Assert: expect a fresh claim rather than ErrInFlight.
+func TestMemoryStoreReleaseFreesKey(t *testing.T) {
+	// Arrange
+	store := idempotency.NewMemoryStore()
+	ctx := context.Background()
+	if _, err := store.Begin(ctx, "key", time.Hour); err != nil {
+		t.Fatalf("expected key to be claimed, got %v", err)
+	}
+
+	// Act
+	if err := store.Release(ctx, "key"); err != nil {
+		t.Fatalf("expected key to be released, got %v", err)
+	}
+	stored, err := store.Begin(ctx, "key", time.Hour)
+
+	// Assert
+	if stored != nil || err != nil {
+		t.Fatalf("expected a fresh claim, got %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,171 @@
+package kvstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mysvelteapp/server_new/internal/platform/kvstore"
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// newGormStore returns a GormStore on a migrated in-memory database.
+func newGormStore(t *testing.T) *kvstore.GormStore {
+	t.Helper()
+	appDB, err := persistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, persistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	return kvstore.NewGormStore(appDB.DB, persistence.DefaultRetryOptions())
+}
+
+// TestMemoryStoreExpiresKeys forgets values once their TTL passes.
+// Arrange: use a store with a controllable clock and set two keys with
+// different TTLs.
+// Act: read both just before and at the first key's expiry.
+// Assert: expect both values before, then ErrNotFound only for the first key.
+func TestMemoryStoreExpiresKeys(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := kvstore.NewMemoryStore().WithClock(func() time.Time { return now })
+	ctx := context.Background()
+	if err := store.Set(ctx, "short", []byte("a"), time.Minute); err != nil {
+		t.Fatalf("expected value to be stored, got %v", err)
+	}
+	if err := store.Set(ctx, "long", []byte("b"), time.Hour); err != nil {
+		t.Fatalf("expected value to be stored, got %v", err)
+	}
+
+	// Act
+	now = now.Add(time.Minute - time.Second)
+	before, beforeErr := store.Get(ctx, "short")
+	now = now.Add(time.Second)
+	expired, expiredErr := store.Get(ctx, "short")
+	live, liveErr := store.Get(ctx, "long")
+
+	// Assert
+	if beforeErr != nil || string(before) != "a" {
+		t.Fatalf("expected value a before expiry, got %q (%v)", before, beforeErr)
+	}
+	if expired != nil || !errors.Is(expiredErr, kvstore.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound at expiry, got %q (%v)", expired, expiredErr)
+	}
+	if liveErr != nil || string(live) != "b" {
+		t.Fatalf("expected value b to outlive the first key, got %q (%v)", live, liveErr)
+	}
+}
+
+// TestMemoryStoreSetReplacesTTL restarts the TTL when a key is set again.
+// Arrange: set a key for a minute and advance the clock by 50 seconds.
+// Act: set it again for a minute and read it 30 seconds later.
+// Assert: expect the new value rather than ErrNotFound.
+func TestMemoryStoreSetReplacesTTL(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := kvstore.NewMemoryStore().WithClock(func() time.Time { return now })
+	ctx := context.Background()
+	if err := store.Set(ctx, "key", []byte("old"), time.Minute); err != nil {
+		t.Fatalf("expected value to be stored, got %v", err)
+	}
+	now = now.Add(50 * time.Second)
+
+	// Act
+	if err := store.Set(ctx, "key", []byte("new"), time.Minute); err != nil {
+		t.Fatalf("expected value to be replaced, got %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	got, err := store.Get(ctx, "key")
+
+	// Assert
+	if err != nil || string(got) != "new" {
+		t.Fatalf("expected value new, got %q (%v)", got, err)
+	}
+}
+
+// TestStoresSetGetDelete runs the Store contract against every implementation.
+// Arrange: build a memory and a GORM store sharing one controllable clock.
+// Act: set, overwrite, read, delete and read a key, then set one that expires.
+// Assert: expect the latest value, then ErrNotFound after delete and expiry,
+// and deleting a missing key to succeed.
+func TestStoresSetGetDelete(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	testCases := []struct {
+		name  string
+		store func(t *testing.T) kvstore.Store
+	}{
+		{name: "memory", store: func(*testing.T) kvstore.Store { return kvstore.NewMemoryStore().WithClock(clock) }},
+		{name: "gorm", store: func(t *testing.T) kvstore.Store { return newGormStore(t).WithClock(clock) }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			store := tc.store(t)
+			ctx := context.Background()
+
+			// Act
+			if err := store.Set(ctx, "key", []byte("first"), time.Hour); err != nil {
+				t.Fatalf("expected value to be stored, got %v", err)
+			}
+			if err := store.Set(ctx, "key", []byte("second"), time.Hour); err != nil {
+				t.Fatalf("expected value to be replaced, got %v", err)
+			}
+			got, getErr := store.Get(ctx, "key")
+			deleteErr := store.Delete(ctx, "key")
+			_, deletedErr := store.Get(ctx, "key")
+			missingErr := store.Delete(ctx, "missing")
+			if err := store.Set(ctx, "brief", []byte("x"), time.Minute); err != nil {
+				t.Fatalf("expected value to be stored, got %v", err)
+			}
+			now = now.Add(time.Minute)
+			_, expiredErr := store.Get(ctx, "brief")
+
+			// Assert
+			if getErr != nil || string(got) != "second" {
+				t.Fatalf("expected value second, got %q (%v)", got, getErr)
+			}
+			if deleteErr != nil || missingErr != nil {
+				t.Fatalf("expected deletes to succeed, got %v and %v", deleteErr, missingErr)
+			}
+			if !errors.Is(deletedErr, kvstore.ErrNotFound) || !errors.Is(expiredErr, kvstore.ErrNotFound) {
+				t.Fatalf("expected ErrNotFound after delete and expiry, got %v and %v", deletedErr, expiredErr)
+			}
+		})
+	}
+}
+
+// TestGormStoreDeleteExpired removes only rows past their TTL.
+// Arrange: store one key for a minute and one for an hour.
+// Act: delete entries expired two minutes later.
+// Assert: expect one row removed and the long-lived key still readable.
+func TestGormStoreDeleteExpired(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newGormStore(t).WithClock(func() time.Time { return now })
+	ctx := context.Background()
+	if err := store.Set(ctx, "short", []byte("a"), time.Minute); err != nil {
+		t.Fatalf("expected value to be stored, got %v", err)
+	}
+	if err := store.Set(ctx, "long", []byte("b"), time.Hour); err != nil {
+		t.Fatalf("expected value to be stored, got %v", err)
+	}
+
+	// Act
+	deleted, err := store.DeleteExpired(ctx, now.Add(2*time.Minute))
+
+	// Assert
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected one expired row deleted, got %d (%v)", deleted, err)
+	}
+	if got, err := store.Get(ctx, "long"); err != nil || string(got) != "b" {
+		t.Fatalf("expected the long-lived key to remain, got %q (%v)", got, err)
+	}
+}
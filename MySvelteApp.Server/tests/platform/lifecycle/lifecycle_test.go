@@ -0,0 +1,68 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/lifecycle"
+)
+
+// TestShutdownRunsHooksInReverseOrder tears down dependents first.
+// Arrange: register three hooks that record their names.
+// Act: shut the registry down.
+// Assert: expect the hooks to run last-registered first.
+func TestShutdownRunsHooksInReverseOrder(t *testing.T) {
+	// Arrange
+	registry := lifecycle.NewRegistry()
+	var order []string
+	for _, name := range []string{"tracing", "database", "server"} {
+		registry.Register(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	// Act
+	err := registry.Shutdown(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []string{"server", "database", "tracing"}; !slices.Equal(order, want) {
+		t.Fatalf("expected order %v, got %v", want, order)
+	}
+}
+
+// TestShutdownContinuesAfterFailure runs every hook even when one fails.
+// Arrange: register a failing hook before a succeeding one.
+// Act: shut the registry down twice.
+// Assert: expect both hooks to run once and the failure to be named in the error.
+func TestShutdownContinuesAfterFailure(t *testing.T) {
+	// Arrange
+	registry := lifecycle.NewRegistry()
+	calls := 0
+	registry.Register("tracing", func(context.Context) error {
+		calls++
+		return errors.New("exporter unreachable")
+	})
+	registry.Register("server", func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	// Act
+	err := registry.Shutdown(context.Background())
+	again := registry.Shutdown(context.Background())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "shutdown tracing") {
+		t.Fatalf("expected the tracing failure to be reported, got %v", err)
+	}
+	if again != nil || calls != 2 {
+		t.Fatalf("expected each hook to run once, got %d calls and %v", calls, again)
+	}
+}
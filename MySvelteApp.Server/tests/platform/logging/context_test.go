@@ -0,0 +1,47 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+// TestFromContextReturnsStoredLogger retrieves the logger put in the context.
+// Arrange: store a buffer-backed logger with a request field in a context.
+// Act: log through FromContext.
+// Assert: expect the entry to carry the stored field.
+func TestFromContextReturnsStoredLogger(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil)).With("request_id", "req-1")
+	ctx := logging.NewContext(context.Background(), logger)
+
+	// Act
+	logging.FromContext(ctx).Info("hello")
+
+	// Assert
+	if !strings.Contains(buf.String(), "request_id=req-1") {
+		t.Fatalf("expected request_id in log entry, got %q", buf.String())
+	}
+}
+
+// TestFromContextFallsBackToDefault tolerates contexts without a logger.
+// Arrange: use a bare background context.
+// Act: call FromContext.
+// Assert: expect slog.Default to be returned.
+func TestFromContextFallsBackToDefault(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+
+	// Act
+	logger := logging.FromContext(ctx)
+
+	// Assert
+	if logger != slog.Default() {
+		t.Fatalf("expected default logger, got %v", logger)
+	}
+}
@@ -0,0 +1,107 @@
+package logging_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/logging"
+)
+
+// TestNewLoggerRotatesFileOutput moves a full log file aside.
+// Arrange: configure file output with a one megabyte size limit.
+// Act: log more than a megabyte of entries and close the writer.
+// Assert: expect a timestamped backup next to the active log file.
+func TestNewLoggerRotatesFileOutput(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	config := logging.DefaultConfig()
+	config.Output = filepath.Join(dir, "app.log")
+	config.MaxSizeMB = 1
+	config.MaxBackups = 2
+	logger, closer := logging.NewLogger(config)
+	payload := strings.Repeat("x", 1024)
+
+	// Act
+	for i := 0; i < 1100; i++ {
+		logger.Info("filler", "payload", payload)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+
+	// Assert
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if err != nil {
+		t.Fatalf("expected glob to succeed, got %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup file, got %v", backups)
+	}
+	info, err := os.Stat(config.Output)
+	if err != nil {
+		t.Fatalf("expected active log file to exist, got %v", err)
+	}
+	if info.Size() == 0 || info.Size() > 1024*1024 {
+		t.Fatalf("expected active log file within the size limit, got %d bytes", info.Size())
+	}
+}
+
+// TestNewLoggerStdoutCloserIsNoop keeps standard streams open.
+// Arrange: use the default stdout configuration.
+// Act: close the returned closer.
+// Assert: expect no error.
+func TestNewLoggerStdoutCloserIsNoop(t *testing.T) {
+	// Arrange
+	_, closer := logging.NewLogger(logging.DefaultConfig())
+
+	// Act
+	err := closer.Close()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+// TestNewLoggerFormatsTimeAsRFC3339UTC matches the log aggregation format.
+// Arrange: configure JSON file output with RFC3339 and UTC enabled.
+// Act: log one entry and read it back.
+// Assert: expect a UTC RFC3339 timestamp ending in "Z".
+func TestNewLoggerFormatsTimeAsRFC3339UTC(t *testing.T) {
+	// Arrange
+	config := logging.DefaultConfig()
+	config.Format = "json"
+	config.Output = filepath.Join(t.TempDir(), "app.log")
+	config.TimeFormat = time.RFC3339
+	config.UTC = true
+	logger, closer := logging.NewLogger(config)
+
+	// Act
+	logger.Info("hello")
+	if err := closer.Close(); err != nil {
+		t.Fatalf("expected close to succeed, got %v", err)
+	}
+	contents, err := os.ReadFile(config.Output)
+	if err != nil {
+		t.Fatalf("expected log file to be readable, got %v", err)
+	}
+	var entry struct {
+		Time string `json:"time"`
+	}
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		t.Fatalf("expected JSON log entry, got %v", err)
+	}
+
+	// Assert
+	parsed, err := time.Parse(time.RFC3339, entry.Time)
+	if err != nil {
+		t.Fatalf("expected RFC3339 timestamp, got %q", entry.Time)
+	}
+	if !strings.HasSuffix(entry.Time, "Z") || parsed.Location() != time.UTC {
+		t.Fatalf("expected UTC timestamp, got %q", entry.Time)
+	}
+}
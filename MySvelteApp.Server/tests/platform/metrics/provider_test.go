@@ -0,0 +1,130 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"mysvelteapp/server_new/internal/platform/metrics"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// restoreMeterProvider puts back the global provider a test replaces.
+func restoreMeterProvider(t *testing.T) {
+	t.Helper()
+	previous := otel.GetMeterProvider()
+	t.Cleanup(func() { otel.SetMeterProvider(previous) })
+}
+
+// TestPrometheusExporterPublishesOTelCounters scrapes OpenTelemetry counters from the registry.
+// Arrange: install the prometheus exporter on a fresh registry.
+// Act: add to a counter through the global meter with attributes, then scrape.
+// Assert: expect the Prometheus-named series with its labels and total.
+func TestPrometheusExporterPublishesOTelCounters(t *testing.T) {
+	// Arrange
+	restoreMeterProvider(t)
+	registry := metrics.NewRegistry()
+	if _, err := metrics.New(metrics.ExporterPrometheus, registry, discardLogger()); err != nil {
+		t.Fatalf("expected provider, got %v", err)
+	}
+	counter, err := otel.Meter("test").Int64Counter("http.server.panics", metric.WithDescription("Recovered panics"))
+	if err != nil {
+		t.Fatalf("expected counter, got %v", err)
+	}
+
+	// Act
+	counter.Add(context.Background(), 2, metric.WithAttributes(attribute.String("http.route", "/boom")))
+	counter.Add(context.Background(), 1)
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	body := recorder.Body.String()
+	for _, want := range []string{
+		"# HELP http_server_panics_total Recovered panics",
+		`http_server_panics_total{http_route="/boom"} 2`,
+		"http_server_panics_total 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the scrape, got %q", want, body)
+		}
+	}
+}
+
+// TestNoneExporterDiscardsMeasurements keeps local runs free of a collector.
+// Arrange: install the none exporter.
+// Act: add to a counter through the provider's meter, then scrape the registry.
+// Assert: expect nothing recorded.
+func TestNoneExporterDiscardsMeasurements(t *testing.T) {
+	// Arrange
+	restoreMeterProvider(t)
+	registry := metrics.NewRegistry()
+	provider, err := metrics.New(metrics.ExporterNone, registry, discardLogger())
+	if err != nil {
+		t.Fatalf("expected provider, got %v", err)
+	}
+
+	// Act
+	counter, _ := provider.Meter("test").Int64Counter("jobs")
+	counter.Add(context.Background(), 1)
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	if body := recorder.Body.String(); body != "" {
+		t.Fatalf("expected an empty scrape, got %q", body)
+	}
+}
+
+// TestNewRejectsUnknownExporter fails fast on configuration typos.
+// Arrange: pick an exporter name that is not supported.
+// Act: call New.
+// Assert: expect an error naming the exporter.
+func TestNewRejectsUnknownExporter(t *testing.T) {
+	// Arrange
+	restoreMeterProvider(t)
+
+	// Act
+	_, err := metrics.New("statsd", metrics.NewRegistry(), discardLogger())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "statsd") {
+		t.Fatalf("expected an unsupported exporter error, got %v", err)
+	}
+}
+
+// TestPrometheusExporterRejectsUnsupportedInstruments fails loudly instead of dropping data.
+// Arrange: install the prometheus exporter on a fresh registry.
+// Act: create a histogram and a float counter through the global meter.
+// Assert: expect both to return an error naming the instrument.
+func TestPrometheusExporterRejectsUnsupportedInstruments(t *testing.T) {
+	// Arrange
+	restoreMeterProvider(t)
+	if _, err := metrics.New(metrics.ExporterPrometheus, metrics.NewRegistry(), discardLogger()); err != nil {
+		t.Fatalf("expected provider, got %v", err)
+	}
+	meter := otel.Meter("test")
+
+	// Act
+	_, histogramErr := meter.Int64Histogram("http.server.duration")
+	_, floatErr := meter.Float64Counter("pokeapi.bytes")
+
+	// Assert
+	if histogramErr == nil || !strings.Contains(histogramErr.Error(), "http.server.duration") {
+		t.Fatalf("expected an unsupported histogram error, got %v", histogramErr)
+	}
+	if floatErr == nil || !strings.Contains(floatErr.Error(), "pokeapi.bytes") {
+		t.Fatalf("expected an unsupported float counter error, got %v", floatErr)
+	}
+}
@@ -0,0 +1,36 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/metrics"
+)
+
+// TestRegistryCounterIsSharedByName returns the same counter for repeated registrations.
+// Arrange: register a counter twice under one name.
+// Act: increment through both handles and scrape.
+// Assert: expect one metric family whose series are sorted by label value.
+func TestRegistryCounterIsSharedByName(t *testing.T) {
+	// Arrange
+	registry := metrics.NewRegistry()
+	first := registry.Counter("jobs_total", "Jobs by state.", "state")
+	second := registry.Counter("jobs_total", "Jobs by state.", "state")
+
+	// Act
+	first.Inc("ok")
+	second.Inc("ok")
+	second.Inc("failed")
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	if first.Value("ok") != 2 {
+		t.Fatalf("expected 2 ok jobs, got %d", first.Value("ok"))
+	}
+	want := "# HELP jobs_total Jobs by state.\n# TYPE jobs_total counter\njobs_total{state=\"failed\"} 1\njobs_total{state=\"ok\"} 2\n"
+	if body := recorder.Body.String(); body != want {
+		t.Fatalf("expected %q, got %q", want, body)
+	}
+}
@@ -0,0 +1,125 @@
+package persistence_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"mysvelteapp/server_new/internal/platform/metrics"
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// TestNewAppDBAppliesPoolOptions confirms pool settings reach the sql.DB.
+// Arrange: choose non-default pool values.
+// Act: open an in-memory SQLite database with them.
+// Assert: expect the sql.DB stats to report the configured limits.
+func TestNewAppDBAppliesPoolOptions(t *testing.T) {
+	// Arrange
+	pool := persistence.PoolOptions{
+		MaxOpenConns:    3,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: time.Minute,
+	}
+
+	// Act
+	appDB, err := persistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, pool)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sqlDB, err := appDB.DB.DB()
+	if err != nil {
+		t.Fatalf("expected sql.DB, got %v", err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if got := sqlDB.Stats().MaxOpenConnections; got != pool.MaxOpenConns {
+		t.Fatalf("expected max open connections %d, got %d", pool.MaxOpenConns, got)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		t.Fatalf("expected ping to succeed, got %v", err)
+	}
+	if idle := sqlDB.Stats().Idle; idle > pool.MaxIdleConns {
+		t.Fatalf("expected at most %d idle connections, got %d", pool.MaxIdleConns, idle)
+	}
+}
+
+// TestNewAppDBFailsFastOnUnopenablePath reports a bad SQLite path at startup.
+// Arrange: point the DSN at a file inside a directory that does not exist.
+// Act: construct the AppDB.
+// Assert: expect an error naming the dialect and the path problem.
+func TestNewAppDBFailsFastOnUnopenablePath(t *testing.T) {
+	// Arrange
+	dsn := "file:" + filepath.Join(t.TempDir(), "missing", "app.db")
+
+	// Act
+	appDB, err := persistence.NewAppDB(sqlite.Open(dsn), &gorm.Config{}, persistence.PoolOptions{})
+
+	// Assert
+	if err == nil {
+		t.Fatalf("expected an error, got AppDB %v", appDB)
+	}
+	for _, want := range []string{"sqlite", "directory exists and is writable"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+// TestAppDBPingAndStats exposes connectivity and pool state without reaching into gorm.
+// Arrange: open an in-memory SQLite database capped at two connections.
+// Act: ping it and read the pool statistics.
+// Assert: expect the ping to succeed and the stats to reflect the cap and the opened connection.
+func TestAppDBPingAndStats(t *testing.T) {
+	// Arrange
+	appDB, err := persistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, persistence.PoolOptions{MaxOpenConns: 2, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Act
+	pingErr := appDB.Ping(context.Background())
+	stats := appDB.Stats()
+
+	// Assert
+	if pingErr != nil {
+		t.Fatalf("expected ping to succeed, got %v", pingErr)
+	}
+	if stats.MaxOpenConnections != 2 || stats.OpenConnections < 1 {
+		t.Fatalf("expected a capped pool with an open connection, got %+v", stats)
+	}
+}
+
+// TestRegisterPoolMetricsPublishesGauges reads pool statistics at scrape time.
+// Arrange: register the pool gauges of an in-memory database.
+// Act: scrape the registry.
+// Assert: expect the pool gauges with current values.
+func TestRegisterPoolMetricsPublishesGauges(t *testing.T) {
+	// Arrange
+	appDB, err := persistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, persistence.PoolOptions{MaxOpenConns: 4, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	registry := metrics.NewRegistry()
+	appDB.RegisterPoolMetrics(registry)
+
+	// Act
+	recorder := httptest.NewRecorder()
+	registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	body := recorder.Body.String()
+	for _, want := range []string{"# TYPE db_pool_open_connections gauge", "db_pool_max_open_connections 4", "db_pool_wait_duration_seconds 0"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the scrape, got %q", want, body)
+		}
+	}
+}
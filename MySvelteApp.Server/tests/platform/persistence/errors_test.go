@@ -0,0 +1,37 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// TestWrapErrorPreservesChain names the operation without hiding the cause.
+// Arrange: wrap context.DeadlineExceeded as GetByUsername, and wrap nil.
+// Act: inspect the wrapped error with errors.Is and errors.As.
+// Assert: expect the cause and operation to be found, the operation to prefix
+// the message, and nil to stay nil.
+func TestWrapErrorPreservesChain(t *testing.T) {
+	// Arrange
+	err := persistence.WrapError("GetByUsername", context.DeadlineExceeded)
+
+	// Act
+	var repoErr *persistence.RepositoryError
+	found := errors.As(err, &repoErr)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the cause to be preserved, got %v", err)
+	}
+	if !found || repoErr.Op != "GetByUsername" {
+		t.Fatalf("expected a RepositoryError for GetByUsername, got %#v", err)
+	}
+	if got, want := err.Error(), "GetByUsername: "+context.DeadlineExceeded.Error(); got != want {
+		t.Fatalf("expected message %q, got %q", want, got)
+	}
+	if wrapped := persistence.WrapError("Add", nil); wrapped != nil {
+		t.Fatalf("expected nil to stay nil, got %v", wrapped)
+	}
+}
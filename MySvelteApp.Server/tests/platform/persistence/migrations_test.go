@@ -0,0 +1,132 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	authdomain "mysvelteapp/server_new/internal/modules/auth/domain"
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+func newMemoryAppDB(t *testing.T) *persistence.AppDB {
+	t.Helper()
+	appDB, err := persistence.NewAppDB(sqlite.Open("file::memory:"), &gorm.Config{}, persistence.PoolOptions{MaxOpenConns: 1, MaxIdleConns: 1})
+	if err != nil {
+		t.Fatalf("expected database to open, got %v", err)
+	}
+	return appDB
+}
+
+func countAppliedMigrations(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Table("schema_migrations").Count(&count).Error; err != nil {
+		t.Fatalf("expected schema_migrations to be readable, got %v", err)
+	}
+	return count
+}
+
+// TestMigrateFreshDatabase applies every migration exactly once.
+// Arrange: open an empty in-memory database.
+// Act: run Migrate twice.
+// Assert: expect all migrations recorded once and the schema usable, with
+// email change tokens kept in kv_entries rather than on users.
+func TestMigrateFreshDatabase(t *testing.T) {
+	// Arrange
+	appDB := newMemoryAppDB(t)
+
+	// Act
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected first migration run to succeed, got %v", err)
+	}
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected second migration run to succeed, got %v", err)
+	}
+
+	// Assert
+	if got, want := countAppliedMigrations(t, appDB.DB), int64(len(persistence.Migrations())); got != want {
+		t.Fatalf("expected %d applied migrations, got %d", want, got)
+	}
+	user, err := authdomain.NewUser("migrated", "migrated@example.com", "hash", "salt")
+	if err != nil {
+		t.Fatalf("expected user to build, got %v", err)
+	}
+	if err := appDB.DB.Create(user).Error; err != nil {
+		t.Fatalf("expected insert into migrated schema to succeed, got %v", err)
+	}
+	if !appDB.DB.Migrator().HasTable("kv_entries") {
+		t.Fatalf("expected the kv_entries table to exist")
+	}
+	if appDB.DB.Migrator().HasColumn(&authdomain.User{}, "email_change_token_hash") {
+		t.Fatalf("expected email change tokens to have moved out of users")
+	}
+}
+
+// TestMigrateLegacyAutoMigratedDatabase detects schemas created by AutoMigrate.
+// Arrange: build the users table with AutoMigrate and seed a row without a normalized username.
+// Act: run Migrate.
+// Assert: expect success and the normalized username to be backfilled.
+func TestMigrateLegacyAutoMigratedDatabase(t *testing.T) {
+	// Arrange
+	appDB := newMemoryAppDB(t)
+	if err := appDB.DB.AutoMigrate(&authdomain.User{}); err != nil {
+		t.Fatalf("expected legacy schema to build, got %v", err)
+	}
+	if err := appDB.DB.Exec(
+		"INSERT INTO users (username, normalized_username, email, password_hash, password_salt) VALUES (?, '', ?, 'hash', 'salt')",
+		"Legacy", "legacy@example.com",
+	).Error; err != nil {
+		t.Fatalf("expected legacy row insert to succeed, got %v", err)
+	}
+
+	// Act
+	err := appDB.Migrate(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected migration to succeed, got %v", err)
+	}
+	var user authdomain.User
+	if err := appDB.DB.Where("username = ?", "Legacy").Take(&user).Error; err != nil {
+		t.Fatalf("expected legacy user to load, got %v", err)
+	}
+	if user.NormalizedUsername != "legacy" {
+		t.Fatalf("expected normalized username to be backfilled, got %q", user.NormalizedUsername)
+	}
+}
+
+// TestMigrateReconcilesRowsWrittenByOlderReleases repairs rows an old binary inserts mid-rollout.
+// Arrange: migrate a database, then insert a row without a normalized username or role.
+// Act: run Migrate again.
+// Assert: expect both columns backfilled.
+func TestMigrateReconcilesRowsWrittenByOlderReleases(t *testing.T) {
+	// Arrange
+	appDB := newMemoryAppDB(t)
+	if err := appDB.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected migration to succeed, got %v", err)
+	}
+	if err := appDB.DB.Exec(
+		"INSERT INTO users (username, normalized_username, email, password_hash, password_salt, role) VALUES (?, '', ?, 'hash', 'salt', '')",
+		"OldBinary", "old@example.com",
+	).Error; err != nil {
+		t.Fatalf("expected legacy row insert to succeed, got %v", err)
+	}
+
+	// Act
+	err := appDB.Migrate(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected migration to succeed, got %v", err)
+	}
+	var user authdomain.User
+	if err := appDB.DB.Where("username = ?", "OldBinary").Take(&user).Error; err != nil {
+		t.Fatalf("expected user to load, got %v", err)
+	}
+	if user.NormalizedUsername != "oldbinary" || user.Role != authdomain.RoleUser {
+		t.Fatalf("expected normalized username and default role, got %q and %q", user.NormalizedUsername, user.Role)
+	}
+}
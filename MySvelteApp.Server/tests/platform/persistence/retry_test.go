@@ -0,0 +1,125 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+func fastRetryOptions() persistence.RetryOptions {
+	return persistence.RetryOptions{Attempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+}
+
+// TestRetryRecoversFromTransientErrors retries lock contention until success.
+// Arrange: build an operation that reports SQLITE_BUSY twice, then succeeds.
+// Act: run it through Retry.
+// Assert: expect success after three calls.
+func TestRetryRecoversFromTransientErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	op := func() error {
+		calls++
+		if calls <= 2 {
+			return errors.New("database is locked (5) (SQLITE_BUSY)")
+		}
+		return nil
+	}
+
+	// Act
+	err := persistence.Retry(context.Background(), fastRetryOptions(), op)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+// TestRetrySkipsPermanentErrors never retries constraint violations.
+// Arrange: build an operation that fails with a unique violation.
+// Act: run it through Retry.
+// Assert: expect the error returned after a single call.
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	// Arrange
+	calls := 0
+	uniqueErr := errors.New("UNIQUE constraint failed: users.username")
+	op := func() error {
+		calls++
+		return uniqueErr
+	}
+
+	// Act
+	err := persistence.Retry(context.Background(), fastRetryOptions(), op)
+
+	// Assert
+	if !errors.Is(err, uniqueErr) {
+		t.Fatalf("expected unique violation, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single call, got %d", calls)
+	}
+}
+
+// TestRetryGivesUpAfterAttempts bounds retries of persistent contention.
+// Arrange: build an operation that always reports a serialization failure.
+// Act: run it through Retry.
+// Assert: expect the transient error after the configured number of attempts.
+func TestRetryGivesUpAfterAttempts(t *testing.T) {
+	// Arrange
+	calls := 0
+	op := func() error {
+		calls++
+		return errors.New("ERROR: could not serialize access due to concurrent update (SQLSTATE 40001)")
+	}
+
+	// Act
+	err := persistence.Retry(context.Background(), fastRetryOptions(), op)
+
+	// Assert
+	if !persistence.IsTransientError(err) {
+		t.Fatalf("expected transient error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+// TestRetryRunsOnceInsideTransaction leaves retries to the transaction boundary.
+// Arrange: build an operation that always reports a serialization failure.
+// Act: run it through Retry, once inside WithinTransaction and once around it.
+// Assert: expect a single call inside the transaction, and the whole
+// transaction re-run for every attempt when Retry wraps it.
+func TestRetryRunsOnceInsideTransaction(t *testing.T) {
+	// Arrange
+	db := newMemoryAppDB(t).DB
+	calls := 0
+	op := func() error {
+		calls++
+		return errors.New("ERROR: could not serialize access (SQLSTATE 40001)")
+	}
+
+	// Act
+	_ = persistence.WithinTransaction(context.Background(), db, func(ctx context.Context) error {
+		return persistence.Retry(ctx, fastRetryOptions(), op)
+	})
+	inside := calls
+	calls = 0
+	_ = persistence.Retry(context.Background(), fastRetryOptions(), func() error {
+		return persistence.WithinTransaction(context.Background(), db, func(ctx context.Context) error {
+			return persistence.Retry(ctx, fastRetryOptions(), op)
+		})
+	})
+
+	// Assert
+	if inside != 1 {
+		t.Fatalf("expected 1 call inside the transaction, got %d", inside)
+	}
+	if calls != 3 {
+		t.Fatalf("expected the transaction to run 3 times, got %d", calls)
+	}
+}
@@ -0,0 +1,108 @@
+package persistence_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"mysvelteapp/server_new/internal/platform/persistence"
+)
+
+// TestAsUniqueViolation identifies the violated index across drivers.
+// Arrange: table-drive SQLite and Postgres unique violations plus unrelated errors.
+// Act: inspect each error.
+// Assert: expect violations recognised with the right columns, and other errors ignored.
+func TestAsUniqueViolation(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		violation bool
+		column    string
+		other     string
+	}{
+		{
+			name:      "sqlite single column",
+			err:       errors.New("UNIQUE constraint failed: users.email"),
+			violation: true,
+			column:    "email",
+			other:     "username",
+		},
+		{
+			name:      "sqlite composite index",
+			err:       errors.New("UNIQUE constraint failed: favorite_pokemons.user_id, favorite_pokemons.name"),
+			violation: true,
+			column:    "name",
+			other:     "email",
+		},
+		{
+			name:      "postgres with key detail",
+			err:       errors.New(`ERROR: duplicate key value violates unique constraint "idx_users_username" (SQLSTATE 23505); Key (username)=(ash) already exists.`),
+			violation: true,
+			column:    "username",
+			other:     "email",
+		},
+		{
+			name:      "postgres gorm index name only",
+			err:       errors.New(`ERROR: duplicate key value violates unique constraint "idx_users_email" (SQLSTATE 23505)`),
+			violation: true,
+			column:    "email",
+			other:     "username",
+		},
+		{
+			name:      "postgres default constraint name only",
+			err:       errors.New(`ERROR: duplicate key value violates unique constraint "users_username_key" (SQLSTATE 23505)`),
+			violation: true,
+			column:    "username",
+			other:     "email",
+		},
+		{
+			name:      "translated gorm error",
+			err:       gorm.ErrDuplicatedKey,
+			violation: true,
+		},
+		{
+			name: "not null failure",
+			err:  errors.New("NOT NULL constraint failed: users.email"),
+		},
+		{
+			name: "nil",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			violation, ok := persistence.AsUniqueViolation(tc.err)
+
+			// Assert
+			if ok != tc.violation {
+				t.Fatalf("expected violation %t, got %t for %v", tc.violation, ok, tc.err)
+			}
+			if tc.column != "" && !violation.Involves(tc.column) {
+				t.Fatalf("expected %+v to involve %q", violation, tc.column)
+			}
+			if tc.other != "" && violation.Involves(tc.other) {
+				t.Fatalf("expected %+v not to involve %q", violation, tc.other)
+			}
+		})
+	}
+}
+
+// TestAsUniqueViolationKeepsCompositeColumns reports every column of a composite index.
+// Arrange: build a SQLite composite unique violation.
+// Act: inspect it.
+// Assert: expect the table and both columns in index order.
+func TestAsUniqueViolationKeepsCompositeColumns(t *testing.T) {
+	// Arrange
+	err := errors.New("UNIQUE constraint failed: favorite_pokemons.user_id, favorite_pokemons.name")
+
+	// Act
+	violation, _ := persistence.AsUniqueViolation(err)
+
+	// Assert
+	if violation.Table != "favorite_pokemons" || !slices.Equal(violation.Columns, []string{"user_id", "name"}) {
+		t.Fatalf("expected favorite_pokemons (user_id, name), got %+v", violation)
+	}
+}
@@ -0,0 +1,115 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/ratelimit"
+)
+
+// TestLimiterRefillsOverTime allows bursts up to the limit, then refills.
+// Arrange: allow 2 events per minute with a controllable clock.
+// Act: spend the burst, try again, then advance half the interval.
+// Assert: expect a rejection with a retry hint, then one more allowance.
+func TestLimiterRefillsOverTime(t *testing.T) {
+	// Arrange
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.New(2, time.Minute).WithClock(func() time.Time { return now })
+	limiter.Allow("client")
+	limiter.Allow("client")
+
+	// Act
+	blocked, retryAfter := limiter.Allow("client")
+	now = now.Add(30 * time.Second)
+	refilled, _ := limiter.Allow("client")
+
+	// Assert
+	if blocked {
+		t.Fatalf("expected third event to be rejected")
+	}
+	if retryAfter <= 0 || retryAfter > 30*time.Second {
+		t.Fatalf("expected retry hint within 30s, got %s", retryAfter)
+	}
+	if !refilled {
+		t.Fatalf("expected a token after half the interval")
+	}
+}
+
+// TestLimiterTracksKeysIndependently keeps one client from starving another.
+// Arrange: allow 1 event per minute.
+// Act: spend the budget for one key and try a second key.
+// Assert: expect the second key to be allowed.
+func TestLimiterTracksKeysIndependently(t *testing.T) {
+	// Arrange
+	limiter := ratelimit.New(1, time.Minute)
+	limiter.Allow("first")
+
+	// Act
+	allowed, _ := limiter.Allow("second")
+
+	// Assert
+	if !allowed {
+		t.Fatalf("expected an independent budget per key")
+	}
+}
+
+// TestLimiterWithBurstAllowsSpikesAtTheSustainedRate absorbs shared-address spikes.
+// Arrange: allow 2 events per minute with a burst of 5 and a controllable clock.
+// Act: spend the burst, try again, then advance half the interval.
+// Assert: expect 5 allowances, a rejection, then refills at the base rate only.
+func TestLimiterWithBurstAllowsSpikesAtTheSustainedRate(t *testing.T) {
+	// Arrange
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := ratelimit.New(2, time.Minute).WithBurst(5).WithClock(func() time.Time { return now })
+
+	// Act
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if ok, _ := limiter.Allow("office"); ok {
+			allowed++
+		}
+	}
+	blocked, _ := limiter.Allow("office")
+	now = now.Add(30 * time.Second)
+	refilled, _ := limiter.Allow("office")
+	drained, _ := limiter.Allow("office")
+
+	// Assert
+	if allowed != 5 {
+		t.Fatalf("expected the burst of 5 to be allowed, got %d", allowed)
+	}
+	if blocked {
+		t.Fatalf("expected the event after the burst to be rejected")
+	}
+	if !refilled || drained {
+		t.Fatalf("expected exactly one token after half the interval, got %t then %t", refilled, drained)
+	}
+}
+
+// TestLimiterWaitHonorsContext stops waiting for a token when the caller gives up.
+// Arrange: allow 1 event per hour and spend it.
+// Act: wait for another token with a context that ends after 20ms.
+// Assert: expect context.DeadlineExceeded shortly after the deadline.
+func TestLimiterWaitHonorsContext(t *testing.T) {
+	// Arrange
+	limiter := ratelimit.New(1, time.Hour)
+	if err := limiter.Wait(context.Background(), "client"); err != nil {
+		t.Fatalf("expected the first token immediately, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+
+	// Act
+	err := limiter.Wait(ctx, "client")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the wait to end with the context, took %s", elapsed)
+	}
+}
@@ -0,0 +1,99 @@
+package validation_test
+
+import (
+	"regexp"
+	"testing"
+
+	"mysvelteapp/server_new/internal/platform/validation"
+)
+
+// TestRules accepts and rejects values at each helper's boundary.
+// Arrange: table-drive each built-in rule with a passing and a failing value.
+// Act: validate both values against the rule.
+// Assert: expect the passing value accepted and the failing one reported with the rule's code.
+func TestRules(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule validation.Rule
+		pass string
+		fail string
+		code string
+	}{
+		{name: "required", rule: validation.Required("required"), pass: "a", fail: " \t", code: validation.CodeRequired},
+		{name: "min length counts characters", rule: validation.MinLen(3, "short"), pass: "äöü", fail: "äö", code: validation.CodeTooShort},
+		{name: "max length counts characters", rule: validation.MaxLen(3, "long"), pass: "äöü", fail: "abcd", code: validation.CodeTooLong},
+		{name: "min bytes", rule: validation.MinBytes(4, "short"), pass: "äö", fail: "abc", code: validation.CodeTooShort},
+		{name: "max bytes", rule: validation.MaxBytes(4, "long"), pass: "abcd", fail: "äöü", code: validation.CodeTooLong},
+		{name: "matches", rule: validation.Matches(regexp.MustCompile(`^[a-z-]+$`), "format"), pass: "mr-mime", fail: "Mr Mime", code: validation.CodeInvalidFormat},
+		{name: "email", rule: validation.Email("email"), pass: "ash@example.com", fail: "Ash <ash@example.com>", code: validation.CodeInvalidFormat},
+		{name: "check", rule: validation.Check("odd", "odd", func(v string) bool { return len(v)%2 == 0 }), pass: "ab", fail: "abc", code: "odd"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			var v validation.Validator
+
+			// Act
+			passed := v.Field("value", tc.pass, tc.rule)
+			failed := v.Field("value", tc.fail, tc.rule)
+
+			// Assert
+			if !passed {
+				t.Fatalf("expected %q to pass, got %+v", tc.pass, v.Errors())
+			}
+			if failed {
+				t.Fatalf("expected %q to fail", tc.fail)
+			}
+			if errs := v.Errors(); len(errs) != 1 || errs[0].Code != tc.code {
+				t.Fatalf("expected one %s error, got %+v", tc.code, errs)
+			}
+		})
+	}
+}
+
+// TestValidatorAccumulatesFirstFailurePerField stops each field at its first broken rule.
+// Arrange: give two fields several failing rules and one field none.
+// Act: validate all three fields.
+// Assert: expect one error per failing field, in order, carrying the first rule's message.
+func TestValidatorAccumulatesFirstFailurePerField(t *testing.T) {
+	// Arrange
+	var v validation.Validator
+
+	// Act
+	v.Field("username", "", validation.Required("Username is required."), validation.MinLen(3, "Too short."))
+	v.Field("nickname", "misty", validation.MaxLen(10, "Too long."))
+	v.Field("email", "misty", validation.MaxBytes(3, "Too long."), validation.Email("Invalid email."))
+
+	// Assert
+	errs := v.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %+v", errs)
+	}
+	if errs[0] != (validation.FieldError{Field: "username", Code: validation.CodeRequired, Message: "Username is required."}) {
+		t.Fatalf("expected the username required error first, got %+v", errs[0])
+	}
+	if errs[1].Field != "email" || errs[1].Message != "Too long." {
+		t.Fatalf("expected the email length error second, got %+v", errs[1])
+	}
+	if first, ok := v.First(); !ok || first != errs[0] {
+		t.Fatalf("expected First to return %+v, got %+v", errs[0], first)
+	}
+}
+
+// TestValidatorZeroValueIsValid reports nothing until a rule fails.
+// Arrange: use a zero Validator.
+// Act: ask for its first error.
+// Assert: expect none.
+func TestValidatorZeroValueIsValid(t *testing.T) {
+	// Arrange
+	var v validation.Validator
+
+	// Act
+	_, ok := v.First()
+
+	// Assert
+	if ok || len(v.Errors()) != 0 {
+		t.Fatalf("expected no errors, got %+v", v.Errors())
+	}
+}
@@ -0,0 +1,91 @@
+package workers_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"mysvelteapp/server_new/internal/platform/workers"
+)
+
+// TestStopCancelsWorkersPromptly lets shutdown finish without waiting on tickers.
+// Arrange: start a worker that blocks until its context is cancelled.
+// Act: stop the coordinator with a generous grace period.
+// Assert: expect the worker to observe cancellation and Stop to return quickly without error.
+func TestStopCancelsWorkersPromptly(t *testing.T) {
+	// Arrange
+	coordinator := workers.New(nil)
+	stopped := make(chan error, 1)
+	coordinator.Go("purge", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped <- ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+
+	// Act
+	err := coordinator.Stop(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the worker to stop promptly, took %s", elapsed)
+	}
+	if workerErr := <-stopped; !errors.Is(workerErr, context.Canceled) {
+		t.Fatalf("expected the worker context to be cancelled, got %v", workerErr)
+	}
+	if running := coordinator.Running(); len(running) != 0 {
+		t.Fatalf("expected no running workers, got %v", running)
+	}
+}
+
+// TestStopNamesWorkersThatOverrunGracePeriod reports which worker held shutdown up.
+// Arrange: start a worker that ignores cancellation until released.
+// Act: stop the coordinator with a short grace period.
+// Assert: expect a deadline error naming the stuck worker.
+func TestStopNamesWorkersThatOverrunGracePeriod(t *testing.T) {
+	// Arrange
+	coordinator := workers.New(nil)
+	release := make(chan struct{})
+	defer close(release)
+	coordinator.Go("count refresh", func(context.Context) {
+		<-release
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := coordinator.Stop(ctx)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "count refresh") {
+		t.Fatalf("expected a deadline error naming the worker, got %v", err)
+	}
+}
+
+// TestPanickingWorkerDoesNotBlockStop keeps one faulty worker from hanging shutdown.
+// Arrange: start a worker that panics immediately.
+// Act: stop the coordinator.
+// Assert: expect Stop to return without error.
+func TestPanickingWorkerDoesNotBlockStop(t *testing.T) {
+	// Arrange
+	coordinator := workers.New(nil)
+	coordinator.Go("lockout cleanup", func(context.Context) {
+		panic("boom")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Act
+	err := coordinator.Stop(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}